@@ -1,3 +1,13 @@
+// send the csrf token from its cookie with every api request
+const axios = require('axios').default
+axios.defaults.xsrfCookieName = 'PL_CSRF'
+axios.defaults.xsrfHeaderName = 'X-CSRF-Token'
+
+// a realm may be served under a path prefix, which its api requests must be sent under too
+const pathPrefixMeta = document.querySelector('meta[name="realm-path-prefix"]')
+const pathPrefix = pathPrefixMeta !== null ? pathPrefixMeta.getAttribute('content') : ''
+axios.defaults.baseURL = pathPrefix
+
 // load components
 Vue.component("leaderboard", require("./components/leaderboard/LeaderboardComponent.vue").default)
 Vue.component("leaderboard-page", require("./components/leaderboard/LeaderboardPageComponent.vue").default)
@@ -27,16 +37,31 @@ if (logout !== null) {
     logout.addEventListener('click', function(){ $('#logoutModal').modal('show'); console.log('yepp!') })
 }
 
-const logoutAction = document.getElementById('logout-action')
-if (logoutAction !== null) {
-    logoutAction.addEventListener('click', function(){
+// logOut revokes the current session on the server (or every session, if everywhere is true), then clears the auth cookie
+const logOut = function(everywhere) {
+    const url = pathPrefix + (everywhere ? '/api/logout/everywhere' : '/api/logout')
+    const clearCookieAndLeave = function() {
         // fix to
         const loc = window.location
         const domain = loc.host.split(':' + loc.port)[0]
-        const cookieString = 'PL_AUTH=;expires=Thu, 01 Jan 1970 00:00:01 GMT;path=/;domain='
+        const cookieString = 'PL_AUTH=;expires=Thu, 01 Jan 1970 00:00:01 GMT;path=' + pathPrefix + '/;domain='
         // reset cookie values
         document.cookie = cookieString + domain         // root domain
         document.cookie = cookieString + '.' + domain   // wildcard sub-domains
-        window.location = '/'
-    })
+        window.location = pathPrefix + '/'
+    }
+    const csrfCookie = document.cookie.split('; ').find(function(c){ return c.startsWith('PL_CSRF=') })
+    const headers = {'X-CSRF-Token': csrfCookie ? csrfCookie.split('=')[1] : ''}
+    // the cookie must be cleared even if the session could not be revoked
+    fetch(url, {method: 'POST', credentials: 'same-origin', headers: headers}).finally(clearCookieAndLeave)
+}
+
+const logoutAction = document.getElementById('logout-action')
+if (logoutAction !== null) {
+    logoutAction.addEventListener('click', function(){ logOut(false) })
+}
+
+const logoutEverywhereAction = document.getElementById('logout-everywhere-action')
+if (logoutEverywhereAction !== null) {
+    logoutEverywhereAction.addEventListener('click', function(){ logOut(true) })
 }
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `standings_quarantine` (
+    `id` VARCHAR(36) NOT NULL,
+    `season_id` VARCHAR(10) NOT NULL,
+    `round_number` INT(11) NOT NULL,
+    `rankings` JSON NOT NULL,
+    `anomalies` JSON NOT NULL,
+    `status` VARCHAR(20) NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    `reviewed_at` DATETIME NULL,
+    PRIMARY KEY (id),
+    INDEX `season_round_status_index` (season_id, round_number, status)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `standings_correction` (
+    `id` VARCHAR(36) NOT NULL,
+    `standings_id` VARCHAR(36) NOT NULL,
+    `season_id` VARCHAR(10) NOT NULL,
+    `round_number` INT(11) NOT NULL,
+    `previous_rankings` JSON NOT NULL,
+    `corrected_rankings` JSON NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    PRIMARY KEY (id),
+    INDEX `season_round_index` (season_id, round_number),
+    FOREIGN KEY (standings_id) REFERENCES standings (id)
+);
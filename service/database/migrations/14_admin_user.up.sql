@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS `admin_user` (
+    `id` VARCHAR(36) NOT NULL,
+    `username` VARCHAR(64) NOT NULL,
+    `password_hash` VARCHAR(255) NOT NULL,
+    `realm_roles` JSON NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    `updated_at` DATETIME NULL DEFAULT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY `username_index` (username)
+);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS `audit_record` (
+    `id` VARCHAR(36) NOT NULL,
+    `realm_name` VARCHAR(255) NOT NULL,
+    `actor_type` VARCHAR(255) NOT NULL,
+    `actor_id` VARCHAR(255) NOT NULL,
+    `action` VARCHAR(255) NOT NULL,
+    `target_type` VARCHAR(255) NOT NULL,
+    `target_id` VARCHAR(255) NOT NULL,
+    `before_value` JSON NULL DEFAULT NULL,
+    `after_value` JSON NULL DEFAULT NULL,
+    `created_at` DATETIME NOT NULL,
+    PRIMARY KEY (id),
+    INDEX `realm_target_index` (realm_name, target_type, target_id),
+    INDEX `realm_actor_index` (realm_name, actor_id)
+);
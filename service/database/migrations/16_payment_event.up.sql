@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS `payment_event` (
+    `id` VARCHAR(255) NOT NULL,
+    `realm_name` VARCHAR(255) NOT NULL,
+    `event_type` VARCHAR(255) NOT NULL,
+    `provider_event_type` VARCHAR(255) NOT NULL,
+    `entry_id` VARCHAR(36) NULL DEFAULT NULL,
+    `payment_ref` VARCHAR(255) NULL DEFAULT NULL,
+    `amount` DECIMAL(10,2) NOT NULL DEFAULT 0,
+    `currency` VARCHAR(3) NULL DEFAULT NULL,
+    `payload` JSON NOT NULL,
+    `outcome` VARCHAR(255) NULL DEFAULT NULL,
+    `received_at` DATETIME NOT NULL,
+    `processed_at` DATETIME NULL DEFAULT NULL,
+    PRIMARY KEY (realm_name, id),
+    INDEX `payment_ref_index` (payment_ref)
+);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `prize_payout` (
+    `id` VARCHAR(36) NOT NULL,
+    `realm_name` VARCHAR(255) NOT NULL,
+    `season_id` VARCHAR(255) NOT NULL,
+    `entry_id` VARCHAR(36) NULL DEFAULT NULL,
+    `category` VARCHAR(255) NOT NULL,
+    `round_number` INT NOT NULL DEFAULT 0,
+    `amount` DECIMAL(10,2) NOT NULL,
+    `currency` VARCHAR(3) NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    PRIMARY KEY (id),
+    INDEX `realm_season_index` (realm_name, season_id)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE `entry`
+DROP INDEX `entrant_nickname_index`,
+DROP COLUMN `active_nickname`,
+DROP COLUMN `reminder_sent_at`,
+ADD UNIQUE KEY `entrant_nickname_index` (entrant_nickname, season_id, realm_name);
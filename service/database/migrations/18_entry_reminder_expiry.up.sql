@@ -0,0 +1,5 @@
+ALTER TABLE `entry`
+ADD COLUMN `reminder_sent_at` DATETIME NULL AFTER `approved_at`,
+ADD COLUMN `active_nickname` VARCHAR(255) AS (IF(`status` = 'expired', NULL, `entrant_nickname`)) VIRTUAL,
+DROP INDEX `entrant_nickname_index`,
+ADD UNIQUE KEY `entrant_nickname_index` (active_nickname, season_id, realm_name);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `signup_session` (
+    `id` VARCHAR(32) NOT NULL,
+    `realm_name` VARCHAR(255) NOT NULL,
+    `season_id` VARCHAR(255) NOT NULL,
+    `entry_id` VARCHAR(36) NOT NULL,
+    `step` VARCHAR(255) NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    `updated_at` DATETIME NULL DEFAULT NULL,
+    `expires_at` DATETIME NOT NULL,
+    PRIMARY KEY (id),
+    INDEX `entry_index` (entry_id)
+);
@@ -0,0 +1,3 @@
+DELETE FROM `token`;
+ALTER TABLE `token`
+MODIFY COLUMN `id` VARCHAR(64) NOT NULL;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS `rate_limit` (
+    `limit_key` VARCHAR(512) NOT NULL,
+    `window_start` DATETIME NOT NULL,
+    `attempts` INT(11) NOT NULL,
+    `expires_at` DATETIME NOT NULL,
+    PRIMARY KEY (limit_key, window_start),
+    INDEX `expires_at_index` (expires_at)
+);
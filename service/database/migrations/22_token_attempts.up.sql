@@ -0,0 +1,3 @@
+ALTER TABLE `token`
+ADD COLUMN `attempts` INT(11) NOT NULL DEFAULT 0
+AFTER `expires_at`;
@@ -0,0 +1,2 @@
+ALTER TABLE `entry`
+DROP COLUMN `email_verified_at`;
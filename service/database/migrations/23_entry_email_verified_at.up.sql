@@ -0,0 +1,3 @@
+ALTER TABLE `entry`
+ADD COLUMN `email_verified_at` DATETIME NULL AFTER `reminder_sent_at`;
+UPDATE `entry` SET `email_verified_at` = `created_at`;
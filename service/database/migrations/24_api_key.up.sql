@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `api_key` (
+    `id` VARCHAR(36) NOT NULL,
+    `realm_name` VARCHAR(255) NOT NULL,
+    `name` VARCHAR(64) NOT NULL,
+    `key_hash` VARCHAR(64) NOT NULL,
+    `usage_count` BIGINT NOT NULL DEFAULT 0,
+    `last_used_at` DATETIME NULL DEFAULT NULL,
+    `created_at` DATETIME NOT NULL,
+    `revoked_at` DATETIME NULL DEFAULT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY `key_hash_index` (key_hash),
+    INDEX `realm_index` (realm_name)
+);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS `entry_cap_lock` (
+    `realm_name` VARCHAR(255) NOT NULL,
+    `season_id` VARCHAR(10) NOT NULL,
+    PRIMARY KEY (realm_name, season_id)
+);
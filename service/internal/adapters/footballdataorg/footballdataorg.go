@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"prediction-league/service/internal/adapters"
 	"prediction-league/service/internal/domain"
+	"time"
 )
 
 const baseURL = "https://api.football-data.org"
@@ -62,6 +63,40 @@ func (c *Client) RetrieveLatestStandingsBySeason(ctx context.Context, s domain.S
 	return standings, nil
 }
 
+// RetrieveFixturesBySeason implements this method on the domain.FixtureDataSource interface
+func (c *Client) RetrieveFixturesBySeason(ctx context.Context, s domain.Season) ([]domain.Fixture, error) {
+	req, err := c.prepareRetrieveMatchesRequest(ctx, s.ClientID.Value(), s.Live.From.Year())
+	if err != nil {
+		return nil, fmt.Errorf("cannot prepare retrieve matches request: %w", err)
+	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get retrieve matches response: %w", err)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read retrieve matches response body: %w", err)
+	}
+
+	var matchesResp competitionMatchesGetResponse
+	if err := json.Unmarshal(body, &matchesResp); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal retrieve matches response: %w", err)
+	}
+
+	fixtures := make([]domain.Fixture, 0)
+	for _, m := range matchesResp.Matches {
+		fixture, err := m.toFixture(c.tc)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert match to fixture: %w", err)
+		}
+		fixtures = append(fixtures, fixture)
+	}
+
+	return fixtures, nil
+}
+
 // NewClient generates a new Client
 func NewClient(apiToken string, tc domain.TeamCollection, hc adapters.HTTPClient) (*Client, error) {
 	if tc == nil {
@@ -86,6 +121,19 @@ func (c *Client) prepareRetrieveStandingsRequest(ctx context.Context, compID str
 	return req, nil
 }
 
+func (c *Client) prepareRetrieveMatchesRequest(ctx context.Context, compID string, year int) (*http.Request, error) {
+	url := fmt.Sprintf("%s/v2/competitions/%s/matches?season=%d", baseURL, compID, year)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate request: url '%s': %w", url, err)
+	}
+
+	req.Header.Add("X-Auth-Token", c.apiToken)
+
+	return req, nil
+}
+
 // competitionStandingsGetResponse defines the expected payload structure of the request to retrieve the current standings
 type competitionStandingsGetResponse struct {
 	Season struct {
@@ -139,3 +187,40 @@ func getOverallStandings(resp competitionStandingsGetResponse) (*competitionStan
 	}
 	return nil, errors.New("cannot find standings with type of total")
 }
+
+// competitionMatchesGetResponse defines the expected payload structure of the request to retrieve a season's matches
+type competitionMatchesGetResponse struct {
+	Matches []match `json:"matches"`
+}
+
+// match defines the nested payload structure within the response that retrieves a season's matches
+type match struct {
+	UTCDate  time.Time `json:"utcDate"`
+	Matchday int       `json:"matchday"`
+	HomeTeam struct {
+		ID int `json:"id"`
+	} `json:"homeTeam"`
+	AwayTeam struct {
+		ID int `json:"id"`
+	} `json:"awayTeam"`
+}
+
+// toFixture transforms a match object to a more abstracted Fixture object
+func (m *match) toFixture(tc domain.TeamCollection) (domain.Fixture, error) {
+	home, err := tc.GetByResourceID(domain.TeamIdentifier{TeamID: m.HomeTeam.ID})
+	if err != nil {
+		return domain.Fixture{}, err
+	}
+
+	away, err := tc.GetByResourceID(domain.TeamIdentifier{TeamID: m.AwayTeam.ID})
+	if err != nil {
+		return domain.Fixture{}, err
+	}
+
+	return domain.Fixture{
+		MatchWeek:  m.Matchday,
+		KickOff:    m.UTCDate,
+		HomeTeamID: home.ID,
+		AwayTeamID: away.ID,
+	}, nil
+}
@@ -289,6 +289,146 @@ func TestClient_RetrieveLatestStandingsBySeason(t *testing.T) {
 	})
 }
 
+func TestClient_RetrieveFixturesBySeason(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dt := time.Date(2018, 5, 26, 14, 0, 0, 0, loc)
+	apiToken := "my-token"
+	s := domain.Season{
+		ID:       "season-id",
+		ClientID: domain.SeasonIdentifier{SeasonID: "season-client-id"},
+		Live:     domain.TimeFrame{From: dt},
+	}
+
+	tc := domain.TeamCollection{
+		"aaa": domain.Team{
+			ID:       "AFCB",
+			ClientID: domain.TeamIdentifier{TeamID: 1111},
+		},
+		"bbb": domain.Team{
+			ID:       "PTFC",
+			ClientID: domain.TeamIdentifier{TeamID: 2222},
+		},
+	}
+
+	t.Run("happy path must produce the expected fixtures", func(t *testing.T) {
+		hc := &mockHTTPClient{func(req *http.Request) (*http.Response, error) {
+			wantURL := "https://api.football-data.org/v2/competitions/season-client-id/matches?season=2018"
+			gotURL := req.URL.String()
+			diff := cmp.Diff(wantURL, gotURL)
+			if diff != "" {
+				t.Fatalf("want request url '%s', got '%s', diff: %s", wantURL, gotURL, diff)
+			}
+
+			wantHdr := apiToken
+			gotHdr := req.Header.Get("X-Auth-Token")
+			diff = cmp.Diff(wantHdr, gotHdr)
+			if diff != "" {
+				t.Fatalf("want token heander '%s', got '%s', diff: %s", wantHdr, gotHdr, diff)
+			}
+
+			body := `{
+				"matches": [
+					{
+						"utcDate": "2018-08-11T14:00:00Z",
+						"matchday": 1,
+						"homeTeam": {
+							"id": 1111
+						},
+						"awayTeam": {
+							"id": 2222
+						}
+					},
+					{
+						"utcDate": "2018-08-18T16:30:00Z",
+						"matchday": 2,
+						"homeTeam": {
+							"id": 2222
+						},
+						"awayTeam": {
+							"id": 1111
+						}
+					}
+				]
+			}`
+
+			resp := &http.Response{Body: ioutil.NopCloser(bytes.NewReader([]byte(body)))}
+
+			return resp, nil
+		}}
+
+		wantFixtures := []domain.Fixture{
+			{
+				MatchWeek:  1,
+				KickOff:    time.Date(2018, 8, 11, 14, 0, 0, 0, time.UTC),
+				HomeTeamID: "AFCB",
+				AwayTeamID: "PTFC",
+			},
+			{
+				MatchWeek:  2,
+				KickOff:    time.Date(2018, 8, 18, 16, 30, 0, 0, time.UTC),
+				HomeTeamID: "PTFC",
+				AwayTeamID: "AFCB",
+			},
+		}
+
+		cl := Client{apiToken, tc, hc}
+		gotFixtures, err := cl.RetrieveFixturesBySeason(context.Background(), s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		diff := cmp.Diff(wantFixtures, gotFixtures)
+		if diff != "" {
+			t.Fatalf("want fixtures %+v, got %+v, diff: %s", wantFixtures, gotFixtures, diff)
+		}
+	})
+
+	t.Run("failed call to http client must return expected error", func(t *testing.T) {
+		hc := &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("sad times :'(")
+		}}
+
+		cl := &Client{hc: hc}
+
+		wantErrMsg := "cannot get retrieve matches response: sad times :'("
+		_, gotErr := cl.RetrieveFixturesBySeason(context.Background(), s)
+		if gotErr == nil || gotErr.Error() != wantErrMsg {
+			t.Fatalf("want error msg %s, got %+v (%T)", wantErrMsg, gotErr, gotErr)
+		}
+	})
+
+	t.Run("failure to convert match must return expected error", func(t *testing.T) {
+		hc := &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{Body: ioutil.NopCloser(bytes.NewBuffer([]byte(
+				`{
+						"matches": [
+							{
+								"utcDate": "2018-08-11T14:00:00Z",
+								"homeTeam": {
+									"id": 99999
+								},
+								"awayTeam": {
+									"id": 1111
+								}
+							}
+						]
+					}`,
+			)))}, nil
+		}}
+
+		cl := &Client{tc: tc, hc: hc}
+
+		wantErrMsg := "cannot convert match to fixture: team client resource id 99999: not found"
+		_, gotErr := cl.RetrieveFixturesBySeason(context.Background(), s)
+		if gotErr == nil || gotErr.Error() != wantErrMsg {
+			t.Fatalf("want error msg %s, got %+v (%T)", wantErrMsg, gotErr, gotErr)
+		}
+	})
+}
+
 type doFunc func(*http.Request) (*http.Response, error)
 
 type mockHTTPClient struct {
@@ -0,0 +1,129 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"prediction-league/service/internal/domain"
+	"time"
+)
+
+// adminUserDBFields defines the fields used regularly in AdminUser-related transactions
+var adminUserDBFields = []string{
+	"username",
+	"password_hash",
+	"realm_roles",
+}
+
+// AdminUserRepo defines our DB-backed AdminUser data store
+type AdminUserRepo struct {
+	db *sql.DB
+}
+
+// Insert inserts a new AdminUser into the database
+func (a *AdminUserRepo) Insert(ctx context.Context, user *domain.AdminUser) error {
+	stmt := `INSERT INTO admin_user (id, ` + getDBFieldsStringFromFields(adminUserDBFields) + `, created_at)
+					VALUES (?, ?, ?, ?, ?)`
+
+	roles, err := json.Marshal(&user.RealmRoles)
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.db.ExecContext(
+		ctx,
+		stmt,
+		user.ID,
+		user.Username,
+		user.PasswordHash,
+		roles,
+		user.CreatedAt,
+	); err != nil {
+		return wrapDBError(err)
+	}
+
+	return nil
+}
+
+// Update updates an existing AdminUser in the database
+func (a *AdminUserRepo) Update(ctx context.Context, user *domain.AdminUser) error {
+	stmt := `UPDATE admin_user
+				SET ` + getDBFieldsWithEqualsPlaceholdersStringFromFields(adminUserDBFields) + `, updated_at = ?
+				WHERE id = ?`
+
+	roles, err := json.Marshal(&user.RealmRoles)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Truncate(time.Second)
+
+	if _, err := a.db.ExecContext(
+		ctx,
+		stmt,
+		user.Username,
+		user.PasswordHash,
+		roles,
+		now,
+		user.ID,
+	); err != nil {
+		return wrapDBError(err)
+	}
+
+	user.UpdatedAt = &now
+
+	return nil
+}
+
+// Select retrieves AdminUsers from our database based on the provided criteria
+func (a *AdminUserRepo) Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]domain.AdminUser, error) {
+	whereStmt, params := dbWhereStmt(criteria, matchAny)
+
+	stmt := `SELECT id, ` + getDBFieldsStringFromFields(adminUserDBFields) + `, created_at, updated_at FROM admin_user ` + whereStmt
+
+	rows, err := a.db.QueryContext(ctx, stmt, params...)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	var users []domain.AdminUser
+	var roles []byte
+
+	for rows.Next() {
+		user := domain.AdminUser{}
+
+		if err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.PasswordHash,
+			&roles,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		); err != nil {
+			return nil, wrapDBError(err)
+		}
+
+		if err := json.Unmarshal(roles, &user.RealmRoles); err != nil {
+			return nil, wrapDBError(err)
+		}
+
+		users = append(users, user)
+	}
+
+	if len(users) == 0 {
+		return nil, domain.MissingDBRecordError{Err: errors.New("no admin users found")}
+	}
+
+	return users, nil
+}
+
+// NewAdminUserRepo instantiates a new AdminUserRepo with the provided DB agent
+func NewAdminUserRepo(db *sql.DB) (*AdminUserRepo, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db: %w", domain.ErrIsNil)
+	}
+	return &AdminUserRepo{db: db}, nil
+}
@@ -0,0 +1,153 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"prediction-league/service/internal/domain"
+	"time"
+)
+
+// apiKeyDBFields defines the fields used regularly in APIKey-related transactions
+var apiKeyDBFields = []string{
+	"realm_name",
+	"name",
+	"key_hash",
+	"usage_count",
+	"last_used_at",
+	"created_at",
+	"revoked_at",
+}
+
+// APIKeyRepo defines our DB-backed APIKey data store
+type APIKeyRepo struct {
+	db *sql.DB
+}
+
+// Insert inserts a new APIKey into the database
+func (a *APIKeyRepo) Insert(ctx context.Context, key *domain.APIKey) error {
+	stmt := `INSERT INTO api_key (id, ` + getDBFieldsStringFromFields(apiKeyDBFields) + `)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	if _, err := a.db.ExecContext(
+		ctx,
+		stmt,
+		key.ID,
+		key.RealmName,
+		key.Name,
+		key.KeyHash,
+		key.UsageCount,
+		key.LastUsedAt,
+		key.CreatedAt,
+		key.RevokedAt,
+	); err != nil {
+		return wrapDBError(err)
+	}
+
+	return nil
+}
+
+// Update updates an existing APIKey in the database
+func (a *APIKeyRepo) Update(ctx context.Context, key *domain.APIKey) error {
+	stmt := `UPDATE api_key
+				SET ` + getDBFieldsWithEqualsPlaceholdersStringFromFields(apiKeyDBFields) + `
+				WHERE id = ?`
+
+	res, err := a.db.ExecContext(
+		ctx,
+		stmt,
+		key.RealmName,
+		key.Name,
+		key.KeyHash,
+		key.UsageCount,
+		key.LastUsedAt,
+		key.CreatedAt,
+		key.RevokedAt,
+		key.ID,
+	)
+	if err != nil {
+		return wrapDBError(err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return wrapDBError(err)
+	}
+
+	if affected == 0 {
+		return domain.MissingDBRecordError{Err: fmt.Errorf("api key id %s: not found", key.ID)}
+	}
+
+	return nil
+}
+
+// Select retrieves APIKeys from our database based on the provided criteria
+func (a *APIKeyRepo) Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]domain.APIKey, error) {
+	whereStmt, params := dbWhereStmt(criteria, matchAny)
+
+	stmt := `SELECT id, ` + getDBFieldsStringFromFields(apiKeyDBFields) + ` FROM api_key ` + whereStmt
+
+	rows, err := a.db.QueryContext(ctx, stmt, params...)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	var keys []domain.APIKey
+
+	for rows.Next() {
+		key := domain.APIKey{}
+
+		if err := rows.Scan(
+			&key.ID,
+			&key.RealmName,
+			&key.Name,
+			&key.KeyHash,
+			&key.UsageCount,
+			&key.LastUsedAt,
+			&key.CreatedAt,
+			&key.RevokedAt,
+		); err != nil {
+			return nil, wrapDBError(err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return nil, domain.MissingDBRecordError{Err: errors.New("no api keys found")}
+	}
+
+	return keys, nil
+}
+
+// IncrementUsage counts a single use of the APIKey with the provided ID at the provided time.
+// The count is incremented by the database, so that concurrent requests made with the same APIKey are all counted
+func (a *APIKeyRepo) IncrementUsage(ctx context.Context, id string, usedAt time.Time) error {
+	stmt := `UPDATE api_key SET usage_count = usage_count + 1, last_used_at = ? WHERE id = ?`
+
+	res, err := a.db.ExecContext(ctx, stmt, usedAt, id)
+	if err != nil {
+		return wrapDBError(err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return wrapDBError(err)
+	}
+
+	if affected == 0 {
+		return domain.MissingDBRecordError{Err: fmt.Errorf("api key id %s: not found", id)}
+	}
+
+	return nil
+}
+
+// NewAPIKeyRepo instantiates a new APIKeyRepo with the provided DB agent
+func NewAPIKeyRepo(db *sql.DB) (*APIKeyRepo, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db: %w", domain.ErrIsNil)
+	}
+	return &APIKeyRepo{db: db}, nil
+}
@@ -0,0 +1,91 @@
+package mysqldb_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"prediction-league/service/internal/adapters/mysqldb"
+	"prediction-league/service/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewAPIKeyRepo(t *testing.T) {
+	t.Run("passing invalid parameters must return expected error", func(t *testing.T) {
+		db := &sql.DB{}
+
+		tt := []struct {
+			db      *sql.DB
+			wantErr error
+		}{
+			{nil, domain.ErrIsNil},
+			{db, nil},
+		}
+		for idx, tc := range tt {
+			repo, gotErr := mysqldb.NewAPIKeyRepo(tc.db)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("tc #%d: want error %s (%T), got %s (%T)", idx, tc.wantErr, tc.wantErr, gotErr, gotErr)
+			}
+			if tc.wantErr == nil && repo == nil {
+				t.Fatalf("tc #%d: want non-empty repo, got nil", idx)
+			}
+		}
+	})
+}
+
+func TestAPIKeyRepo_IncrementUsage(t *testing.T) {
+	t.Cleanup(truncate)
+
+	ctx := context.Background()
+
+	repo, err := mysqldb.NewAPIKeyRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := &domain.APIKey{
+		ID:        id,
+		RealmName: "TEST_REALM",
+		Name:      "Discord bot",
+		KeyHash:   "api_key_hash",
+		CreatedAt: testDate.In(utc),
+	}
+	if err := repo.Insert(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("increment usage of an existing api key must accumulate", func(t *testing.T) {
+		usedAt := testDate.In(utc).Add(time.Minute)
+
+		for i := 0; i < 3; i++ {
+			if err := repo.IncrementUsage(ctx, id.String(), usedAt); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		keys, err := repo.Select(ctx, map[string]interface{}{"id": id.String()}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keys[0].UsageCount != 3 {
+			t.Fatalf("want usage count %d, got %d", 3, keys[0].UsageCount)
+		}
+		if keys[0].LastUsedAt == nil || !keys[0].LastUsedAt.Equal(usedAt) {
+			t.Fatalf("want last used at %s, got %v", usedAt, keys[0].LastUsedAt)
+		}
+	})
+
+	t.Run("increment usage of a non-existent api key must fail", func(t *testing.T) {
+		err := repo.IncrementUsage(ctx, "non_existent_id", testDate)
+		if !errors.As(err, &domain.MissingDBRecordError{}) {
+			t.Fatalf("want error %T, got %T", domain.MissingDBRecordError{}, err)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"prediction-league/service/internal/domain"
+)
+
+// auditRecordDBFields defines the fields used regularly in AuditRecord-related transactions
+var auditRecordDBFields = []string{
+	"realm_name",
+	"actor_type",
+	"actor_id",
+	"action",
+	"target_type",
+	"target_id",
+	"before_value",
+	"after_value",
+	"created_at",
+}
+
+// AuditRepo defines our DB-backed AuditRecord data store
+type AuditRepo struct {
+	db *sql.DB
+}
+
+// Insert inserts a new AuditRecord into the database
+func (a *AuditRepo) Insert(ctx context.Context, record *domain.AuditRecord) error {
+	stmt := `INSERT INTO audit_record (id, ` + getDBFieldsStringFromFields(auditRecordDBFields) + `)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	if _, err := a.db.ExecContext(
+		ctx,
+		stmt,
+		record.ID,
+		record.RealmName,
+		record.ActorType,
+		record.ActorID,
+		record.Action,
+		record.TargetType,
+		record.TargetID,
+		nullableJSON(record.Before),
+		nullableJSON(record.After),
+		record.CreatedAt,
+	); err != nil {
+		return wrapDBError(err)
+	}
+
+	return nil
+}
+
+// Select retrieves AuditRecords from our database based on the provided criteria
+func (a *AuditRepo) Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]domain.AuditRecord, error) {
+	whereStmt, params := dbWhereStmt(criteria, matchAny)
+
+	stmt := `SELECT id, ` + getDBFieldsStringFromFields(auditRecordDBFields) + ` FROM audit_record ` + whereStmt
+
+	rows, err := a.db.QueryContext(ctx, stmt, params...)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	var records []domain.AuditRecord
+
+	for rows.Next() {
+		record := domain.AuditRecord{}
+		var before, after []byte
+
+		if err := rows.Scan(
+			&record.ID,
+			&record.RealmName,
+			&record.ActorType,
+			&record.ActorID,
+			&record.Action,
+			&record.TargetType,
+			&record.TargetID,
+			&before,
+			&after,
+			&record.CreatedAt,
+		); err != nil {
+			return nil, wrapDBError(err)
+		}
+
+		record.Before = before
+		record.After = after
+
+		records = append(records, record)
+	}
+
+	if len(records) == 0 {
+		return nil, domain.MissingDBRecordError{Err: errors.New("no audit records found")}
+	}
+
+	return records, nil
+}
+
+// NewAuditRepo instantiates a new AuditRepo with the provided DB agent
+func NewAuditRepo(db *sql.DB) (*AuditRepo, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db: %w", domain.ErrIsNil)
+	}
+	return &AuditRepo{db: db}, nil
+}
+
+// nullableJSON returns the provided JSON as a value that can be written to a nullable JSON column
+func nullableJSON(b []byte) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+	return b
+}
@@ -21,6 +21,8 @@ var entryDBFields = []string{
 	"payment_method",
 	"payment_ref",
 	"approved_at",
+	"reminder_sent_at",
+	"email_verified_at",
 }
 
 // EntryRepo defines our DB-backed Entry data store
@@ -31,7 +33,7 @@ type EntryRepo struct {
 // Insert inserts a new Entry into the database
 func (e *EntryRepo) Insert(ctx context.Context, entry *domain.Entry) error {
 	stmt := `INSERT INTO entry (id, ` + getDBFieldsStringFromFields(entryDBFields) + `, created_at)
-					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	now := time.Now().Truncate(time.Second)
 
@@ -48,6 +50,8 @@ func (e *EntryRepo) Insert(ctx context.Context, entry *domain.Entry) error {
 		entry.PaymentMethod,
 		entry.PaymentRef,
 		entry.ApprovedAt,
+		entry.ReminderSentAt,
+		entry.EmailVerifiedAt,
 		now,
 	)
 	if err != nil {
@@ -80,6 +84,8 @@ func (e *EntryRepo) Update(ctx context.Context, entry *domain.Entry) error {
 		entry.PaymentMethod,
 		entry.PaymentRef,
 		entry.ApprovedAt,
+		entry.ReminderSentAt,
+		entry.EmailVerifiedAt,
 		now,
 		entry.ID,
 	)
@@ -93,6 +99,223 @@ func (e *EntryRepo) Update(ctx context.Context, entry *domain.Entry) error {
 	return nil
 }
 
+// InsertWithinCap inserts a new Entry into the database, placing it on the waitlist instead if the provided number of
+// places has already been taken in its realm and season. Places are counted and the Entry is inserted within a single
+// transaction that holds the realm and season's entry cap lock, so that concurrent sign-ups cannot exceed the cap
+func (e *EntryRepo) InsertWithinCap(ctx context.Context, entry *domain.Entry, entryCap int) (err error) {
+	stmt := `INSERT INTO entry (id, ` + getDBFieldsStringFromFields(entryDBFields) + `, created_at)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	tx, err := e.db.BeginTx(ctx, nil)
+	if err != nil {
+		return wrapDBError(err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	placesTaken, err := lockEntryCap(ctx, tx, entry.RealmName, entry.SeasonID)
+	if err != nil {
+		return err
+	}
+
+	status := entry.Status
+	if placesTaken >= entryCap {
+		status = domain.EntryStatusWaitlisted
+	}
+
+	now := time.Now().Truncate(time.Second)
+
+	if _, err = tx.ExecContext(
+		ctx,
+		stmt,
+		entry.ID,
+		entry.SeasonID,
+		entry.RealmName,
+		entry.EntrantName,
+		entry.EntrantNickname,
+		entry.EntrantEmail,
+		status,
+		entry.PaymentMethod,
+		entry.PaymentRef,
+		entry.ApprovedAt,
+		entry.ReminderSentAt,
+		entry.EmailVerifiedAt,
+		now,
+	); err != nil {
+		return wrapDBError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return wrapDBError(err)
+	}
+
+	entry.Status = status
+	entry.CreatedAt = now
+
+	return nil
+}
+
+// PromoteWithinCap gives the provided waitlisted Entry a place in its realm and season by writing its status and
+// reminder timestamp, as long as fewer than the provided number of places have been taken. The check and the update
+// happen within a single transaction that holds the realm and season's entry cap lock. domain.ErrEntryCapReached is
+// returned if there is no place available, and domain.ErrEntryNotWaitlisted if the Entry is no longer waitlisted
+func (e *EntryRepo) PromoteWithinCap(ctx context.Context, entry *domain.Entry, entryCap int) (err error) {
+	stmt := `UPDATE entry
+				SET status = ?, reminder_sent_at = ?, updated_at = ?
+				WHERE id = ? AND status = ?`
+
+	tx, err := e.db.BeginTx(ctx, nil)
+	if err != nil {
+		return wrapDBError(err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	placesTaken, err := lockEntryCap(ctx, tx, entry.RealmName, entry.SeasonID)
+	if err != nil {
+		return err
+	}
+
+	if placesTaken >= entryCap {
+		err = domain.ErrEntryCapReached
+		return err
+	}
+
+	now := time.Now().Truncate(time.Second)
+
+	res, err := tx.ExecContext(
+		ctx,
+		stmt,
+		entry.Status,
+		entry.ReminderSentAt,
+		now,
+		entry.ID,
+		domain.EntryStatusWaitlisted,
+	)
+	if err != nil {
+		return wrapDBError(err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return wrapDBError(err)
+	}
+
+	if affected == 0 {
+		err = domain.ErrEntryNotWaitlisted
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return wrapDBError(err)
+	}
+
+	entry.UpdatedAt = &now
+
+	return nil
+}
+
+// RestoreWithinCap gives the provided Entry back a place in its realm and season by writing its status and approval
+// timestamp, as long as it still has the provided status and fewer than the provided number of places have been taken.
+// The check and the update happen within a single transaction that holds the realm and season's entry cap lock.
+// domain.ErrEntryCapReached is returned if there is no place available, and a domain.MissingDBRecordError if the Entry
+// no longer has the provided status
+func (e *EntryRepo) RestoreWithinCap(ctx context.Context, entry *domain.Entry, fromStatus string, entryCap int) (err error) {
+	stmt := `UPDATE entry
+				SET status = ?, approved_at = ?, updated_at = ?
+				WHERE id = ? AND status = ?`
+
+	tx, err := e.db.BeginTx(ctx, nil)
+	if err != nil {
+		return wrapDBError(err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	placesTaken, err := lockEntryCap(ctx, tx, entry.RealmName, entry.SeasonID)
+	if err != nil {
+		return err
+	}
+
+	if placesTaken >= entryCap {
+		err = domain.ErrEntryCapReached
+		return err
+	}
+
+	now := time.Now().Truncate(time.Second)
+
+	res, err := tx.ExecContext(
+		ctx,
+		stmt,
+		entry.Status,
+		entry.ApprovedAt,
+		now,
+		entry.ID,
+		fromStatus,
+	)
+	if err != nil {
+		return wrapDBError(err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return wrapDBError(err)
+	}
+
+	if affected == 0 {
+		err = domain.MissingDBRecordError{Err: fmt.Errorf("entry id %s with status %s: not found", entry.ID, fromStatus)}
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return wrapDBError(err)
+	}
+
+	entry.UpdatedAt = &now
+
+	return nil
+}
+
+// lockEntryCap acquires the entry cap lock of the provided realm and season for the duration of the provided transaction,
+// and returns the number of Entries that hold a place in them
+func lockEntryCap(ctx context.Context, tx *sql.Tx, realmName, seasonID string) (int, error) {
+	// writing the lock row takes an exclusive lock on it, which is held until the transaction ends
+	if _, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO entry_cap_lock (realm_name, season_id) VALUES (?, ?)
+				ON DUPLICATE KEY UPDATE realm_name = realm_name`,
+		realmName,
+		seasonID,
+	); err != nil {
+		return 0, wrapDBError(err)
+	}
+
+	row := tx.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*) FROM entry WHERE realm_name = ? AND season_id = ? AND status IN (?, ?)`,
+		realmName,
+		seasonID,
+		domain.EntryStatusPending,
+		domain.EntryStatusPaid,
+	)
+
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return 0, wrapDBError(err)
+	}
+
+	return count, nil
+}
+
 // Select retrieves Entries from our database based on the provided criteria
 func (e *EntryRepo) Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]domain.Entry, error) {
 	whereStmt, params := dbWhereStmt(criteria, matchAny)
@@ -120,6 +343,8 @@ func (e *EntryRepo) Select(ctx context.Context, criteria map[string]interface{},
 			&entry.PaymentMethod,
 			&entry.PaymentRef,
 			&entry.ApprovedAt,
+			&entry.ReminderSentAt,
+			&entry.EmailVerifiedAt,
 			&entry.CreatedAt,
 			&entry.UpdatedAt,
 		); err != nil {
@@ -1,13 +1,13 @@
 package mysqldb
 
 import (
+	"crypto/rand"
 	"database/sql"
 	"errors"
 	"fmt"
-	"math/rand"
+	"math/big"
 	"prediction-league/service/internal/domain"
 	"strings"
-	"time"
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/golang-migrate/migrate/v4"
@@ -21,7 +21,19 @@ func ConnectAndMigrate(mysqlURL, migURL string, l domain.Logger) (*sql.DB, error
 	if err != nil {
 		return nil, fmt.Errorf("cannot open db connection: %w", err)
 	}
-	driver, err := migmysql.WithInstance(db, &migmysql.Config{})
+
+	// a migration can comprise several statements, which the app connection is deliberately not allowed to run
+	migDSN, err := mysql.ParseDSN(mysqlURL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse db connection url: %w", err)
+	}
+	migDSN.MultiStatements = true
+
+	migDB, err := sql.Open("mysql", migDSN.FormatDSN())
+	if err != nil {
+		return nil, fmt.Errorf("cannot open migration db connection: %w", err)
+	}
+	driver, err := migmysql.WithInstance(migDB, &migmysql.Config{})
 	if err != nil {
 		return nil, fmt.Errorf("cannot open mysql driver instance: %w", err)
 	}
@@ -33,6 +45,7 @@ func ConnectAndMigrate(mysqlURL, migURL string, l domain.Logger) (*sql.DB, error
 	if err != nil {
 		return nil, fmt.Errorf("cannot open migration instance: %w", err)
 	}
+	defer mig.Close()
 
 	if err := mig.Up(); err != nil {
 		switch err {
@@ -83,21 +96,22 @@ func dbWhereStmt(criteria map[string]interface{}, matchAny bool) (stmt string, p
 	return stmt, params
 }
 
-// generateAlphaNumericString generates an alphanumeric string to the provided length
-func generateAlphaNumericString(length int) string {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+// generateAlphaNumericString generates a cryptographically random alphanumeric string to the provided length
+func generateAlphaNumericString(length int) (string, error) {
 	source := "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789abcdefghijklmnopqrstuvwxyz-_"
-	var generated string
+	sourceLen := big.NewInt(int64(len(source)))
 
-	sourceLen := len(source)
+	generated := make([]byte, length)
 
-	for i := 0; i < length; i++ {
-		randInt := r.Int63n(int64(sourceLen))
-		randByte := []byte(source)[randInt]
-		generated += string(randByte)
+	for i := range generated {
+		randInt, err := rand.Int(rand.Reader, sourceLen)
+		if err != nil {
+			return "", err
+		}
+		generated[i] = source[randInt.Int64()]
 	}
 
-	return generated
+	return string(generated), nil
 }
 
 // getDBFieldsStringFromFields returns a statement-ready string of fields names
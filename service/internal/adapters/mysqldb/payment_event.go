@@ -0,0 +1,167 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"prediction-league/service/internal/domain"
+)
+
+// paymentEventDBFields defines the fields used regularly in PaymentEvent-related transactions
+var paymentEventDBFields = []string{
+	"event_type",
+	"provider_event_type",
+	"entry_id",
+	"payment_ref",
+	"amount",
+	"currency",
+	"payload",
+	"outcome",
+	"received_at",
+	"processed_at",
+}
+
+// PaymentEventRepo defines our DB-backed PaymentEvent data store
+type PaymentEventRepo struct {
+	db *sql.DB
+}
+
+// Insert inserts a new PaymentEvent into the database
+func (p *PaymentEventRepo) Insert(ctx context.Context, event *domain.PaymentEvent) error {
+	stmt := `INSERT INTO payment_event (id, realm_name, ` + getDBFieldsStringFromFields(paymentEventDBFields) + `)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	if _, err := p.db.ExecContext(
+		ctx,
+		stmt,
+		event.ID,
+		event.RealmName,
+		event.Type,
+		event.ProviderEventType,
+		nullableString(event.EntryID),
+		nullableString(event.PaymentRef),
+		event.Amount,
+		nullableString(event.Currency),
+		event.Payload,
+		nullableString(event.Outcome),
+		event.ReceivedAt,
+		event.ProcessedAt,
+	); err != nil {
+		return wrapDBError(err)
+	}
+
+	return nil
+}
+
+// Update updates the outcome of an existing PaymentEvent in the database
+func (p *PaymentEventRepo) Update(ctx context.Context, event *domain.PaymentEvent) error {
+	stmt := `UPDATE payment_event
+				SET outcome = ?, processed_at = ?
+				WHERE realm_name = ? AND id = ?`
+
+	res, err := p.db.ExecContext(ctx, stmt, nullableString(event.Outcome), event.ProcessedAt, event.RealmName, event.ID)
+	if err != nil {
+		return wrapDBError(err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return wrapDBError(err)
+	}
+
+	if affected == 0 {
+		return domain.MissingDBRecordError{Err: fmt.Errorf("payment event id %s: not found", event.ID)}
+	}
+
+	return nil
+}
+
+// Delete deletes an existing PaymentEvent from the database
+func (p *PaymentEventRepo) Delete(ctx context.Context, event *domain.PaymentEvent) error {
+	stmt := `DELETE FROM payment_event WHERE realm_name = ? AND id = ?`
+
+	res, err := p.db.ExecContext(ctx, stmt, event.RealmName, event.ID)
+	if err != nil {
+		return wrapDBError(err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return wrapDBError(err)
+	}
+
+	if affected == 0 {
+		return domain.MissingDBRecordError{Err: fmt.Errorf("payment event id %s: not found", event.ID)}
+	}
+
+	return nil
+}
+
+// Select retrieves PaymentEvents from our database based on the provided criteria
+func (p *PaymentEventRepo) Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]domain.PaymentEvent, error) {
+	whereStmt, params := dbWhereStmt(criteria, matchAny)
+
+	stmt := `SELECT id, realm_name, ` + getDBFieldsStringFromFields(paymentEventDBFields) + ` FROM payment_event ` + whereStmt
+
+	rows, err := p.db.QueryContext(ctx, stmt, params...)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	var events []domain.PaymentEvent
+
+	for rows.Next() {
+		event := domain.PaymentEvent{}
+		var entryID, paymentRef, currency, outcome sql.NullString
+		var payload []byte
+
+		if err := rows.Scan(
+			&event.ID,
+			&event.RealmName,
+			&event.Type,
+			&event.ProviderEventType,
+			&entryID,
+			&paymentRef,
+			&event.Amount,
+			&currency,
+			&payload,
+			&outcome,
+			&event.ReceivedAt,
+			&event.ProcessedAt,
+		); err != nil {
+			return nil, wrapDBError(err)
+		}
+
+		event.EntryID = entryID.String
+		event.PaymentRef = paymentRef.String
+		event.Currency = currency.String
+		event.Payload = payload
+		event.Outcome = outcome.String
+
+		events = append(events, event)
+	}
+
+	if len(events) == 0 {
+		return nil, domain.MissingDBRecordError{Err: errors.New("no payment events found")}
+	}
+
+	return events, nil
+}
+
+// NewPaymentEventRepo instantiates a new PaymentEventRepo with the provided DB agent
+func NewPaymentEventRepo(db *sql.DB) (*PaymentEventRepo, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db: %w", domain.ErrIsNil)
+	}
+	return &PaymentEventRepo{db: db}, nil
+}
+
+// nullableString returns the provided string as a value that can be written to a nullable column
+func nullableString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,119 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"prediction-league/service/internal/domain"
+)
+
+// prizePayoutDBFields defines the fields used regularly in PrizePayout-related transactions
+var prizePayoutDBFields = []string{
+	"realm_name",
+	"season_id",
+	"entry_id",
+	"category",
+	"round_number",
+	"amount",
+	"currency",
+	"created_at",
+}
+
+// PrizePayoutRepo defines our DB-backed PrizePayout data store
+type PrizePayoutRepo struct {
+	db *sql.DB
+}
+
+// Insert inserts the provided PrizePayouts into the database within a single transaction, so that either all
+// of them are stored or none of them are
+func (p *PrizePayoutRepo) Insert(ctx context.Context, payouts ...*domain.PrizePayout) (err error) {
+	stmt := `INSERT INTO prize_payout (id, ` + getDBFieldsStringFromFields(prizePayoutDBFields) + `)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return wrapDBError(err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for _, payout := range payouts {
+		if _, err = tx.ExecContext(
+			ctx,
+			stmt,
+			payout.ID,
+			payout.RealmName,
+			payout.SeasonID,
+			nullableString(payout.EntryID),
+			payout.Category,
+			payout.RoundNumber,
+			payout.Amount,
+			payout.Currency,
+			payout.CreatedAt,
+		); err != nil {
+			return wrapDBError(err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return wrapDBError(err)
+	}
+
+	return nil
+}
+
+// Select retrieves PrizePayouts from our database based on the provided criteria
+func (p *PrizePayoutRepo) Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]domain.PrizePayout, error) {
+	whereStmt, params := dbWhereStmt(criteria, matchAny)
+
+	stmt := `SELECT id, ` + getDBFieldsStringFromFields(prizePayoutDBFields) + ` FROM prize_payout ` + whereStmt
+
+	rows, err := p.db.QueryContext(ctx, stmt, params...)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	var payouts []domain.PrizePayout
+
+	for rows.Next() {
+		payout := domain.PrizePayout{}
+		var entryID sql.NullString
+
+		if err := rows.Scan(
+			&payout.ID,
+			&payout.RealmName,
+			&payout.SeasonID,
+			&entryID,
+			&payout.Category,
+			&payout.RoundNumber,
+			&payout.Amount,
+			&payout.Currency,
+			&payout.CreatedAt,
+		); err != nil {
+			return nil, wrapDBError(err)
+		}
+
+		payout.EntryID = entryID.String
+
+		payouts = append(payouts, payout)
+	}
+
+	if len(payouts) == 0 {
+		return nil, domain.MissingDBRecordError{Err: errors.New("no prize payouts found")}
+	}
+
+	return payouts, nil
+}
+
+// NewPrizePayoutRepo instantiates a new PrizePayoutRepo with the provided DB agent
+func NewPrizePayoutRepo(db *sql.DB) (*PrizePayoutRepo, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db: %w", domain.ErrIsNil)
+	}
+	return &PrizePayoutRepo{db: db}, nil
+}
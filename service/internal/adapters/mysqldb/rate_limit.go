@@ -0,0 +1,59 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"prediction-league/service/internal/domain"
+	"time"
+)
+
+// RateLimitRepo defines our DB-backed store of rate limit attempts, which is shared by every instance of the service
+type RateLimitRepo struct {
+	db *sql.DB
+}
+
+// Increment implements domain.RateLimitStore
+func (r *RateLimitRepo) Increment(ctx context.Context, key string, windowStart, expiresAt time.Time) (int, error) {
+	// LAST_INSERT_ID(expr) hands the attempts written by this statement back to it alone,
+	// so the count that is returned is never affected by a concurrent increment
+	stmt := `INSERT INTO rate_limit (limit_key, window_start, attempts, expires_at)
+				VALUES (?, ?, LAST_INSERT_ID(1), ?)
+				ON DUPLICATE KEY UPDATE attempts = LAST_INSERT_ID(attempts + 1)`
+
+	res, err := r.db.ExecContext(ctx, stmt, key, windowStart, expiresAt)
+	if err != nil {
+		return 0, wrapDBError(err)
+	}
+
+	attempts, err := res.LastInsertId()
+	if err != nil {
+		return 0, wrapDBError(err)
+	}
+
+	// discard windows that ended before this one began, so that the table does not grow indefinitely
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM rate_limit WHERE expires_at < ?`, windowStart); err != nil {
+		return 0, wrapDBError(err)
+	}
+
+	return int(attempts), nil
+}
+
+// Decrement implements domain.RateLimitStore
+func (r *RateLimitRepo) Decrement(ctx context.Context, key string, windowStart time.Time) error {
+	stmt := `UPDATE rate_limit SET attempts = attempts - 1 WHERE limit_key = ? AND window_start = ? AND attempts > 0`
+
+	if _, err := r.db.ExecContext(ctx, stmt, key, windowStart); err != nil {
+		return wrapDBError(err)
+	}
+
+	return nil
+}
+
+// NewRateLimitRepo instantiates a new RateLimitRepo with the provided DB agent
+func NewRateLimitRepo(db *sql.DB) (*RateLimitRepo, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db: %w", domain.ErrIsNil)
+	}
+	return &RateLimitRepo{db: db}, nil
+}
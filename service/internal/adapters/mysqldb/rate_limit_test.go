@@ -0,0 +1,130 @@
+package mysqldb_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"prediction-league/service/internal/adapters/mysqldb"
+	"prediction-league/service/internal/domain"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimitRepo(t *testing.T) {
+	t.Run("passing invalid parameters must return expected error", func(t *testing.T) {
+		db := &sql.DB{}
+
+		tt := []struct {
+			db      *sql.DB
+			wantErr error
+		}{
+			{nil, domain.ErrIsNil},
+			{db, nil},
+		}
+		for idx, tc := range tt {
+			repo, gotErr := mysqldb.NewRateLimitRepo(tc.db)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("tc #%d: want error %s (%T), got %s (%T)", idx, tc.wantErr, tc.wantErr, gotErr, gotErr)
+			}
+			if tc.wantErr == nil && repo == nil {
+				t.Fatalf("tc #%d: want non-empty repo, got nil", idx)
+			}
+		}
+	})
+}
+
+func TestRateLimitRepo_Increment(t *testing.T) {
+	t.Cleanup(truncate)
+
+	ctx := context.Background()
+
+	repo, err := mysqldb.NewRateLimitRepo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := "TEST_REALM|magic_login|ip:127.0.0.1"
+	windowStart := testDate.In(utc)
+	window := 15 * time.Minute
+
+	t.Run("increment attempts within the same window must accumulate", func(t *testing.T) {
+		for i := 1; i <= 3; i++ {
+			cnt, err := repo.Increment(ctx, key, windowStart, windowStart.Add(window))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cnt != i {
+				t.Fatalf("want %d attempts, got %d", i, cnt)
+			}
+		}
+	})
+
+	t.Run("decrement attempts must give back an attempt", func(t *testing.T) {
+		if err := repo.Decrement(ctx, key, windowStart); err != nil {
+			t.Fatal(err)
+		}
+
+		cnt, err := repo.Increment(ctx, key, windowStart, windowStart.Add(window))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cnt != 3 {
+			t.Fatalf("want %d attempts, got %d", 3, cnt)
+		}
+	})
+
+	t.Run("increment attempts concurrently must count every attempt exactly once", func(t *testing.T) {
+		concurrentKey := key + "|concurrent"
+		const attempts = 10
+
+		var wg sync.WaitGroup
+		counts := make(chan int, attempts)
+		for i := 0; i < attempts; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				cnt, err := repo.Increment(ctx, concurrentKey, windowStart, windowStart.Add(window))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				counts <- cnt
+			}()
+		}
+		wg.Wait()
+		close(counts)
+
+		seen := make(map[int]bool)
+		for cnt := range counts {
+			if seen[cnt] {
+				t.Fatalf("want unique attempt counts, got %d more than once", cnt)
+			}
+			seen[cnt] = true
+		}
+		if len(seen) != attempts {
+			t.Fatalf("want %d attempt counts, got %d", attempts, len(seen))
+		}
+	})
+
+	t.Run("increment attempts in a later window must start afresh and discard expired windows", func(t *testing.T) {
+		nextWindowStart := windowStart.Add(2 * window)
+
+		cnt, err := repo.Increment(ctx, key, nextWindowStart, nextWindowStart.Add(window))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cnt != 1 {
+			t.Fatalf("want %d attempts, got %d", 1, cnt)
+		}
+
+		// expired window has been discarded, so counting against it starts afresh too
+		cnt, err = repo.Increment(ctx, key, windowStart, windowStart.Add(window))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cnt != 1 {
+			t.Fatalf("want %d attempts, got %d", 1, cnt)
+		}
+	})
+}
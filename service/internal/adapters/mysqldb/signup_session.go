@@ -0,0 +1,167 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"prediction-league/service/internal/domain"
+)
+
+// signupSessionDBFields defines the fields used regularly in SignupSession-related transactions
+var signupSessionDBFields = []string{
+	"realm_name",
+	"season_id",
+	"entry_id",
+	"step",
+	"created_at",
+	"updated_at",
+	"expires_at",
+}
+
+// SignupSessionRepo defines our DB-backed SignupSession data store
+type SignupSessionRepo struct {
+	db *sql.DB
+}
+
+// Insert inserts a new SignupSession into the database
+func (s *SignupSessionRepo) Insert(ctx context.Context, session *domain.SignupSession) error {
+	stmt := `INSERT INTO signup_session (id, ` + getDBFieldsStringFromFields(signupSessionDBFields) + `)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	if _, err := s.db.ExecContext(
+		ctx,
+		stmt,
+		session.ID,
+		session.RealmName,
+		session.SeasonID,
+		session.EntryID,
+		session.Step,
+		session.CreatedAt,
+		session.UpdatedAt,
+		session.ExpiresAt,
+	); err != nil {
+		return wrapDBError(err)
+	}
+
+	return nil
+}
+
+// Update updates an existing SignupSession in the database
+func (s *SignupSessionRepo) Update(ctx context.Context, session *domain.SignupSession) error {
+	stmt := `UPDATE signup_session
+				SET ` + getDBFieldsWithEqualsPlaceholdersStringFromFields(signupSessionDBFields) + `
+				WHERE id = ?`
+
+	res, err := s.db.ExecContext(
+		ctx,
+		stmt,
+		session.RealmName,
+		session.SeasonID,
+		session.EntryID,
+		session.Step,
+		session.CreatedAt,
+		session.UpdatedAt,
+		session.ExpiresAt,
+		session.ID,
+	)
+	if err != nil {
+		return wrapDBError(err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return wrapDBError(err)
+	}
+
+	if affected == 0 {
+		return domain.MissingDBRecordError{Err: fmt.Errorf("signup session id %s: not found", session.ID)}
+	}
+
+	return nil
+}
+
+// Select retrieves SignupSessions from our database based on the provided criteria
+func (s *SignupSessionRepo) Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]domain.SignupSession, error) {
+	whereStmt, params := dbWhereStmt(criteria, matchAny)
+
+	stmt := `SELECT id, ` + getDBFieldsStringFromFields(signupSessionDBFields) + ` FROM signup_session ` + whereStmt
+
+	rows, err := s.db.QueryContext(ctx, stmt, params...)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	var sessions []domain.SignupSession
+
+	for rows.Next() {
+		session := domain.SignupSession{}
+
+		if err := rows.Scan(
+			&session.ID,
+			&session.RealmName,
+			&session.SeasonID,
+			&session.EntryID,
+			&session.Step,
+			&session.CreatedAt,
+			&session.UpdatedAt,
+			&session.ExpiresAt,
+		); err != nil {
+			return nil, wrapDBError(err)
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	if len(sessions) == 0 {
+		return nil, domain.MissingDBRecordError{Err: errors.New("no signup sessions found")}
+	}
+
+	return sessions, nil
+}
+
+// ExistsByID determines whether a SignupSession with the provided ID exists in the database
+func (s *SignupSessionRepo) ExistsByID(ctx context.Context, id string) error {
+	stmt := `SELECT COUNT(*) FROM signup_session WHERE id = ?`
+
+	row := s.db.QueryRowContext(ctx, stmt, id)
+
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return wrapDBError(err)
+	}
+
+	if count == 0 {
+		return domain.MissingDBRecordError{Err: fmt.Errorf("signup session id %s: not found", id)}
+	}
+
+	return nil
+}
+
+// GenerateUniqueSignupSessionID returns a string representing a unique SignupSession ID
+func (s *SignupSessionRepo) GenerateUniqueSignupSessionID(ctx context.Context) (string, error) {
+	id, err := generateAlphaNumericString(domain.TokenLength)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.ExistsByID(ctx, id); err != nil {
+		switch err.(type) {
+		case domain.MissingDBRecordError:
+			return id, nil
+		default:
+			return "", err
+		}
+	}
+
+	return s.GenerateUniqueSignupSessionID(ctx)
+}
+
+// NewSignupSessionRepo instantiates a new SignupSessionRepo with the provided DB agent
+func NewSignupSessionRepo(db *sql.DB) (*SignupSessionRepo, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db: %w", domain.ErrIsNil)
+	}
+	return &SignupSessionRepo{db: db}, nil
+}
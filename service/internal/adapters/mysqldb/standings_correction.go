@@ -0,0 +1,113 @@
+package mysqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"prediction-league/service/internal/domain"
+)
+
+// standingsCorrectionDBFields defines the fields used regularly in StandingsCorrection-related transactions
+var standingsCorrectionDBFields = []string{
+	"standings_id",
+	"season_id",
+	"round_number",
+	"previous_rankings",
+	"corrected_rankings",
+	"created_at",
+}
+
+// StandingsCorrectionRepo defines our DB-backed StandingsCorrection data store
+type StandingsCorrectionRepo struct {
+	db *sql.DB
+}
+
+// Insert inserts a new StandingsCorrection into the database
+func (s *StandingsCorrectionRepo) Insert(ctx context.Context, correction *domain.StandingsCorrection) error {
+	stmt := `INSERT INTO standings_correction (id, ` + getDBFieldsStringFromFields(standingsCorrectionDBFields) + `)
+					VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	previous, err := json.Marshal(&correction.PreviousRankings)
+	if err != nil {
+		return err
+	}
+
+	corrected, err := json.Marshal(&correction.CorrectedRankings)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(
+		ctx,
+		stmt,
+		correction.ID,
+		correction.StandingsID,
+		correction.SeasonID,
+		correction.RoundNumber,
+		previous,
+		corrected,
+		correction.CreatedAt,
+	); err != nil {
+		return wrapDBError(err)
+	}
+
+	return nil
+}
+
+// Select retrieves StandingsCorrections from our database based on the provided criteria
+func (s *StandingsCorrectionRepo) Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]domain.StandingsCorrection, error) {
+	whereStmt, params := dbWhereStmt(criteria, matchAny)
+
+	stmt := `SELECT id, ` + getDBFieldsStringFromFields(standingsCorrectionDBFields) + ` FROM standings_correction ` + whereStmt
+
+	rows, err := s.db.QueryContext(ctx, stmt, params...)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	var corrections []domain.StandingsCorrection
+	var previous, corrected []byte
+
+	for rows.Next() {
+		correction := domain.StandingsCorrection{}
+
+		if err := rows.Scan(
+			&correction.ID,
+			&correction.StandingsID,
+			&correction.SeasonID,
+			&correction.RoundNumber,
+			&previous,
+			&corrected,
+			&correction.CreatedAt,
+		); err != nil {
+			return nil, wrapDBError(err)
+		}
+
+		if err := json.Unmarshal(previous, &correction.PreviousRankings); err != nil {
+			return nil, wrapDBError(err)
+		}
+
+		if err := json.Unmarshal(corrected, &correction.CorrectedRankings); err != nil {
+			return nil, wrapDBError(err)
+		}
+
+		corrections = append(corrections, correction)
+	}
+
+	if len(corrections) == 0 {
+		return nil, domain.MissingDBRecordError{Err: errors.New("no standings corrections found")}
+	}
+
+	return corrections, nil
+}
+
+// NewStandingsCorrectionRepo instantiates a new StandingsCorrectionRepo with the provided DB agent
+func NewStandingsCorrectionRepo(db *sql.DB) (*StandingsCorrectionRepo, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db: %w", domain.ErrIsNil)
+	}
+	return &StandingsCorrectionRepo{db: db}, nil
+}
@@ -0,0 +1,133 @@
+package mysqldb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/net/context"
+	"prediction-league/service/internal/domain"
+)
+
+// standingsQuarantineDBFields defines the fields used regularly in QuarantinedStandings-related transactions
+var standingsQuarantineDBFields = []string{
+	"season_id",
+	"round_number",
+	"rankings",
+	"anomalies",
+	"status",
+	"created_at",
+	"reviewed_at",
+}
+
+// StandingsQuarantineRepo defines our DB-backed QuarantinedStandings data store
+type StandingsQuarantineRepo struct {
+	db *sql.DB
+}
+
+// Insert inserts a new QuarantinedStandings into the database
+func (s *StandingsQuarantineRepo) Insert(ctx context.Context, qs *domain.QuarantinedStandings) error {
+	stmt := `INSERT INTO standings_quarantine (id, ` + getDBFieldsStringFromFields(standingsQuarantineDBFields) + `)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	rankings, err := json.Marshal(&qs.Rankings)
+	if err != nil {
+		return err
+	}
+
+	anomalies, err := json.Marshal(&qs.Anomalies)
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		stmt,
+		qs.ID,
+		qs.SeasonID,
+		qs.RoundNumber,
+		rankings,
+		anomalies,
+		qs.Status,
+		qs.CreatedAt,
+		qs.ReviewedAt,
+	)
+	if err != nil {
+		return wrapDBError(err)
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+// Update updates the review status of an existing QuarantinedStandings in the database
+func (s *StandingsQuarantineRepo) Update(ctx context.Context, qs *domain.QuarantinedStandings) error {
+	stmt := `UPDATE standings_quarantine
+				SET status = ?, reviewed_at = ?
+				WHERE id = ?`
+
+	rows, err := s.db.QueryContext(ctx, stmt, qs.Status, qs.ReviewedAt, qs.ID)
+	if err != nil {
+		return wrapDBError(err)
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+// Select retrieves QuarantinedStandings from our database based on the provided criteria
+func (s *StandingsQuarantineRepo) Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]domain.QuarantinedStandings, error) {
+	whereStmt, params := dbWhereStmt(criteria, matchAny)
+
+	stmt := `SELECT id, ` + getDBFieldsStringFromFields(standingsQuarantineDBFields) + ` FROM standings_quarantine ` + whereStmt
+
+	rows, err := s.db.QueryContext(ctx, stmt, params...)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	var retrieved []domain.QuarantinedStandings
+	var rankings, anomalies []byte
+
+	for rows.Next() {
+		qs := domain.QuarantinedStandings{}
+
+		if err := rows.Scan(
+			&qs.ID,
+			&qs.SeasonID,
+			&qs.RoundNumber,
+			&rankings,
+			&anomalies,
+			&qs.Status,
+			&qs.CreatedAt,
+			&qs.ReviewedAt,
+		); err != nil {
+			return nil, wrapDBError(err)
+		}
+
+		if err := json.Unmarshal(rankings, &qs.Rankings); err != nil {
+			return nil, wrapDBError(err)
+		}
+
+		if err := json.Unmarshal(anomalies, &qs.Anomalies); err != nil {
+			return nil, wrapDBError(err)
+		}
+
+		retrieved = append(retrieved, qs)
+	}
+
+	if len(retrieved) == 0 {
+		return nil, domain.MissingDBRecordError{Err: errors.New("no quarantined standings found")}
+	}
+
+	return retrieved, nil
+}
+
+// NewStandingsQuarantineRepo instantiates a new StandingsQuarantineRepo with the provided DB agent
+func NewStandingsQuarantineRepo(db *sql.DB) (*StandingsQuarantineRepo, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db: %w", domain.ErrIsNil)
+	}
+	return &StandingsQuarantineRepo{db: db}, nil
+}
@@ -0,0 +1,103 @@
+package mysqldb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/net/context"
+	"prediction-league/service/internal/domain"
+)
+
+// standingsSnapshotDBFields defines the fields used regularly in StandingsSnapshot-related transactions
+var standingsSnapshotDBFields = []string{
+	"standings_id",
+	"season_id",
+	"round_number",
+	"rankings",
+	"created_at",
+}
+
+// StandingsSnapshotRepo defines our DB-backed StandingsSnapshot data store
+type StandingsSnapshotRepo struct {
+	db *sql.DB
+}
+
+// Insert inserts a new StandingsSnapshot into the database
+func (s *StandingsSnapshotRepo) Insert(ctx context.Context, snapshot *domain.StandingsSnapshot) error {
+	stmt := `INSERT INTO standings_snapshot (id, ` + getDBFieldsStringFromFields(standingsSnapshotDBFields) + `)
+					VALUES (?, ?, ?, ?, ?, ?)`
+
+	rankings, err := json.Marshal(&snapshot.Rankings)
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		stmt,
+		snapshot.ID,
+		snapshot.StandingsID,
+		snapshot.SeasonID,
+		snapshot.RoundNumber,
+		rankings,
+		snapshot.CreatedAt,
+	)
+	if err != nil {
+		return wrapDBError(err)
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+// Select retrieves StandingsSnapshots from our database based on the provided criteria
+func (s *StandingsSnapshotRepo) Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]domain.StandingsSnapshot, error) {
+	whereStmt, params := dbWhereStmt(criteria, matchAny)
+
+	stmt := `SELECT id, ` + getDBFieldsStringFromFields(standingsSnapshotDBFields) + ` FROM standings_snapshot ` + whereStmt + ` ORDER BY created_at ASC`
+
+	rows, err := s.db.QueryContext(ctx, stmt, params...)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	var snapshots []domain.StandingsSnapshot
+	var rankings []byte
+
+	for rows.Next() {
+		snapshot := domain.StandingsSnapshot{}
+
+		if err := rows.Scan(
+			&snapshot.ID,
+			&snapshot.StandingsID,
+			&snapshot.SeasonID,
+			&snapshot.RoundNumber,
+			&rankings,
+			&snapshot.CreatedAt,
+		); err != nil {
+			return nil, wrapDBError(err)
+		}
+
+		if err := json.Unmarshal(rankings, &snapshot.Rankings); err != nil {
+			return nil, wrapDBError(err)
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if len(snapshots) == 0 {
+		return nil, domain.MissingDBRecordError{Err: errors.New("no standings snapshots found")}
+	}
+
+	return snapshots, nil
+}
+
+// NewStandingsSnapshotRepo instantiates a new StandingsSnapshotRepo with the provided DB agent
+func NewStandingsSnapshotRepo(db *sql.DB) (*StandingsSnapshotRepo, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db: %w", domain.ErrIsNil)
+	}
+	return &StandingsSnapshotRepo{db: db}, nil
+}
@@ -16,6 +16,7 @@ var tokenDBFields = []string{
 	"issued_at",
 	"redeemed_at",
 	"expires_at",
+	"attempts",
 }
 
 // TokenRepo defines our DB-backed Token data store
@@ -26,7 +27,7 @@ type TokenRepo struct {
 // Insert inserts a new Token into the database
 func (t *TokenRepo) Insert(ctx context.Context, token *domain.Token) error {
 	stmt := `INSERT INTO token (id, ` + getDBFieldsStringFromFields(tokenDBFields) + `)
-					VALUES (?, ?, ?, ?, ?, ?)`
+					VALUES (?, ?, ?, ?, ?, ?, ?)`
 
 	rows, err := t.db.QueryContext(
 		ctx,
@@ -37,6 +38,7 @@ func (t *TokenRepo) Insert(ctx context.Context, token *domain.Token) error {
 		token.IssuedAt,
 		token.RedeemedAt,
 		token.ExpiresAt,
+		token.Attempts,
 	)
 	if err != nil {
 		return wrapDBError(err)
@@ -69,6 +71,7 @@ func (t *TokenRepo) Select(ctx context.Context, criteria map[string]interface{},
 			&token.IssuedAt,
 			&token.RedeemedAt,
 			&token.ExpiresAt,
+			&token.Attempts,
 		); err != nil {
 			return nil, wrapDBError(err)
 		}
@@ -96,6 +99,7 @@ func (t *TokenRepo) Update(ctx context.Context, token *domain.Token) error {
 		token.IssuedAt,
 		token.RedeemedAt,
 		token.ExpiresAt,
+		token.Attempts,
 		token.ID,
 	)
 	if err != nil {
@@ -109,6 +113,62 @@ func (t *TokenRepo) Update(ctx context.Context, token *domain.Token) error {
 	return nil
 }
 
+// Redeem writes the redemption and expiry timestamps of the provided Token, as long as it has not already been redeemed.
+// A domain.MissingDBRecordError is returned if no such unredeemed Token exists, so that of several concurrent attempts to
+// redeem the same Token, only one ever succeeds
+func (t *TokenRepo) Redeem(ctx context.Context, token *domain.Token) error {
+	stmt := `UPDATE token
+			SET redeemed_at = ?, expires_at = ?
+			WHERE id = ? AND redeemed_at IS NULL`
+
+	res, err := t.db.ExecContext(
+		ctx,
+		stmt,
+		token.RedeemedAt,
+		token.ExpiresAt,
+		token.ID,
+	)
+	if err != nil {
+		return wrapDBError(err)
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return wrapDBError(err)
+	}
+
+	if cnt == 0 {
+		return domain.MissingDBRecordError{Err: errors.New("unredeemed token does not exist")}
+	}
+
+	return nil
+}
+
+// IncrementAttempts counts an attempt against the Token with the provided ID, as long as fewer than the provided maximum
+// number of attempts have been counted against it already. A domain.MissingDBRecordError is returned if no such Token
+// exists, so that concurrent attempts can never be counted beyond the maximum
+func (t *TokenRepo) IncrementAttempts(ctx context.Context, id string, maxAttempts int) error {
+	stmt := `UPDATE token
+			SET attempts = attempts + 1
+			WHERE id = ? AND attempts < ?`
+
+	res, err := t.db.ExecContext(ctx, stmt, id, maxAttempts)
+	if err != nil {
+		return wrapDBError(err)
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return wrapDBError(err)
+	}
+
+	if cnt == 0 {
+		return domain.MissingDBRecordError{Err: errors.New("token with remaining attempts does not exist")}
+	}
+
+	return nil
+}
+
 // Delete deletes Tokens from our database based on the provided criteria
 func (t *TokenRepo) Delete(ctx context.Context, criteria map[string]interface{}, matchAny bool) (int64, error) {
 	whereStmt, params := dbWhereStmt(criteria, matchAny)
@@ -146,22 +206,6 @@ func (t *TokenRepo) ExistsByID(ctx context.Context, id string) error {
 	return nil
 }
 
-// GenerateUniqueTokenID returns a string representing a unique token ID
-func (t *TokenRepo) GenerateUniqueTokenID(ctx context.Context) (string, error) {
-	id := generateAlphaNumericString(domain.TokenLength)
-
-	if err := t.ExistsByID(ctx, id); err != nil {
-		switch err.(type) {
-		case domain.MissingDBRecordError:
-			return id, nil
-		default:
-			return "", err
-		}
-	}
-
-	return t.GenerateUniqueTokenID(ctx)
-}
-
 // NewTokenRepo instantiates a new TokenRepo with the provided DB agent
 func NewTokenRepo(db *sql.DB) (*TokenRepo, error) {
 	if db == nil {
@@ -0,0 +1,590 @@
+package paypal
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"prediction-league/service/internal/adapters"
+	"prediction-league/service/internal/domain"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// LiveBaseURL is the base url of PayPal's live REST API
+	LiveBaseURL = "https://api-m.paypal.com"
+	// SandboxBaseURL is the base url of PayPal's sandbox REST API
+	SandboxBaseURL = "https://api-m.sandbox.paypal.com"
+
+	orderStatusCompleted   = "COMPLETED"
+	captureStatusCompleted = "COMPLETED"
+
+	webhookAuthAlgo = "SHA256withRSA"
+)
+
+// webhookCertHostnames are the hostnames that PayPal issues the certificates it signs webhook events with for
+var webhookCertHostnames = []string{
+	"messageverificationcerts.paypal.com",
+	"messageverificationcerts.sandbox.paypal.com",
+}
+
+// Client defines our PayPal REST API client
+type Client struct {
+	clientID     string
+	clientSecret string
+	webhookID    string
+	baseURL      string
+	hc           adapters.HTTPClient
+
+	mu             sync.Mutex
+	accessToken    string
+	accessTokenExp time.Time
+	certs          map[string]*x509.Certificate
+	roots          *x509.CertPool // trusted root certificates, or nil to use those of the host
+}
+
+// PaymentMethod implements this method on the domain.PaymentProvider interface
+func (c *Client) PaymentMethod() string {
+	return domain.EntryPaymentMethodPayPal
+}
+
+// CreateOrder implements this method on the domain.PaymentProvider interface
+func (c *Client) CreateOrder(ctx context.Context, entry domain.Entry, fee domain.RealmEntryFee) (domain.PaymentOrder, error) {
+	reqBody := createOrderRequest{
+		Intent: "CAPTURE",
+		PurchaseUnits: []purchaseUnitRequest{
+			{
+				ReferenceID: entry.ID.String(),
+				CustomID:    entry.ID.String(),
+				Description: fmt.Sprintf("Entry fee for %s", entry.EntrantNickname),
+				Amount: amount{
+					CurrencyCode: fee.Currency,
+					Value:        strconv.FormatFloat(float64(fee.Amount), 'f', 2, 32),
+				},
+			},
+		},
+	}
+
+	var orderResp orderResponse
+	if err := c.doJSONRequest(ctx, http.MethodPost, "/v2/checkout/orders", "", reqBody, &orderResp); err != nil {
+		return domain.PaymentOrder{}, fmt.Errorf("cannot create order: %w", err)
+	}
+
+	if orderResp.ID == "" {
+		return domain.PaymentOrder{}, errors.New("cannot create order: empty order id")
+	}
+
+	return domain.PaymentOrder{
+		ID:       orderResp.ID,
+		EntryID:  entry.ID.String(),
+		Amount:   fee.Amount,
+		Currency: fee.Currency,
+	}, nil
+}
+
+// RetrieveOrder implements this method on the domain.PaymentProvider interface
+func (c *Client) RetrieveOrder(ctx context.Context, orderID string) (domain.PaymentOrder, error) {
+	path := fmt.Sprintf("/v2/checkout/orders/%s", url.PathEscape(orderID))
+
+	var orderResp orderResponse
+	if err := c.doJSONRequest(ctx, http.MethodGet, path, "", nil, &orderResp); err != nil {
+		return domain.PaymentOrder{}, fmt.Errorf("cannot retrieve order: %w", err)
+	}
+
+	// orders are only ever created with a single purchase unit, so any other order was not raised by us
+	if len(orderResp.PurchaseUnits) != 1 {
+		return domain.PaymentOrder{}, fmt.Errorf("cannot retrieve order: purchase units count other than 1: %d", len(orderResp.PurchaseUnits))
+	}
+	pu := orderResp.PurchaseUnits[0]
+
+	value, err := strconv.ParseFloat(pu.Amount.Value, 32)
+	if err != nil {
+		return domain.PaymentOrder{}, fmt.Errorf("cannot parse order amount '%s': %w", pu.Amount.Value, err)
+	}
+
+	entryID := pu.CustomID
+	if entryID == "" {
+		entryID = pu.ReferenceID
+	}
+
+	return domain.PaymentOrder{
+		ID:       orderResp.ID,
+		EntryID:  entryID,
+		Amount:   float32(value),
+		Currency: pu.Amount.CurrencyCode,
+	}, nil
+}
+
+// CaptureOrder implements this method on the domain.PaymentProvider interface
+func (c *Client) CaptureOrder(ctx context.Context, orderID string) (domain.VerifiedPayment, error) {
+	path := fmt.Sprintf("/v2/checkout/orders/%s/capture", url.PathEscape(orderID))
+
+	// order id doubles as the request id, so that retrying a capture of the same order is idempotent
+	var orderResp orderResponse
+	if err := c.doJSONRequest(ctx, http.MethodPost, path, orderID, nil, &orderResp); err != nil {
+		return domain.VerifiedPayment{}, fmt.Errorf("cannot capture order: %w", err)
+	}
+
+	if orderResp.Status != orderStatusCompleted {
+		return domain.VerifiedPayment{}, fmt.Errorf("cannot capture order: order status is %s", orderResp.Status)
+	}
+
+	return getVerifiedPayment(orderResp)
+}
+
+// VerifyWebhookEvent implements this method on the domain.PaymentProvider interface.
+// The signature of the provided body is verified against the certificate that PayPal used to sign it,
+// see https://developer.paypal.com/api/rest/webhooks/rest/#link-selfverificationmethod
+func (c *Client) VerifyWebhookEvent(ctx context.Context, header map[string][]string, body []byte) (domain.PaymentEvent, error) {
+	if c.webhookID == "" {
+		return domain.PaymentEvent{}, errors.New("webhook id is empty")
+	}
+
+	hdr := http.Header(header)
+
+	if algo := hdr.Get("Paypal-Auth-Algo"); algo != webhookAuthAlgo {
+		return domain.PaymentEvent{}, fmt.Errorf("unsupported auth algo: %s", algo)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(hdr.Get("Paypal-Transmission-Sig"))
+	if err != nil {
+		return domain.PaymentEvent{}, fmt.Errorf("cannot decode transmission signature: %w", err)
+	}
+
+	cert, err := c.getWebhookCert(ctx, hdr.Get("Paypal-Cert-Url"))
+	if err != nil {
+		return domain.PaymentEvent{}, fmt.Errorf("cannot get webhook cert: %w", err)
+	}
+
+	pubKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return domain.PaymentEvent{}, errors.New("webhook cert does not hold an rsa public key")
+	}
+
+	msg := fmt.Sprintf(
+		"%s|%s|%s|%d",
+		hdr.Get("Paypal-Transmission-Id"),
+		hdr.Get("Paypal-Transmission-Time"),
+		c.webhookID,
+		crc32.ChecksumIEEE(body),
+	)
+	digest := sha256.Sum256([]byte(msg))
+
+	if err := rsa.VerifyPKCS1v15(pubKey, crypto.SHA256, digest[:], sig); err != nil {
+		return domain.PaymentEvent{}, fmt.Errorf("invalid transmission signature: %w", err)
+	}
+
+	var evt webhookEvent
+	if err := json.Unmarshal(body, &evt); err != nil {
+		return domain.PaymentEvent{}, fmt.Errorf("cannot unmarshal webhook event: %w", err)
+	}
+
+	if evt.ID == "" {
+		return domain.PaymentEvent{}, errors.New("webhook event id is empty")
+	}
+
+	return evt.toPaymentEvent()
+}
+
+// NewClient generates a new Client
+func NewClient(clientID, clientSecret, webhookID, baseURL string, hc adapters.HTTPClient) (*Client, error) {
+	switch {
+	case clientID == "":
+		return nil, fmt.Errorf("client id: %w", domain.ErrIsEmpty)
+	case clientSecret == "":
+		return nil, fmt.Errorf("client secret: %w", domain.ErrIsEmpty)
+	case hc == nil:
+		return nil, fmt.Errorf("http client: %w", domain.ErrIsNil)
+	}
+
+	if baseURL == "" {
+		baseURL = LiveBaseURL
+	}
+
+	return &Client{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		webhookID:    webhookID,
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		hc:           hc,
+		certs:        make(map[string]*x509.Certificate),
+	}, nil
+}
+
+// doJSONRequest performs an authenticated request to the provided path and unmarshals the response body into the provided destination
+func (c *Client) doJSONRequest(ctx context.Context, method, path, requestID string, body interface{}, dest interface{}) error {
+	token, err := c.getAccessToken(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot get access token: %w", err)
+	}
+
+	var reqBody []byte
+	if body != nil {
+		if reqBody, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("cannot marshal request body: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(reqBody))
+	if err != nil {
+		return fmt.Errorf("cannot generate request: path '%s': %w", path, err)
+	}
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	req.Header.Add("Content-Type", "application/json")
+	if requestID != "" {
+		req.Header.Add("PayPal-Request-Id", requestID)
+	}
+
+	respBody, err := c.do(req)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(respBody, dest); err != nil {
+		return fmt.Errorf("cannot unmarshal response: %w", err)
+	}
+
+	return nil
+}
+
+// getAccessToken returns a cached access token, or requests a new one if the cached token has expired
+func (c *Client) getAccessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.accessToken != "" && time.Now().Before(c.accessTokenExp) {
+		return c.accessToken, nil
+	}
+
+	form := url.Values{"grant_type": []string{"client_credentials"}}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/oauth2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("cannot generate request: %w", err)
+	}
+
+	req.SetBasicAuth(c.clientID, c.clientSecret)
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	respBody, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+
+	var tokenResp accessTokenResponse
+	if err := json.Unmarshal(respBody, &tokenResp); err != nil {
+		return "", fmt.Errorf("cannot unmarshal response: %w", err)
+	}
+
+	if tokenResp.AccessToken == "" {
+		return "", errors.New("empty access token")
+	}
+
+	// treat token as expired slightly early, so that it cannot expire mid-request
+	c.accessToken = tokenResp.AccessToken
+	c.accessTokenExp = time.Now().Add(time.Duration(tokenResp.ExpiresIn)*time.Second - time.Minute)
+
+	return c.accessToken, nil
+}
+
+// getWebhookCert returns the certificate found at the provided url, which must be served by PayPal
+// and chain up to a trusted root certificate. Certificates are cached by url, but are only returned whilst they remain valid
+func (c *Client) getWebhookCert(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	u, err := url.Parse(certURL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse cert url: %w", err)
+	}
+
+	// only trust certificates that are served by paypal over tls, otherwise anyone could sign a payload with their own
+	if u.Scheme != "https" || !strings.HasSuffix(u.Hostname(), ".paypal.com") {
+		return nil, fmt.Errorf("untrusted cert url: %s", certURL)
+	}
+
+	c.mu.Lock()
+	cert, ok := c.certs[certURL]
+	c.mu.Unlock()
+
+	if !ok {
+		// the lock is not held while the cert is fetched, so that a slow fetch does not hold up every other request.
+		// concurrent fetches of the same url are harmless, since they all produce the same cert
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, certURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("cannot generate request: %w", err)
+		}
+
+		body, err := c.do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		chain, err := parseCertChain(body)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := c.verifyWebhookCertChain(chain); err != nil {
+			return nil, err
+		}
+
+		cert = chain[0]
+
+		c.mu.Lock()
+		c.certs[certURL] = cert
+		c.mu.Unlock()
+	}
+
+	now := time.Now()
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return nil, errors.New("cert is not currently valid")
+	}
+
+	return cert, nil
+}
+
+// verifyWebhookCertChain ensures that the first certificate of the provided chain chains up to a trusted root certificate,
+// via the rest of the chain, and was issued to PayPal for signing webhook events
+func (c *Client) verifyWebhookCertChain(chain []*x509.Certificate) error {
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	leaf := chain[0]
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         c.roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return fmt.Errorf("cannot verify cert chain: %w", err)
+	}
+
+	for _, hostname := range webhookCertHostnames {
+		if leaf.VerifyHostname(hostname) == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("cert was not issued for paypal webhooks: %s", leaf.Subject.CommonName)
+}
+
+// parseCertChain parses each of the pem-encoded certificates found in the provided body, in the order they appear
+func parseCertChain(body []byte) ([]*x509.Certificate, error) {
+	var chain []*x509.Certificate
+
+	for {
+		var block *pem.Block
+		if block, body = pem.Decode(body); block == nil {
+			break
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse cert: %w", err)
+		}
+
+		chain = append(chain, cert)
+	}
+
+	if len(chain) == 0 {
+		return nil, errors.New("cannot decode pem block")
+	}
+
+	return chain, nil
+}
+
+// do executes the provided request and returns the response body, providing the response status code is successful
+func (c *Client) do(req *http.Request) ([]byte, error) {
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get response: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected response status code %d: %s", resp.StatusCode, string(body))
+	}
+
+	return body, nil
+}
+
+// accessTokenResponse defines the expected payload structure of the request to generate an access token
+type accessTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// createOrderRequest defines the payload structure of the request to create an order
+type createOrderRequest struct {
+	Intent        string                `json:"intent"`
+	PurchaseUnits []purchaseUnitRequest `json:"purchase_units"`
+}
+
+// purchaseUnitRequest defines the nested payload structure of a purchase unit within the request to create an order
+type purchaseUnitRequest struct {
+	ReferenceID string `json:"reference_id"`
+	CustomID    string `json:"custom_id"`
+	Description string `json:"description"`
+	Amount      amount `json:"amount"`
+}
+
+// amount defines the nested payload structure of a monetary amount
+type amount struct {
+	CurrencyCode string `json:"currency_code"`
+	Value        string `json:"value"`
+}
+
+// orderResponse defines the expected payload structure of the requests to create, retrieve and capture an order
+type orderResponse struct {
+	ID            string                 `json:"id"`
+	Status        string                 `json:"status"`
+	PurchaseUnits []purchaseUnitResponse `json:"purchase_units"`
+}
+
+// purchaseUnitResponse defines the nested payload structure of a purchase unit within an order response
+type purchaseUnitResponse struct {
+	ReferenceID    string `json:"reference_id"`
+	CustomID       string `json:"custom_id"`
+	SoftDescriptor string `json:"soft_descriptor"`
+	Amount         amount `json:"amount"`
+	Payments       struct {
+		Captures []capture `json:"captures"`
+	} `json:"payments"`
+}
+
+// capture defines the nested payload structure of a captured payment within a purchase unit
+type capture struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"`
+	CustomID string `json:"custom_id"`
+	Amount   amount `json:"amount"`
+}
+
+// getVerifiedPayment returns the first completed capture within the provided order response as a domain.VerifiedPayment
+func getVerifiedPayment(resp orderResponse) (domain.VerifiedPayment, error) {
+	for _, pu := range resp.PurchaseUnits {
+		for _, cpt := range pu.Payments.Captures {
+			if cpt.Status != captureStatusCompleted {
+				continue
+			}
+
+			value, err := strconv.ParseFloat(cpt.Amount.Value, 32)
+			if err != nil {
+				return domain.VerifiedPayment{}, fmt.Errorf("cannot parse capture amount '%s': %w", cpt.Amount.Value, err)
+			}
+
+			entryID := cpt.CustomID
+			if entryID == "" {
+				entryID = pu.ReferenceID
+			}
+
+			return domain.VerifiedPayment{
+				OrderID:      resp.ID,
+				EntryID:      entryID,
+				Reference:    cpt.ID,
+				Amount:       float32(value),
+				Currency:     cpt.Amount.CurrencyCode,
+				MerchantName: pu.SoftDescriptor,
+			}, nil
+		}
+	}
+
+	return domain.VerifiedPayment{}, errors.New("no completed captures found")
+}
+
+// webhookEvent defines the expected payload structure of a webhook event
+type webhookEvent struct {
+	ID        string          `json:"id"`
+	EventType string          `json:"event_type"`
+	Resource  webhookResource `json:"resource"`
+}
+
+// webhookResource defines the nested payload structure of the resource that a webhook event refers to.
+// This is a capture, refund or dispute, depending on the event type
+type webhookResource struct {
+	ID                   string `json:"id"`
+	CustomID             string `json:"custom_id"`
+	Amount               amount `json:"amount"`
+	Links                []link `json:"links"`
+	DisputedTransactions []struct {
+		SellerTransactionID string `json:"seller_transaction_id"`
+	} `json:"disputed_transactions"`
+	DisputeOutcome struct {
+		OutcomeCode string `json:"outcome_code"`
+	} `json:"dispute_outcome"`
+}
+
+// link defines the nested payload structure of a HATEOAS link
+type link struct {
+	Href string `json:"href"`
+	Rel  string `json:"rel"`
+}
+
+// toPaymentEvent transforms a webhookEvent object to a more abstracted domain.PaymentEvent object
+func (w *webhookEvent) toPaymentEvent() (domain.PaymentEvent, error) {
+	event := domain.PaymentEvent{
+		ID:                w.ID,
+		Type:              domain.PaymentEventTypeUnhandled,
+		ProviderEventType: w.EventType,
+		EntryID:           w.Resource.CustomID,
+	}
+
+	switch w.EventType {
+	case "PAYMENT.CAPTURE.COMPLETED":
+		value, err := strconv.ParseFloat(w.Resource.Amount.Value, 32)
+		if err != nil {
+			return domain.PaymentEvent{}, fmt.Errorf("cannot parse capture amount '%s': %w", w.Resource.Amount.Value, err)
+		}
+
+		event.Type = domain.PaymentEventTypeCaptureCompleted
+		event.PaymentRef = w.Resource.ID
+		event.Amount = float32(value)
+		event.Currency = w.Resource.Amount.CurrencyCode
+
+	case "PAYMENT.CAPTURE.REFUNDED", "PAYMENT.CAPTURE.REVERSED":
+		// resource is the refund, which links "up" to the capture that it refunds
+		event.Type = domain.PaymentEventTypeCaptureRefunded
+		for _, l := range w.Resource.Links {
+			if l.Rel == "up" {
+				event.PaymentRef = l.Href[strings.LastIndex(l.Href, "/")+1:]
+			}
+		}
+
+	case "CUSTOMER.DISPUTE.CREATED", "CUSTOMER.DISPUTE.RESOLVED":
+		// disputes carry no custom id, so the entry is identified by the capture that is being disputed
+		event.EntryID = ""
+		if len(w.Resource.DisputedTransactions) > 0 {
+			event.PaymentRef = w.Resource.DisputedTransactions[0].SellerTransactionID
+		}
+
+		event.Type = domain.PaymentEventTypeDisputeOpened
+		if w.EventType == "CUSTOMER.DISPUTE.RESOLVED" {
+			event.Type = domain.PaymentEventTypeDisputeWon
+			switch w.Resource.DisputeOutcome.OutcomeCode {
+			case "RESOLVED_BUYER_FAVOUR", "RESOLVED_WITH_PAYOUT":
+				event.Type = domain.PaymentEventTypeDisputeLost
+			}
+		}
+	}
+
+	return event, nil
+}
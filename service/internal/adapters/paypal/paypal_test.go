@@ -0,0 +1,741 @@
+package paypal
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"prediction-league/service/internal/adapters"
+	"prediction-league/service/internal/domain"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+func TestNewClient(t *testing.T) {
+	t.Run("passing invalid parameters must return expected error", func(t *testing.T) {
+		hc := &mockHTTPClient{}
+
+		tt := []struct {
+			clientID     string
+			clientSecret string
+			hc           adapters.HTTPClient
+			wantErr      error
+		}{
+			{"", "secret", hc, domain.ErrIsEmpty},
+			{"id", "", hc, domain.ErrIsEmpty},
+			{"id", "secret", nil, domain.ErrIsNil},
+			{"id", "secret", hc, nil},
+		}
+		for idx, tc := range tt {
+			ppCl, gotErr := NewClient(tc.clientID, tc.clientSecret, "", "", tc.hc)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("tc #%d: want error %s (%T), got %s (%T)", idx, tc.wantErr, tc.wantErr, gotErr, gotErr)
+			}
+			if tc.wantErr == nil && ppCl == nil {
+				t.Fatalf("tc #%d: want non-empty client, got nil", idx)
+			}
+		}
+	})
+
+	t.Run("empty base url must default to live api", func(t *testing.T) {
+		ppCl, err := NewClient("id", "secret", "", "", &mockHTTPClient{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ppCl.baseURL != LiveBaseURL {
+			t.Fatalf("want base url %s, got %s", LiveBaseURL, ppCl.baseURL)
+		}
+	})
+}
+
+func TestClient_CreateOrder(t *testing.T) {
+	entry := domain.Entry{
+		ID:              uuid.MustParse("11111111-2222-3333-4444-555555555555"),
+		EntrantNickname: "MrHarryR",
+	}
+	fee := domain.RealmEntryFee{Amount: 12.34, Currency: "GBP"}
+
+	t.Run("happy path must produce the expected order", func(t *testing.T) {
+		hc := newMockPayPalHTTPClient(t, func(req *http.Request) (int, string) {
+			wantURL := SandboxBaseURL + "/v2/checkout/orders"
+			if diff := cmp.Diff(wantURL, req.URL.String()); diff != "" {
+				t.Fatalf("want request url '%s', got '%s', diff: %s", wantURL, req.URL.String(), diff)
+			}
+
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			wantBody := `{"intent":"CAPTURE","purchase_units":[{"reference_id":"11111111-2222-3333-4444-555555555555","custom_id":"11111111-2222-3333-4444-555555555555","description":"Entry fee for MrHarryR","amount":{"currency_code":"GBP","value":"12.34"}}]}`
+			if diff := cmp.Diff(wantBody, string(body)); diff != "" {
+				t.Fatalf("want request body '%s', got '%s', diff: %s", wantBody, string(body), diff)
+			}
+
+			return http.StatusCreated, `{"id": "ORDER123", "status": "CREATED"}`
+		})
+
+		cl, err := NewClient("id", "secret", "", SandboxBaseURL, hc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantOrder := domain.PaymentOrder{
+			ID:       "ORDER123",
+			EntryID:  entry.ID.String(),
+			Amount:   12.34,
+			Currency: "GBP",
+		}
+
+		gotOrder, err := cl.CreateOrder(context.Background(), entry, fee)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(wantOrder, gotOrder); diff != "" {
+			t.Fatalf("want order %+v, got %+v, diff: %s", wantOrder, gotOrder, diff)
+		}
+	})
+
+	t.Run("unsuccessful response status must return expected error", func(t *testing.T) {
+		hc := newMockPayPalHTTPClient(t, func(req *http.Request) (int, string) {
+			return http.StatusUnprocessableEntity, `{"name": "UNPROCESSABLE_ENTITY"}`
+		})
+
+		cl, err := NewClient("id", "secret", "", SandboxBaseURL, hc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantErrMsg := `cannot create order: unexpected response status code 422: {"name": "UNPROCESSABLE_ENTITY"}`
+		_, gotErr := cl.CreateOrder(context.Background(), entry, fee)
+		if gotErr == nil || gotErr.Error() != wantErrMsg {
+			t.Fatalf("want error msg %s, got %+v (%T)", wantErrMsg, gotErr, gotErr)
+		}
+	})
+}
+
+func TestClient_RetrieveOrder(t *testing.T) {
+	t.Run("happy path must produce the expected order", func(t *testing.T) {
+		hc := newMockPayPalHTTPClient(t, func(req *http.Request) (int, string) {
+			wantURL := SandboxBaseURL + "/v2/checkout/orders/ORDER123"
+			if diff := cmp.Diff(wantURL, req.URL.String()); diff != "" {
+				t.Fatalf("want request url '%s', got '%s', diff: %s", wantURL, req.URL.String(), diff)
+			}
+
+			if req.Method != http.MethodGet {
+				t.Fatalf("want request method %s, got %s", http.MethodGet, req.Method)
+			}
+
+			return http.StatusOK, `{
+				"id": "ORDER123",
+				"status": "APPROVED",
+				"purchase_units": [
+					{
+						"reference_id": "11111111-2222-3333-4444-555555555555",
+						"custom_id": "11111111-2222-3333-4444-555555555555",
+						"amount": {"currency_code": "GBP", "value": "12.34"}
+					}
+				]
+			}`
+		})
+
+		cl, err := NewClient("id", "secret", "", SandboxBaseURL, hc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantOrder := domain.PaymentOrder{
+			ID:       "ORDER123",
+			EntryID:  "11111111-2222-3333-4444-555555555555",
+			Amount:   12.34,
+			Currency: "GBP",
+		}
+
+		gotOrder, err := cl.RetrieveOrder(context.Background(), "ORDER123")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(wantOrder, gotOrder); diff != "" {
+			t.Fatalf("want order %+v, got %+v, diff: %s", wantOrder, gotOrder, diff)
+		}
+	})
+
+	t.Run("order with more than one purchase unit must return expected error", func(t *testing.T) {
+		hc := newMockPayPalHTTPClient(t, func(req *http.Request) (int, string) {
+			return http.StatusOK, `{
+				"id": "ORDER123",
+				"status": "APPROVED",
+				"purchase_units": [
+					{"custom_id": "11111111-2222-3333-4444-555555555555", "amount": {"currency_code": "GBP", "value": "12.34"}},
+					{"custom_id": "11111111-2222-3333-4444-555555555555", "amount": {"currency_code": "GBP", "value": "12.34"}}
+				]
+			}`
+		})
+
+		cl, err := NewClient("id", "secret", "", SandboxBaseURL, hc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantErrMsg := "cannot retrieve order: purchase units count other than 1: 2"
+		_, gotErr := cl.RetrieveOrder(context.Background(), "ORDER123")
+		if gotErr == nil || gotErr.Error() != wantErrMsg {
+			t.Fatalf("want error msg %s, got %+v (%T)", wantErrMsg, gotErr, gotErr)
+		}
+	})
+}
+
+func TestClient_CaptureOrder(t *testing.T) {
+	t.Run("happy path must produce the expected verified payment", func(t *testing.T) {
+		hc := newMockPayPalHTTPClient(t, func(req *http.Request) (int, string) {
+			wantURL := SandboxBaseURL + "/v2/checkout/orders/ORDER123/capture"
+			if diff := cmp.Diff(wantURL, req.URL.String()); diff != "" {
+				t.Fatalf("want request url '%s', got '%s', diff: %s", wantURL, req.URL.String(), diff)
+			}
+
+			if req.Header.Get("PayPal-Request-Id") != "ORDER123" {
+				t.Fatalf("want request id header 'ORDER123', got '%s'", req.Header.Get("PayPal-Request-Id"))
+			}
+
+			return http.StatusCreated, `{
+				"id": "ORDER123",
+				"status": "COMPLETED",
+				"purchase_units": [
+					{
+						"reference_id": "11111111-2222-3333-4444-555555555555",
+						"soft_descriptor": "PAYPAL *MRHARRYR",
+						"payments": {
+							"captures": [
+								{
+									"id": "CAPTURE_DECLINED",
+									"status": "DECLINED",
+									"amount": {"currency_code": "GBP", "value": "12.34"}
+								},
+								{
+									"id": "CAPTURE456",
+									"status": "COMPLETED",
+									"custom_id": "11111111-2222-3333-4444-555555555555",
+									"amount": {"currency_code": "GBP", "value": "12.34"}
+								}
+							]
+						}
+					}
+				]
+			}`
+		})
+
+		cl, err := NewClient("id", "secret", "", SandboxBaseURL, hc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantPayment := domain.VerifiedPayment{
+			OrderID:      "ORDER123",
+			EntryID:      "11111111-2222-3333-4444-555555555555",
+			Reference:    "CAPTURE456",
+			Amount:       12.34,
+			Currency:     "GBP",
+			MerchantName: "PAYPAL *MRHARRYR",
+		}
+
+		gotPayment, err := cl.CaptureOrder(context.Background(), "ORDER123")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(wantPayment, gotPayment); diff != "" {
+			t.Fatalf("want payment %+v, got %+v, diff: %s", wantPayment, gotPayment, diff)
+		}
+	})
+
+	t.Run("order that has not completed must return expected error", func(t *testing.T) {
+		hc := newMockPayPalHTTPClient(t, func(req *http.Request) (int, string) {
+			return http.StatusOK, `{"id": "ORDER123", "status": "PAYER_ACTION_REQUIRED"}`
+		})
+
+		cl, err := NewClient("id", "secret", "", SandboxBaseURL, hc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantErrMsg := "cannot capture order: order status is PAYER_ACTION_REQUIRED"
+		_, gotErr := cl.CaptureOrder(context.Background(), "ORDER123")
+		if gotErr == nil || gotErr.Error() != wantErrMsg {
+			t.Fatalf("want error msg %s, got %+v (%T)", wantErrMsg, gotErr, gotErr)
+		}
+	})
+
+	t.Run("failed call to http client must return expected error", func(t *testing.T) {
+		hc := &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("sad times :'(")
+		}}
+
+		cl, err := NewClient("id", "secret", "", SandboxBaseURL, hc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantErrMsg := "cannot capture order: cannot get access token: cannot get response: sad times :'("
+		_, gotErr := cl.CaptureOrder(context.Background(), "ORDER123")
+		if gotErr == nil || gotErr.Error() != wantErrMsg {
+			t.Fatalf("want error msg %s, got %+v (%T)", wantErrMsg, gotErr, gotErr)
+		}
+	})
+}
+
+func TestClient_getAccessToken(t *testing.T) {
+	t.Run("access token must be requested once and then cached", func(t *testing.T) {
+		var tokenRequests int
+
+		hc := &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+			tokenRequests++
+
+			user, pass, ok := req.BasicAuth()
+			if !ok || user != "id" || pass != "secret" {
+				t.Fatalf("want basic auth 'id:secret', got '%s:%s'", user, pass)
+			}
+
+			return newMockResponse(http.StatusOK, `{"access_token": "ACCESS_TOKEN", "expires_in": 32400}`), nil
+		}}
+
+		cl, err := NewClient("id", "secret", "", SandboxBaseURL, hc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 2; i++ {
+			token, err := cl.getAccessToken(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token != "ACCESS_TOKEN" {
+				t.Fatalf("want access token 'ACCESS_TOKEN', got '%s'", token)
+			}
+		}
+
+		if tokenRequests != 1 {
+			t.Fatalf("want 1 token request, got %d", tokenRequests)
+		}
+	})
+}
+
+func TestClient_VerifyWebhookEvent(t *testing.T) {
+	certURL := "https://api.sandbox.paypal.com/v1/notifications/certs/CERT-360caa42-fca2a594-1d93a270"
+	untrustedCertURL := "https://api.sandbox.paypal.com/v1/notifications/certs/CERT-UNTRUSTED"
+	otherHostCertURL := "https://api.sandbox.paypal.com/v1/notifications/certs/CERT-OTHER-HOST"
+
+	root := generateTestCertAuthority(t, "Test Root CA", nil)
+	intermediate := generateTestCertAuthority(t, "Test Intermediate CA", root)
+	untrustedRoot := generateTestCertAuthority(t, "Untrusted Root CA", nil)
+
+	key, leafPEM := generateTestWebhookCert(t, "messageverificationcerts.sandbox.paypal.com", intermediate)
+	untrustedKey, untrustedPEM := generateTestWebhookCert(t, "messageverificationcerts.sandbox.paypal.com", untrustedRoot)
+	otherHostKey, otherHostPEM := generateTestWebhookCert(t, "www.example.com", intermediate)
+
+	certPEMs := map[string][]byte{
+		certURL:          append(leafPEM, intermediate.pem...),
+		untrustedCertURL: untrustedPEM,
+		otherHostCertURL: append(otherHostPEM, intermediate.pem...),
+	}
+
+	var certRequests int
+	hc := &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+		certPEM, ok := certPEMs[req.URL.String()]
+		if !ok {
+			t.Fatalf("want request url '%s', got '%s'", certURL, req.URL.String())
+		}
+		if req.URL.String() == certURL {
+			certRequests++
+		}
+		return newMockResponse(http.StatusOK, string(certPEM)), nil
+	}}
+
+	cl, err := NewClient("id", "secret", "WEBHOOK123", SandboxBaseURL, hc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cl.roots = x509.NewCertPool()
+	if !cl.roots.AppendCertsFromPEM(root.pem) {
+		t.Fatal("cannot append root cert")
+	}
+
+	tt := []struct {
+		name      string
+		body      string
+		wantEvent domain.PaymentEvent
+	}{
+		{
+			name: "signed capture completed event must produce the expected payment event",
+			body: `{
+				"id": "WH-CAPTURE-COMPLETED",
+				"event_type": "PAYMENT.CAPTURE.COMPLETED",
+				"resource": {
+					"id": "CAPTURE456",
+					"status": "COMPLETED",
+					"custom_id": "11111111-2222-3333-4444-555555555555",
+					"amount": {"currency_code": "GBP", "value": "12.34"}
+				}
+			}`,
+			wantEvent: domain.PaymentEvent{
+				ID:                "WH-CAPTURE-COMPLETED",
+				Type:              domain.PaymentEventTypeCaptureCompleted,
+				ProviderEventType: "PAYMENT.CAPTURE.COMPLETED",
+				EntryID:           "11111111-2222-3333-4444-555555555555",
+				PaymentRef:        "CAPTURE456",
+				Amount:            12.34,
+				Currency:          "GBP",
+			},
+		},
+		{
+			name: "signed capture refunded event must produce the expected payment event",
+			body: `{
+				"id": "WH-CAPTURE-REFUNDED",
+				"event_type": "PAYMENT.CAPTURE.REFUNDED",
+				"resource": {
+					"id": "REFUND789",
+					"status": "COMPLETED",
+					"custom_id": "11111111-2222-3333-4444-555555555555",
+					"amount": {"currency_code": "GBP", "value": "12.34"},
+					"links": [
+						{"href": "https://api.sandbox.paypal.com/v2/payments/refunds/REFUND789", "rel": "self"},
+						{"href": "https://api.sandbox.paypal.com/v2/payments/captures/CAPTURE456", "rel": "up"}
+					]
+				}
+			}`,
+			wantEvent: domain.PaymentEvent{
+				ID:                "WH-CAPTURE-REFUNDED",
+				Type:              domain.PaymentEventTypeCaptureRefunded,
+				ProviderEventType: "PAYMENT.CAPTURE.REFUNDED",
+				EntryID:           "11111111-2222-3333-4444-555555555555",
+				PaymentRef:        "CAPTURE456",
+			},
+		},
+		{
+			name: "signed dispute created event must produce the expected payment event",
+			body: `{
+				"id": "WH-DISPUTE-CREATED",
+				"event_type": "CUSTOMER.DISPUTE.CREATED",
+				"resource": {
+					"dispute_id": "PP-D-1234",
+					"disputed_transactions": [{"seller_transaction_id": "CAPTURE456"}]
+				}
+			}`,
+			wantEvent: domain.PaymentEvent{
+				ID:                "WH-DISPUTE-CREATED",
+				Type:              domain.PaymentEventTypeDisputeOpened,
+				ProviderEventType: "CUSTOMER.DISPUTE.CREATED",
+				PaymentRef:        "CAPTURE456",
+			},
+		},
+		{
+			name: "signed dispute resolved in buyer's favour event must produce the expected payment event",
+			body: `{
+				"id": "WH-DISPUTE-RESOLVED",
+				"event_type": "CUSTOMER.DISPUTE.RESOLVED",
+				"resource": {
+					"dispute_id": "PP-D-1234",
+					"disputed_transactions": [{"seller_transaction_id": "CAPTURE456"}],
+					"dispute_outcome": {"outcome_code": "RESOLVED_BUYER_FAVOUR"}
+				}
+			}`,
+			wantEvent: domain.PaymentEvent{
+				ID:                "WH-DISPUTE-RESOLVED",
+				Type:              domain.PaymentEventTypeDisputeLost,
+				ProviderEventType: "CUSTOMER.DISPUTE.RESOLVED",
+				PaymentRef:        "CAPTURE456",
+			},
+		},
+		{
+			name: "signed event of another type must produce an unhandled payment event",
+			body: `{"id": "WH-ORDER-APPROVED", "event_type": "CHECKOUT.ORDER.APPROVED", "resource": {"id": "ORDER123"}}`,
+			wantEvent: domain.PaymentEvent{
+				ID:                "WH-ORDER-APPROVED",
+				Type:              domain.PaymentEventTypeUnhandled,
+				ProviderEventType: "CHECKOUT.ORDER.APPROVED",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			hdr := signTestWebhookEvent(t, key, certURL, "WEBHOOK123", []byte(tc.body))
+
+			gotEvent, err := cl.VerifyWebhookEvent(context.Background(), hdr, []byte(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.wantEvent, gotEvent); diff != "" {
+				t.Fatalf("want event %+v, got %+v, diff: %s", tc.wantEvent, gotEvent, diff)
+			}
+		})
+	}
+
+	t.Run("cert must only be retrieved once", func(t *testing.T) {
+		if certRequests != 1 {
+			t.Fatalf("want 1 cert request, got %d", certRequests)
+		}
+	})
+
+	t.Run("tampered body must fail verification", func(t *testing.T) {
+		body := []byte(`{"id": "WH-1", "event_type": "PAYMENT.CAPTURE.REFUNDED", "resource": {}}`)
+		hdr := signTestWebhookEvent(t, key, certURL, "WEBHOOK123", body)
+
+		tampered := []byte(`{"id": "WH-2", "event_type": "PAYMENT.CAPTURE.REFUNDED", "resource": {}}`)
+		if _, err := cl.VerifyWebhookEvent(context.Background(), hdr, tampered); err == nil {
+			t.Fatal("want error, got nil")
+		}
+	})
+
+	t.Run("body signed for another webhook must fail verification", func(t *testing.T) {
+		body := []byte(`{"id": "WH-1", "event_type": "PAYMENT.CAPTURE.REFUNDED", "resource": {}}`)
+		hdr := signTestWebhookEvent(t, key, certURL, "OTHER_WEBHOOK", body)
+
+		if _, err := cl.VerifyWebhookEvent(context.Background(), hdr, body); err == nil {
+			t.Fatal("want error, got nil")
+		}
+	})
+
+	t.Run("cert url not served by paypal must fail verification", func(t *testing.T) {
+		body := []byte(`{"id": "WH-1", "event_type": "PAYMENT.CAPTURE.REFUNDED", "resource": {}}`)
+		hdr := signTestWebhookEvent(t, key, "https://paypal.com.example.net/cert", "WEBHOOK123", body)
+
+		wantErrMsg := "cannot get webhook cert: untrusted cert url: https://paypal.com.example.net/cert"
+		_, gotErr := cl.VerifyWebhookEvent(context.Background(), hdr, body)
+		if gotErr == nil || gotErr.Error() != wantErrMsg {
+			t.Fatalf("want error msg %s, got %+v (%T)", wantErrMsg, gotErr, gotErr)
+		}
+	})
+
+	t.Run("cert that does not chain up to a trusted root must fail verification", func(t *testing.T) {
+		body := []byte(`{"id": "WH-1", "event_type": "PAYMENT.CAPTURE.REFUNDED", "resource": {}}`)
+		hdr := signTestWebhookEvent(t, untrustedKey, untrustedCertURL, "WEBHOOK123", body)
+
+		_, gotErr := cl.VerifyWebhookEvent(context.Background(), hdr, body)
+		if gotErr == nil || !strings.Contains(gotErr.Error(), "cannot verify cert chain") {
+			t.Fatalf("want cert chain error, got %+v (%T)", gotErr, gotErr)
+		}
+	})
+
+	t.Run("cert that was not issued for paypal webhooks must fail verification", func(t *testing.T) {
+		body := []byte(`{"id": "WH-1", "event_type": "PAYMENT.CAPTURE.REFUNDED", "resource": {}}`)
+		hdr := signTestWebhookEvent(t, otherHostKey, otherHostCertURL, "WEBHOOK123", body)
+
+		wantErrMsg := "cannot get webhook cert: cert was not issued for paypal webhooks: www.example.com"
+		_, gotErr := cl.VerifyWebhookEvent(context.Background(), hdr, body)
+		if gotErr == nil || gotErr.Error() != wantErrMsg {
+			t.Fatalf("want error msg %s, got %+v (%T)", wantErrMsg, gotErr, gotErr)
+		}
+	})
+}
+
+func TestClient_getWebhookCert(t *testing.T) {
+	t.Run("fetching a cert must not hold up other requests", func(t *testing.T) {
+		certURL := "https://api.sandbox.paypal.com/v1/notifications/certs/CERT-SLOW"
+
+		root := generateTestCertAuthority(t, "Test Root CA", nil)
+		_, leafPEM := generateTestWebhookCert(t, "messageverificationcerts.sandbox.paypal.com", root)
+
+		certRequested := make(chan struct{})
+		releaseCert := make(chan struct{})
+
+		hc := &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.String() == certURL {
+				close(certRequested)
+				<-releaseCert
+				return newMockResponse(http.StatusOK, string(leafPEM)), nil
+			}
+			return newMockResponse(http.StatusOK, `{"access_token": "ACCESS_TOKEN", "expires_in": 32400}`), nil
+		}}
+
+		cl, err := NewClient("id", "secret", "WEBHOOK123", SandboxBaseURL, hc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cl.roots = x509.NewCertPool()
+		if !cl.roots.AppendCertsFromPEM(root.pem) {
+			t.Fatal("cannot append root cert")
+		}
+
+		certErr := make(chan error)
+		go func() {
+			_, err := cl.getWebhookCert(context.Background(), certURL)
+			certErr <- err
+		}()
+
+		<-certRequested
+
+		tokenErr := make(chan error)
+		go func() {
+			_, err := cl.getAccessToken(context.Background())
+			tokenErr <- err
+		}()
+
+		select {
+		case err := <-tokenErr:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("want access token whilst cert is being fetched, got timeout")
+		}
+
+		close(releaseCert)
+
+		if err := <-certErr; err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// generateTestWebhookCert returns a new private key, and the pem-encoded certificate for its public key
+// issued for the provided hostname by the provided certificate authority
+func generateTestWebhookCert(t *testing.T, hostname string, issuer *testCertAuthority) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+
+	tpl := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: hostname},
+		DNSNames: []string{hostname},
+	}
+
+	key, cert := createTestCert(t, tpl, issuer)
+
+	return key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
+
+// testCertAuthority represents a certificate that can issue further certificates within the testsuite
+type testCertAuthority struct {
+	cert *x509.Certificate
+	key  *rsa.PrivateKey
+	pem  []byte
+}
+
+// generateTestCertAuthority returns a new certificate authority with the provided name, issued by the provided
+// certificate authority. A nil issuer produces a self-signed root certificate authority
+func generateTestCertAuthority(t *testing.T, name string, issuer *testCertAuthority) *testCertAuthority {
+	t.Helper()
+
+	tpl := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+
+	key, cert := createTestCert(t, tpl, issuer)
+
+	return &testCertAuthority{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}),
+	}
+}
+
+// createTestCert creates a certificate from the provided template for a new private key, issued by the provided
+// certificate authority, or self-signed if the issuer is nil
+func createTestCert(t *testing.T, tpl *x509.Certificate, issuer *testCertAuthority) (*rsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tpl.SerialNumber = big.NewInt(time.Now().UnixNano())
+	tpl.NotBefore = time.Now().Add(-time.Hour)
+	tpl.NotAfter = time.Now().Add(time.Hour)
+
+	parent, parentKey := tpl, key
+	if issuer != nil {
+		parent, parentKey = issuer.cert, issuer.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key, cert
+}
+
+// signTestWebhookEvent returns the headers that PayPal would send alongside the provided body, signed with the provided key
+func signTestWebhookEvent(t *testing.T, key *rsa.PrivateKey, certURL, webhookID string, body []byte) http.Header {
+	t.Helper()
+
+	transmissionID := "b2384410-f8d2-11ed-8b2a-1f1ff5a4b0b6"
+	transmissionTime := "2023-05-22T10:30:00Z"
+
+	msg := fmt.Sprintf("%s|%s|%s|%d", transmissionID, transmissionTime, webhookID, crc32.ChecksumIEEE(body))
+	digest := sha256.Sum256([]byte(msg))
+
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hdr := http.Header{}
+	hdr.Set("Paypal-Auth-Algo", "SHA256withRSA")
+	hdr.Set("Paypal-Cert-Url", certURL)
+	hdr.Set("Paypal-Transmission-Id", transmissionID)
+	hdr.Set("Paypal-Transmission-Time", transmissionTime)
+	hdr.Set("Paypal-Transmission-Sig", base64.StdEncoding.EncodeToString(sig))
+
+	return hdr
+}
+
+// newMockPayPalHTTPClient returns a mockHTTPClient that issues an access token,
+// then delegates every other request to the provided function
+func newMockPayPalHTTPClient(t *testing.T, fn func(req *http.Request) (int, string)) *mockHTTPClient {
+	t.Helper()
+
+	return &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/v1/oauth2/token" {
+			return newMockResponse(http.StatusOK, `{"access_token": "ACCESS_TOKEN", "expires_in": 32400}`), nil
+		}
+
+		if req.Header.Get("Authorization") != "Bearer ACCESS_TOKEN" {
+			t.Fatalf("want authorization header 'Bearer ACCESS_TOKEN', got '%s'", req.Header.Get("Authorization"))
+		}
+
+		status, body := fn(req)
+		return newMockResponse(status, body), nil
+	}}
+}
+
+func newMockResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+	}
+}
+
+type doFunc func(*http.Request) (*http.Response, error)
+
+type mockHTTPClient struct {
+	doFunc
+}
+
+func (m *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return m.doFunc(req)
+}
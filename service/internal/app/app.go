@@ -2,21 +2,31 @@ package app
 
 import (
 	"context"
-	"encoding/base64"
+	"errors"
 	"fmt"
 	"html/template"
 	"log"
+	"net"
 	"net/http"
 	"prediction-league/service/internal/domain"
 	"prediction-league/service/internal/view"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/gorilla/mux"
 )
 
-const authCookieName = "PL_AUTH"
+const (
+	authCookieName   = "PL_AUTH"
+	signupCookieName = "PL_SIGNUP"
+	csrfCookieName   = "PL_CSRF"
+
+	apiKeyHeaderName        = "X-API-Key"
+	forwardedHostHeaderName = "X-Forwarded-Host"
+	forwardedForHeaderName  = "X-Forwarded-For"
+
+	paymentWebhookPath = "/api/payment/webhook"
+)
 
 // closeBody closes the body of the provided request
 func closeBody(r *http.Request) {
@@ -47,38 +57,183 @@ func getRouteParam(r *http.Request, name string, value interface{}) error {
 	return nil
 }
 
-// contextFromRequest extracts data from a given request object and returns an inflated context
+// contextFromRequest extracts data from a given request object and returns an inflated context. A request that has passed
+// through requestContextMiddleware only has its context resolved once, however many times this is called while serving it
 func contextFromRequest(r *http.Request, c *container) (context.Context, context.CancelFunc, error) {
-	ctx, cancel := domain.NewContext()
+	rc, ok := r.Context().Value(requestContextKey{}).(*requestContext)
+	if !ok {
+		ctx, cancel, err := resolveContextFromRequest(r, c)
+		if err != nil {
+			return nil, nil, err
+		}
+		return withAdminUserFromRequest(ctx, r), cancel, nil
+	}
+
+	rc.once.Do(func() {
+		rc.ctx, rc.cancel, rc.err = resolveContextFromRequest(r, c)
+	})
+	if rc.err != nil {
+		return nil, nil, rc.err
+	}
 
-	config := c.config
-	realms := c.realms
+	// the shared context is cancelled by requestContextMiddleware once the request has been served
+	return withAdminUserFromRequest(rc.ctx, r), func() {}, nil
+}
+
+// resolveContextFromRequest returns a new context that carries the realm of the provided request
+func resolveContextFromRequest(r *http.Request, c *container) (context.Context, context.CancelFunc, error) {
+	ctx, cancel := domain.NewContext()
 
-	// realm name is host (strip port)
-	realmName := stripPort(r.Host)
+	// requests made with an api key belong to the realm that the key was issued for, whichever host they were sent to
+	if key := r.Header.Get(apiKeyHeaderName); key != "" && strings.HasPrefix(r.URL.Path, "/api/") {
+		if err := setAPIKeyRealmOnContext(ctx, r, c, key); err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		return ctx, cancel, nil
+	}
 
-	// see if we can find this realm in our config
-	realm, err := realms.GetByName(realmName)
+	// see if we can find the realm that the request was routed to in our config
+	realmName := realmRouteFromRequest(r, c.realms, c.trustedProxies).realmName
+	realm, err := c.realms.GetByName(realmName)
 	if err != nil {
+		cancel()
 		return nil, nil, fmt.Errorf("cannot get realm with id '%s': %w", realmName, err)
 	}
 
 	ctxRealm := domain.RealmFromContext(ctx)
 	*ctxRealm = realm
 
-	// see if request contains any basic auth credentials
-	var userPass []byte
-	authHeader := r.Header.Get("Authorization")
-	split := strings.Split(authHeader, "Basic ")
-	if len(split) == 2 {
-		userPass, _ = base64.StdEncoding.DecodeString(split[1])
-		// if username and password supplied, check if it matches the expected from env/config
-		if string(userPass) == config.AdminBasicAuth {
-			ctx = domain.SetBasicAuthSuccessfulOnContext(ctx)
+	return ctx, cancel, nil
+}
+
+// withAdminUserFromRequest returns a copy of the provided context that carries the admin user whose basic auth credentials
+// were authenticated for the provided request, if any. The admin user is only known once adminAuthMiddleware has run, so
+// it is applied afresh each time rather than being resolved along with the rest of the context
+func withAdminUserFromRequest(ctx context.Context, r *http.Request) context.Context {
+	if user, ok := r.Context().Value(adminUserContextKey{}).(domain.AdminUser); ok {
+		return domain.SetAdminUserOnContext(ctx, user)
+	}
+
+	return ctx
+}
+
+// setAPIKeyRealmOnContext authenticates the provided api key and sets the realm that it was issued for on the provided context.
+// API keys only grant read-only access and never authenticate an admin user, so basic auth credentials are disregarded
+func setAPIKeyRealmOnContext(ctx context.Context, r *http.Request, c *container, key string) error {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return domain.UnauthorizedError{}
+	}
+
+	apiKey, err := c.apiKeyAgent.AuthenticateAPIKey(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	realm, err := c.realms.GetByName(apiKey.RealmName)
+	if err != nil {
+		return fmt.Errorf("cannot get realm with id '%s': %w", apiKey.RealmName, err)
+	}
+
+	ctxRealm := domain.RealmFromContext(ctx)
+	*ctxRealm = realm
+
+	if err := c.rateLimitAgent.Allow(ctx, domain.APIKeyRateLimits(apiKey.ID.String())...); err != nil {
+		return err
+	}
+
+	// a failure to count the request is no reason to refuse it
+	if err := c.apiKeyAgent.RecordAPIKeyUsage(ctx, apiKey); err != nil {
+		c.logger.Errorf("cannot record usage of api key '%s': %s", apiKey.ID.String(), err.Error())
+	}
+
+	return nil
+}
+
+// clientIPFromRequest returns the IP address of the client that sent the provided request. A request received from one
+// of the provided trusted proxies is attributed to the address that the proxy forwarded it for instead, found by walking
+// back through the X-Forwarded-For header until an address that is not a trusted proxy is reached
+func clientIPFromRequest(r *http.Request, trustedProxies []*net.IPNet) string {
+	ip := peerIPFromRequest(r)
+
+	if !isTrustedProxy(ip, trustedProxies) {
+		return ip
+	}
+
+	// every proxy appends the address it received the request from, so the nearest hop is the last one
+	hops := strings.Split(strings.Join(r.Header.Values(forwardedForHeaderName), ","), ",")
+	for idx := len(hops) - 1; idx >= 0; idx-- {
+		hop := strings.TrimSpace(hops[idx])
+		if net.ParseIP(hop) == nil {
+			// anything before an invalid hop cannot be trusted
+			break
+		}
+
+		ip = hop
+		if !isTrustedProxy(hop, trustedProxies) {
+			break
 		}
 	}
 
-	return ctx, cancel, nil
+	return ip
+}
+
+// peerIPFromRequest returns the IP address of the peer that the provided request was received from directly
+func peerIPFromRequest(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// isTrustedProxy determines whether the provided IP address belongs to any of the provided trusted proxies
+func isTrustedProxy(ip string, trustedProxies []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, proxy := range trustedProxies {
+		if proxy.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseTrustedProxies parses the provided IP addresses and CIDR ranges of trusted proxies
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	var parsed []*net.IPNet
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			// a single address is a range of its own
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip address: %s", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			parsed = append(parsed, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr range: %s", proxy)
+		}
+		parsed = append(parsed, ipNet)
+	}
+
+	return parsed, nil
 }
 
 // stripPort removes the port suffix from the provided host string
@@ -86,14 +241,89 @@ func stripPort(host string) string {
 	return strings.Trim(strings.Split(host, ":")[0], " ")
 }
 
-// setAuthCookieValue sets an authorization cookie with the provided value
-func setAuthCookieValue(value string, w http.ResponseWriter, r *http.Request) {
+// realmRouteContextKey identifies the realmRoute stored on the context of a request once it has been routed
+type realmRouteContextKey struct{}
+
+// realmRoute describes how the realm of a request was resolved
+type realmRoute struct {
+	realmName string // name of the realm, or the request host if it does not match any realm
+	host      string // hostname that the browser made the request to, which a proxy may have forwarded
+	prefix    string // path prefix that the realm is served under, or empty if it is served at the root of its host
+}
+
+// resolveRealmRoute resolves the realm of the provided request by its path prefix, then by its forwarded host,
+// then by its host. A forwarded host can be sent by any client, so it is only used for a realm that trusts it, and only
+// when the request was received directly from one of the provided trusted proxies
+func resolveRealmRoute(r *http.Request, realms domain.RealmCollection, trustedProxies []*net.IPNet) realmRoute {
+	host := stripPort(r.Host)
+
+	var fwdHost string
+	if isTrustedProxy(peerIPFromRequest(r), trustedProxies) {
+		fwdHost = stripPort(strings.Split(r.Header.Get(forwardedHostHeaderName), ",")[0])
+	}
+
+	if realm, err := realms.GetByPath(r.URL.Path); err == nil {
+		if fwdHost != "" && realm.Config.TrustForwardedHost {
+			host = fwdHost
+		}
+		return realmRoute{realmName: realm.Config.Name, host: host, prefix: realm.Site.Paths.Prefix}
+	}
+
+	if fwdHost != "" {
+		if realm, err := realms.GetByHost(fwdHost); err == nil && realm.Config.TrustForwardedHost {
+			return realmRoute{realmName: realm.Config.Name, host: fwdHost}
+		}
+	}
+
+	if realm, err := realms.GetByHost(host); err == nil {
+		return realmRoute{realmName: realm.Config.Name, host: host}
+	}
+
+	return realmRoute{realmName: host, host: host}
+}
+
+// realmRouteFromRequest returns the realmRoute that was stored on the provided request when it was routed,
+// or resolves one if it was not
+func realmRouteFromRequest(r *http.Request, realms domain.RealmCollection, trustedProxies []*net.IPNet) realmRoute {
+	if route, ok := r.Context().Value(realmRouteContextKey{}).(realmRoute); ok {
+		return route
+	}
+	return resolveRealmRoute(r, realms, trustedProxies)
+}
+
+// cookieScope returns the domain and path of cookies set in response to the provided request,
+// so that realms served under different path prefixes of the same host each keep their own cookies
+func cookieScope(r *http.Request) (string, string) {
+	if route, ok := r.Context().Value(realmRouteContextKey{}).(realmRoute); ok {
+		return route.host, route.prefix + "/"
+	}
+	return stripPort(r.Host), "/"
+}
+
+// setAuthCookie sets an authorization cookie that identifies the provided auth token until the token expires
+func setAuthCookie(tkn *domain.Token, w http.ResponseWriter, r *http.Request) {
+	cookieDomain, cookiePath := cookieScope(r)
+
 	cookie := &http.Cookie{
 		Name:    authCookieName,
-		Value:   value,
-		Domain:  stripPort(r.Host),
-		Expires: time.Now().Add(domain.TokenValidityDuration[domain.TokenTypeAuth]),
-		Path:    "/",
+		Value:   tkn.ID,
+		Domain:  cookieDomain,
+		Expires: tkn.ExpiresAt,
+		Path:    cookiePath,
+	}
+	http.SetCookie(w, cookie)
+}
+
+// clearAuthCookie expires the authorization cookie
+func clearAuthCookie(w http.ResponseWriter, r *http.Request) {
+	cookieDomain, cookiePath := cookieScope(r)
+
+	cookie := &http.Cookie{
+		Name:   authCookieName,
+		Value:  "",
+		Domain: cookieDomain,
+		MaxAge: -1,
+		Path:   cookiePath,
 	}
 	http.SetCookie(w, cookie)
 }
@@ -109,6 +339,86 @@ func getAuthCookieValue(r *http.Request) string {
 	return ""
 }
 
+// setCSRFCookie sets a cookie containing the provided csrf token, which must be readable by scripts so that they can
+// submit it back alongside each request
+func setCSRFCookie(value string, w http.ResponseWriter, r *http.Request) {
+	cookieDomain, cookiePath := cookieScope(r)
+
+	cookie := &http.Cookie{
+		Name:     csrfCookieName,
+		Value:    value,
+		Domain:   cookieDomain,
+		Path:     cookiePath,
+		SameSite: http.SameSiteLaxMode,
+	}
+	http.SetCookie(w, cookie)
+}
+
+// getCSRFCookieValue retrieves the current value of the csrf cookie
+func getCSRFCookieValue(r *http.Request) string {
+	for _, cookie := range r.Cookies() {
+		if cookie.Name == csrfCookieName {
+			return cookie.Value
+		}
+	}
+
+	return ""
+}
+
+// replaceAuthCookieValue swaps the value of the authorization cookie sent with the provided request,
+// so that handlers further down the chain see the provided value instead
+func replaceAuthCookieValue(value string, r *http.Request) {
+	cookies := r.Cookies()
+
+	r.Header.Del("Cookie")
+	for _, cookie := range cookies {
+		if cookie.Name == authCookieName {
+			cookie.Value = value
+		}
+		r.AddCookie(cookie)
+	}
+}
+
+// setSignupCookie sets a cookie that identifies the provided signup session until the session expires
+func setSignupCookie(session domain.SignupSession, w http.ResponseWriter, r *http.Request) {
+	cookieDomain, cookiePath := cookieScope(r)
+
+	cookie := &http.Cookie{
+		Name:     signupCookieName,
+		Value:    session.ID,
+		Domain:   cookieDomain,
+		Expires:  session.ExpiresAt,
+		Path:     cookiePath,
+		HttpOnly: true,
+	}
+	http.SetCookie(w, cookie)
+}
+
+// clearSignupCookie expires the signup session cookie
+func clearSignupCookie(w http.ResponseWriter, r *http.Request) {
+	cookieDomain, cookiePath := cookieScope(r)
+
+	cookie := &http.Cookie{
+		Name:     signupCookieName,
+		Value:    "",
+		Domain:   cookieDomain,
+		MaxAge:   -1,
+		Path:     cookiePath,
+		HttpOnly: true,
+	}
+	http.SetCookie(w, cookie)
+}
+
+// getSignupCookieValue retrieves the current value of the signup session cookie
+func getSignupCookieValue(r *http.Request) string {
+	cookie, err := r.Cookie(signupCookieName)
+	if err != nil {
+		return ""
+	}
+
+	return cookie.Value
+}
+
 // isLoggedIn determines whether the provided request represents a logged in user
 func isLoggedIn(r *http.Request) bool {
 	if cookieValue := getAuthCookieValue(r); cookieValue == "" {
@@ -132,6 +442,7 @@ func newPage(r *http.Request, c *container, pageTitle, activePage, bannerTitle s
 		BannerTitle:    template.HTML(bannerTitle),
 		ActivePage:     activePage,
 		IsLoggedIn:     isLoggedIn(r),
+		CSRFToken:      getCSRFCookieValue(r),
 		Realm:          realm,
 		SeasonName:     s.ShortName,
 		BuildVersion:   c.config.BuildVersion,
@@ -149,3 +460,53 @@ func retrieveEntryByEmailAddr(ctx context.Context, email, seasonID, realmName st
 
 	return &entry, nil
 }
+
+// loginWithCode redeems the provided login code on behalf of the entrant with the provided email address, and generates
+// a new auth token for their Entry if it is correct. An unknown email address is indistinguishable from an incorrect code,
+// and both count towards the rate limits of the client with the provided IP address
+func loginWithCode(ctx context.Context, c *container, ip, email, code string) (*domain.Token, error) {
+	// each attempt is counted before the code is checked, so that concurrent attempts cannot all get through
+	codeRateLimits := domain.LoginCodeRateLimits(ip)
+	if err := c.rateLimitAgent.Allow(ctx, codeRateLimits...); err != nil {
+		return nil, err
+	}
+
+	entryID, err := redeemLoginCode(ctx, c, email, code)
+	if !errors.As(err, &domain.UnauthorizedError{}) {
+		// only failed attempts at login codes count towards their rate limits
+		if rlErr := c.rateLimitAgent.Release(ctx, codeRateLimits...); rlErr != nil {
+			c.logger.Errorf("cannot release login code attempt: %s", rlErr.Error())
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// magic login link sent in the same email is no longer needed
+	if _, err := c.tokenAgent.DeleteInFlightTokens(ctx, domain.TokenTypeMagicLogin, entryID); err != nil {
+		// log error and continue
+		c.logger.Errorf("cannot purge in-flight magic login tokens for entry id '%s': %s", entryID, err.Error())
+	}
+
+	return c.tokenAgent.GenerateToken(ctx, domain.TokenTypeAuth, entryID)
+}
+
+// redeemLoginCode redeems the provided login code on behalf of the entrant with the provided email address, and returns
+// the ID of their Entry if it is correct
+func redeemLoginCode(ctx context.Context, c *container, email, code string) (string, error) {
+	realm := domain.RealmFromContext(ctx)
+
+	entry, err := retrieveEntryByEmailAddr(ctx, email, realm.Config.SeasonID, realm.Config.Name, c.entryAgent)
+	if err != nil {
+		if errors.As(err, &domain.NotFoundError{}) {
+			return "", domain.UnauthorizedError{}
+		}
+		return "", err
+	}
+
+	if err := c.tokenAgent.RedeemLoginCode(ctx, entry.ID.String(), code); err != nil {
+		return "", err
+	}
+
+	return entry.ID.String(), nil
+}
@@ -0,0 +1,368 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"prediction-league/service/internal/adapters/logger"
+	"prediction-league/service/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestContextFromRequest_APIKey(t *testing.T) {
+	cl := &mockClock{t: time.Date(2018, 5, 26, 14, 0, 0, 0, time.UTC)}
+
+	l, err := logger.NewLogger("DEBUG", &bytes.Buffer{}, cl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aa, err := domain.NewAuditAgent(&mockAuditRepository{}, cl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	akr := &mockAPIKeyRepository{keys: make(map[string]domain.APIKey)}
+	aka, err := domain.NewAPIKeyAgent(akr, aa, cl, []byte("test_token_hash_key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rla, err := domain.NewRateLimitAgent(domain.NewInMemoryRateLimitStore(), cl, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	realm := domain.Realm{Config: domain.RealmConfig{Name: "example.com"}}
+
+	cnt := &container{
+		realms:         domain.RealmCollection{realm},
+		apiKeyAgent:    aka,
+		rateLimitAgent: rla,
+		logger:         l,
+	}
+
+	// issue a key for the realm on behalf of a superadmin
+	adminCtx, cancel := domain.NewContext()
+	defer cancel()
+	*domain.RealmFromContext(adminCtx) = realm
+	adminCtx = domain.SetSystemAdminRoleOnContext(adminCtx, domain.AdminRoleSuperAdmin)
+
+	apiKey, key, err := aka.CreateAPIKey(adminCtx, "Discord bot")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// newAPIKeyRequest returns a request made with the provided api key to a host that does not match any realm
+	newAPIKeyRequest := func(method, path, key string) *http.Request {
+		r := httptest.NewRequest(method, "http://api.unknown.com"+path, nil)
+		r.Header.Set(apiKeyHeaderName, key)
+		return r
+	}
+
+	t.Run("read-only api request with a valid api key must resolve the key's realm", func(t *testing.T) {
+		ctx, cancel, err := contextFromRequest(newAPIKeyRequest(http.MethodGet, "/api/season/latest/leaderboard/1", key), cnt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cancel()
+
+		if got := domain.RealmFromContext(ctx).Config.Name; got != realm.Config.Name {
+			t.Fatalf("want realm %s, got %s", realm.Config.Name, got)
+		}
+
+		// the request must have been counted against the key
+		if got := akr.keys[apiKey.ID.String()].UsageCount; got != 1 {
+			t.Fatalf("want usage count %d, got %d", 1, got)
+		}
+	})
+
+	t.Run("api request with a valid api key that resolves its context more than once must only be counted once", func(t *testing.T) {
+		usageCount := akr.keys[apiKey.ID.String()].UsageCount
+
+		var ctx context.Context
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the same request context is resolved by both a middleware and the handler
+			for i := 0; i < 2; i++ {
+				var cancel context.CancelFunc
+				var err error
+				ctx, cancel, err = contextFromRequest(r, cnt)
+				if err != nil {
+					t.Fatal(err)
+				}
+				cancel()
+			}
+
+			if err := ctx.Err(); err != nil {
+				t.Fatalf("want context still active while serving the request, got %s", err.Error())
+			}
+		})
+
+		requestContextMiddleware(next).ServeHTTP(httptest.NewRecorder(), newAPIKeyRequest(http.MethodGet, "/api/season/latest", key))
+
+		if got := akr.keys[apiKey.ID.String()].UsageCount; got != usageCount+1 {
+			t.Fatalf("want usage count %d, got %d", usageCount+1, got)
+		}
+
+		if ctx.Err() == nil {
+			t.Fatal("want context cancelled once the request has been served, got active context")
+		}
+	})
+
+	t.Run("api request with an invalid api key must fail", func(t *testing.T) {
+		_, _, err := contextFromRequest(newAPIKeyRequest(http.MethodGet, "/api/season/latest", "pl_not_a_valid_key"), cnt)
+		if !errors.As(err, &domain.UnauthorizedError{}) {
+			t.Fatalf("want error %T, got %T", domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("api request with a valid api key that is not read-only must fail", func(t *testing.T) {
+		_, _, err := contextFromRequest(newAPIKeyRequest(http.MethodPost, "/api/season/latest/entry", key), cnt)
+		if !errors.As(err, &domain.UnauthorizedError{}) {
+			t.Fatalf("want error %T, got %T", domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("frontend request with a valid api key must resolve the realm by host", func(t *testing.T) {
+		_, _, err := contextFromRequest(newAPIKeyRequest(http.MethodGet, "/leaderboard", key), cnt)
+		if err == nil {
+			t.Fatal("want error, got nil")
+		}
+	})
+
+	t.Run("api requests with a valid api key beyond its rate limit must fail", func(t *testing.T) {
+		var err error
+		for i := 0; i < 100 && err == nil; i++ {
+			var cancel context.CancelFunc
+			_, cancel, err = contextFromRequest(newAPIKeyRequest(http.MethodGet, "/api/season/latest", key), cnt)
+			if cancel != nil {
+				cancel()
+			}
+		}
+		if !errors.As(err, &domain.RateLimitedError{}) {
+			t.Fatalf("want error %T, got %T", domain.RateLimitedError{}, err)
+		}
+	})
+}
+
+func TestResolveRealmRoute(t *testing.T) {
+	realms := domain.RealmCollection{
+		{Config: domain.RealmConfig{Name: "example.com", Aliases: []string{"www.example.com"}}, Site: domain.RealmSite{Paths: domain.NewRealmSitePaths("")}},
+		{Config: domain.RealmConfig{Name: "proxied.com", TrustForwardedHost: true}, Site: domain.RealmSite{Paths: domain.NewRealmSitePaths("")}},
+		{Config: domain.RealmConfig{Name: "league", PathPrefix: true}, Site: domain.RealmSite{Paths: domain.NewRealmSitePaths("/r/league")}},
+	}
+
+	trustedProxies, err := parseTrustedProxies([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name          string
+		url           string
+		remoteAddr    string
+		forwardedHost string
+		want          realmRoute
+	}{
+		{
+			name: "request to realm name must resolve realm by host",
+			url:  "http://example.com:3000/leaderboard",
+			want: realmRoute{realmName: "example.com", host: "example.com"},
+		},
+		{
+			name: "request to realm alias must resolve realm by host",
+			url:  "http://www.example.com/leaderboard",
+			want: realmRoute{realmName: "example.com", host: "www.example.com"},
+		},
+		{
+			name:          "request forwarded from trusted host by trusted proxy must resolve realm by forwarded host",
+			url:           "http://10.0.0.1:8080/leaderboard",
+			remoteAddr:    "10.0.0.3:54321",
+			forwardedHost: "proxied.com, 10.0.0.2",
+			want:          realmRoute{realmName: "proxied.com", host: "proxied.com"},
+		},
+		{
+			name:          "request forwarded from trusted host by untrusted peer must resolve realm by host",
+			url:           "http://10.0.0.1:8080/leaderboard",
+			remoteAddr:    "203.0.113.7:54321",
+			forwardedHost: "proxied.com",
+			want:          realmRoute{realmName: "10.0.0.1", host: "10.0.0.1"},
+		},
+		{
+			name:          "request forwarded from untrusted host must resolve realm by host",
+			url:           "http://www.example.com/leaderboard",
+			forwardedHost: "example.com",
+			want:          realmRoute{realmName: "example.com", host: "www.example.com"},
+		},
+		{
+			name: "request to realm path prefix must resolve realm by path",
+			url:  "http://example.com/r/league/leaderboard",
+			want: realmRoute{realmName: "league", host: "example.com", prefix: "/r/league"},
+		},
+		{
+			name: "request to unknown host must fall back to host",
+			url:  "http://unknown.com/leaderboard",
+			want: realmRoute{realmName: "unknown.com", host: "unknown.com"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			if tc.remoteAddr != "" {
+				r.RemoteAddr = tc.remoteAddr
+			}
+			if tc.forwardedHost != "" {
+				r.Header.Set(forwardedHostHeaderName, tc.forwardedHost)
+			}
+
+			if got := resolveRealmRoute(r, realms, trustedProxies); got != tc.want {
+				t.Fatalf("want route %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestClientIPFromRequest(t *testing.T) {
+	trustedProxies, err := parseTrustedProxies([]string{"10.0.0.0/8", "192.168.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		want         string
+	}{
+		{
+			name:       "request received directly must be attributed to remote address",
+			remoteAddr: "203.0.113.1:1234",
+			want:       "203.0.113.1",
+		},
+		{
+			name:         "request from untrusted remote address must disregard forwarded for",
+			remoteAddr:   "203.0.113.1:1234",
+			forwardedFor: "198.51.100.1",
+			want:         "203.0.113.1",
+		},
+		{
+			name:         "request from trusted proxy must be attributed to forwarded address",
+			remoteAddr:   "10.0.0.1:1234",
+			forwardedFor: "198.51.100.1",
+			want:         "198.51.100.1",
+		},
+		{
+			name:         "request through several trusted proxies must be attributed to nearest untrusted address",
+			remoteAddr:   "10.0.0.1:1234",
+			forwardedFor: "203.0.113.99, 198.51.100.1, 192.168.0.1",
+			want:         "198.51.100.1",
+		},
+		{
+			name:         "request from trusted proxy with invalid forwarded address must stop at last valid hop",
+			remoteAddr:   "10.0.0.1:1234",
+			forwardedFor: "not-an-ip, 10.0.0.2",
+			want:         "10.0.0.2",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+			r.RemoteAddr = tc.remoteAddr
+			if tc.forwardedFor != "" {
+				r.Header.Set(forwardedForHeaderName, tc.forwardedFor)
+			}
+
+			if got := clientIPFromRequest(r, trustedProxies); got != tc.want {
+				t.Fatalf("want client ip %s, got %s", tc.want, got)
+			}
+		})
+	}
+
+	t.Run("parse invalid trusted proxy must fail", func(t *testing.T) {
+		if _, err := parseTrustedProxies([]string{"not-a-proxy"}); err == nil {
+			t.Fatal("want error, got nil")
+		}
+	})
+}
+
+// mockAPIKeyRepository holds APIKeys in memory, keyed by their ID
+type mockAPIKeyRepository struct {
+	keys map[string]domain.APIKey
+}
+
+func (m *mockAPIKeyRepository) Insert(_ context.Context, key *domain.APIKey) error {
+	m.keys[key.ID.String()] = *key
+	return nil
+}
+
+func (m *mockAPIKeyRepository) Update(_ context.Context, key *domain.APIKey) error {
+	m.keys[key.ID.String()] = *key
+	return nil
+}
+
+func (m *mockAPIKeyRepository) Select(_ context.Context, criteria map[string]interface{}, _ bool) ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	for _, key := range m.keys {
+		if hash, ok := criteria["key_hash"]; ok && hash != key.KeyHash {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, domain.MissingDBRecordError{Err: errors.New("no api keys found")}
+	}
+	return keys, nil
+}
+
+func (m *mockAPIKeyRepository) IncrementUsage(_ context.Context, id string, usedAt time.Time) error {
+	key := m.keys[id]
+	key.UsageCount++
+	key.LastUsedAt = &usedAt
+	m.keys[id] = key
+	return nil
+}
+
+// mockAuditRepository discards every AuditRecord it is given
+type mockAuditRepository struct{}
+
+func (m *mockAuditRepository) Insert(_ context.Context, _ *domain.AuditRecord) error {
+	return nil
+}
+
+func (m *mockAuditRepository) Select(_ context.Context, _ map[string]interface{}, _ bool) ([]domain.AuditRecord, error) {
+	return nil, domain.MissingDBRecordError{Err: errors.New("no audit records found")}
+}
+
+// mockAdminUserRepository holds AdminUsers in memory, keyed by their username
+type mockAdminUserRepository struct {
+	users map[string]domain.AdminUser
+}
+
+func (m *mockAdminUserRepository) Insert(_ context.Context, user *domain.AdminUser) error {
+	m.users[user.Username] = *user
+	return nil
+}
+
+func (m *mockAdminUserRepository) Update(_ context.Context, user *domain.AdminUser) error {
+	m.users[user.Username] = *user
+	return nil
+}
+
+func (m *mockAdminUserRepository) Select(_ context.Context, criteria map[string]interface{}, _ bool) ([]domain.AdminUser, error) {
+	var users []domain.AdminUser
+	for _, user := range m.users {
+		if username, ok := criteria["username"]; ok && username != user.Username {
+			continue
+		}
+		users = append(users, user)
+	}
+	if len(users) == 0 {
+		return nil, domain.MissingDBRecordError{Err: errors.New("no admin users found")}
+	}
+	return users, nil
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"prediction-league/service/internal/domain"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/robfig/cron/v3"
 )
@@ -20,17 +22,42 @@ const (
 	predictionWindowClosingCronSpec = "48 16 * * *"
 
 	// retrieveLatestStandingsCronSpec determines the frequency by which the RetrieveLatestStandingsWorker will run
+	// if no fixtures are available for its season
 	retrieveLatestStandingsCronSpec = "@every 0h15m"
+
+	// paymentReminderCronSpec determines the frequency by which the PaymentReminderWorker will run
+	// (i.e. every hour, at 21 minutes past)
+	paymentReminderCronSpec = "21 * * * *"
+
+	// tokenJanitorCronSpec determines the frequency by which the TokenJanitorWorker will run
+	// (i.e. every day at 3:17am)
+	tokenJanitorCronSpec = "17 3 * * *"
+
+	// refreshFixturesInterval determines how often a season's fixtures are retrieved again while its standings are polled,
+	// so that rescheduled or postponed fixtures are picked up
+	refreshFixturesInterval = 6 * time.Hour
+
+	// retrieveFixturesTimeout determines how long to wait for a season's fixtures to be retrieved from the football data source
+	retrieveFixturesTimeout = 10 * time.Second
 )
 
 // CronHandler encapsulates the logic required to generate our cron jobs
 type CronHandler struct {
 	entryAgent                 *domain.EntryAgent
 	standingsAgent             *domain.StandingsAgent
+	quarantineAgent            *domain.StandingsQuarantineAgent
+	snapshotAgent              *domain.StandingsSnapshotAgent
+	correctionAgent            *domain.StandingsCorrectionAgent
 	scoredEntryPredictionAgent *domain.ScoredEntryPredictionAgent
 	commsAgent                 *domain.CommunicationsAgent
 	mwSubmissionAgent          *domain.MatchWeekSubmissionAgent
 	mwResultAgent              *domain.MatchWeekResultAgent
+	prizeAgent                 *domain.PrizeAgent
+	tokenAgent                 *domain.TokenAgent
+	waitlistAgent              *domain.WaitlistAgent
+	tokenJanitorStats          *domain.TokenJanitorStats
+	paymentReminderDelay       time.Duration
+	tokenRetentionPeriod       time.Duration
 	seasonCollection           domain.SeasonCollection
 	teamCollection             domain.TeamCollection
 	realmCollection            domain.RealmCollection
@@ -100,8 +127,23 @@ func (c *CronHandler) generateJobConfigs(seasons []domain.Season) ([]*jobConfig,
 		}
 
 		jobs = append(jobs, j)
+
+		j, err = c.newPaymentReminderJob(s)
+		if err != nil {
+			return nil, fmt.Errorf("cannot generate new payment reminder job: %w", err)
+		}
+
+		jobs = append(jobs, j)
 	}
 
+	// tokens are not bound to a season, so a single job cleans up after every realm
+	j, err := c.newTokenJanitorJob()
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate new token janitor job: %w", err)
+	}
+
+	jobs = append(jobs, j)
+
 	return jobs, nil
 }
 
@@ -116,9 +158,13 @@ func (c *CronHandler) newRetrieveLatestStandingsJob(season domain.Season) (*jobC
 		Logger:                     c.logger,
 		EntryAgent:                 c.entryAgent,
 		StandingsAgent:             c.standingsAgent,
+		QuarantineAgent:            c.quarantineAgent,
+		SnapshotAgent:              c.snapshotAgent,
+		CorrectionAgent:            c.correctionAgent,
 		ScoredEntryPredictionAgent: c.scoredEntryPredictionAgent,
 		MatchWeekSubmissionAgent:   c.mwSubmissionAgent,
 		MatchWeekResultAgent:       c.mwResultAgent,
+		PrizeAgent:                 c.prizeAgent,
 		EmailIssuer:                c.commsAgent,
 		FootballClient:             c.footballClient,
 	}
@@ -129,23 +175,176 @@ func (c *CronHandler) newRetrieveLatestStandingsJob(season domain.Season) (*jobC
 	}
 
 	task, err := domain.HandleWorker(jobName, 5, worker, c.logger)
+	if err != nil {
+		return nil, fmt.Errorf("cannot handle retrieve latest standings worker: %w", err)
+	}
+
+	fixtures, err := c.retrieveFixtures(season)
+	if err != nil {
+		c.logger.Errorf("%s: polling at fixed interval: %s", jobName, err.Error())
+		return &jobConfig{
+			spec: retrieveLatestStandingsCronSpec,
+			task: task,
+		}, nil
+	}
+
+	schedule := domain.NewPollingSchedule(season, fixtures)
+
+	var refreshMux sync.Mutex
+	refreshedAt := c.clock.Now()
+
+	// refreshFixtures retrieves the season's fixtures again once they are old enough, so the schedule follows any changes
+	refreshFixtures := func() {
+		refreshMux.Lock()
+		defer refreshMux.Unlock()
+
+		if c.clock.Now().Sub(refreshedAt) < refreshFixturesInterval {
+			return
+		}
+		// a failed attempt also waits for the next interval, rather than retrying on every poll
+		refreshedAt = c.clock.Now()
+
+		fixtures, err := c.retrieveFixtures(season)
+		if err != nil {
+			c.logger.Errorf("%s: cannot refresh fixtures: %s", jobName, err.Error())
+			return
+		}
+
+		schedule.SetFixtures(fixtures)
+	}
 
 	return &jobConfig{
-		spec: retrieveLatestStandingsCronSpec,
+		schedule: schedule,
+		task: func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			finalised, err := worker.HasFinalisedSeason(ctx)
+			if err != nil {
+				c.logger.Errorf("%s: cannot determine whether season is finalised: %s", jobName, err.Error())
+			}
+			if finalised {
+				// nothing more to retrieve, so stop polling
+				c.logger.Infof("%s: season is finalised, polling stopped", jobName)
+				schedule.Stop()
+				return
+			}
+
+			refreshFixtures()
+			task()
+		},
+	}, nil
+}
+
+// newPaymentReminderJob returns a new job that reminds entrants to pay for their entry into the provided season,
+// promotes waitlisted entries into places given up by unpaid entries, and expires any entries that remain unpaid
+// once the season has stopped accepting entries
+func (c *CronHandler) newPaymentReminderJob(season domain.Season) (*jobConfig, error) {
+	jobName := strings.ToLower(fmt.Sprintf("payment-reminder-%s", season.ID))
+
+	worker, err := domain.NewPaymentReminderWorker(domain.PaymentReminderWorkerParams{
+		Season:          season,
+		RealmCollection: c.realmCollection,
+		ReminderDelay:   c.paymentReminderDelay,
+		Clock:           c.clock,
+		Logger:          c.logger,
+		EntryAgent:      c.entryAgent,
+		TokenAgent:      c.tokenAgent,
+		WaitlistAgent:   c.waitlistAgent,
+		EmailIssuer:     c.commsAgent,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot instantiate payment reminder worker: %w", err)
+	}
+
+	task, err := domain.HandleWorker(jobName, 30, worker, c.logger)
+	if err != nil {
+		return nil, fmt.Errorf("cannot handle payment reminder worker: %w", err)
+	}
+
+	return &jobConfig{
+		spec: paymentReminderCronSpec,
+		task: task,
+	}, nil
+}
+
+// newTokenJanitorJob returns a new job that deletes expired tokens, and redeemed tokens that are older than the retention period
+func (c *CronHandler) newTokenJanitorJob() (*jobConfig, error) {
+	worker, err := domain.NewTokenJanitorWorker(domain.TokenJanitorWorkerParams{
+		RetentionPeriod: c.tokenRetentionPeriod,
+		Clock:           c.clock,
+		Logger:          c.logger,
+		TokenAgent:      c.tokenAgent,
+		Stats:           c.tokenJanitorStats,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot instantiate token janitor worker: %w", err)
+	}
+
+	task, err := domain.HandleWorker("token-janitor", 60, worker, c.logger)
+	if err != nil {
+		return nil, fmt.Errorf("cannot handle token janitor worker: %w", err)
+	}
+
+	return &jobConfig{
+		spec: tokenJanitorCronSpec,
 		task: task,
 	}, nil
 }
 
+// retrieveFixtures returns the fixtures of the provided season, which determine when its latest standings are polled.
+// Fixtures are read from the season's schedule file if one exists, otherwise they are retrieved from the football data source
+func (c *CronHandler) retrieveFixtures(season domain.Season) ([]domain.Fixture, error) {
+	fixtures, err := domain.GetFixturesFromFile(season.ID)
+	switch {
+	case err == nil:
+		// we have a schedule file
+	case errors.As(err, &domain.NotFoundError{}):
+		fds, ok := c.footballClient.(domain.FixtureDataSource)
+		if !ok || season.ClientID == nil {
+			return nil, errors.New("no fixture data source available")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), retrieveFixturesTimeout)
+		defer cancel()
+
+		fixtures, err = fds.RetrieveFixturesBySeason(ctx, season)
+		if err != nil {
+			return nil, fmt.Errorf("cannot retrieve fixtures: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("cannot get fixtures from file: %w", err)
+	}
+
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("no fixtures found for season '%s'", season.ID)
+	}
+
+	return fixtures, nil
+}
+
 func NewCronHandler(c *container) (*CronHandler, error) {
 	if c == nil {
 		return nil, fmt.Errorf("container: %w", domain.ErrIsNil)
 	}
+	if c.config == nil {
+		return nil, fmt.Errorf("config: %w", domain.ErrIsNil)
+	}
 	if c.entryAgent == nil {
 		return nil, fmt.Errorf("entry agent: %w", domain.ErrIsNil)
 	}
 	if c.standingsAgent == nil {
 		return nil, fmt.Errorf("standings agent: %w", domain.ErrIsNil)
 	}
+	if c.quarantineAgent == nil {
+		return nil, fmt.Errorf("standings quarantine agent: %w", domain.ErrIsNil)
+	}
+	if c.snapshotAgent == nil {
+		return nil, fmt.Errorf("standings snapshot agent: %w", domain.ErrIsNil)
+	}
+	if c.correctionAgent == nil {
+		return nil, fmt.Errorf("standings correction agent: %w", domain.ErrIsNil)
+	}
 	if c.sepAgent == nil {
 		return nil, fmt.Errorf("scored entry prediction agent: %w", domain.ErrIsNil)
 	}
@@ -158,6 +357,18 @@ func NewCronHandler(c *container) (*CronHandler, error) {
 	if c.mwResultAgent == nil {
 		return nil, fmt.Errorf("match week result agent: %w", domain.ErrIsNil)
 	}
+	if c.prizeAgent == nil {
+		return nil, fmt.Errorf("prize agent: %w", domain.ErrIsNil)
+	}
+	if c.tokenAgent == nil {
+		return nil, fmt.Errorf("token agent: %w", domain.ErrIsNil)
+	}
+	if c.waitlistAgent == nil {
+		return nil, fmt.Errorf("waitlist agent: %w", domain.ErrIsNil)
+	}
+	if c.tokenJanitorStats == nil {
+		return nil, fmt.Errorf("token janitor stats: %w", domain.ErrIsNil)
+	}
 	if c.seasons == nil {
 		return nil, fmt.Errorf("season collection: %w", domain.ErrIsNil)
 	}
@@ -180,10 +391,19 @@ func NewCronHandler(c *container) (*CronHandler, error) {
 	return &CronHandler{
 		entryAgent:                 c.entryAgent,
 		standingsAgent:             c.standingsAgent,
+		quarantineAgent:            c.quarantineAgent,
+		snapshotAgent:              c.snapshotAgent,
+		correctionAgent:            c.correctionAgent,
 		scoredEntryPredictionAgent: c.sepAgent,
 		commsAgent:                 c.commsAgent,
 		mwSubmissionAgent:          c.mwSubmissionAgent,
 		mwResultAgent:              c.mwResultAgent,
+		prizeAgent:                 c.prizeAgent,
+		tokenAgent:                 c.tokenAgent,
+		waitlistAgent:              c.waitlistAgent,
+		tokenJanitorStats:          c.tokenJanitorStats,
+		paymentReminderDelay:       c.config.PaymentReminderDelay,
+		tokenRetentionPeriod:       c.config.TokenRetentionPeriod,
 		seasonCollection:           c.seasons,
 		teamCollection:             c.teams,
 		realmCollection:            c.realms,
@@ -198,6 +418,11 @@ func newCronFromJobConfigs(jobs []*jobConfig) (*cron.Cron, error) {
 	cr := cron.New()
 
 	for _, j := range jobs {
+		if j.schedule != nil {
+			cr.Schedule(j.schedule, cron.FuncJob(j.task))
+			continue
+		}
+
 		if _, err := cr.AddFunc(j.spec, j.task); err != nil {
 			return nil, fmt.Errorf("cannot add function: %w", err)
 		}
@@ -206,8 +431,9 @@ func newCronFromJobConfigs(jobs []*jobConfig) (*cron.Cron, error) {
 	return cr, nil
 }
 
-// jobConfig encapsulates our cron jobConfig attributes
+// jobConfig encapsulates our cron jobConfig attributes, a schedule takes precedence over a spec if provided
 type jobConfig struct {
-	spec string
-	task func()
+	spec     string
+	schedule cron.Schedule
+	task     func()
 }
@@ -2,6 +2,7 @@ package app
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"net/http"
 	"prediction-league/service/internal/adapters/footballdataorg"
@@ -14,10 +15,18 @@ import (
 func TestNewCronHandler(t *testing.T) {
 	ea := &domain.EntryAgent{}
 	sa := &domain.StandingsAgent{}
+	qa := &domain.StandingsQuarantineAgent{}
+	ssa := &domain.StandingsSnapshotAgent{}
+	sca := &domain.StandingsCorrectionAgent{}
 	sepa := &domain.ScoredEntryPredictionAgent{}
 	ca := &domain.CommunicationsAgent{}
 	mwsa := &domain.MatchWeekSubmissionAgent{}
 	mwra := &domain.MatchWeekResultAgent{}
+	pza := &domain.PrizeAgent{}
+	tka := &domain.TokenAgent{}
+	wla := &domain.WaitlistAgent{}
+	tjs := &domain.TokenJanitorStats{}
+	cfg := &Config{}
 	sc := make(domain.SeasonCollection)
 	tc := make(domain.TeamCollection)
 	rlms := make(domain.RealmCollection, 0)
@@ -29,10 +38,18 @@ func TestNewCronHandler(t *testing.T) {
 		name    string
 		ea      *domain.EntryAgent
 		sa      *domain.StandingsAgent
+		qa      *domain.StandingsQuarantineAgent
+		ssa     *domain.StandingsSnapshotAgent
+		sca     *domain.StandingsCorrectionAgent
 		sepa    *domain.ScoredEntryPredictionAgent
 		ca      *domain.CommunicationsAgent
 		mwsa    *domain.MatchWeekSubmissionAgent
 		mwra    *domain.MatchWeekResultAgent
+		pza     *domain.PrizeAgent
+		tka     *domain.TokenAgent
+		wla     *domain.WaitlistAgent
+		tjs     *domain.TokenJanitorStats
+		cfg     *Config
 		sc      domain.SeasonCollection
 		tc      domain.TeamCollection
 		rlms    domain.RealmCollection
@@ -41,19 +58,27 @@ func TestNewCronHandler(t *testing.T) {
 		fds     domain.FootballDataSource
 		wantErr error
 	}{
-		{"missing entry agent", nil, sa, sepa, ca, mwsa, mwra, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
-		{"missing standings agent", ea, nil, sepa, ca, mwsa, mwra, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
-		{"missing scored entry predictions agent", ea, sa, nil, ca, mwsa, mwra, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
-		{"missing comms agent", ea, sa, sepa, nil, mwsa, mwra, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
-		{"missing match week submission agent", ea, sa, sepa, ca, nil, mwra, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
-		{"missing match week result agent", ea, sa, sepa, ca, mwsa, nil, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
-		{"missing season collection", ea, sa, sepa, ca, mwsa, mwra, nil, tc, rlms, cl, l, fds, domain.ErrIsNil},
-		{"missing team collection", ea, sa, sepa, ca, mwsa, mwra, sc, nil, rlms, cl, l, fds, domain.ErrIsNil},
-		{"missing realm collection", ea, sa, sepa, ca, mwsa, mwra, sc, tc, nil, cl, l, fds, domain.ErrIsNil},
-		{"missing clock", ea, sa, sepa, ca, mwsa, mwra, sc, tc, rlms, nil, l, fds, domain.ErrIsNil},
-		{"missing logger", ea, sa, sepa, ca, mwsa, mwra, sc, tc, rlms, cl, nil, fds, domain.ErrIsNil},
-		{"missing football client", ea, sa, sepa, ca, mwsa, mwra, sc, tc, rlms, cl, l, nil, domain.ErrIsNil},
-		{"no missing dependencies", ea, sa, sepa, ca, mwsa, mwra, sc, tc, rlms, cl, l, fds, nil},
+		{"missing entry agent", nil, sa, qa, ssa, sca, sepa, ca, mwsa, mwra, pza, tka, wla, tjs, cfg, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
+		{"missing standings agent", ea, nil, qa, ssa, sca, sepa, ca, mwsa, mwra, pza, tka, wla, tjs, cfg, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
+		{"missing standings quarantine agent", ea, sa, nil, ssa, sca, sepa, ca, mwsa, mwra, pza, tka, wla, tjs, cfg, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
+		{"missing standings snapshot agent", ea, sa, qa, nil, sca, sepa, ca, mwsa, mwra, pza, tka, wla, tjs, cfg, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
+		{"missing standings correction agent", ea, sa, qa, ssa, nil, sepa, ca, mwsa, mwra, pza, tka, wla, tjs, cfg, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
+		{"missing scored entry predictions agent", ea, sa, qa, ssa, sca, nil, ca, mwsa, mwra, pza, tka, wla, tjs, cfg, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
+		{"missing comms agent", ea, sa, qa, ssa, sca, sepa, nil, mwsa, mwra, pza, tka, wla, tjs, cfg, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
+		{"missing match week submission agent", ea, sa, qa, ssa, sca, sepa, ca, nil, mwra, pza, tka, wla, tjs, cfg, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
+		{"missing match week result agent", ea, sa, qa, ssa, sca, sepa, ca, mwsa, nil, pza, tka, wla, tjs, cfg, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
+		{"missing prize agent", ea, sa, qa, ssa, sca, sepa, ca, mwsa, mwra, nil, tka, wla, tjs, cfg, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
+		{"missing token agent", ea, sa, qa, ssa, sca, sepa, ca, mwsa, mwra, pza, nil, wla, tjs, cfg, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
+		{"missing waitlist agent", ea, sa, qa, ssa, sca, sepa, ca, mwsa, mwra, pza, tka, nil, tjs, cfg, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
+		{"missing token janitor stats", ea, sa, qa, ssa, sca, sepa, ca, mwsa, mwra, pza, tka, wla, nil, cfg, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
+		{"missing config", ea, sa, qa, ssa, sca, sepa, ca, mwsa, mwra, pza, tka, wla, tjs, nil, sc, tc, rlms, cl, l, fds, domain.ErrIsNil},
+		{"missing season collection", ea, sa, qa, ssa, sca, sepa, ca, mwsa, mwra, pza, tka, wla, tjs, cfg, nil, tc, rlms, cl, l, fds, domain.ErrIsNil},
+		{"missing team collection", ea, sa, qa, ssa, sca, sepa, ca, mwsa, mwra, pza, tka, wla, tjs, cfg, sc, nil, rlms, cl, l, fds, domain.ErrIsNil},
+		{"missing realm collection", ea, sa, qa, ssa, sca, sepa, ca, mwsa, mwra, pza, tka, wla, tjs, cfg, sc, tc, nil, cl, l, fds, domain.ErrIsNil},
+		{"missing clock", ea, sa, qa, ssa, sca, sepa, ca, mwsa, mwra, pza, tka, wla, tjs, cfg, sc, tc, rlms, nil, l, fds, domain.ErrIsNil},
+		{"missing logger", ea, sa, qa, ssa, sca, sepa, ca, mwsa, mwra, pza, tka, wla, tjs, cfg, sc, tc, rlms, cl, nil, fds, domain.ErrIsNil},
+		{"missing football client", ea, sa, qa, ssa, sca, sepa, ca, mwsa, mwra, pza, tka, wla, tjs, cfg, sc, tc, rlms, cl, l, nil, domain.ErrIsNil},
+		{"no missing dependencies", ea, sa, qa, ssa, sca, sepa, ca, mwsa, mwra, pza, tka, wla, tjs, cfg, sc, tc, rlms, cl, l, fds, nil},
 	}
 
 	for idx, tc := range tt {
@@ -61,10 +86,18 @@ func TestNewCronHandler(t *testing.T) {
 			cnt := &container{
 				entryAgent:        tc.ea,
 				standingsAgent:    tc.sa,
+				quarantineAgent:   tc.qa,
+				snapshotAgent:     tc.ssa,
+				correctionAgent:   tc.sca,
 				sepAgent:          tc.sepa,
 				commsAgent:        tc.ca,
 				mwSubmissionAgent: tc.mwsa,
 				mwResultAgent:     tc.mwra,
+				prizeAgent:        tc.pza,
+				tokenAgent:        tc.tka,
+				waitlistAgent:     tc.wla,
+				tokenJanitorStats: tc.tjs,
+				config:            tc.cfg,
 				seasons:           tc.sc,
 				teams:             tc.tc,
 				realms:            tc.rlms,
@@ -130,9 +163,16 @@ func TestCronFactory_GenerateCron(t *testing.T) {
 		ea := &domain.EntryAgent{}
 		ca := &domain.CommunicationsAgent{}
 		sa := &domain.StandingsAgent{}
+		qa := &domain.StandingsQuarantineAgent{}
+		ssa := &domain.StandingsSnapshotAgent{}
+		sca := &domain.StandingsCorrectionAgent{}
 		sepa := &domain.ScoredEntryPredictionAgent{}
 		mwsa := &domain.MatchWeekSubmissionAgent{}
 		mwra := &domain.MatchWeekResultAgent{}
+		pza := &domain.PrizeAgent{}
+		tka := &domain.TokenAgent{}
+		wla := &domain.WaitlistAgent{}
+		tjs := &domain.TokenJanitorStats{}
 
 		buf := &bytes.Buffer{}
 		loc, err := time.LoadLocation("Europe/London")
@@ -159,7 +199,14 @@ func TestCronFactory_GenerateCron(t *testing.T) {
 			commsAgent:                 ca,
 			mwSubmissionAgent:          mwsa,
 			mwResultAgent:              mwra,
+			prizeAgent:                 pza,
+			tokenAgent:                 tka,
+			waitlistAgent:              wla,
+			tokenJanitorStats:          tjs,
 			standingsAgent:             sa,
+			quarantineAgent:            qa,
+			snapshotAgent:              ssa,
+			correctionAgent:            sca,
 			scoredEntryPredictionAgent: sepa,
 			logger:                     l,
 			footballClient:             fds,
@@ -170,13 +217,89 @@ func TestCronFactory_GenerateCron(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		// 1 job per season
-		if len(cr.Entries()) != 2 {
-			t.Fatalf("want 2 cron entries, got %d", len(cr.Entries()))
+		// 2 jobs per season, plus the token janitor
+		if len(cr.Entries()) != 5 {
+			t.Fatalf("want 5 cron entries, got %d", len(cr.Entries()))
 		}
 	})
 }
 
+func TestCronHandler_NewRetrieveLatestStandingsJob(t *testing.T) {
+	season := domain.Season{
+		ID:       "season-id",
+		ClientID: domain.SeasonIdentifier{SeasonID: "season-client-id"},
+		Live: domain.TimeFrame{
+			From:  time.Date(2018, 8, 10, 0, 0, 0, 0, time.UTC),
+			Until: time.Date(2019, 5, 12, 23, 59, 59, 0, time.UTC),
+		},
+	}
+
+	l, err := logger.NewLogger("DEBUG", &bytes.Buffer{}, &mockClock{t: season.Live.From})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newHandler := func(fds domain.FootballDataSource) *CronHandler {
+		return &CronHandler{
+			teamCollection:             make(domain.TeamCollection),
+			clock:                      &domain.RealClock{},
+			entryAgent:                 &domain.EntryAgent{},
+			commsAgent:                 &domain.CommunicationsAgent{},
+			mwSubmissionAgent:          &domain.MatchWeekSubmissionAgent{},
+			mwResultAgent:              &domain.MatchWeekResultAgent{},
+			prizeAgent:                 &domain.PrizeAgent{},
+			standingsAgent:             &domain.StandingsAgent{},
+			quarantineAgent:            &domain.StandingsQuarantineAgent{},
+			snapshotAgent:              &domain.StandingsSnapshotAgent{},
+			correctionAgent:            &domain.StandingsCorrectionAgent{},
+			scoredEntryPredictionAgent: &domain.ScoredEntryPredictionAgent{},
+			logger:                     l,
+			footballClient:             fds,
+		}
+	}
+
+	t.Run("must use polling schedule when fixtures are available", func(t *testing.T) {
+		fds := &mockFixtureDataSource{fixtures: []domain.Fixture{
+			{MatchWeek: 1, KickOff: time.Date(2018, 8, 10, 19, 0, 0, 0, time.UTC)},
+		}}
+
+		j, err := newHandler(fds).newRetrieveLatestStandingsJob(season)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, ok := j.schedule.(*domain.PollingSchedule); !ok {
+			t.Fatalf("want polling schedule, got %T", j.schedule)
+		}
+	})
+
+	t.Run("must fall back to fixed spec when fixtures cannot be retrieved", func(t *testing.T) {
+		fds := &mockFixtureDataSource{err: errors.New("sad times :'(")}
+
+		j, err := newHandler(fds).newRetrieveLatestStandingsJob(season)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if j.schedule != nil {
+			t.Fatalf("want nil schedule, got %T", j.schedule)
+		}
+		if j.spec != retrieveLatestStandingsCronSpec {
+			t.Fatalf("want spec %s, got %s", retrieveLatestStandingsCronSpec, j.spec)
+		}
+	})
+}
+
+type mockFixtureDataSource struct {
+	domain.FootballDataSource
+	fixtures []domain.Fixture
+	err      error
+}
+
+func (m *mockFixtureDataSource) RetrieveFixturesBySeason(_ context.Context, _ domain.Season) ([]domain.Fixture, error) {
+	return m.fixtures, m.err
+}
+
 type mockClock struct {
 	t time.Time
 	domain.Clock
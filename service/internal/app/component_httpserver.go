@@ -68,7 +68,7 @@ func NewHTTPServer(cnt *container) (*httpServer, error) {
 
 	srv := &http.Server{
 		Addr:              fmt.Sprintf(":%s", cnt.config.ServicePort),
-		Handler:           newRouter(cnt),
+		Handler:           realmRoutingHandler(cnt, newRouter(cnt)),
 		WriteTimeout:      5 * time.Second,
 		ReadTimeout:       5 * time.Second,
 		IdleTimeout:       5 * time.Second,
@@ -83,6 +83,12 @@ func NewHTTPServer(cnt *container) (*httpServer, error) {
 // newRouter instantiates a router for the HTTP server
 func newRouter(cnt *container) *mux.Router {
 	rtr := mux.NewRouter()
+	rtr.Use(
+		requestContextMiddleware,
+		securityHeadersMiddleware(cnt),
+		csrfMiddleware(cnt),
+		renewSessionMiddleware(cnt),
+	)
 
 	// api endpoints
 	api := rtr.PathPrefix("/api").Subrouter()
@@ -90,15 +96,48 @@ func newRouter(cnt *container) *mux.Router {
 	api.HandleFunc("/season/{season_id}", retrieveSeasonHandler(cnt)).Methods(http.MethodGet)
 	api.HandleFunc("/season/{season_id}/entry", createEntryHandler(cnt)).Methods(http.MethodPost)
 	api.HandleFunc("/season/{season_id}/leaderboard/{round_number:[0-9]+}", retrieveLeaderBoardHandler(cnt)).Methods(http.MethodGet)
+	api.HandleFunc("/season/{season_id}/prizes", retrievePrizesHandler(cnt)).Methods(http.MethodGet)
 
 	api.HandleFunc("/entry/{entry_id}/prediction", createEntryPredictionHandler(cnt)).Methods(http.MethodPost)
 	api.HandleFunc("/entry/{entry_id}/prediction", retrieveLatestEntryPredictionHandler(cnt)).Methods(http.MethodGet)
 	api.HandleFunc("/entry/{entry_id}/scored/{round_number:[0-9]+}", retrieveLatestScoredEntryPrediction(cnt)).Methods(http.MethodGet)
 	api.HandleFunc("/entry/{entry_id}/payment", updateEntryPaymentDetailsHandler(cnt)).Methods(http.MethodPatch)
+	api.HandleFunc("/entry/{entry_id}/payment/order", createEntryPaymentOrderHandler(cnt)).Methods(http.MethodPost)
+	api.HandleFunc("/entry/{entry_id}/payment/capture", captureEntryPaymentOrderHandler(cnt)).Methods(http.MethodPost)
+	api.HandleFunc("/payment/webhook", paymentWebhookHandler(cnt)).Methods(http.MethodPost)
+	api.HandleFunc("/login/code", loginWithCodeHandler(cnt)).Methods(http.MethodPost)
+	api.HandleFunc("/logout", logoutHandler(cnt, false)).Methods(http.MethodPost)
+	api.HandleFunc("/logout/everywhere", logoutHandler(cnt, true)).Methods(http.MethodPost)
 
 	// requires basic auth
-	api.HandleFunc("/entry/{entry_id}/approve", approveEntryByIDHandler(cnt)).Methods(http.MethodPatch)
-	api.HandleFunc("/entry/{entry_id}/generate-login", generateExtendedMagicLoginTokenHandler(cnt)).Methods(http.MethodPost)
+	admin := api.NewRoute().Subrouter()
+	admin.Use(adminAuthMiddleware(cnt))
+
+	admin.HandleFunc("/entries", retrieveEntriesHandler(cnt)).Methods(http.MethodGet)
+	admin.HandleFunc("/entries/approve", approveEntriesHandler(cnt)).Methods(http.MethodPatch)
+	admin.HandleFunc("/entries/payments/export", exportEntryPaymentsHandler(cnt)).Methods(http.MethodGet)
+	admin.HandleFunc("/entries/payments/reconcile", reconcileEntryPaymentsHandler(cnt)).Methods(http.MethodPost)
+	admin.HandleFunc("/entry/{entry_id}", retrieveEntryByIDHandler(cnt)).Methods(http.MethodGet)
+	admin.HandleFunc("/entry/{entry_id}", updateEntrantDetailsHandler(cnt)).Methods(http.MethodPatch)
+	admin.HandleFunc("/entry/{entry_id}/withdraw", excludeEntryByIDHandler(cnt, domain.EntryStatusWithdrawn)).Methods(http.MethodPatch)
+	admin.HandleFunc("/entry/{entry_id}/disqualify", excludeEntryByIDHandler(cnt, domain.EntryStatusDisqualified)).Methods(http.MethodPatch)
+	admin.HandleFunc("/entry/{entry_id}/approve", approveEntryByIDHandler(cnt)).Methods(http.MethodPatch)
+	admin.HandleFunc("/entry/{entry_id}/generate-login", generateExtendedMagicLoginTokenHandler(cnt)).Methods(http.MethodPost)
+	admin.HandleFunc("/entry/{entry_id}/revoke-sessions", revokeEntrySessionsHandler(cnt)).Methods(http.MethodPost)
+	admin.HandleFunc("/season/{season_id}/standings/quarantine", retrieveQuarantinedStandingsHandler(cnt)).Methods(http.MethodGet)
+	admin.HandleFunc("/standings/quarantine/{quarantine_id}/accept", reviewQuarantinedStandingsHandler(cnt, domain.QuarantineStatusAccepted)).Methods(http.MethodPatch)
+	admin.HandleFunc("/standings/quarantine/{quarantine_id}/reject", reviewQuarantinedStandingsHandler(cnt, domain.QuarantineStatusRejected)).Methods(http.MethodPatch)
+	admin.HandleFunc("/season/{season_id}/standings/{round_number:[0-9]+}/snapshots", retrieveStandingsSnapshotsHandler(cnt)).Methods(http.MethodGet)
+	admin.HandleFunc("/standings/snapshot/{from_snapshot_id}/diff/{to_snapshot_id}", diffStandingsSnapshotsHandler(cnt)).Methods(http.MethodGet)
+	admin.HandleFunc("/season/{season_id}/standings/corrections", retrieveStandingsCorrectionsHandler(cnt)).Methods(http.MethodGet)
+	admin.HandleFunc("/admin-users", retrieveAdminUsersHandler(cnt)).Methods(http.MethodGet)
+	admin.HandleFunc("/audit", retrieveAuditRecordsHandler(cnt)).Methods(http.MethodGet)
+	admin.HandleFunc("/tokens/janitor", retrieveTokenJanitorRunHandler(cnt)).Methods(http.MethodGet)
+	admin.HandleFunc("/admin-users", createAdminUserHandler(cnt)).Methods(http.MethodPost)
+	admin.HandleFunc("/admin-users/{username}/role", setAdminUserRoleHandler(cnt)).Methods(http.MethodPatch)
+	admin.HandleFunc("/api-keys", retrieveAPIKeysHandler(cnt)).Methods(http.MethodGet)
+	admin.HandleFunc("/api-keys", createAPIKeyHandler(cnt)).Methods(http.MethodPost)
+	admin.HandleFunc("/api-keys/{api_key_id}/revoke", revokeAPIKeyHandler(cnt)).Methods(http.MethodPatch)
 
 	// serve static assets
 	assets := http.Dir("./resources/dist")
@@ -113,7 +152,10 @@ func newRouter(cnt *container) *mux.Router {
 
 	rtr.HandleFunc("/login", frontendGenerateMagicLoginHandler(cnt)).Methods(http.MethodPost)
 	rtr.HandleFunc("/login/failed", frontendMagicLoginFailedHandler(cnt)).Methods(http.MethodGet)
+	rtr.HandleFunc("/login/code", frontendLoginCodeHandler(cnt)).Methods(http.MethodGet)
+	rtr.HandleFunc("/login/code", frontendRedeemLoginCodeHandler(cnt)).Methods(http.MethodPost)
 	rtr.HandleFunc("/login/{magic_token_id}", frontendRedeemMagicLoginHandler(cnt)).Methods(http.MethodGet)
+	rtr.HandleFunc("/verify-email/{verification_token_id}", frontendVerifyEmailHandler(cnt)).Methods(http.MethodGet)
 
 	return rtr
 }
@@ -3,6 +3,7 @@ package app
 import (
 	"fmt"
 	"prediction-league/service/internal/domain"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
@@ -10,16 +11,25 @@ import (
 
 // Config encapsulate the required options
 type Config struct {
-	ServicePort          string `envconfig:"SERVICE_PORT" required:"true"`
-	MySQLURL             string `envconfig:"MYSQL_URL" required:"true"`
-	MigrationsPath       string `envconfig:"MIGRATIONS_PATH" required:"true"`
-	AdminBasicAuth       string `envconfig:"ADMIN_BASIC_AUTH" required:"true"`
-	LogLevel             string `envconfig:"LOG_LEVEL" required:"true"`
-	FootballDataAPIToken string `envconfig:"FOOTBALLDATA_API_TOKEN" required:"true"`
-	PayPalClientID       string `envconfig:"PAYPAL_CLIENT_ID" required:"true"`
-	MailgunAPIKey        string `envconfig:"MAILGUN_API_KEY" required:"true"`
-	BuildVersion         string
-	BuildTimestamp       string
+	ServicePort              string        `envconfig:"SERVICE_PORT" required:"true"`
+	MySQLURL                 string        `envconfig:"MYSQL_URL" required:"true"`
+	MigrationsPath           string        `envconfig:"MIGRATIONS_PATH" required:"true"`
+	AdminBasicAuth           string        `envconfig:"ADMIN_BASIC_AUTH" required:"true"`
+	LogLevel                 string        `envconfig:"LOG_LEVEL" required:"true"`
+	FootballDataAPIToken     string        `envconfig:"FOOTBALLDATA_API_TOKEN" required:"true"`
+	PayPalClientID           string        `envconfig:"PAYPAL_CLIENT_ID" required:"true"`
+	PayPalClientSecret       string        `envconfig:"PAYPAL_CLIENT_SECRET"`
+	PayPalAPIBaseURL         string        `envconfig:"PAYPAL_API_BASE_URL"`
+	PayPalWebhookID          string        `envconfig:"PAYPAL_WEBHOOK_ID"`
+	StubPaymentWebhookSecret string        `envconfig:"STUB_PAYMENT_WEBHOOK_SECRET"`
+	MailgunAPIKey            string        `envconfig:"MAILGUN_API_KEY" required:"true"`
+	PaymentReminderDelay     time.Duration `envconfig:"PAYMENT_REMINDER_DELAY" default:"48h"`
+	TokenRetentionPeriod     time.Duration `envconfig:"TOKEN_RETENTION_PERIOD" default:"168h"`
+	TokenHashKey             string        `envconfig:"TOKEN_HASH_KEY" required:"true"`
+	RateLimitStore           string        `envconfig:"RATE_LIMIT_STORE" default:"memory"`
+	TrustedProxies           []string      `envconfig:"TRUSTED_PROXIES"`
+	BuildVersion             string
+	BuildTimestamp           string
 }
 
 // ConfigOption defines a type of function for modifying a Config object
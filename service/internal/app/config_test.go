@@ -6,6 +6,7 @@ import (
 	"prediction-league/service/internal/app"
 	"prediction-league/service/internal/domain"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -67,14 +68,23 @@ func TestNewLoadEnvConfigOption(t *testing.T) {
 		opt := app.NewLoadEnvConfigOption(l, "testdata/config_test.env", "non_existent_path")
 
 		wantConfig := &app.Config{
-			ServicePort:          "1234",
-			MySQLURL:             "test-db-user:test-db-pwd@tcp(localhost:3306)/test-db-name?parseTime=true",
-			MigrationsPath:       "test_migrations_url",
-			AdminBasicAuth:       "test_admin_basic_auth",
-			LogLevel:             "test_loglevel",
-			FootballDataAPIToken: "test_football_data_api_token",
-			PayPalClientID:       "test_paypal_client_id",
-			MailgunAPIKey:        "test_mailgun_api_key",
+			ServicePort:              "1234",
+			MySQLURL:                 "test-db-user:test-db-pwd@tcp(localhost:3306)/test-db-name?parseTime=true",
+			MigrationsPath:           "test_migrations_url",
+			AdminBasicAuth:           "test_admin_basic_auth",
+			LogLevel:                 "test_loglevel",
+			FootballDataAPIToken:     "test_football_data_api_token",
+			PayPalClientID:           "test_paypal_client_id",
+			PayPalClientSecret:       "test_paypal_client_secret",
+			PayPalAPIBaseURL:         "test_paypal_api_base_url",
+			PayPalWebhookID:          "test_paypal_webhook_id",
+			StubPaymentWebhookSecret: "test_stub_payment_webhook_secret",
+			MailgunAPIKey:            "test_mailgun_api_key",
+			PaymentReminderDelay:     36 * time.Hour,
+			TokenRetentionPeriod:     96 * time.Hour,
+			TokenHashKey:             "test_token_hash_key",
+			RateLimitStore:           "test_rate_limit_store",
+			TrustedProxies:           []string{"10.0.0.0/8", "192.168.0.1"},
 		}
 
 		gotConfig := &app.Config{}
@@ -4,10 +4,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"net"
 	"prediction-league/service/internal/adapters"
 	"prediction-league/service/internal/adapters/footballdataorg"
 	"prediction-league/service/internal/adapters/mailgun"
 	"prediction-league/service/internal/adapters/mysqldb"
+	"prediction-league/service/internal/adapters/paypal"
 	"prediction-league/service/internal/domain"
 	"time"
 
@@ -15,6 +17,11 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	rateLimitStoreMemory = "memory"
+	rateLimitStoreMySQL  = "mysql"
+)
+
 // container encapsulates the app dependencies
 type container struct {
 	config            *Config
@@ -25,8 +32,21 @@ type container struct {
 	commsAgent        *domain.CommunicationsAgent
 	entryAgent        *domain.EntryAgent
 	standingsAgent    *domain.StandingsAgent
+	quarantineAgent   *domain.StandingsQuarantineAgent
+	snapshotAgent     *domain.StandingsSnapshotAgent
+	correctionAgent   *domain.StandingsCorrectionAgent
 	sepAgent          *domain.ScoredEntryPredictionAgent
 	tokenAgent        *domain.TokenAgent
+	signupAgent       *domain.SignupSessionAgent
+	waitlistAgent     *domain.WaitlistAgent
+	tokenJanitorStats *domain.TokenJanitorStats
+	rateLimitAgent    *domain.RateLimitAgent
+	trustedProxies    []*net.IPNet
+	adminUserAgent    *domain.AdminUserAgent
+	apiKeyAgent       *domain.APIKeyAgent
+	auditAgent        *domain.AuditAgent
+	paymentAgent      *domain.PaymentAgent
+	prizeAgent        *domain.PrizeAgent
 	lbAgent           *domain.LeaderBoardAgent
 	mwSubmissionAgent *domain.MatchWeekSubmissionAgent
 	mwResultAgent     *domain.MatchWeekResultAgent
@@ -107,6 +127,26 @@ func NewContainer(cfg *Config, l domain.Logger, cl domain.Clock) (*container, fu
 		}
 	}
 
+	// instantiate paypal client
+	var pp domain.PaymentProvider
+	switch {
+	case cfg.PayPalClientID != "" && cfg.PayPalClientSecret == "":
+		// payments must never be left to a provider that takes none while the frontend is taking them via paypal
+		return nil, nil, errors.New("missing paypal client secret: required when paypal client id is set")
+	case cfg.PayPalClientID != "":
+		hc := adapters.NewRealHTTPClient(10)
+		pp, err = paypal.NewClient(cfg.PayPalClientID, cfg.PayPalClientSecret, cfg.PayPalWebhookID, cfg.PayPalAPIBaseURL, hc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot instantiate paypal client: %w", err)
+		}
+	default:
+		l.Info("missing paypal client id: payments will be verified by stub payment provider...")
+		pp, err = domain.NewStubPaymentProvider(cfg.StubPaymentWebhookSecret, l)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot instantiate stub payment provider: %w", err)
+		}
+	}
+
 	// instantiate repos
 	er, err := mysqldb.NewEntryRepo(db)
 	if err != nil {
@@ -124,10 +164,46 @@ func NewContainer(cfg *Config, l domain.Logger, cl domain.Clock) (*container, fu
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot instantiate standings repo: %w", err)
 	}
+	sqr, err := mysqldb.NewStandingsQuarantineRepo(db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate standings quarantine repo: %w", err)
+	}
+	ssr, err := mysqldb.NewStandingsSnapshotRepo(db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate standings snapshot repo: %w", err)
+	}
+	scr, err := mysqldb.NewStandingsCorrectionRepo(db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate standings correction repo: %w", err)
+	}
 	tr, err := mysqldb.NewTokenRepo(db)
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot instantiate token repo: %w", err)
 	}
+	per, err := mysqldb.NewPaymentEventRepo(db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate payment event repo: %w", err)
+	}
+	ppr, err := mysqldb.NewPrizePayoutRepo(db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate prize payout repo: %w", err)
+	}
+	sgr, err := mysqldb.NewSignupSessionRepo(db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate signup session repo: %w", err)
+	}
+	ar, err := mysqldb.NewAuditRepo(db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate audit repo: %w", err)
+	}
+	aur, err := mysqldb.NewAdminUserRepo(db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate admin user repo: %w", err)
+	}
+	akr, err := mysqldb.NewAPIKeyRepo(db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate api key repo: %w", err)
+	}
 	mwSubmissionRepo, err := mysqldb.NewMatchWeekSubmissionRepo(db, uuid.NewUUID, time.Now)
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot instantiate match week submission repo: %w", err)
@@ -137,31 +213,95 @@ func NewContainer(cfg *Config, l domain.Logger, cl domain.Clock) (*container, fu
 		return nil, nil, fmt.Errorf("cannot instantiate match week result repo: %w", err)
 	}
 
+	// instantiate rate limit store
+	var rls domain.RateLimitStore
+	switch cfg.RateLimitStore {
+	case rateLimitStoreMySQL:
+		rls, err = mysqldb.NewRateLimitRepo(db)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot instantiate rate limit repo: %w", err)
+		}
+	case rateLimitStoreMemory:
+		l.Info("rate limit attempts will be held in memory: limits are not shared between instances...")
+		rls = domain.NewInMemoryRateLimitStore()
+	default:
+		return nil, nil, fmt.Errorf("invalid rate limit store: %s", cfg.RateLimitStore)
+	}
+
+	trustedProxies, err := parseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
 	// instantiate agents
 	ca, err := domain.NewCommunicationsAgent(er, epr, sr, emlQ, tpl, sc, tc, rc)
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot instantiate communications agent: %w", err)
 	}
-	ea, err := domain.NewEntryAgent(er, epr, sr, sc, cl)
+	aa, err := domain.NewAuditAgent(ar, cl)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate audit agent: %w", err)
+	}
+	ea, err := domain.NewEntryAgent(er, epr, sr, sc, aa, cl)
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot instantiate entry agent: %w", err)
 	}
+	pza, err := domain.NewPrizeAgent(er, sepr, ppr, sc, rc, cl)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate prize agent: %w", err)
+	}
 	sa, err := domain.NewStandingsAgent(sr)
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot instantiate standings agent: %w", err)
 	}
+	sqa, err := domain.NewStandingsQuarantineAgent(sqr, cl)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate standings quarantine agent: %w", err)
+	}
+	ssa, err := domain.NewStandingsSnapshotAgent(ssr, cl)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate standings snapshot agent: %w", err)
+	}
+	sca, err := domain.NewStandingsCorrectionAgent(scr, cl)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate standings correction agent: %w", err)
+	}
 	sepa, err := domain.NewScoredEntryPredictionAgent(er, epr, sr, sepr)
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot instantiate scored entry prediction agent: %w", err)
 	}
-	ta, err := domain.NewTokenAgent(tr, cl, l)
+	ta, err := domain.NewTokenAgent(tr, aa, cl, l, []byte(cfg.TokenHashKey))
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot instantiate token agent: %w", err)
 	}
+	sga, err := domain.NewSignupSessionAgent(sgr, cl)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate signup session agent: %w", err)
+	}
+	wla, err := domain.NewWaitlistAgent(ea, ta, ca, l)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate waitlist agent: %w", err)
+	}
+	pa, err := domain.NewPaymentAgent(ea, wla, pp, per, rc, cl)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate payment agent: %w", err)
+	}
+	aua, err := domain.NewAdminUserAgent(aur, cl)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate admin user agent: %w", err)
+	}
+	aka, err := domain.NewAPIKeyAgent(akr, aa, cl, []byte(cfg.TokenHashKey))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate api key agent: %w", err)
+	}
 	lba, err := domain.NewLeaderBoardAgent(er, epr, sr, sepr, sc)
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot instantiate leaderboard agent: %w", err)
 	}
+	rla, err := domain.NewRateLimitAgent(rls, cl, l)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot instantiate rate limit agent: %w", err)
+	}
 	mwSubmissionAgent, err := domain.NewMatchWeekSubmissionAgent(mwSubmissionRepo)
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot instantiate match week submission agent: %w", err)
@@ -171,6 +311,13 @@ func NewContainer(cfg *Config, l domain.Logger, cl domain.Clock) (*container, fu
 		return nil, nil, fmt.Errorf("cannot instantiate match week result agent: %w", err)
 	}
 
+	// ensure the admin user defined by config exists, so that there is always someone able to manage other admin users
+	ctx, cancel := domain.NewContext()
+	defer cancel()
+	if _, err := aua.BootstrapAdminUser(ctx, cfg.AdminBasicAuth); err != nil {
+		return nil, nil, fmt.Errorf("cannot bootstrap admin user: %w", err)
+	}
+
 	cnt := &container{
 		cfg,
 		rc,
@@ -180,8 +327,21 @@ func NewContainer(cfg *Config, l domain.Logger, cl domain.Clock) (*container, fu
 		ca,
 		ea,
 		sa,
+		sqa,
+		ssa,
+		sca,
 		sepa,
 		ta,
+		sga,
+		wla,
+		domain.NewTokenJanitorStats(),
+		rla,
+		trustedProxies,
+		aua,
+		aka,
+		aa,
+		pa,
+		pza,
 		lba,
 		mwSubmissionAgent,
 		mwResultAgent,
@@ -0,0 +1,116 @@
+package app
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"prediction-league/service/internal/domain"
+)
+
+func retrieveAdminUsersHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		users, err := c.adminUserAgent.RetrieveAdminUsers(ctx)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		okResponse(&data{
+			Type:    "admin_users",
+			Content: users,
+		}).writeTo(w)
+	}
+}
+
+func createAdminUserHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var input createAdminUserRequest
+
+		// read request body
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			internalError(err).writeTo(w)
+			return
+		}
+		defer closeBody(r)
+
+		// parse request body
+		if err := json.Unmarshal(body, &input); err != nil {
+			responseFromError(domain.BadRequestError{Err: err}).writeTo(w)
+			return
+		}
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		user, err := c.adminUserAgent.CreateAdminUser(ctx, input.Username, input.Password, input.RealmRoles)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		createdResponse(&data{
+			Type:    "admin_user",
+			Content: user,
+		}).writeTo(w)
+	}
+}
+
+func setAdminUserRoleHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var input setAdminUserRoleRequest
+
+		// read request body
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			internalError(err).writeTo(w)
+			return
+		}
+		defer closeBody(r)
+
+		// parse request body
+		if err := json.Unmarshal(body, &input); err != nil {
+			responseFromError(domain.BadRequestError{Err: err}).writeTo(w)
+			return
+		}
+
+		// parse username from route
+		var username string
+		if err := getRouteParam(r, "username", &username); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		user, err := c.adminUserAgent.SetAdminUserRole(ctx, username, input.RealmName, input.Role)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		okResponse(&data{
+			Type:    "admin_user",
+			Content: user,
+		}).writeTo(w)
+	}
+}
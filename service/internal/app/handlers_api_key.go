@@ -0,0 +1,103 @@
+package app
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"prediction-league/service/internal/domain"
+)
+
+func retrieveAPIKeysHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		keys, err := c.apiKeyAgent.RetrieveAPIKeys(ctx)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		okResponse(&data{
+			Type:    "api_keys",
+			Content: keys,
+		}).writeTo(w)
+	}
+}
+
+func createAPIKeyHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var input createAPIKeyRequest
+
+		// read request body
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			internalError(err).writeTo(w)
+			return
+		}
+		defer closeBody(r)
+
+		// parse request body
+		if err := json.Unmarshal(body, &input); err != nil {
+			responseFromError(domain.BadRequestError{Err: err}).writeTo(w)
+			return
+		}
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		apiKey, key, err := c.apiKeyAgent.CreateAPIKey(ctx, input.Name)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		createdResponse(&data{
+			Type: "api_key",
+			Content: createAPIKeyResponse{
+				APIKey: apiKey,
+				Key:    key,
+			},
+		}).writeTo(w)
+	}
+}
+
+func revokeAPIKeyHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// parse api key id from route
+		var apiKeyID string
+		if err := getRouteParam(r, "api_key_id", &apiKeyID); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		apiKey, err := c.apiKeyAgent.RevokeAPIKeyByID(ctx, apiKeyID)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		okResponse(&data{
+			Type:    "api_key",
+			Content: apiKey,
+		}).writeTo(w)
+	}
+}
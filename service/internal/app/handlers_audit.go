@@ -0,0 +1,38 @@
+package app
+
+import (
+	"net/http"
+	"prediction-league/service/internal/domain"
+)
+
+func retrieveAuditRecordsHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		filter := domain.AuditFilter{
+			ActorID:    query.Get("actor_id"),
+			Action:     query.Get("action"),
+			TargetType: query.Get("target_type"),
+			TargetID:   query.Get("target_id"),
+		}
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		records, err := c.auditAgent.RetrieveRecordsByFilter(ctx, filter)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		okResponse(&data{
+			Type:    "audit_records",
+			Content: records,
+		}).writeTo(w)
+	}
+}
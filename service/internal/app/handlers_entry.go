@@ -1,12 +1,14 @@
 package app
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"prediction-league/service/internal/domain"
+	"strconv"
 )
 
 func createEntryHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
@@ -58,12 +60,54 @@ func createEntryHandler(c *container) func(w http.ResponseWriter, r *http.Reques
 			return
 		}
 
+		// refuse any further attempts at the realm PIN from clients that have already got it wrong too many times.
+		// each attempt is counted before the PIN is checked, so that concurrent attempts cannot all get through
+		pinRateLimits := domain.RealmPINRateLimits(clientIPFromRequest(r, c.trustedProxies))
+		if err := c.rateLimitAgent.Allow(ctx, pinRateLimits...); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
 		domain.GuardFromContext(ctx).SetAttempt(input.RealmPIN)
 
 		// create entry
 		createdEntry, err := c.entryAgent.CreateEntry(ctx, entry, &season)
+		if !errors.As(err, &domain.UnauthorizedError{}) {
+			// only failed attempts at the realm PIN count towards its rate limits
+			if rlErr := c.rateLimitAgent.Release(ctx, pinRateLimits...); rlErr != nil {
+				c.logger.Errorf("cannot release realm pin attempt: %s", rlErr.Error())
+			}
+		}
+		resumed := false
 		if err != nil {
-			responseFromError(err).writeTo(w)
+			if !errors.Is(err, domain.ErrEntryAwaitingPayment) {
+				responseFromError(err).writeTo(w)
+				return
+			}
+
+			// entrant has already created this entry, but left the sign-up workflow before paying for it
+			createdEntry, err = resumePendingEntry(ctx, c, r, entry.EntrantEmail, season, err)
+			if err != nil {
+				responseFromError(err).writeTo(w)
+				return
+			}
+			resumed = true
+		}
+
+		if !resumed {
+			issueEmailVerification(ctx, c, createdEntry)
+		}
+
+		if createdEntry.Status == domain.EntryStatusWaitlisted {
+			// entrant has nothing to pay for until they are promoted from the waitlist, which is when they receive a registration token
+			createdResponse(&data{
+				Type: "entry",
+				Content: createEntryResponse{
+					ID:         createdEntry.ID.String(),
+					Nickname:   createdEntry.EntrantNickname,
+					Waitlisted: true,
+				},
+			}).writeTo(w)
 			return
 		}
 
@@ -74,13 +118,29 @@ func createEntryHandler(c *container) func(w http.ResponseWriter, r *http.Reques
 			return
 		}
 
-		createdResponse(&data{
+		if !resumed {
+			// a failure here only prevents the entrant from resuming the sign-up workflow later, so the entry still stands
+			session, err := c.signupAgent.StartSignupSession(ctx, createdEntry, season)
+			if err != nil {
+				c.logger.Errorf("cannot start signup session for entry '%s': %s", createdEntry.ID.String(), err.Error())
+			} else {
+				setSignupCookie(session, w, r)
+			}
+		}
+
+		resp := createdResponse
+		if resumed {
+			resp = okResponse
+		}
+
+		resp(&data{
 			Type: "entry",
 			Content: createEntryResponse{
 				ID:                createdEntry.ID.String(),
 				Nickname:          createdEntry.EntrantNickname,
 				RegistrationToken: regToken.ID,
 				NeedsPayment:      c.config.PayPalClientID != "",
+				Resumed:           resumed,
 			},
 		}).writeTo(w)
 	}
@@ -127,6 +187,12 @@ func updateEntryPaymentDetailsHandler(c *container) func(w http.ResponseWriter,
 
 		isPayPalConfigMissing := c.config.PayPalClientID == ""
 
+		// payments made via paypal are captured by the server, so an entrant can only ever claim to have paid by other means
+		if input.PaymentMethod != domain.EntryPaymentMethodOther || !isPayPalConfigMissing {
+			responseFromError(domain.ValidationError{Reasons: []string{"invalid payment method"}}).writeTo(w)
+			return
+		}
+
 		// retrieve registration token
 		regTkn, err := c.tokenAgent.RetrieveTokenByID(ctx, input.RegistrationToken)
 		if err != nil {
@@ -159,11 +225,166 @@ func updateEntryPaymentDetailsHandler(c *container) func(w http.ResponseWriter,
 			return
 		}
 
+		// entrant has reached the end of the sign-up workflow
+		completeSignupSession(ctx, c, w, r, entryID)
+
 		// success!
 		okResponse(nil).writeTo(w)
 	}
 }
 
+func createEntryPaymentOrderHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var input createEntryPaymentOrderRequest
+
+		// read request body
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			internalError(err).writeTo(w)
+			return
+		}
+		defer closeBody(r)
+
+		// parse request body
+		if err := json.Unmarshal(body, &input); err != nil {
+			responseFromError(domain.BadRequestError{Err: err}).writeTo(w)
+			return
+		}
+
+		// parse entry ID from route
+		var entryID string
+		if err := getRouteParam(r, "entry_id", &entryID); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		// retrieve registration token
+		regTkn, err := c.tokenAgent.RetrieveTokenByID(ctx, input.RegistrationToken)
+		if err != nil {
+			responseFromError(domain.BadRequestError{Err: errors.New("invalid token")}).writeTo(w)
+			return
+		}
+
+		// check token validity
+		if !c.tokenAgent.IsTokenValid(regTkn, domain.TokenTypeEntryRegistration, entryID) {
+			responseFromError(domain.BadRequestError{Err: errors.New("invalid token")}).writeTo(w)
+			return
+		}
+
+		// create payment order with provider
+		order, err := c.paymentAgent.CreatePaymentOrder(ctx, entryID)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// success!
+		createdResponse(&data{
+			Type:    "payment_order",
+			Content: createEntryPaymentOrderResponse{OrderID: order.ID},
+		}).writeTo(w)
+	}
+}
+
+func captureEntryPaymentOrderHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var input captureEntryPaymentOrderRequest
+
+		// read request body
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			internalError(err).writeTo(w)
+			return
+		}
+		defer closeBody(r)
+
+		// parse request body
+		if err := json.Unmarshal(body, &input); err != nil {
+			responseFromError(domain.BadRequestError{Err: err}).writeTo(w)
+			return
+		}
+
+		// parse entry ID from route
+		var entryID string
+		if err := getRouteParam(r, "entry_id", &entryID); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		// retrieve registration token
+		regTkn, err := c.tokenAgent.RetrieveTokenByID(ctx, input.RegistrationToken)
+		if err != nil {
+			responseFromError(domain.BadRequestError{Err: errors.New("invalid token")}).writeTo(w)
+			return
+		}
+
+		// check token validity
+		if !c.tokenAgent.IsTokenValid(regTkn, domain.TokenTypeEntryRegistration, entryID) {
+			responseFromError(domain.BadRequestError{Err: errors.New("invalid token")}).writeTo(w)
+			return
+		}
+
+		// capture and verify payment with provider, which approves the entry
+		entry, payment, err := c.paymentAgent.CapturePaymentOrder(ctx, entryID, input.OrderID)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		merchantName := payment.MerchantName
+		if merchantName == "" {
+			merchantName = "PayPal"
+		}
+
+		paymentDetails := domain.PaymentDetails{
+			Amount:       domain.RealmFromContext(ctx).EntryFee.Label,
+			Reference:    payment.Reference,
+			MerchantName: merchantName,
+		}
+
+		// issue new entry email
+		if err := c.commsAgent.IssueNewEntryEmail(ctx, &entry, &paymentDetails); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// redeem registration token
+		if err := c.tokenAgent.RedeemToken(ctx, *regTkn); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// entrant has reached the end of the sign-up workflow
+		completeSignupSession(ctx, c, w, r, entryID)
+
+		// success!
+		okResponse(&data{
+			Type: "payment",
+			Content: captureEntryPaymentOrderResponse{
+				PaymentRef:   payment.Reference,
+				MerchantName: merchantName,
+				Approved:     entry.IsApproved(),
+			},
+		}).writeTo(w)
+	}
+}
+
 func createEntryPredictionHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// get context from request
@@ -230,11 +451,19 @@ func createEntryPredictionHandler(c *container) func(w http.ResponseWriter, r *h
 			return
 		}
 
+		// retrieve the prediction being superseded, if there is one, so that the change can be audited
+		var before interface{}
+		if currentEP, err := c.entryAgent.RetrieveEntryPredictionByTimestamp(ctx, entry, c.clock.Now()); err == nil {
+			before = map[string]interface{}{"entry_prediction_id": currentEP.ID, "ranking_ids": currentEP.Rankings.GetIDs()}
+		}
+
 		// create entry prediction for entry
-		if _, err := c.entryAgent.AddEntryPredictionToEntry(ctx, newEP, entry); err != nil {
+		updatedEntry, err := c.entryAgent.AddEntryPredictionToEntry(ctx, newEP, entry)
+		if err != nil {
 			responseFromError(err).writeTo(w)
 			return
 		}
+		createdEP := updatedEntry.EntryPredictions[len(updatedEntry.EntryPredictions)-1]
 
 		// redeem prediction token
 		if err := c.tokenAgent.RedeemToken(ctx, *predTkn); err != nil {
@@ -242,6 +471,19 @@ func createEntryPredictionHandler(c *container) func(w http.ResponseWriter, r *h
 			return
 		}
 
+		// the prediction has already been saved at this point, so don't fail the request if it can't be audited
+		if _, err := c.auditAgent.Record(
+			ctx,
+			domain.EntrantAuditActor(entry),
+			domain.AuditActionEntryPredictionCreated,
+			domain.AuditTargetTypeEntry,
+			entry.ID.String(),
+			before,
+			map[string]interface{}{"entry_prediction_id": createdEP.ID, "ranking_ids": createdEP.Rankings.GetIDs()},
+		); err != nil {
+			c.logger.Errorf("cannot audit entry prediction '%s': %s", createdEP.ID, err.Error())
+		}
+
 		// success!
 		okResponse(nil).writeTo(w)
 	}
@@ -374,6 +616,265 @@ func generateExtendedMagicLoginTokenHandler(c *container) func(w http.ResponseWr
 	}
 }
 
+func retrieveEntriesHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		filter := domain.EntryFilter{
+			RealmName: query.Get("realm"),
+			SeasonID:  query.Get("season"),
+			Status:    query.Get("status"),
+		}
+
+		// parse optional approval flag from query
+		if approved := query.Get("approved"); approved != "" {
+			b, err := strconv.ParseBool(approved)
+			if err != nil {
+				responseFromError(domain.BadRequestError{Err: fmt.Errorf("invalid approved value: %s", approved)}).writeTo(w)
+				return
+			}
+			filter.Approved = &b
+		}
+
+		// parse optional email verification flag from query
+		if emailVerified := query.Get("email_verified"); emailVerified != "" {
+			b, err := strconv.ParseBool(emailVerified)
+			if err != nil {
+				responseFromError(domain.BadRequestError{Err: fmt.Errorf("invalid email_verified value: %s", emailVerified)}).writeTo(w)
+				return
+			}
+			filter.EmailVerified = &b
+		}
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		if filter.SeasonID == "latest" {
+			// use the current realm's season ID instead
+			filter.SeasonID = domain.RealmFromContext(ctx).Config.SeasonID
+		}
+
+		entries, err := c.entryAgent.RetrieveEntriesByFilter(ctx, filter)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		content := make([]adminEntryResponse, 0)
+		for _, entry := range entries {
+			content = append(content, newAdminEntryResponse(entry))
+		}
+
+		okResponse(&data{
+			Type:    "entries",
+			Content: content,
+		}).writeTo(w)
+	}
+}
+
+func retrieveEntryByIDHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// parse entry id from route
+		var entryID string
+		if err := getRouteParam(r, "entry_id", &entryID); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		entry, err := c.entryAgent.RetrieveEntryWithPredictionHistoryByID(ctx, entryID)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		okResponse(&data{
+			Type:    "entry",
+			Content: newAdminEntryResponse(entry),
+		}).writeTo(w)
+	}
+}
+
+func updateEntrantDetailsHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var input updateEntrantDetailsRequest
+
+		// read request body
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			internalError(err).writeTo(w)
+			return
+		}
+		defer closeBody(r)
+
+		// parse request body
+		if err := json.Unmarshal(body, &input); err != nil {
+			responseFromError(domain.BadRequestError{Err: err}).writeTo(w)
+			return
+		}
+
+		// parse entry id from route
+		var entryID string
+		if err := getRouteParam(r, "entry_id", &entryID); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		entry, err := c.entryAgent.UpdateEntrantDetailsByID(ctx, entryID, input.EntrantNickname, input.EntrantEmail)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// a changed email address must be verified again before the entry can be approved
+		if input.EntrantEmail != nil && !entry.IsEmailVerified() {
+			issueEmailVerification(ctx, c, entry)
+		}
+
+		okResponse(&data{
+			Type:    "entry",
+			Content: newAdminEntryResponse(entry),
+		}).writeTo(w)
+	}
+}
+
+func approveEntriesHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var input approveEntriesRequest
+
+		// read request body
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			internalError(err).writeTo(w)
+			return
+		}
+		defer closeBody(r)
+
+		// parse request body
+		if err := json.Unmarshal(body, &input); err != nil {
+			responseFromError(domain.BadRequestError{Err: err}).writeTo(w)
+			return
+		}
+
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		approved, failed, err := c.entryAgent.ApproveEntriesByIDs(ctx, input.EntryIDs)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		content := approveEntriesResponse{
+			Approved: make([]string, 0),
+			Failed:   make(map[string]string),
+		}
+		for _, entry := range approved {
+			content.Approved = append(content.Approved, entry.ID.String())
+		}
+		for id, err := range failed {
+			content.Failed[id] = err.Error()
+		}
+
+		okResponse(&data{
+			Type:    "approval",
+			Content: content,
+		}).writeTo(w)
+	}
+}
+
+func excludeEntryByIDHandler(c *container, status string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// parse entry id from route
+		var entryID string
+		if err := getRouteParam(r, "entry_id", &entryID); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		exclude := c.waitlistAgent.WithdrawEntryByID
+		if status == domain.EntryStatusDisqualified {
+			exclude = c.waitlistAgent.DisqualifyEntryByID
+		}
+
+		entry, err := exclude(ctx, entryID)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		okResponse(&data{
+			Type:    "entry",
+			Content: newAdminEntryResponse(entry),
+		}).writeTo(w)
+	}
+}
+
+func revokeEntrySessionsHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// parse entry id from route
+		var entryID string
+		if err := getRouteParam(r, "entry_id", &entryID); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		// ensure entry exists within the current realm
+		entry, err := c.entryAgent.RetrieveEntryByID(ctx, entryID)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		revoked, err := c.tokenAgent.RevokeAllAuthTokensByEntryID(ctx, entry.ID.String())
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		okResponse(&data{
+			Type:    "logout",
+			Content: logoutResponse{Revoked: revoked},
+		}).writeTo(w)
+	}
+}
+
 func retrieveLatestScoredEntryPrediction(c *container) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// parse entry ID from route
@@ -467,3 +968,81 @@ func getResponseRankingsFromStandingsRankings(
 
 	return rankingsWithStandingsPosition, nil
 }
+
+// resumePendingEntry retrieves the Entry with the provided email that is still awaiting payment, so that its entrant can
+// return to the payment step of the sign-up workflow. Only the signup session that created the Entry can resume it
+// directly, otherwise its entrant is emailed a link to do so and the provided error is returned
+func resumePendingEntry(ctx context.Context, c *container, r *http.Request, email string, season domain.Season, awaitingPaymentErr error) (domain.Entry, error) {
+	realm := domain.RealmFromContext(ctx)
+
+	entry, err := c.entryAgent.RetrieveEntryByEntrantEmail(ctx, email, season.ID, realm.Config.Name)
+	if err != nil {
+		return domain.Entry{}, err
+	}
+
+	if sessionID := getSignupCookieValue(r); sessionID != "" {
+		session, err := c.signupAgent.RetrieveSignupSessionByID(ctx, sessionID)
+		if err == nil && session.EntryID == entry.ID.String() && session.Step == domain.SignupStepPayment {
+			return entry, nil
+		}
+	}
+
+	regToken, err := c.tokenAgent.GenerateTokenExpiringAt(ctx, domain.TokenTypeEntryRegistration, entry.ID.String(), season.EntriesAccepted.Until)
+	if err != nil {
+		return domain.Entry{}, err
+	}
+
+	if err := c.commsAgent.IssuePaymentReminderEmail(ctx, &entry, regToken); err != nil {
+		return domain.Entry{}, err
+	}
+
+	if !entry.IsEmailVerified() {
+		issueEmailVerification(ctx, c, entry)
+	}
+
+	return domain.Entry{}, awaitingPaymentErr
+}
+
+// issueEmailVerification emails the entrant of the provided Entry a link to verify its email address, replacing any link
+// that was sent before. Failures are only logged, since the entrant can request another link by entering again
+func issueEmailVerification(ctx context.Context, c *container, entry domain.Entry) {
+	if _, err := c.tokenAgent.DeleteInFlightTokens(ctx, domain.TokenTypeEmailVerification, entry.ID.String()); err != nil {
+		c.logger.Errorf("cannot purge in-flight email verification tokens for entry id '%s': %s", entry.ID.String(), err.Error())
+	}
+
+	vTkn, err := c.tokenAgent.GenerateToken(ctx, domain.TokenTypeEmailVerification, entry.ID.String())
+	if err != nil {
+		c.logger.Errorf("cannot generate email verification token for entry id '%s': %s", entry.ID.String(), err.Error())
+		return
+	}
+
+	if err := c.commsAgent.IssueEmailVerificationEmail(ctx, &entry, vTkn.ID); err != nil {
+		c.logger.Errorf("cannot issue email verification email for entry id '%s': %s", entry.ID.String(), err.Error())
+	}
+}
+
+// completeSignupSession marks the signup session for the Entry with the provided ID as complete and clears its cookie,
+// so that the entrant is not returned to the payment step of the sign-up workflow again
+func completeSignupSession(ctx context.Context, c *container, w http.ResponseWriter, r *http.Request, entryID string) {
+	sessionID := getSignupCookieValue(r)
+	if sessionID == "" {
+		return
+	}
+
+	session, err := c.signupAgent.RetrieveSignupSessionByID(ctx, sessionID)
+	if err != nil {
+		// session has expired or no longer exists, so the cookie is of no further use
+		clearSignupCookie(w, r)
+		return
+	}
+
+	if session.EntryID != entryID {
+		return
+	}
+
+	if _, err := c.signupAgent.UpdateSignupSessionStep(ctx, session.ID, domain.SignupStepComplete); err != nil {
+		c.logger.Errorf("cannot complete signup session '%s': %s", session.ID, err.Error())
+	}
+
+	clearSignupCookie(w, r)
+}
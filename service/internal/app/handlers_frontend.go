@@ -2,6 +2,7 @@ package app
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -56,6 +57,7 @@ func frontendLeaderBoardHandler(c *container) func(w http.ResponseWriter, r *htt
 			c.entryAgent,
 			c.standingsAgent,
 			c.lbAgent,
+			c.prizeAgent,
 			c.seasons,
 			c.teams,
 			c.clock,
@@ -121,6 +123,27 @@ func frontendJoinHandler(c *container) func(w http.ResponseWriter, r *http.Reque
 			EntryFee:        realm.EntryFee,
 		}
 
+		// entrant may be returning from a payment reminder email to pay for an existing entry
+		if tknID := r.URL.Query().Get("token"); tknID != "" && entriesOpen {
+			resumed, err := getResumedEntry(ctx, c, tknID)
+			if err != nil {
+				// fall back to a fresh registration
+				c.logger.Infof("cannot resume entry from payment reminder: %s", err.Error())
+			}
+			data.ResumedEntry = resumed
+		}
+
+		// entrant may be returning to the sign-up workflow after leaving it before paying
+		if sessionID := getSignupCookieValue(r); sessionID != "" && data.ResumedEntry == nil && entriesOpen {
+			resumed, err := getResumedEntryFromSignupSession(ctx, c, sessionID)
+			if err != nil {
+				// session can no longer be resumed, so fall back to a fresh registration
+				c.logger.Infof("cannot resume entry from signup session: %s", err.Error())
+				clearSignupCookie(w, r)
+			}
+			data.ResumedEntry = resumed
+		}
+
 		p := newPage(r, c, "Enter", "join", "Enter", data)
 
 		if err := c.templates.ExecuteTemplate(w, "join", p); err != nil {
@@ -130,6 +153,70 @@ func frontendJoinHandler(c *container) func(w http.ResponseWriter, r *http.Reque
 	}
 }
 
+// getResumedEntry retrieves the unpaid Entry identified by the provided registration token,
+// so that its entrant can return to the payment step of the join page
+func getResumedEntry(ctx context.Context, c *container, tknID string) (*view.ResumedEntry, error) {
+	regTkn, err := c.tokenAgent.RetrieveTokenByID(ctx, tknID)
+	if err != nil {
+		return nil, err
+	}
+
+	// registration token value is the id of the entry it was issued for
+	if !c.tokenAgent.IsTokenValid(regTkn, domain.TokenTypeEntryRegistration, regTkn.Value) || regTkn.RedeemedAt != nil {
+		return nil, errors.New("invalid token")
+	}
+
+	entry, err := c.entryAgent.RetrieveEntryByID(ctx, regTkn.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Status != domain.EntryStatusPending {
+		return nil, fmt.Errorf("entry status is %s", entry.Status)
+	}
+
+	return &view.ResumedEntry{
+		ID:           entry.ID.String(),
+		Email:        entry.EntrantEmail,
+		RegToken:     regTkn.ID,
+		NeedsPayment: c.config.PayPalClientID != "",
+	}, nil
+}
+
+// getResumedEntryFromSignupSession retrieves the unpaid Entry that the signup session with the provided ID was started for,
+// along with a new registration token, so that its entrant can return to the payment step of the join page
+func getResumedEntryFromSignupSession(ctx context.Context, c *container, sessionID string) (*view.ResumedEntry, error) {
+	session, err := c.signupAgent.RetrieveSignupSessionByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Step != domain.SignupStepPayment {
+		return nil, fmt.Errorf("signup session step is %s", session.Step)
+	}
+
+	entry, err := c.entryAgent.RetrieveEntryByID(ctx, session.EntryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Status != domain.EntryStatusPending {
+		return nil, fmt.Errorf("entry status is %s", entry.Status)
+	}
+
+	regTkn, err := c.tokenAgent.GenerateToken(ctx, domain.TokenTypeEntryRegistration, entry.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return &view.ResumedEntry{
+		ID:           entry.ID.String(),
+		Email:        entry.EntrantEmail,
+		RegToken:     regTkn.ID,
+		NeedsPayment: c.config.PayPalClientID != "",
+	}, nil
+}
+
 func frontendPredictionHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var writeResponse = func(data view.PredictionPageData) {
@@ -202,6 +289,20 @@ func frontendGenerateMagicLoginHandler(c *container) func(w http.ResponseWriter,
 		}
 		defer cancel()
 
+		// limit attempts before looking up the entry, so that being blocked does not reveal whether the entry exists
+		if err := c.rateLimitAgent.Allow(ctx, domain.MagicLoginRateLimits(clientIPFromRequest(r, c.trustedProxies), input.EmailAddr)...); err != nil {
+			var rlErr domain.RateLimitedError
+			if errors.As(err, &rlErr) {
+				w.Header().Set("Retry-After", retryAfterSeconds(rlErr.RetryAfter))
+				w.WriteHeader(http.StatusTooManyRequests)
+				writeResponse(view.GenerateMagicLoginPageData{Err: errors.New("too many login attempts - please try again later")})
+				return
+			}
+			c.logger.Errorf("cannot check magic login rate limits: %s", err.Error())
+			writeResponse(view.GenerateMagicLoginPageData{Err: genericErr})
+			return
+		}
+
 		// get realm from context
 		realm := domain.RealmFromContext(ctx)
 
@@ -242,8 +343,16 @@ func frontendGenerateMagicLoginHandler(c *container) func(w http.ResponseWriter,
 			return
 		}
 
-		// issue email with magic login link
-		if err := c.commsAgent.IssueMagicLoginEmail(nil, entry, mTkn.ID); err != nil {
+		// generate new login code, for entrants who cannot follow the magic login link
+		loginCode, err := c.tokenAgent.GenerateLoginCode(ctx, entry.ID.String())
+		if err != nil {
+			c.logger.Errorf("cannot generate login code for entry id '%s': %s", entry.ID.String(), err.Error())
+			writeResponse(view.GenerateMagicLoginPageData{Err: genericErr})
+			return
+		}
+
+		// issue email with magic login link and login code
+		if err := c.commsAgent.IssueMagicLoginEmail(nil, entry, mTkn.ID, loginCode); err != nil {
 			c.logger.Errorf("cannot issue magic login email for entry id '%s': %s", entry.ID.String(), err.Error())
 			writeResponse(view.GenerateMagicLoginPageData{Err: genericErr})
 			return
@@ -274,6 +383,7 @@ func frontendRedeemMagicLoginHandler(c *container) func(w http.ResponseWriter, r
 
 		realm := domain.RealmFromContext(ctx)
 		redirOk := realm.GetFullMyTableURL()
+		redirFail = realm.Site.Paths.Login + "/failed"
 
 		// parse magic token from route
 		var mTknID string
@@ -333,7 +443,7 @@ func frontendRedeemMagicLoginHandler(c *container) func(w http.ResponseWriter, r
 			responseFromError(err).writeTo(w)
 			return
 		}
-		setAuthCookieValue(authTkn.ID, w, r)
+		setAuthCookie(authTkn, w, r)
 
 		// redeem magic token
 		if err := c.tokenAgent.RedeemToken(ctx, *mTkn); err != nil {
@@ -341,11 +451,156 @@ func frontendRedeemMagicLoginHandler(c *container) func(w http.ResponseWriter, r
 			c.logger.Errorf("cannot redeem magic token id '%s': %s", mTkn.ID, err.Error())
 		}
 
+		// login code sent in the same email is no longer needed
+		if _, err := c.tokenAgent.DeleteInFlightTokens(ctx, domain.TokenTypeLoginCode, entry.ID.String()); err != nil {
+			// log error and continue
+			c.logger.Errorf("cannot purge in-flight login codes for entry id '%s': %s", entry.ID.String(), err.Error())
+		}
+
 		// all ok!
 		writeRedirect(redirOk)
 	}
 }
 
+func frontendLoginCodeHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		buf := &bytes.Buffer{}
+		p := newPage(r, c, "Login", "", "Login", view.LoginCodePageData{})
+		if err := c.templates.ExecuteTemplate(buf, "login-code", p); err != nil {
+			internalError(fmt.Errorf("cannot execute template: %w", err)).writeTo(w)
+			return
+		}
+		w.Write(buf.Bytes())
+	}
+}
+
+func frontendRedeemLoginCodeHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var writeResponse = func(data view.LoginCodePageData) {
+			p := newPage(r, c, "Login", "", "Login", data)
+
+			if err := c.templates.ExecuteTemplate(w, "login-code", p); err != nil {
+				internalError(fmt.Errorf("cannot execute template: %w", err)).writeTo(w)
+			}
+		}
+
+		// parse request body (standard form)
+		if err := r.ParseForm(); err != nil {
+			c.logger.Errorf("cannot parse form: %s", err.Error())
+			writeResponse(view.LoginCodePageData{Err: genericErr})
+			return
+		}
+		input := redeemLoginCodeRequest{
+			EmailAddr: r.Form.Get("email_addr"),
+			Code:      r.Form.Get("code"),
+		}
+
+		// check that input is valid
+		if input.EmailAddr == "" || input.Code == "" {
+			writeResponse(view.LoginCodePageData{Err: errors.New("invalid request"), EmailAddr: input.EmailAddr})
+			return
+		}
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			c.logger.Errorf("cannot get context from request: %s", err.Error())
+			writeResponse(view.LoginCodePageData{Err: genericErr, EmailAddr: input.EmailAddr})
+			return
+		}
+		defer cancel()
+
+		authTkn, err := loginWithCode(ctx, c, clientIPFromRequest(r, c.trustedProxies), input.EmailAddr, input.Code)
+		if err != nil {
+			var rlErr domain.RateLimitedError
+			if errors.As(err, &rlErr) {
+				w.Header().Set("Retry-After", retryAfterSeconds(rlErr.RetryAfter))
+				w.WriteHeader(http.StatusTooManyRequests)
+				writeResponse(view.LoginCodePageData{Err: errors.New("too many login attempts - please try again later"), EmailAddr: input.EmailAddr})
+				return
+			}
+			if errors.As(err, &domain.UnauthorizedError{}) {
+				writeResponse(view.LoginCodePageData{Err: errors.New("invalid or expired login code"), EmailAddr: input.EmailAddr})
+				return
+			}
+			c.logger.Errorf("cannot login with code for '%s': %s", input.EmailAddr, err.Error())
+			writeResponse(view.LoginCodePageData{Err: genericErr, EmailAddr: input.EmailAddr})
+			return
+		}
+		setAuthCookie(authTkn, w, r)
+
+		// all ok!
+		// stay on the host that the code was entered on, since it may be an alias of the origin that the auth cookie is not set for
+		w.Header().Set("Location", domain.RealmFromContext(ctx).Site.Paths.MyTable)
+		w.WriteHeader(http.StatusFound)
+	}
+}
+
+func frontendVerifyEmailHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var writeResponse = func(data view.EmailVerificationPageData) {
+			p := newPage(r, c, "Verify Email", "", "Verify Email", data)
+
+			if err := c.templates.ExecuteTemplate(w, "email-verification", p); err != nil {
+				internalError(fmt.Errorf("cannot execute template: %w", err)).writeTo(w)
+			}
+		}
+
+		invalidLinkErr := errors.New("Oh no! That's not a valid link :'(")
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			c.logger.Errorf("cannot get context from request: %s", err.Error())
+			writeResponse(view.EmailVerificationPageData{Err: genericErr})
+			return
+		}
+		defer cancel()
+
+		// parse verification token from route
+		var vTknID string
+		if err := getRouteParam(r, "verification_token_id", &vTknID); err != nil {
+			c.logger.Errorf("cannot parse route param 'verification_token_id': %s", err.Error())
+			writeResponse(view.EmailVerificationPageData{Err: invalidLinkErr})
+			return
+		}
+
+		// retrieve token
+		vTkn, err := c.tokenAgent.RetrieveTokenByID(ctx, vTknID)
+		if err != nil {
+			if !errors.As(err, &domain.NotFoundError{}) {
+				c.logger.Errorf("cannot retrieve verification token '%s': %s", vTknID, err.Error())
+			}
+			writeResponse(view.EmailVerificationPageData{Err: invalidLinkErr})
+			return
+		}
+
+		// is token a valid email verification token?
+		if vTkn.Type != domain.TokenTypeEmailVerification || vTkn.RedeemedAt != nil || vTkn.ExpiresAt.Before(c.clock.Now()) {
+			writeResponse(view.EmailVerificationPageData{Err: invalidLinkErr})
+			return
+		}
+
+		if _, err := c.paymentAgent.VerifyEntryEmailByID(ctx, vTkn.Value); err != nil {
+			if !errors.As(err, &domain.ConflictError{}) {
+				c.logger.Errorf("cannot verify email of entry with verification token id '%s': value '%s': %s", vTkn.ID, vTkn.Value, err.Error())
+				writeResponse(view.EmailVerificationPageData{Err: genericErr})
+				return
+			}
+			// entry has already been verified, so the link has served its purpose
+		}
+
+		// redeem verification token
+		if err := c.tokenAgent.RedeemToken(ctx, *vTkn); err != nil {
+			// log error and continue
+			c.logger.Errorf("cannot redeem verification token id '%s': %s", vTkn.ID, err.Error())
+		}
+
+		// all ok!
+		writeResponse(view.EmailVerificationPageData{})
+	}
+}
+
 func frontendMagicLoginFailedHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		buf := &bytes.Buffer{}
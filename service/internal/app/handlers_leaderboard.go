@@ -7,7 +7,7 @@ import (
 	"prediction-league/service/internal/view"
 )
 
-func getLeaderBoardPageData(ctx context.Context, entryAgent *domain.EntryAgent, standingsAgent *domain.StandingsAgent, leaderBoardAgent *domain.LeaderBoardAgent, sc domain.SeasonCollection, tc domain.TeamCollection, cl domain.Clock) view.LeaderBoardPageData {
+func getLeaderBoardPageData(ctx context.Context, entryAgent *domain.EntryAgent, standingsAgent *domain.StandingsAgent, leaderBoardAgent *domain.LeaderBoardAgent, prizeAgent *domain.PrizeAgent, sc domain.SeasonCollection, tc domain.TeamCollection, cl domain.Clock) view.LeaderBoardPageData {
 	var data view.LeaderBoardPageData
 
 	realm := domain.RealmFromContext(ctx)
@@ -37,7 +37,13 @@ func getLeaderBoardPageData(ctx context.Context, entryAgent *domain.EntryAgent,
 	case domain.NotFoundError:
 		// leaderboard can't be generated, an empty one will be returned
 	case nil:
-		// we've got a valid leaderboard
+		// we've got a valid leaderboard, so let's see what its entries stand to win
+		if err := prizeAgent.ApplyProjectedPayouts(ctx, seasonID, leaderBoard); err != nil {
+			data.Err = err
+			return data
+		}
+		data.PrizePot = leaderBoard.PrizePot
+
 		rawRankings, err := json.Marshal(leaderBoard.Rankings)
 		if err != nil {
 			data.Err = err
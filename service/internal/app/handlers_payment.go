@@ -0,0 +1,221 @@
+package app
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"prediction-league/service/internal/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func paymentWebhookHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// read request body, which must remain untouched so that its signature can be verified
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			internalError(err).writeTo(w)
+			return
+		}
+		defer closeBody(r)
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		// verify and apply payment event
+		event, err := c.paymentAgent.ProcessWebhookEvent(ctx, r.Header, body)
+		if err != nil {
+			c.logger.Errorf("cannot process payment webhook event: %s", err.Error())
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		c.logger.Infof("processed payment webhook event %s (%s): %s", event.ID, event.ProviderEventType, event.Outcome)
+
+		// success!
+		okResponse(&data{
+			Type: "payment_event",
+			Content: paymentEventResponse{
+				ID:      event.ID,
+				Type:    event.Type,
+				Outcome: event.Outcome,
+			},
+		}).writeTo(w)
+	}
+}
+
+func exportEntryPaymentsHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		realm := domain.RealmFromContext(ctx)
+
+		filter := domain.EntryFilter{
+			RealmName: realm.Config.Name,
+			SeasonID:  r.URL.Query().Get("season"),
+		}
+		if filter.SeasonID == "" || filter.SeasonID == "latest" {
+			// use the current realm's season ID instead
+			filter.SeasonID = realm.Config.SeasonID
+		}
+
+		entries, err := c.entryAgent.RetrieveEntriesByFilter(ctx, filter)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		amounts, err := c.paymentAgent.RetrievePaidAmounts(ctx, entries)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// build csv in memory so that a failure can still be reported as a regular error response
+		buf := &bytes.Buffer{}
+		cw := csv.NewWriter(buf)
+		cw.Write([]string{
+			"entry_id",
+			"season_id",
+			"entrant_name",
+			"entrant_nickname",
+			"entrant_email",
+			"status",
+			"payment_method",
+			"payment_ref",
+			"amount",
+			"currency",
+			"approved",
+			"approved_at",
+			"created_at",
+		})
+		for _, entry := range entries {
+			// amount is only populated when a payment is actually held for the entry
+			var amount, currency string
+			if paid, ok := amounts[entry.ID.String()]; ok {
+				amount = strconv.FormatFloat(float64(paid), 'f', 2, 32)
+				currency = realm.EntryFee.Currency
+			}
+
+			cw.Write([]string{
+				entry.ID.String(),
+				entry.SeasonID,
+				csvSafeString(entry.EntrantName),
+				csvSafeString(entry.EntrantNickname),
+				csvSafeString(entry.EntrantEmail),
+				entry.Status,
+				csvSafeString(stringFromPtr(entry.PaymentMethod)),
+				csvSafeString(stringFromPtr(entry.PaymentRef)),
+				amount,
+				currency,
+				strconv.FormatBool(entry.IsApproved()),
+				timeStringFromPtr(entry.ApprovedAt),
+				entry.CreatedAt.Format(time.RFC3339),
+			})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			internalError(err).writeTo(w)
+			return
+		}
+
+		// success!
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(
+			"attachment; filename=\"%s-%s-payments.csv\"",
+			realm.Config.Name,
+			filter.SeasonID,
+		))
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes())
+	}
+}
+
+func reconcileEntryPaymentsHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// parse optional approval flag from query
+		var approve bool
+		if raw := r.URL.Query().Get("approve"); raw != "" {
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				responseFromError(domain.BadRequestError{Err: fmt.Errorf("invalid approve value: %s", raw)}).writeTo(w)
+				return
+			}
+			approve = b
+		}
+
+		// read request body
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			internalError(err).writeTo(w)
+			return
+		}
+		defer closeBody(r)
+
+		// parse statement from csv request body
+		lines, err := domain.ParsePaymentStatementCSV(bytes.NewReader(body))
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		rec, err := c.entryAgent.ReconcilePayments(ctx, lines, approve)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// success!
+		okResponse(&data{
+			Type:    "payment_reconciliation",
+			Content: rec,
+		}).writeTo(w)
+	}
+}
+
+// stringFromPtr returns the value of the provided string pointer, or an empty string if it is nil
+func stringFromPtr(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// csvSafeString returns the provided value prefixed with a single quote if it begins with a character that
+// spreadsheet applications would otherwise interpret as the start of a formula
+func csvSafeString(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// timeStringFromPtr returns the provided time pointer formatted as RFC3339, or an empty string if it is nil
+func timeStringFromPtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -92,9 +92,59 @@ func retrieveLeaderBoardHandler(c *container) func(w http.ResponseWriter, r *htt
 			return
 		}
 
+		if err := c.prizeAgent.ApplyProjectedPayouts(ctx, seasonID, lb); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
 		okResponse(&data{
 			Type:    "leaderboard",
 			Content: lb,
 		}).writeTo(w)
 	}
 }
+
+func retrievePrizesHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// parse season ID from route
+		var seasonID string
+		if err := getRouteParam(r, "season_id", &seasonID); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		if seasonID == "latest" {
+			// use the current realm's season ID instead
+			seasonID = domain.RealmFromContext(ctx).Config.SeasonID
+		}
+
+		pot, err := c.prizeAgent.RetrievePrizePot(ctx, seasonID)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// payouts remain empty until the season has been finalised
+		payouts, err := c.prizeAgent.RetrievePrizePayouts(ctx, seasonID)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		okResponse(&data{
+			Type: "prizes",
+			Content: prizesResponse{
+				PrizePot: pot,
+				Payouts:  payouts,
+			},
+		}).writeTo(w)
+	}
+}
@@ -0,0 +1,193 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"prediction-league/service/internal/domain"
+)
+
+func retrieveQuarantinedStandingsHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// parse season ID from route
+		var seasonID string
+		if err := getRouteParam(r, "season_id", &seasonID); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		if seasonID == "latest" {
+			// use the current realm's season ID instead
+			seasonID = domain.RealmFromContext(ctx).Config.SeasonID
+		}
+
+		if _, err := c.seasons.GetByID(seasonID); err != nil {
+			notFoundError(fmt.Errorf("invalid season: %s", seasonID)).writeTo(w)
+			return
+		}
+
+		quarantined, err := c.quarantineAgent.RetrieveQuarantinedStandingsBySeasonID(ctx, seasonID)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		okResponse(&data{
+			Type:    "quarantined_standings",
+			Content: quarantined,
+		}).writeTo(w)
+	}
+}
+
+func reviewQuarantinedStandingsHandler(c *container, status string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// parse quarantine ID from route
+		var quarantineID string
+		if err := getRouteParam(r, "quarantine_id", &quarantineID); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		reviewed, err := c.quarantineAgent.ReviewQuarantinedStandings(ctx, quarantineID, status)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		okResponse(&data{
+			Type:    "quarantined_standings",
+			Content: reviewed,
+		}).writeTo(w)
+	}
+}
+
+func retrieveStandingsSnapshotsHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// parse season ID from route
+		var seasonID string
+		if err := getRouteParam(r, "season_id", &seasonID); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// parse round number from route
+		var roundNumber int
+		if err := getRouteParam(r, "round_number", &roundNumber); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		if seasonID == "latest" {
+			// use the current realm's season ID instead
+			seasonID = domain.RealmFromContext(ctx).Config.SeasonID
+		}
+
+		snapshots, err := c.snapshotAgent.RetrieveSnapshotsBySeasonAndRoundNumber(ctx, seasonID, roundNumber)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		okResponse(&data{
+			Type:    "snapshots",
+			Content: snapshots,
+		}).writeTo(w)
+	}
+}
+
+func diffStandingsSnapshotsHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// parse snapshot IDs from route
+		var fromID, toID string
+		if err := getRouteParam(r, "from_snapshot_id", &fromID); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		if err := getRouteParam(r, "to_snapshot_id", &toID); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		diff, err := c.snapshotAgent.DiffSnapshots(ctx, fromID, toID)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		okResponse(&data{
+			Type:    "diff",
+			Content: diff,
+		}).writeTo(w)
+	}
+}
+
+func retrieveStandingsCorrectionsHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// parse season ID from route
+		var seasonID string
+		if err := getRouteParam(r, "season_id", &seasonID); err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		if seasonID == "latest" {
+			// use the current realm's season ID instead
+			seasonID = domain.RealmFromContext(ctx).Config.SeasonID
+		}
+
+		if _, err := c.seasons.GetByID(seasonID); err != nil {
+			notFoundError(fmt.Errorf("invalid season: %s", seasonID)).writeTo(w)
+			return
+		}
+
+		corrections, err := c.correctionAgent.RetrieveCorrectionsBySeasonID(ctx, seasonID)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		okResponse(&data{
+			Type:    "standings_corrections",
+			Content: corrections,
+		}).writeTo(w)
+	}
+}
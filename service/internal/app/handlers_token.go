@@ -0,0 +1,121 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"prediction-league/service/internal/domain"
+)
+
+func retrieveTokenJanitorRunHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		run, err := c.tokenJanitorStats.RetrieveLastRun(ctx)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+
+		okResponse(&data{
+			Type:    "token_janitor_run",
+			Content: run,
+		}).writeTo(w)
+	}
+}
+
+func logoutHandler(c *container, everywhere bool) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		authTknID := getAuthCookieValue(r)
+
+		// the cookie is cleared whatever happens, so that the browser is always logged out
+		clearAuthCookie(w, r)
+
+		if authTknID == "" {
+			unauthorizedError().writeTo(w)
+			return
+		}
+
+		var revoked int64
+		switch {
+		case everywhere:
+			revoked, err = c.tokenAgent.RevokeAllAuthTokensByAuthToken(ctx, authTknID)
+			if err != nil {
+				responseFromError(err).writeTo(w)
+				return
+			}
+		default:
+			// a session that has already been removed is as logged out as it can be
+			revoked, err = c.tokenAgent.RevokeAuthToken(ctx, authTknID)
+			if err != nil && !errors.As(err, &domain.NotFoundError{}) {
+				responseFromError(err).writeTo(w)
+				return
+			}
+		}
+
+		okResponse(&data{
+			Type:    "logout",
+			Content: logoutResponse{Revoked: revoked},
+		}).writeTo(w)
+	}
+}
+
+func loginWithCodeHandler(c *container) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var input redeemLoginCodeRequest
+
+		// read request body
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			internalError(err).writeTo(w)
+			return
+		}
+		defer closeBody(r)
+
+		// parse request body
+		if err := json.Unmarshal(body, &input); err != nil {
+			responseFromError(domain.BadRequestError{Err: err}).writeTo(w)
+			return
+		}
+
+		if input.EmailAddr == "" || input.Code == "" {
+			responseFromError(domain.BadRequestError{Err: errors.New("email address and code are required")}).writeTo(w)
+			return
+		}
+
+		// get context from request
+		ctx, cancel, err := contextFromRequest(r, c)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		defer cancel()
+
+		authTkn, err := loginWithCode(ctx, c, clientIPFromRequest(r, c.trustedProxies), input.EmailAddr, input.Code)
+		if err != nil {
+			responseFromError(err).writeTo(w)
+			return
+		}
+		setAuthCookie(authTkn, w, r)
+
+		okResponse(&data{
+			Type:    "login",
+			Content: loginResponse{EntryID: authTkn.Value, ExpiresAt: authTkn.ExpiresAt},
+		}).writeTo(w)
+	}
+}
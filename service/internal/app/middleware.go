@@ -0,0 +1,291 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"prediction-league/service/internal/domain"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	csrfHeaderName = "X-CSRF-Token"
+	csrfFormField  = "csrf_token"
+)
+
+// realmRoutingHandler resolves the realm of each request before it reaches the provided handler. The path prefix of a realm
+// that is served under one is removed from the request, so that it matches the same routes as it would at the root of a host
+func realmRoutingHandler(c *container, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := resolveRealmRoute(r, c.realms, c.trustedProxies)
+		r = r.WithContext(context.WithValue(r.Context(), realmRouteContextKey{}, route))
+
+		if route.prefix != "" {
+			u := *r.URL
+			u.Path = strings.TrimPrefix(u.Path, route.prefix)
+			if u.Path == "" {
+				u.Path = "/"
+			}
+			u.RawPath = ""
+			r.URL = &u
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestContextKey identifies the requestContext stored on the context of each request
+type requestContextKey struct{}
+
+// requestContext holds the context resolved for a request, so that the middlewares and handler that serve it all share
+// the same one, and anything counted while resolving it, such as the usage of an api key, is only counted once
+type requestContext struct {
+	once   sync.Once
+	ctx    context.Context
+	cancel context.CancelFunc
+	err    error
+}
+
+// requestContextMiddleware stores an empty requestContext on each request, which is resolved the first time it is needed
+// and cancelled once the request has been served
+func requestContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rc := &requestContext{}
+		r = r.WithContext(context.WithValue(r.Context(), requestContextKey{}, rc))
+
+		defer func() {
+			if rc.cancel != nil {
+				rc.cancel()
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// securityHeadersMiddleware sets the security headers configured for the realm of each request on its response
+func securityHeadersMiddleware(c *container) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// an unknown realm is left for the handler to reject, but its response still receives the default headers
+			realm, _ := c.realms.GetByName(realmRouteFromRequest(r, c.realms, c.trustedProxies).realmName)
+			writeSecurityHeaders(w.Header(), realm.SecurityHeaders)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeSecurityHeaders sets the provided security headers, falling back to a safe default for any that must always be sent
+func writeSecurityHeaders(h http.Header, sh domain.RealmSecurityHeaders) {
+	frameOptions := sh.FrameOptions
+	if frameOptions == "" {
+		frameOptions = "DENY"
+	}
+
+	referrerPolicy := sh.ReferrerPolicy
+	if referrerPolicy == "" {
+		referrerPolicy = "strict-origin-when-cross-origin"
+	}
+
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Set("X-Frame-Options", frameOptions)
+	h.Set("Referrer-Policy", referrerPolicy)
+
+	// a content security policy depends on which third parties a realm embeds, so there is no default that suits every realm
+	if sh.ContentSecurityPolicy != "" {
+		h.Set("Content-Security-Policy", sh.ContentSecurityPolicy)
+	}
+
+	if sh.StrictTransportSecurity != "" {
+		h.Set("Strict-Transport-Security", sh.StrictTransportSecurity)
+	}
+}
+
+// csrfMiddleware protects against cross-site request forgery using double-submit tokens. Each browser is issued a random
+// token in a cookie, which must be submitted back in a header or form field alongside every request that requires it.
+// Another site can cause the cookie to be sent, but cannot read it, so cannot submit a matching token
+func csrfMiddleware(c *container) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cookieToken := getCSRFCookieValue(r)
+
+			if cookieToken == "" {
+				token, err := generateCSRFToken()
+				if err != nil {
+					c.logger.Errorf("cannot generate csrf token: %s", err.Error())
+					internalError(err).writeTo(w)
+					return
+				}
+				setCSRFCookie(token, w, r)
+
+				// the browser does not have the cookie yet, so pages rendered for this request must see the new token instead
+				r.AddCookie(&http.Cookie{Name: csrfCookieName, Value: token})
+			}
+
+			if requiresCSRFToken(r) && !isValidCSRFToken(cookieToken, submittedCSRFToken(r)) {
+				c.logger.Infof("csrf: rejected %s request to %s from %s", r.Method, r.URL.Path, clientIPFromRequest(r, c.trustedProxies))
+				forbiddenError("invalid csrf token").writeTo(w)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requiresCSRFToken determines whether the provided request must be accompanied by a csrf token
+func requiresCSRFToken(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+
+	// payment webhooks are sent by the provider rather than a browser, and are verified by their signature instead
+	if r.URL.Path == paymentWebhookPath {
+		return false
+	}
+
+	// credentials held in a header cannot be attached to a request by another site, unlike cookies, so admin requests
+	// made with basic auth or an api key are left alone. every other request is protected whether the visitor is logged
+	// in or not, so that another site cannot log them in, sign them up or submit a form on their behalf
+	_, _, hasBasicAuth := r.BasicAuth()
+	return !hasBasicAuth && r.Header.Get(apiKeyHeaderName) == ""
+}
+
+// submittedCSRFToken retrieves the csrf token submitted with the provided request, from either its header or its form
+func submittedCSRFToken(r *http.Request) string {
+	if token := r.Header.Get(csrfHeaderName); token != "" {
+		return token
+	}
+
+	// only reads the body of a form submission, so api requests are left for the handler to read
+	return r.PostFormValue(csrfFormField)
+}
+
+// isValidCSRFToken determines whether the submitted csrf token matches the one held in the cookie
+func isValidCSRFToken(cookieToken, submittedToken string) bool {
+	if cookieToken == "" || submittedToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookieToken), []byte(submittedToken)) == 1
+}
+
+// generateCSRFToken generates a cryptographically random csrf token
+func generateCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// renewSessionMiddleware renews the session identified by the auth cookie of each request once it is close to expiry,
+// so that an entrant who remains active is not logged out part-way through making changes
+func renewSessionMiddleware(c *container) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// static assets are requested alongside every page, so leave the renewal to the page itself
+			if authTknID := getAuthCookieValue(r); authTknID != "" && !strings.HasPrefix(r.URL.Path, "/assets") {
+				renewSession(c, authTknID, w, r)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// renewSession replaces the provided auth token with a renewed one if required, and rotates the auth cookie to match
+func renewSession(c *container, authTknID string, w http.ResponseWriter, r *http.Request) {
+	// get context from request
+	ctx, cancel, err := contextFromRequest(r, c)
+	if err != nil {
+		c.logger.Errorf("cannot renew session: %s", err.Error())
+		return
+	}
+	defer cancel()
+
+	renewed, err := c.tokenAgent.RenewAuthToken(ctx, authTknID)
+	if err != nil {
+		// an invalid or expired session is left for the handler to reject
+		if !errors.As(err, &domain.NotFoundError{}) && !errors.As(err, &domain.UnauthorizedError{}) {
+			c.logger.Errorf("cannot renew session: %s", err.Error())
+		}
+		return
+	}
+
+	if renewed == nil {
+		return
+	}
+
+	setAuthCookie(renewed, w, r)
+
+	// the original auth token is only valid for a short grace period now, so the handler must only ever see the renewed one
+	replaceAuthCookieValue(renewed.ID, r)
+}
+
+// adminUserContextKey identifies the AdminUser stored on the context of a request once its basic auth credentials
+// have been authenticated
+type adminUserContextKey struct{}
+
+// adminAuthMiddleware authenticates the basic auth credentials of each request made to an admin endpoint,
+// so that the cost of verifying a password is never incurred by a request to any other endpoint
+func adminAuthMiddleware(c *container) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			username, password, ok := r.BasicAuth()
+
+			// api keys never authenticate an admin user, so basic auth credentials are disregarded alongside one
+			if !ok || r.Header.Get(apiKeyHeaderName) != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, err := authenticateAdminUser(c, username, password, r)
+			switch {
+			case err == nil:
+				r = r.WithContext(context.WithValue(r.Context(), adminUserContextKey{}, user))
+			case !errors.As(err, &domain.UnauthorizedError{}):
+				responseFromError(err).writeTo(w)
+				return
+			}
+
+			// invalid credentials are not an error here, since any operation that requires an admin user will check for one itself
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// authenticateAdminUser returns the AdminUser that matches the provided credentials, as long as the client that sent them
+// has not already got them wrong too many times
+func authenticateAdminUser(c *container, username, password string, r *http.Request) (domain.AdminUser, error) {
+	// get context from request
+	ctx, cancel, err := contextFromRequest(r, c)
+	if err != nil {
+		return domain.AdminUser{}, err
+	}
+	defer cancel()
+
+	// each attempt is counted before the credentials are checked, so that concurrent attempts cannot all get through
+	loginRateLimits := domain.AdminLoginRateLimits(clientIPFromRequest(r, c.trustedProxies))
+	if err := c.rateLimitAgent.Allow(ctx, loginRateLimits...); err != nil {
+		return domain.AdminUser{}, err
+	}
+
+	user, err := c.adminUserAgent.Authenticate(ctx, username, password)
+	if !errors.As(err, &domain.UnauthorizedError{}) {
+		// only failed attempts at admin credentials count towards their rate limits
+		if rlErr := c.rateLimitAgent.Release(ctx, loginRateLimits...); rlErr != nil {
+			c.logger.Errorf("cannot release admin login attempt: %s", rlErr.Error())
+		}
+	}
+
+	return user, err
+}
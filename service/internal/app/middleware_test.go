@@ -0,0 +1,403 @@
+package app
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"prediction-league/service/internal/adapters/logger"
+	"prediction-league/service/internal/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSRFMiddleware(t *testing.T) {
+	l, err := logger.NewLogger("DEBUG", &bytes.Buffer{}, &mockClock{t: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cnt := &container{logger: l}
+
+	csrfToken := "csrf-token-from-cookie"
+
+	// serve passes the provided request through the middleware, reporting whether it reached the handler
+	serve := func(r *http.Request) (*httptest.ResponseRecorder, bool) {
+		var reached bool
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reached = true
+		})
+
+		w := httptest.NewRecorder()
+		csrfMiddleware(cnt)(next).ServeHTTP(w, r)
+
+		return w, reached
+	}
+
+	// newFormRequest returns a request that submits the provided form values to the provided path
+	newFormRequest := func(path string, form url.Values) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return r
+	}
+
+	t.Run("request without a csrf cookie must be issued one", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/prediction", nil)
+
+		w, reached := serve(r)
+		if !reached {
+			t.Fatal("want request to reach handler")
+		}
+
+		var issued string
+		for _, cookie := range w.Result().Cookies() {
+			if cookie.Name == csrfCookieName {
+				issued = cookie.Value
+			}
+		}
+		if issued == "" {
+			t.Fatal("want csrf cookie to be issued")
+		}
+
+		// the page rendered by the same request must be able to embed the new token
+		if getCSRFCookieValue(r) != issued {
+			t.Fatalf("want csrf cookie value %s, got %s", issued, getCSRFCookieValue(r))
+		}
+	})
+
+	t.Run("cross-site api request made with the auth cookie must be rejected", func(t *testing.T) {
+		for _, token := range []string{"", "not-the-csrf-token"} {
+			r := httptest.NewRequest(http.MethodPost, "/api/entry/abc/prediction", strings.NewReader(`{}`))
+			r.AddCookie(&http.Cookie{Name: authCookieName, Value: "auth-token"})
+			r.AddCookie(&http.Cookie{Name: csrfCookieName, Value: csrfToken})
+			if token != "" {
+				r.Header.Set(csrfHeaderName, token)
+			}
+
+			w, reached := serve(r)
+			if reached {
+				t.Fatalf("token '%s': want request not to reach handler", token)
+			}
+			if w.Code != http.StatusForbidden {
+				t.Fatalf("want status %d, got %d", http.StatusForbidden, w.Code)
+			}
+		}
+	})
+
+	t.Run("cross-site api request made with the auth cookie but no csrf cookie must be rejected", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/api/logout", nil)
+		r.AddCookie(&http.Cookie{Name: authCookieName, Value: "auth-token"})
+		r.Header.Set(csrfHeaderName, csrfToken)
+
+		w, reached := serve(r)
+		if reached {
+			t.Fatal("want request not to reach handler")
+		}
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("want status %d, got %d", http.StatusForbidden, w.Code)
+		}
+	})
+
+	t.Run("api request made with the auth cookie and a matching csrf header must succeed", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/api/entry/abc/prediction", strings.NewReader(`{}`))
+		r.AddCookie(&http.Cookie{Name: authCookieName, Value: "auth-token"})
+		r.AddCookie(&http.Cookie{Name: csrfCookieName, Value: csrfToken})
+		r.Header.Set(csrfHeaderName, csrfToken)
+
+		if _, reached := serve(r); !reached {
+			t.Fatal("want request to reach handler")
+		}
+	})
+
+	t.Run("cross-site api request made without the auth cookie must be rejected", func(t *testing.T) {
+		for _, path := range []string{"/api/login/code", "/api/season/abc/entry"} {
+			r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{}`))
+			r.AddCookie(&http.Cookie{Name: csrfCookieName, Value: csrfToken})
+
+			w, reached := serve(r)
+			if reached {
+				t.Fatalf("path '%s': want request not to reach handler", path)
+			}
+			if w.Code != http.StatusForbidden {
+				t.Fatalf("want status %d, got %d", http.StatusForbidden, w.Code)
+			}
+		}
+	})
+
+	t.Run("api request made before login with the csrf cookie issued to its page must succeed", func(t *testing.T) {
+		w, _ := serve(httptest.NewRequest(http.MethodGet, "/login", nil))
+
+		var issued *http.Cookie
+		for _, cookie := range w.Result().Cookies() {
+			if cookie.Name == csrfCookieName {
+				issued = cookie
+			}
+		}
+		if issued == nil {
+			t.Fatal("want csrf cookie to be issued")
+		}
+
+		r := httptest.NewRequest(http.MethodPost, "/api/login/code", strings.NewReader(`{}`))
+		r.AddCookie(&http.Cookie{Name: csrfCookieName, Value: issued.Value})
+		r.Header.Set(csrfHeaderName, issued.Value)
+
+		if _, reached := serve(r); !reached {
+			t.Fatal("want request to reach handler")
+		}
+	})
+
+	t.Run("admin api request made with basic auth or an api key must succeed", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPatch, "/api/entry/abc/approve", nil)
+		r.SetBasicAuth("admin", "password")
+
+		if _, reached := serve(r); !reached {
+			t.Fatal("basic auth: want request to reach handler")
+		}
+
+		r = httptest.NewRequest(http.MethodPatch, "/api/entry/abc/approve", nil)
+		r.Header.Set(apiKeyHeaderName, "api-key")
+
+		if _, reached := serve(r); !reached {
+			t.Fatal("api key: want request to reach handler")
+		}
+	})
+
+	t.Run("payment webhook request must succeed", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/api/payment/webhook", strings.NewReader(`{}`))
+
+		if _, reached := serve(r); !reached {
+			t.Fatal("want request to reach handler")
+		}
+	})
+
+	t.Run("cross-site form submission must be rejected", func(t *testing.T) {
+		r := newFormRequest("/login", url.Values{"email_addr": {"harry.redknapp@football.net"}})
+		r.AddCookie(&http.Cookie{Name: csrfCookieName, Value: csrfToken})
+
+		w, reached := serve(r)
+		if reached {
+			t.Fatal("want request not to reach handler")
+		}
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("want status %d, got %d", http.StatusForbidden, w.Code)
+		}
+	})
+
+	t.Run("form submission with a matching csrf form field must succeed", func(t *testing.T) {
+		r := newFormRequest("/login", url.Values{
+			"email_addr": {"harry.redknapp@football.net"},
+			"csrf_token": {csrfToken},
+		})
+		r.AddCookie(&http.Cookie{Name: csrfCookieName, Value: csrfToken})
+
+		if _, reached := serve(r); !reached {
+			t.Fatal("want request to reach handler")
+		}
+
+		// the form must remain readable by the handler
+		if r.FormValue("email_addr") != "harry.redknapp@football.net" {
+			t.Fatalf("want email_addr harry.redknapp@football.net, got %s", r.FormValue("email_addr"))
+		}
+	})
+}
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	realm := domain.Realm{
+		Config: domain.RealmConfig{Name: "example.com"},
+		SecurityHeaders: domain.RealmSecurityHeaders{
+			ContentSecurityPolicy:   "frame-ancestors 'none'",
+			StrictTransportSecurity: "max-age=31536000",
+			FrameOptions:            "SAMEORIGIN",
+			ReferrerPolicy:          "no-referrer",
+		},
+	}
+
+	cnt := &container{realms: domain.RealmCollection{realm}}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	t.Run("response for a realm must have its configured headers", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "http://example.com:3000/", nil)
+		w := httptest.NewRecorder()
+
+		securityHeadersMiddleware(cnt)(next).ServeHTTP(w, r)
+
+		want := map[string]string{
+			"Content-Security-Policy":   "frame-ancestors 'none'",
+			"Strict-Transport-Security": "max-age=31536000",
+			"X-Frame-Options":           "SAMEORIGIN",
+			"Referrer-Policy":           "no-referrer",
+			"X-Content-Type-Options":    "nosniff",
+		}
+		for name, value := range want {
+			if got := w.Header().Get(name); got != value {
+				t.Fatalf("header %s: want '%s', got '%s'", name, value, got)
+			}
+		}
+	})
+
+	t.Run("response for a realm without configured headers must have the defaults", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "http://unknown.com/", nil)
+		w := httptest.NewRecorder()
+
+		securityHeadersMiddleware(cnt)(next).ServeHTTP(w, r)
+
+		want := map[string]string{
+			"Content-Security-Policy":   "",
+			"Strict-Transport-Security": "",
+			"X-Frame-Options":           "DENY",
+			"Referrer-Policy":           "strict-origin-when-cross-origin",
+			"X-Content-Type-Options":    "nosniff",
+		}
+		for name, value := range want {
+			if got := w.Header().Get(name); got != value {
+				t.Fatalf("header %s: want '%s', got '%s'", name, value, got)
+			}
+		}
+	})
+}
+
+func TestRealmRoutingHandler(t *testing.T) {
+	cnt := &container{
+		realms: domain.RealmCollection{
+			{Config: domain.RealmConfig{Name: "example.com"}, Site: domain.RealmSite{Paths: domain.NewRealmSitePaths("")}},
+			{Config: domain.RealmConfig{Name: "league", PathPrefix: true}, Site: domain.RealmSite{Paths: domain.NewRealmSitePaths("/r/league")}},
+		},
+	}
+
+	// serve passes the provided request through the handler, returning the request that reached the next handler
+	serve := func(r *http.Request) *http.Request {
+		var reached *http.Request
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reached = r
+		})
+
+		realmRoutingHandler(cnt, next).ServeHTTP(httptest.NewRecorder(), r)
+
+		return reached
+	}
+
+	t.Run("request to realm path prefix must be routed without it", func(t *testing.T) {
+		for path, wantPath := range map[string]string{
+			"/r/league":                 "/",
+			"/r/league/":                "/",
+			"/r/league/api/season/2020": "/api/season/2020",
+		} {
+			got := serve(httptest.NewRequest(http.MethodGet, "http://example.com"+path, nil))
+			if got.URL.Path != wantPath {
+				t.Fatalf("want path %s, got %s", wantPath, got.URL.Path)
+			}
+			if name := realmRouteFromRequest(got, nil, nil).realmName; name != "league" {
+				t.Fatalf("want realm %s, got %s", "league", name)
+			}
+			if cookieDomain, cookiePath := cookieScope(got); cookieDomain != "example.com" || cookiePath != "/r/league/" {
+				t.Fatalf("want cookie scope %s%s, got %s%s", "example.com", "/r/league/", cookieDomain, cookiePath)
+			}
+		}
+	})
+
+	t.Run("request to realm host must be routed unchanged", func(t *testing.T) {
+		got := serve(httptest.NewRequest(http.MethodGet, "http://example.com/r/unknown/leaderboard", nil))
+		if got.URL.Path != "/r/unknown/leaderboard" {
+			t.Fatalf("want path %s, got %s", "/r/unknown/leaderboard", got.URL.Path)
+		}
+		if name := realmRouteFromRequest(got, nil, nil).realmName; name != "example.com" {
+			t.Fatalf("want realm %s, got %s", "example.com", name)
+		}
+		if cookieDomain, cookiePath := cookieScope(got); cookieDomain != "example.com" || cookiePath != "/" {
+			t.Fatalf("want cookie scope %s%s, got %s%s", "example.com", "/", cookieDomain, cookiePath)
+		}
+	})
+}
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	cl := &mockClock{t: time.Date(2018, 5, 26, 14, 0, 0, 0, time.UTC)}
+
+	l, err := logger.NewLogger("DEBUG", &bytes.Buffer{}, cl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aua, err := domain.NewAdminUserAgent(&mockAdminUserRepository{users: make(map[string]domain.AdminUser)}, cl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rla, err := domain.NewRateLimitAgent(domain.NewInMemoryRateLimitStore(), cl, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cnt := &container{
+		realms:         domain.RealmCollection{{Config: domain.RealmConfig{Name: "example.com"}}},
+		adminUserAgent: aua,
+		rateLimitAgent: rla,
+		logger:         l,
+	}
+
+	ctx, cancel := domain.NewContext()
+	defer cancel()
+
+	if _, err := aua.BootstrapAdminUser(ctx, "admin:password123"); err != nil {
+		t.Fatal(err)
+	}
+
+	// serve passes a request made with the provided credentials through the middleware,
+	// returning the response and whether the next handler saw an authenticated admin user
+	serve := func(username, password string) (*httptest.ResponseRecorder, bool) {
+		var authenticated bool
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel, err := contextFromRequest(r, cnt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cancel()
+
+			_, authenticated = domain.AdminUserFromContext(ctx)
+		})
+
+		r := httptest.NewRequest(http.MethodGet, "http://example.com/api/entries", nil)
+		if username != "" {
+			r.SetBasicAuth(username, password)
+		}
+		w := httptest.NewRecorder()
+
+		adminAuthMiddleware(cnt)(next).ServeHTTP(w, r)
+
+		return w, authenticated
+	}
+
+	t.Run("request with valid credentials must be authenticated", func(t *testing.T) {
+		if _, ok := serve("admin", "password123"); !ok {
+			t.Fatal("want authenticated admin user, got none")
+		}
+	})
+
+	t.Run("request without credentials must not be authenticated", func(t *testing.T) {
+		if _, ok := serve("", ""); ok {
+			t.Fatal("want no admin user, got one")
+		}
+	})
+
+	t.Run("request with invalid credentials must not be authenticated", func(t *testing.T) {
+		if _, ok := serve("admin", "not_the_password"); ok {
+			t.Fatal("want no admin user, got one")
+		}
+	})
+
+	t.Run("requests with invalid credentials beyond the rate limit must be refused", func(t *testing.T) {
+		var w *httptest.ResponseRecorder
+		for i := 0; i < 20; i++ {
+			w, _ = serve("admin", "not_the_password")
+		}
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("want status %d, got %d", http.StatusTooManyRequests, w.Code)
+		}
+
+		// valid credentials from the same client are refused too, until the window ends
+		if _, ok := serve("admin", "password123"); ok {
+			t.Fatal("want no admin user, got one")
+		}
+	})
+}
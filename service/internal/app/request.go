@@ -27,6 +27,15 @@ type updateEntryPaymentDetailsRequest struct {
 	RegistrationToken string `json:"reg_token"`
 }
 
+type createEntryPaymentOrderRequest struct {
+	RegistrationToken string `json:"reg_token"`
+}
+
+type captureEntryPaymentOrderRequest struct {
+	OrderID           string `json:"order_id"`
+	RegistrationToken string `json:"reg_token"`
+}
+
 type createEntryPredictionRequest struct {
 	PredictionToken string   `json:"entry_pred_token"`
 	RankingIDs     []string `json:"ranking_ids"`
@@ -39,3 +48,32 @@ func (r createEntryPredictionRequest) ToEntryPredictionModel() domain.EntryPredi
 type generateMagicLoginRequest struct {
 	EmailAddr string
 }
+
+type redeemLoginCodeRequest struct {
+	EmailAddr string `json:"email_addr"`
+	Code      string `json:"code"`
+}
+
+type updateEntrantDetailsRequest struct {
+	EntrantNickname *string `json:"entrant_nickname"`
+	EntrantEmail    *string `json:"entrant_email"`
+}
+
+type approveEntriesRequest struct {
+	EntryIDs []string `json:"entry_ids"`
+}
+
+type createAdminUserRequest struct {
+	Username   string            `json:"username"`
+	Password   string            `json:"password"`
+	RealmRoles map[string]string `json:"realm_roles"`
+}
+
+type createAPIKeyRequest struct {
+	Name string `json:"name"`
+}
+
+type setAdminUserRoleRequest struct {
+	RealmName string `json:"realm"`
+	Role      string `json:"role"`
+}
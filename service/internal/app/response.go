@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 	"prediction-league/service/internal/domain"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -18,6 +19,8 @@ type response struct {
 	Code    int    `json:"code"`           // Any valid HTTP response code
 	Message string `json:"message"`        // Any relevant message (optional)
 	Data    *data  `json:"data,omitempty"` // Data to pass along to the response (optional)
+
+	retryAfter time.Duration // Duration after which the request can be retried, sent as a header (optional)
 }
 
 // writeTo writes a JSON response to a HTTP writer.
@@ -27,6 +30,9 @@ func (r response) writeTo(w http.ResponseWriter) error {
 		w.WriteHeader(r.Code)
 		return nil
 	}
+	if r.retryAfter > 0 {
+		w.Header().Set("Retry-After", retryAfterSeconds(r.retryAfter))
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(r.Code)
 	return json.NewEncoder(w).Encode(r)
@@ -55,6 +61,21 @@ func unauthorizedError() *response {
 	return &response{Code: http.StatusUnauthorized, Message: "unauthorized"}
 }
 
+// forbiddenError returns a prepared 403 Forbidden error, including the message passed by the user in the message field of the response object.
+func forbiddenError(msg interface{}) *response {
+	return &response{Code: http.StatusForbidden, Message: fmt.Sprintf("forbidden: %v", msg)}
+}
+
+// tooManyRequestsError returns a prepared 429 Too Many Requests response, advising that the request can be retried after the provided duration.
+func tooManyRequestsError(retryAfter time.Duration) *response {
+	return &response{Code: http.StatusTooManyRequests, Message: "too many requests", retryAfter: retryAfter}
+}
+
+// retryAfterSeconds returns the provided duration as a whole number of seconds for a Retry-After header, rounding up.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(int((d + time.Second - 1) / time.Second))
+}
+
 // notFoundError returns a prepared 404 Not Found response, including the message passed by the user in the message field of the response object.
 func notFoundError(msg interface{}) *response {
 	return &response{Code: http.StatusNotFound, Message: fmt.Sprintf("resource not found: %v", msg)}
@@ -70,6 +91,33 @@ type createEntryResponse struct {
 	Nickname          string `json:"nickname"`
 	RegistrationToken string `json:"reg_token"`
 	NeedsPayment      bool   `json:"needs_payment"`
+	Resumed           bool   `json:"resumed"`
+	Waitlisted        bool   `json:"waitlisted"`
+}
+
+type logoutResponse struct {
+	Revoked int64 `json:"revoked"`
+}
+
+type loginResponse struct {
+	EntryID   string    `json:"entry_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type createEntryPaymentOrderResponse struct {
+	OrderID string `json:"order_id"`
+}
+
+type captureEntryPaymentOrderResponse struct {
+	PaymentRef   string `json:"payment_ref"`
+	MerchantName string `json:"merchant_name"`
+	Approved     bool   `json:"approved"`
+}
+
+type paymentEventResponse struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Outcome string `json:"outcome"`
 }
 
 type retrieveSeasonResponse struct {
@@ -93,6 +141,74 @@ type scoredEntryPredictionResponseRanking struct {
 	MetaPosition int `json:"meta_position"`
 }
 
+type adminEntryResponse struct {
+	ID               string                         `json:"id"`
+	SeasonID         string                         `json:"season_id"`
+	RealmName        string                         `json:"realm_name"`
+	EntrantName      string                         `json:"entrant_name"`
+	EntrantNickname  string                         `json:"entrant_nickname"`
+	EntrantEmail     string                         `json:"entrant_email"`
+	Status           string                         `json:"status"`
+	PaymentMethod    *string                        `json:"payment_method"`
+	PaymentRef       *string                        `json:"payment_ref"`
+	EntryPredictions []adminEntryPredictionResponse `json:"entry_predictions,omitempty"`
+	ApprovedAt       *time.Time                     `json:"approved_at"`
+	EmailVerifiedAt  *time.Time                     `json:"email_verified_at"`
+	CreatedAt        time.Time                      `json:"created_at"`
+	UpdatedAt        *time.Time                     `json:"updated_at"`
+}
+
+type adminEntryPredictionResponse struct {
+	ID         string    `json:"id"`
+	RankingIDs []string  `json:"ranking_ids"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// newAdminEntryResponse returns an adminEntryResponse inflated from the provided Entry
+func newAdminEntryResponse(entry domain.Entry) adminEntryResponse {
+	var predictions []adminEntryPredictionResponse
+	for _, ep := range entry.EntryPredictions {
+		predictions = append(predictions, adminEntryPredictionResponse{
+			ID:         ep.ID.String(),
+			RankingIDs: ep.Rankings.GetIDs(),
+			CreatedAt:  ep.CreatedAt,
+		})
+	}
+
+	return adminEntryResponse{
+		ID:               entry.ID.String(),
+		SeasonID:         entry.SeasonID,
+		RealmName:        entry.RealmName,
+		EntrantName:      entry.EntrantName,
+		EntrantNickname:  entry.EntrantNickname,
+		EntrantEmail:     entry.EntrantEmail,
+		Status:           entry.Status,
+		PaymentMethod:    entry.PaymentMethod,
+		PaymentRef:       entry.PaymentRef,
+		EntryPredictions: predictions,
+		ApprovedAt:       entry.ApprovedAt,
+		EmailVerifiedAt:  entry.EmailVerifiedAt,
+		CreatedAt:        entry.CreatedAt,
+		UpdatedAt:        entry.UpdatedAt,
+	}
+}
+
+type approveEntriesResponse struct {
+	Approved []string          `json:"approved"`
+	Failed   map[string]string `json:"failed"`
+}
+
+// createAPIKeyResponse includes the key itself alongside the APIKey, since this is the only time it can be seen
+type createAPIKeyResponse struct {
+	domain.APIKey
+	Key string `json:"key"`
+}
+
+type prizesResponse struct {
+	PrizePot domain.PrizePot      `json:"prize_pot"`
+	Payouts  []domain.PrizePayout `json:"payouts"`
+}
+
 // responseFromError returns a rest package-level error from a domain-level error
 func responseFromError(err error) *response {
 	switch {
@@ -127,6 +243,11 @@ func responseFromError(err error) *response {
 			},
 		}
 
+	case errors.As(err, &domain.RateLimitedError{}):
+		var rlErr domain.RateLimitedError
+		errors.As(err, &rlErr)
+		return tooManyRequestsError(rlErr.RetryAfter)
+
 	case errors.As(err, &domain.BadRequestError{}):
 		return &response{
 			Code:    http.StatusBadRequest,
@@ -0,0 +1,477 @@
+package domain
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// AdminRoleViewer represents an AdminUser who can view, but not modify, admin resources within a realm
+	AdminRoleViewer = "viewer"
+	// AdminRoleApprover represents an AdminUser who can approve and manage entries within a realm
+	AdminRoleApprover = "approver"
+	// AdminRoleSuperAdmin represents an AdminUser who has full control within a realm, including managing other AdminUsers
+	AdminRoleSuperAdmin = "superadmin"
+
+	// AdminRealmAll represents a role that applies to every realm
+	AdminRealmAll = "*"
+
+	// adminPasswordMinLength defines the minimum number of characters permitted for an AdminUser's password
+	adminPasswordMinLength = 8
+	// adminPasswordIterations defines the number of PBKDF2 iterations used when hashing an AdminUser's password
+	adminPasswordIterations = 100000
+	// adminPasswordSaltLength defines the number of random bytes used to salt an AdminUser's password
+	adminPasswordSaltLength = 16
+	// adminPasswordHashScheme identifies the algorithm that was used to produce a stored password hash
+	adminPasswordHashScheme = "pbkdf2-sha256"
+	// adminCredentialsCacheDuration defines how long a verified password is remembered for,
+	// so that each request made by an admin user does not have to derive its hash again
+	adminCredentialsCacheDuration = 15 * time.Minute
+)
+
+// adminRoleRanks determines the order of precedence of admin roles, with each role inheriting the permissions of those below it
+var adminRoleRanks = map[string]int{
+	AdminRoleViewer:     1,
+	AdminRoleApprover:   2,
+	AdminRoleSuperAdmin: 3,
+}
+
+// AdminUser represents a named user who is permitted to perform admin operations,
+// according to the role that they hold within each realm
+type AdminUser struct {
+	ID           uuid.UUID         `db:"id" json:"id"`
+	Username     string            `db:"username" json:"username"`
+	PasswordHash string            `db:"password_hash" json:"-"`
+	RealmRoles   map[string]string `db:"realm_roles" json:"realm_roles"`
+	CreatedAt    time.Time         `db:"created_at" json:"created_at"`
+	UpdatedAt    *time.Time        `db:"updated_at" json:"updated_at"`
+}
+
+// RoleForRealm returns the role that the AdminUser holds within the provided realm.
+// A role held for all realms applies if it outranks any role held for the specific realm
+func (a AdminUser) RoleForRealm(realmName string) string {
+	role := a.RealmRoles[realmName]
+	if all, ok := a.RealmRoles[AdminRealmAll]; ok && adminRoleRanks[all] > adminRoleRanks[role] {
+		role = all
+	}
+	return role
+}
+
+// HasRole determines whether the AdminUser holds at least the provided role within the provided realm
+func (a AdminUser) HasRole(realmName, role string) bool {
+	required, ok := adminRoleRanks[role]
+	if !ok {
+		return false
+	}
+	return adminRoleRanks[a.RoleForRealm(realmName)] >= required
+}
+
+// AdminUserRepository defines the interface for transacting with our AdminUser data source
+type AdminUserRepository interface {
+	Insert(ctx context.Context, user *AdminUser) error
+	Update(ctx context.Context, user *AdminUser) error
+	Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]AdminUser, error)
+}
+
+// verifiedAdminCredentials represents a password that has been verified against an AdminUser's stored password hash
+type verifiedAdminCredentials struct {
+	passwordHash string
+	digest       []byte
+	expiresAt    time.Time
+}
+
+// AdminUserAgent defines the behaviours for handling AdminUsers
+type AdminUserAgent struct {
+	aur AdminUserRepository
+	cl  Clock
+
+	// verified holds recently verified credentials by username. Passwords are only held as a keyed digest,
+	// using a key that is generated on startup and never leaves memory
+	verifiedMu  sync.Mutex
+	verified    map[string]verifiedAdminCredentials
+	verifiedKey []byte
+}
+
+// Authenticate returns the AdminUser whose username and password match those provided
+func (a *AdminUserAgent) Authenticate(ctx context.Context, username, password string) (AdminUser, error) {
+	user, err := a.retrieveAdminUserByUsername(ctx, username)
+	if err != nil {
+		if errors.As(err, &NotFoundError{}) {
+			// verify against a throwaway hash anyway, so that response times don't reveal which usernames exist
+			verifyAdminPassword(unknownAdminUserPasswordHash, password)
+			return AdminUser{}, UnauthorizedError{errors.New("invalid credentials")}
+		}
+		return AdminUser{}, err
+	}
+
+	if a.isVerified(user, password) {
+		return user, nil
+	}
+
+	if !verifyAdminPassword(user.PasswordHash, password) {
+		return AdminUser{}, UnauthorizedError{errors.New("invalid credentials")}
+	}
+
+	a.setVerified(user, password)
+
+	return user, nil
+}
+
+// isVerified determines whether the provided password has recently been verified for the provided AdminUser,
+// and the AdminUser's password has not changed since
+func (a *AdminUserAgent) isVerified(user AdminUser, password string) bool {
+	a.verifiedMu.Lock()
+	defer a.verifiedMu.Unlock()
+
+	v, ok := a.verified[user.Username]
+	if !ok || v.passwordHash != user.PasswordHash || !a.cl.Now().Before(v.expiresAt) {
+		return false
+	}
+
+	return hmac.Equal(v.digest, a.passwordDigest(password))
+}
+
+// setVerified remembers that the provided password has been verified for the provided AdminUser
+func (a *AdminUserAgent) setVerified(user AdminUser, password string) {
+	a.verifiedMu.Lock()
+	defer a.verifiedMu.Unlock()
+
+	now := a.cl.Now()
+
+	// discard expired credentials, so that memory usage does not grow indefinitely
+	for username, v := range a.verified {
+		if !now.Before(v.expiresAt) {
+			delete(a.verified, username)
+		}
+	}
+
+	a.verified[user.Username] = verifiedAdminCredentials{
+		passwordHash: user.PasswordHash,
+		digest:       a.passwordDigest(password),
+		expiresAt:    now.Add(adminCredentialsCacheDuration),
+	}
+}
+
+// passwordDigest returns a keyed digest of the provided password
+func (a *AdminUserAgent) passwordDigest(password string) []byte {
+	mac := hmac.New(sha256.New, a.verifiedKey)
+	mac.Write([]byte(password))
+	return mac.Sum(nil)
+}
+
+// CreateAdminUser creates a new AdminUser with the provided credentials and realm roles
+func (a *AdminUserAgent) CreateAdminUser(ctx context.Context, username, password string, realmRoles map[string]string) (AdminUser, error) {
+	// ensure that the requester is a superadmin of every realm that they are granting roles for
+	if _, ok := AdminUserFromContext(ctx); !ok {
+		return AdminUser{}, UnauthorizedError{}
+	}
+	for realmName := range realmRoles {
+		if !HasAdminRoleForRealm(ctx, realmName, AdminRoleSuperAdmin) {
+			return AdminUser{}, UnauthorizedError{}
+		}
+	}
+
+	user := AdminUser{
+		Username:   strings.Trim(username, " "),
+		RealmRoles: realmRoles,
+	}
+
+	if err := sanitiseAdminUser(&user, password); err != nil {
+		return AdminUser{}, err
+	}
+
+	if _, err := a.retrieveAdminUserByUsername(ctx, user.Username); err == nil {
+		return AdminUser{}, ConflictError{errors.New("admin user already exists")}
+	} else if !errors.As(err, &NotFoundError{}) {
+		return AdminUser{}, err
+	}
+
+	return a.insertAdminUser(ctx, user, password)
+}
+
+// RetrieveAdminUsers retrieves the AdminUsers who hold a role within the current realm, ordered by username.
+// Only a superadmin of all realms retrieves every AdminUser along with all of their realm roles
+func (a *AdminUserAgent) RetrieveAdminUsers(ctx context.Context) ([]AdminUser, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleSuperAdmin) {
+		return nil, UnauthorizedError{}
+	}
+	realmName := RealmFromContext(ctx).Config.Name
+	allRealms := HasAdminRoleForRealm(ctx, AdminRealmAll, AdminRoleSuperAdmin)
+
+	users, err := a.aur.Select(ctx, map[string]interface{}{}, false)
+	if err != nil {
+		if errors.As(err, &MissingDBRecordError{}) {
+			return []AdminUser{}, nil
+		}
+		return nil, domainErrorFromRepositoryError(err)
+	}
+
+	if !allRealms {
+		users = filterAdminUsersByRealm(users, realmName)
+	}
+
+	sort.SliceStable(users, func(i, j int) bool {
+		return users[i].Username < users[j].Username
+	})
+
+	return users, nil
+}
+
+// SetAdminUserRole grants the provided role within the provided realm to the AdminUser with the provided username.
+// An empty role revokes any role that the AdminUser holds within the realm
+func (a *AdminUserAgent) SetAdminUserRole(ctx context.Context, username, realmName, role string) (AdminUser, error) {
+	// ensure that the requester is a superadmin of the realm that they are changing roles for
+	if !HasAdminRoleForRealm(ctx, realmName, AdminRoleSuperAdmin) {
+		return AdminUser{}, UnauthorizedError{}
+	}
+
+	if realmName == "" {
+		return AdminUser{}, ValidationError{Reasons: []string{"Realm must not be empty"}}
+	}
+
+	if role != "" && !isValidAdminRole(role) {
+		return AdminUser{}, ValidationError{Reasons: []string{fmt.Sprintf("%s is not a valid role", role)}}
+	}
+
+	user, err := a.retrieveAdminUserByUsername(ctx, username)
+	if err != nil {
+		return AdminUser{}, err
+	}
+
+	if requester, ok := AdminUserFromContext(ctx); ok && requester.ID == user.ID {
+		// prevent superadmins from accidentally locking themselves out
+		return AdminUser{}, ConflictError{errors.New("cannot change own role")}
+	}
+
+	if user.RealmRoles == nil {
+		user.RealmRoles = make(map[string]string)
+	}
+
+	switch role {
+	case "":
+		delete(user.RealmRoles, realmName)
+	default:
+		user.RealmRoles[realmName] = role
+	}
+
+	if err := a.aur.Update(ctx, &user); err != nil {
+		return AdminUser{}, domainErrorFromRepositoryError(err)
+	}
+
+	return user, nil
+}
+
+// BootstrapAdminUser ensures that an AdminUser exists with the credentials provided in the format "username:password",
+// holding the superadmin role for all realms. An existing AdminUser with the same username is left unchanged,
+// so that a password or role changed since is not reset on every startup
+func (a *AdminUserAgent) BootstrapAdminUser(ctx context.Context, credentials string) (AdminUser, error) {
+	split := strings.SplitN(credentials, ":", 2)
+	if len(split) != 2 || split[0] == "" || split[1] == "" {
+		return AdminUser{}, ValidationError{Reasons: []string{"admin credentials must be in the format username:password"}}
+	}
+	username, password := split[0], split[1]
+
+	user, err := a.retrieveAdminUserByUsername(ctx, username)
+	switch {
+	case err == nil:
+		return user, nil
+	case !errors.As(err, &NotFoundError{}):
+		return AdminUser{}, err
+	}
+
+	// credentials that pre-date admin users are not held to the rules for new admin users,
+	// so that existing deployments can still start up
+	user = AdminUser{
+		Username:   username,
+		RealmRoles: map[string]string{AdminRealmAll: AdminRoleSuperAdmin},
+	}
+
+	return a.insertAdminUser(ctx, user, password)
+}
+
+// insertAdminUser hashes the provided password and writes the provided AdminUser to the database
+func (a *AdminUserAgent) insertAdminUser(ctx context.Context, user AdminUser, password string) (AdminUser, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return AdminUser{}, InternalError{err}
+	}
+
+	hash, err := hashAdminPassword(password)
+	if err != nil {
+		return AdminUser{}, InternalError{err}
+	}
+
+	user.ID = id
+	user.PasswordHash = hash
+	user.CreatedAt = a.cl.Now().Truncate(time.Second)
+	user.UpdatedAt = nil
+
+	if err := a.aur.Insert(ctx, &user); err != nil {
+		return AdminUser{}, domainErrorFromRepositoryError(err)
+	}
+
+	return user, nil
+}
+
+// retrieveAdminUserByUsername retrieves the AdminUser with the provided username
+func (a *AdminUserAgent) retrieveAdminUserByUsername(ctx context.Context, username string) (AdminUser, error) {
+	users, err := a.aur.Select(ctx, map[string]interface{}{
+		"username": username,
+	}, false)
+	if err != nil {
+		return AdminUser{}, domainErrorFromRepositoryError(err)
+	}
+
+	if len(users) != 1 {
+		return AdminUser{}, InternalError{fmt.Errorf("admin users count other than 1: %d", len(users))}
+	}
+
+	return users[0], nil
+}
+
+// NewAdminUserAgent returns a new AdminUserAgent using the provided repository
+func NewAdminUserAgent(aur AdminUserRepository, cl Clock) (*AdminUserAgent, error) {
+	switch {
+	case aur == nil:
+		return nil, fmt.Errorf("admin user repository: %w", ErrIsNil)
+	case cl == nil:
+		return nil, fmt.Errorf("clock: %w", ErrIsNil)
+	}
+
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("cannot generate key: %w", err)
+	}
+
+	return &AdminUserAgent{
+		aur:         aur,
+		cl:          cl,
+		verified:    make(map[string]verifiedAdminCredentials),
+		verifiedKey: key,
+	}, nil
+}
+
+// filterAdminUsersByRealm returns the provided AdminUsers who hold a role within the provided realm,
+// with only the roles that apply to the provided realm
+func filterAdminUsersByRealm(users []AdminUser, realmName string) []AdminUser {
+	var filtered = make([]AdminUser, 0)
+
+	for _, user := range users {
+		if user.RoleForRealm(realmName) == "" {
+			continue
+		}
+
+		realmRoles := make(map[string]string)
+		for _, name := range []string{realmName, AdminRealmAll} {
+			if role, ok := user.RealmRoles[name]; ok {
+				realmRoles[name] = role
+			}
+		}
+		user.RealmRoles = realmRoles
+
+		filtered = append(filtered, user)
+	}
+
+	return filtered
+}
+
+// sanitiseAdminUser validates the provided AdminUser and the password that it will be created with
+func sanitiseAdminUser(user *AdminUser, password string) error {
+	var validationMsgs []string
+
+	if !regexp.MustCompile(`^[a-zA-Z0-9._-]{1,64}$`).MatchString(user.Username) {
+		validationMsgs = append(validationMsgs, "Username must be 1-64 characters and only contain A-Z, a-z, 0-9, '.', '_' or '-'")
+	}
+	if len(password) < adminPasswordMinLength {
+		validationMsgs = append(validationMsgs, fmt.Sprintf("Password must be %d characters or more", adminPasswordMinLength))
+	}
+	if len(user.RealmRoles) == 0 {
+		validationMsgs = append(validationMsgs, "Realm Roles must not be empty")
+	}
+	for realmName, role := range user.RealmRoles {
+		if !isValidAdminRole(role) {
+			validationMsgs = append(validationMsgs, fmt.Sprintf("%s is not a valid role for realm %s", role, realmName))
+		}
+	}
+
+	if len(validationMsgs) > 0 {
+		return ValidationError{Reasons: validationMsgs}
+	}
+
+	return nil
+}
+
+func isValidAdminRole(role string) bool {
+	_, ok := adminRoleRanks[role]
+	return ok
+}
+
+// unknownAdminUserPasswordHash is verified against when authenticating a username that doesn't exist
+var unknownAdminUserPasswordHash = fmt.Sprintf(
+	"%s$%d$%s$%s",
+	adminPasswordHashScheme,
+	adminPasswordIterations,
+	base64.RawStdEncoding.EncodeToString(make([]byte, adminPasswordSaltLength)),
+	base64.RawStdEncoding.EncodeToString(make([]byte, sha256.Size)),
+)
+
+// hashAdminPassword returns an encoded PBKDF2 hash of the provided password, using a random salt
+func hashAdminPassword(password string) (string, error) {
+	salt := make([]byte, adminPasswordSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("cannot generate salt: %w", err)
+	}
+
+	key := pbkdf2.Key([]byte(password), salt, adminPasswordIterations, sha256.Size, sha256.New)
+
+	return fmt.Sprintf(
+		"%s$%d$%s$%s",
+		adminPasswordHashScheme,
+		adminPasswordIterations,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// verifyAdminPassword determines whether the provided password matches the provided encoded hash
+func verifyAdminPassword(encoded, password string) bool {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != adminPasswordHashScheme {
+		return false
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return false
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+
+	got := pbkdf2.Key([]byte(password), salt, iterations, sha256.Size, sha256.New)
+
+	return subtle.ConstantTimeCompare(want, got) == 1
+}
@@ -0,0 +1,382 @@
+package domain_test
+
+import (
+	"errors"
+	"prediction-league/service/internal/domain"
+	"testing"
+
+	"gotest.tools/assert/cmp"
+)
+
+func TestNewAdminUserAgent(t *testing.T) {
+	t.Run("passing invalid parameters must return expected error", func(t *testing.T) {
+		cl := &mockClock{}
+
+		tt := []struct {
+			aur     domain.AdminUserRepository
+			cl      domain.Clock
+			wantErr error
+		}{
+			{nil, cl, domain.ErrIsNil},
+			{aur, nil, domain.ErrIsNil},
+			{aur, cl, nil},
+		}
+		for idx, tc := range tt {
+			agent, gotErr := domain.NewAdminUserAgent(tc.aur, tc.cl)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("tc #%d: want error %s (%T), got %s (%T)", idx, tc.wantErr, tc.wantErr, gotErr, gotErr)
+			}
+			if tc.wantErr == nil && agent == nil {
+				t.Fatalf("tc #%d: want non-empty agent, got nil", idx)
+			}
+		}
+	})
+}
+
+func TestAdminUser_HasRole(t *testing.T) {
+	user := domain.AdminUser{
+		RealmRoles: map[string]string{
+			domain.AdminRealmAll: domain.AdminRoleViewer,
+			testRealmName:        domain.AdminRoleApprover,
+		},
+	}
+
+	tt := []struct {
+		name      string
+		realmName string
+		role      string
+		want      bool
+	}{
+		{"role held for realm must be permitted", testRealmName, domain.AdminRoleApprover, true},
+		{"lower role than held for realm must be permitted", testRealmName, domain.AdminRoleViewer, true},
+		{"higher role than held for realm must not be permitted", testRealmName, domain.AdminRoleSuperAdmin, false},
+		{"role held for all realms must be permitted for other realm", "OTHER_REALM", domain.AdminRoleViewer, true},
+		{"role held for one realm must not be permitted for other realm", "OTHER_REALM", domain.AdminRoleApprover, false},
+		{"invalid role must not be permitted", testRealmName, "not_a_role", false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := user.HasRole(tc.realmName, tc.role); got != tc.want {
+				expectedGot(t, tc.want, got)
+			}
+		})
+	}
+
+	t.Run("user with no roles must not be permitted", func(t *testing.T) {
+		if (domain.AdminUser{}).HasRole(testRealmName, domain.AdminRoleViewer) {
+			expectedGot(t, false, true)
+		}
+	})
+}
+
+func TestAdminUserAgent_BootstrapAdminUser(t *testing.T) {
+	t.Cleanup(truncate)
+
+	agent, err := domain.NewAdminUserAgent(aur, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := testContextDefault(t)
+	defer cancel()
+
+	t.Run("bootstrap with malformed credentials must fail", func(t *testing.T) {
+		_, err := agent.BootstrapAdminUser(ctx, "no_separator")
+		if !cmp.ErrorType(err, domain.ValidationError{})().Success() {
+			expectedTypeOfGot(t, domain.ValidationError{}, err)
+		}
+	})
+
+	t.Run("bootstrap new admin user must create superadmin of all realms", func(t *testing.T) {
+		user, err := agent.BootstrapAdminUser(ctx, "admin:password123")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.RoleForRealm(domain.AdminRealmAll) != domain.AdminRoleSuperAdmin {
+			expectedGot(t, domain.AdminRoleSuperAdmin, user.RoleForRealm(domain.AdminRealmAll))
+		}
+
+		if _, err := agent.Authenticate(ctx, "admin", "password123"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("bootstrap existing admin user with new password must not reset password", func(t *testing.T) {
+		if _, err := agent.BootstrapAdminUser(ctx, "admin:newpassword123"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := agent.Authenticate(ctx, "admin", "password123"); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := agent.Authenticate(ctx, "admin", "newpassword123")
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("bootstrap new admin user with legacy short password must succeed", func(t *testing.T) {
+		if _, err := agent.BootstrapAdminUser(ctx, "legacy:short"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := agent.Authenticate(ctx, "legacy", "short"); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestAdminUserAgent_Authenticate(t *testing.T) {
+	t.Cleanup(truncate)
+
+	agent, err := domain.NewAdminUserAgent(aur, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := testContextDefault(t)
+	defer cancel()
+
+	if _, err := agent.BootstrapAdminUser(ctx, "admin:password123"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("authenticate with valid credentials must succeed", func(t *testing.T) {
+		user, err := agent.Authenticate(ctx, "admin", "password123")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.Username != "admin" {
+			expectedGot(t, "admin", user.Username)
+		}
+	})
+
+	t.Run("authenticate with invalid password must fail", func(t *testing.T) {
+		_, err := agent.Authenticate(ctx, "admin", "not_the_password")
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("authenticate with non-existent username must fail", func(t *testing.T) {
+		_, err := agent.Authenticate(ctx, "not_an_admin", "password123")
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+}
+
+func TestAdminUserAgent_CreateAdminUser(t *testing.T) {
+	t.Cleanup(truncate)
+
+	agent, err := domain.NewAdminUserAgent(aur, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	approverRoles := map[string]string{testRealmName: domain.AdminRoleApprover}
+
+	t.Run("create admin user as superadmin must succeed", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		user, err := agent.CreateAdminUser(ctx, "approver", "password123", approverRoles)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantUser := domain.AdminUser{
+			ID:           user.ID,
+			Username:     "approver",
+			PasswordHash: user.PasswordHash,
+			RealmRoles:   approverRoles,
+			CreatedAt:    testDate,
+		}
+		cmpDiff(t, "admin user", wantUser, user)
+
+		if _, err := agent.Authenticate(ctx, "approver", "password123"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("create admin user with existing username must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		_, err := agent.CreateAdminUser(ctx, "approver", "password123", approverRoles)
+		if !cmp.ErrorType(err, domain.ConflictError{})().Success() {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+	})
+
+	t.Run("create admin user with invalid details must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		_, err := agent.CreateAdminUser(ctx, "not a username", "short", map[string]string{testRealmName: "not_a_role"})
+		if !cmp.ErrorType(err, domain.ValidationError{})().Success() {
+			expectedTypeOfGot(t, domain.ValidationError{}, err)
+		}
+
+		var vErr domain.ValidationError
+		errors.As(err, &vErr)
+		if len(vErr.Reasons) != 3 {
+			expectedGot(t, 3, len(vErr.Reasons))
+		}
+	})
+
+	t.Run("create admin user as approver must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, domain.AdminUser{RealmRoles: approverRoles})
+		defer cancel()
+
+		_, err := agent.CreateAdminUser(ctx, "viewer", "password123", map[string]string{testRealmName: domain.AdminRoleViewer})
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("create admin user with no authenticated admin user must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		_, err := agent.CreateAdminUser(ctx, "viewer", "password123", nil)
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+}
+
+func TestAdminUserAgent_SetAdminUserRole(t *testing.T) {
+	t.Cleanup(truncate)
+
+	agent, err := domain.NewAdminUserAgent(aur, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := testContextDefault(t)
+	ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+	defer cancel()
+
+	if _, err := agent.CreateAdminUser(ctx, "viewer", "password123", map[string]string{testRealmName: domain.AdminRoleViewer}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("promote admin user must succeed", func(t *testing.T) {
+		user, err := agent.SetAdminUserRole(ctx, "viewer", testRealmName, domain.AdminRoleApprover)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !user.HasRole(testRealmName, domain.AdminRoleApprover) {
+			expectedGot(t, true, false)
+		}
+	})
+
+	t.Run("revoke admin user role must succeed", func(t *testing.T) {
+		user, err := agent.SetAdminUserRole(ctx, "viewer", testRealmName, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.HasRole(testRealmName, domain.AdminRoleViewer) {
+			expectedGot(t, false, true)
+		}
+	})
+
+	t.Run("set invalid role must fail", func(t *testing.T) {
+		_, err := agent.SetAdminUserRole(ctx, "viewer", testRealmName, "not_a_role")
+		if !cmp.ErrorType(err, domain.ValidationError{})().Success() {
+			expectedTypeOfGot(t, domain.ValidationError{}, err)
+		}
+	})
+
+	t.Run("set role for non-existent admin user must fail", func(t *testing.T) {
+		_, err := agent.SetAdminUserRole(ctx, "not_an_admin", testRealmName, domain.AdminRoleViewer)
+		if !cmp.ErrorType(err, domain.NotFoundError{})().Success() {
+			expectedTypeOfGot(t, domain.NotFoundError{}, err)
+		}
+	})
+
+	t.Run("set role as superadmin of different realm must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, domain.AdminUser{
+			RealmRoles: map[string]string{"OTHER_REALM": domain.AdminRoleSuperAdmin},
+		})
+		defer cancel()
+
+		_, err := agent.SetAdminUserRole(ctx, "viewer", testRealmName, domain.AdminRoleViewer)
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+}
+
+func TestAdminUserAgent_RetrieveAdminUsers(t *testing.T) {
+	t.Cleanup(truncate)
+
+	agent, err := domain.NewAdminUserAgent(aur, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := testContextDefault(t)
+	ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+	defer cancel()
+
+	if _, err := agent.CreateAdminUser(ctx, "viewer", "password123", map[string]string{
+		testRealmName: domain.AdminRoleViewer,
+		"OTHER_REALM": domain.AdminRoleApprover,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := agent.CreateAdminUser(ctx, "other", "password123", map[string]string{"OTHER_REALM": domain.AdminRoleSuperAdmin}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("retrieve admin users as superadmin of all realms must return every admin user", func(t *testing.T) {
+		users, err := agent.RetrieveAdminUsers(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(users) != 2 {
+			expectedGot(t, 2, len(users))
+		}
+	})
+
+	t.Run("retrieve admin users as superadmin of realm must only return admin users of realm", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, domain.AdminUser{
+			RealmRoles: map[string]string{testRealmName: domain.AdminRoleSuperAdmin},
+		})
+		defer cancel()
+
+		users, err := agent.RetrieveAdminUsers(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(users) != 1 {
+			expectedGot(t, 1, len(users))
+		}
+		if _, ok := users[0].RealmRoles["OTHER_REALM"]; ok {
+			expectedGot(t, false, true)
+		}
+	})
+
+	t.Run("retrieve admin users as approver must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, domain.AdminUser{
+			RealmRoles: map[string]string{testRealmName: domain.AdminRoleApprover},
+		})
+		defer cancel()
+
+		_, err := agent.RetrieveAdminUsers(ctx)
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+}
@@ -0,0 +1,237 @@
+package domain
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// apiKeyPrefix identifies a string as an APIKey, so that a leaked key is easy to recognise
+	apiKeyPrefix = "pl_"
+	// apiKeyNameMaxLength defines the maximum number of characters permitted for an APIKey's name
+	apiKeyNameMaxLength = 64
+)
+
+// APIKey represents a credential issued by an Admin to a third-party client, such as a bot or spreadsheet,
+// which grants read-only access to the API of a single realm
+type APIKey struct {
+	ID         uuid.UUID  `db:"id" json:"id"`
+	RealmName  string     `db:"realm_name" json:"realm_name"`
+	Name       string     `db:"name" json:"name"`
+	KeyHash    string     `db:"key_hash" json:"-"`
+	UsageCount int64      `db:"usage_count" json:"usage_count"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"last_used_at"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at"`
+}
+
+// IsRevoked determines whether the APIKey has been revoked
+func (a APIKey) IsRevoked() bool {
+	return a.RevokedAt != nil
+}
+
+// APIKeyRepository defines the interface for transacting with our APIKey data source
+type APIKeyRepository interface {
+	Insert(ctx context.Context, key *APIKey) error
+	Update(ctx context.Context, key *APIKey) error
+	Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]APIKey, error)
+	IncrementUsage(ctx context.Context, id string, usedAt time.Time) error
+}
+
+// APIKeyAgent defines the behaviours for handling APIKeys
+type APIKeyAgent struct {
+	akr APIKeyRepository
+	aa  *AuditAgent
+	cl  Clock
+	hk  []byte
+}
+
+// CreateAPIKey issues a new APIKey with the provided name for the current realm.
+// The key itself is returned alongside the APIKey, as only a keyed hash of it is stored and it cannot be retrieved again
+func (a *APIKeyAgent) CreateAPIKey(ctx context.Context, name string) (APIKey, string, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleSuperAdmin) {
+		return APIKey{}, "", UnauthorizedError{}
+	}
+
+	name = strings.Trim(name, " ")
+	switch {
+	case name == "":
+		return APIKey{}, "", ValidationError{Reasons: []string{"Name must not be empty"}}
+	case len(name) > apiKeyNameMaxLength:
+		return APIKey{}, "", ValidationError{Reasons: []string{fmt.Sprintf("Name must not exceed %d characters", apiKeyNameMaxLength)}}
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return APIKey{}, "", InternalError{err}
+	}
+
+	secret, err := generateTokenID()
+	if err != nil {
+		return APIKey{}, "", InternalError{err}
+	}
+	key := apiKeyPrefix + secret
+
+	apiKey := APIKey{
+		ID:        id,
+		RealmName: RealmFromContext(ctx).Config.Name,
+		Name:      name,
+		KeyHash:   a.hashAPIKey(key),
+		CreatedAt: a.cl.Now().Truncate(time.Second),
+	}
+
+	if err := a.akr.Insert(ctx, &apiKey); err != nil {
+		return APIKey{}, "", domainErrorFromRepositoryError(err)
+	}
+
+	if _, err := a.aa.Record(
+		ctx,
+		AdminAuditActorFromContext(ctx),
+		AuditActionAPIKeyCreated,
+		AuditTargetTypeAPIKey,
+		apiKey.ID.String(),
+		nil,
+		map[string]interface{}{"name": apiKey.Name},
+	); err != nil {
+		return APIKey{}, "", err
+	}
+
+	return apiKey, key, nil
+}
+
+// RetrieveAPIKeys retrieves every APIKey that has been issued for the current realm, most recently created first
+func (a *APIKeyAgent) RetrieveAPIKeys(ctx context.Context) ([]APIKey, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleSuperAdmin) {
+		return nil, UnauthorizedError{}
+	}
+
+	keys, err := a.akr.Select(ctx, map[string]interface{}{
+		"realm_name": RealmFromContext(ctx).Config.Name,
+	}, false)
+	if err != nil {
+		if errors.As(err, &MissingDBRecordError{}) {
+			return []APIKey{}, nil
+		}
+		return nil, domainErrorFromRepositoryError(err)
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+// RevokeAPIKeyByID revokes the APIKey with the provided ID, so that it can no longer be used
+func (a *APIKeyAgent) RevokeAPIKeyByID(ctx context.Context, id string) (APIKey, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleSuperAdmin) {
+		return APIKey{}, UnauthorizedError{}
+	}
+
+	keys, err := a.akr.Select(ctx, map[string]interface{}{
+		"id":         id,
+		"realm_name": RealmFromContext(ctx).Config.Name,
+	}, false)
+	if err != nil {
+		return APIKey{}, domainErrorFromRepositoryError(err)
+	}
+
+	if len(keys) != 1 {
+		return APIKey{}, NotFoundError{fmt.Errorf("api key id: %s not found", id)}
+	}
+
+	apiKey := keys[0]
+
+	if apiKey.IsRevoked() {
+		return APIKey{}, ConflictError{errors.New("api key has already been revoked")}
+	}
+
+	now := a.cl.Now().Truncate(time.Second)
+	apiKey.RevokedAt = &now
+
+	if err := a.akr.Update(ctx, &apiKey); err != nil {
+		return APIKey{}, domainErrorFromRepositoryError(err)
+	}
+
+	if _, err := a.aa.Record(
+		ctx,
+		AdminAuditActorFromContext(ctx),
+		AuditActionAPIKeyRevoked,
+		AuditTargetTypeAPIKey,
+		apiKey.ID.String(),
+		map[string]interface{}{"revoked_at": nil},
+		map[string]interface{}{"revoked_at": apiKey.RevokedAt},
+	); err != nil {
+		return APIKey{}, err
+	}
+
+	return apiKey, nil
+}
+
+// AuthenticateAPIKey returns the unrevoked APIKey that matches the provided key, regardless of the current realm,
+// so that the realm it was issued for can be determined from the key alone
+func (a *APIKeyAgent) AuthenticateAPIKey(ctx context.Context, key string) (APIKey, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return APIKey{}, UnauthorizedError{errors.New("invalid api key")}
+	}
+
+	keys, err := a.akr.Select(ctx, map[string]interface{}{
+		"key_hash": a.hashAPIKey(key),
+	}, false)
+	if err != nil {
+		if errors.As(err, &MissingDBRecordError{}) {
+			return APIKey{}, UnauthorizedError{errors.New("invalid api key")}
+		}
+		return APIKey{}, domainErrorFromRepositoryError(err)
+	}
+
+	if len(keys) != 1 || keys[0].IsRevoked() {
+		return APIKey{}, UnauthorizedError{errors.New("invalid api key")}
+	}
+
+	return keys[0], nil
+}
+
+// RecordAPIKeyUsage counts a single request made with the provided APIKey
+func (a *APIKeyAgent) RecordAPIKeyUsage(ctx context.Context, apiKey APIKey) error {
+	if err := a.akr.IncrementUsage(ctx, apiKey.ID.String(), a.cl.Now().Truncate(time.Second)); err != nil {
+		return domainErrorFromRepositoryError(err)
+	}
+
+	return nil
+}
+
+// hashAPIKey returns the keyed hash of the provided key, which is what identifies the APIKey in the data store
+func (a *APIKeyAgent) hashAPIKey(key string) string {
+	mac := hmac.New(sha256.New, a.hk)
+	mac.Write([]byte(key))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewAPIKeyAgent returns a new APIKeyAgent using the provided repository and audit agent,
+// which stores the keys it issues as a hash keyed with the provided hash key
+func NewAPIKeyAgent(akr APIKeyRepository, aa *AuditAgent, cl Clock, hashKey []byte) (*APIKeyAgent, error) {
+	switch {
+	case akr == nil:
+		return nil, fmt.Errorf("api key repository: %w", ErrIsNil)
+	case aa == nil:
+		return nil, fmt.Errorf("audit agent: %w", ErrIsNil)
+	case cl == nil:
+		return nil, fmt.Errorf("clock: %w", ErrIsNil)
+	case len(hashKey) == 0:
+		return nil, fmt.Errorf("hash key: %w", ErrIsNil)
+	}
+	return &APIKeyAgent{akr, aa, cl, hashKey}, nil
+}
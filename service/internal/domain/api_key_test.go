@@ -0,0 +1,253 @@
+package domain_test
+
+import (
+	"errors"
+	"prediction-league/service/internal/domain"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert/cmp"
+)
+
+func TestNewAPIKeyAgent(t *testing.T) {
+	t.Run("passing invalid parameters must return expected error", func(t *testing.T) {
+		cl := &mockClock{}
+
+		tt := []struct {
+			akr     domain.APIKeyRepository
+			aa      *domain.AuditAgent
+			cl      domain.Clock
+			hk      []byte
+			wantErr error
+		}{
+			{nil, aa, cl, testTokenHashKey, domain.ErrIsNil},
+			{akr, nil, cl, testTokenHashKey, domain.ErrIsNil},
+			{akr, aa, nil, testTokenHashKey, domain.ErrIsNil},
+			{akr, aa, cl, nil, domain.ErrIsNil},
+			{akr, aa, cl, testTokenHashKey, nil},
+		}
+		for idx, tc := range tt {
+			agent, gotErr := domain.NewAPIKeyAgent(tc.akr, tc.aa, tc.cl, tc.hk)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("tc #%d: want error %s (%T), got %s (%T)", idx, tc.wantErr, tc.wantErr, gotErr, gotErr)
+			}
+			if tc.wantErr == nil && agent == nil {
+				t.Fatalf("tc #%d: want non-empty agent, got nil", idx)
+			}
+		}
+	})
+}
+
+func TestAPIKeyAgent_CreateAPIKey(t *testing.T) {
+	t.Cleanup(truncate)
+
+	agent, err := domain.NewAPIKeyAgent(akr, aa, &mockClock{t: testDate}, testTokenHashKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("create api key with valid credentials must succeed", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		apiKey, key, err := agent.CreateAPIKey(ctx, " Discord bot ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if apiKey.Name != "Discord bot" {
+			expectedGot(t, "Discord bot", apiKey.Name)
+		}
+		if apiKey.RealmName != testRealmName {
+			expectedGot(t, testRealmName, apiKey.RealmName)
+		}
+		if !strings.HasPrefix(key, "pl_") {
+			expectedGot(t, "key with prefix pl_", key)
+		}
+
+		// only a hash of the key must be stored
+		if apiKey.KeyHash == "" || strings.Contains(apiKey.KeyHash, key) {
+			expectedGot(t, "hashed key", apiKey.KeyHash)
+		}
+
+		// creation must have been audited
+		records, err := ar.Select(ctx, map[string]interface{}{
+			"action":    domain.AuditActionAPIKeyCreated,
+			"target_id": apiKey.ID.String(),
+		}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if records[0].ActorID != testSuperAdmin.Username {
+			expectedGot(t, testSuperAdmin.Username, records[0].ActorID)
+		}
+	})
+
+	t.Run("create api key with empty name must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		_, _, err := agent.CreateAPIKey(ctx, " ")
+		if !cmp.ErrorType(err, domain.ValidationError{})().Success() {
+			expectedTypeOfGot(t, domain.ValidationError{}, err)
+		}
+	})
+
+	t.Run("create api key with invalid credentials must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		_, _, err := agent.CreateAPIKey(ctx, "Discord bot")
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+}
+
+func TestAPIKeyAgent_AuthenticateAPIKey(t *testing.T) {
+	t.Cleanup(truncate)
+
+	agent, err := domain.NewAPIKeyAgent(akr, aa, &mockClock{t: testDate}, testTokenHashKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := testContextDefault(t)
+	ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+	defer cancel()
+
+	apiKey, key, err := agent.CreateAPIKey(ctx, "Discord bot")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("authenticate valid api key from any realm must succeed", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		domain.RealmFromContext(ctx).Config.Name = "DIFFERENT_REALM"
+
+		got, err := agent.AuthenticateAPIKey(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != apiKey.ID {
+			expectedGot(t, apiKey.ID, got.ID)
+		}
+		if got.RealmName != testRealmName {
+			expectedGot(t, testRealmName, got.RealmName)
+		}
+	})
+
+	t.Run("authenticate unknown api key must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		for _, k := range []string{"pl_not_a_valid_key", "not_a_valid_key", ""} {
+			_, err := agent.AuthenticateAPIKey(ctx, k)
+			if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+				expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+			}
+		}
+	})
+
+	t.Run("record usage of api key must increment its usage count", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		for i := 0; i < 2; i++ {
+			if err := agent.RecordAPIKeyUsage(ctx, apiKey); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		got, err := agent.AuthenticateAPIKey(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.UsageCount != 2 {
+			expectedGot(t, 2, got.UsageCount)
+		}
+		if got.LastUsedAt == nil {
+			expectedNonEmpty(t, "APIKey.LastUsedAt")
+		}
+	})
+
+	t.Run("authenticate revoked api key must fail", func(t *testing.T) {
+		if _, err := agent.RevokeAPIKeyByID(ctx, apiKey.ID.String()); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := agent.AuthenticateAPIKey(ctx, key)
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+}
+
+func TestAPIKeyAgent_RevokeAPIKeyByID(t *testing.T) {
+	t.Cleanup(truncate)
+
+	agent, err := domain.NewAPIKeyAgent(akr, aa, &mockClock{t: testDate}, testTokenHashKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := testContextDefault(t)
+	ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+	defer cancel()
+
+	apiKey, _, err := agent.CreateAPIKey(ctx, "League stats spreadsheet")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("revoke api key with invalid credentials must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		_, err := agent.RevokeAPIKeyByID(ctx, apiKey.ID.String())
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("revoke api key from another realm must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		domain.RealmFromContext(ctx).Config.Name = "DIFFERENT_REALM"
+
+		_, err := agent.RevokeAPIKeyByID(ctx, apiKey.ID.String())
+		if !cmp.ErrorType(err, domain.NotFoundError{})().Success() {
+			expectedTypeOfGot(t, domain.NotFoundError{}, err)
+		}
+	})
+
+	t.Run("revoke api key with valid credentials must succeed", func(t *testing.T) {
+		revoked, err := agent.RevokeAPIKeyByID(ctx, apiKey.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !revoked.IsRevoked() {
+			expectedGot(t, "revoked api key true", "revoked api key false")
+		}
+
+		keys, err := agent.RetrieveAPIKeys(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(keys) != 1 || !keys[0].IsRevoked() {
+			expectedGot(t, "1 revoked api key", keys)
+		}
+	})
+
+	t.Run("revoke api key that has already been revoked must fail", func(t *testing.T) {
+		_, err := agent.RevokeAPIKeyByID(ctx, apiKey.ID.String())
+		if !cmp.ErrorType(err, domain.ConflictError{})().Success() {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+	})
+}
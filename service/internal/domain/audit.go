@@ -0,0 +1,191 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// AuditActorTypeAdmin represents an action performed by an authenticated AdminUser
+	AuditActorTypeAdmin = "admin"
+	// AuditActorTypeEntrant represents an action performed by the entrant who owns an Entry
+	AuditActorTypeEntrant = "entrant"
+	// AuditActorTypeSystem represents an action performed by the system itself
+	AuditActorTypeSystem = "system"
+
+	// AuditTargetTypeEntry represents an action performed on an Entry
+	AuditTargetTypeEntry = "entry"
+	// AuditTargetTypeAPIKey represents an action performed on an APIKey
+	AuditTargetTypeAPIKey = "api_key"
+
+	// AuditActionEntryApproved represents the approval of an Entry
+	AuditActionEntryApproved = "entry_approved"
+	// AuditActionExtendedTokenGenerated represents the generation of an extended Token on behalf of an Entry
+	AuditActionExtendedTokenGenerated = "extended_token_generated"
+	// AuditActionEntryPredictionCreated represents a new EntryPrediction being made for an Entry
+	AuditActionEntryPredictionCreated = "entry_prediction_created"
+	// AuditActionEntryPaymentStatusChanged represents a change to the status of an Entry following an event raised by the payment provider
+	AuditActionEntryPaymentStatusChanged = "entry_payment_status_changed"
+	// AuditActionEntryExpired represents an unpaid Entry expiring once its Season has stopped accepting entries
+	AuditActionEntryExpired = "entry_expired"
+	// AuditActionEntryPromoted represents a waitlisted Entry being given a place that has become available
+	AuditActionEntryPromoted = "entry_promoted"
+	// AuditActionEntrySessionsRevoked represents every authenticated session of an Entry being revoked
+	AuditActionEntrySessionsRevoked = "entry_sessions_revoked"
+	// AuditActionAPIKeyCreated represents a new APIKey being issued
+	AuditActionAPIKeyCreated = "api_key_created"
+	// AuditActionAPIKeyRevoked represents an APIKey being revoked
+	AuditActionAPIKeyRevoked = "api_key_revoked"
+)
+
+// AuditActor represents the party responsible for an audited action
+type AuditActor struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// AdminAuditActorFromContext returns an AuditActor representing the AdminUser on the provided context,
+// or the system if no AdminUser has been authenticated
+func AdminAuditActorFromContext(ctx context.Context) AuditActor {
+	if user, ok := AdminUserFromContext(ctx); ok {
+		return AuditActor{Type: AuditActorTypeAdmin, ID: user.Username}
+	}
+	return AuditActor{Type: AuditActorTypeSystem}
+}
+
+// EntrantAuditActor returns an AuditActor representing the entrant who owns the provided Entry
+func EntrantAuditActor(entry Entry) AuditActor {
+	return AuditActor{Type: AuditActorTypeEntrant, ID: entry.ID.String()}
+}
+
+// AuditRecord represents a single action that has been recorded within the audit log
+type AuditRecord struct {
+	ID         uuid.UUID       `db:"id" json:"id"`
+	RealmName  string          `db:"realm_name" json:"realm_name"`
+	ActorType  string          `db:"actor_type" json:"actor_type"`
+	ActorID    string          `db:"actor_id" json:"actor_id"`
+	Action     string          `db:"action" json:"action"`
+	TargetType string          `db:"target_type" json:"target_type"`
+	TargetID   string          `db:"target_id" json:"target_id"`
+	Before     json.RawMessage `db:"before_value" json:"before"`
+	After      json.RawMessage `db:"after_value" json:"after"`
+	CreatedAt  time.Time       `db:"created_at" json:"created_at"`
+}
+
+// AuditRepository defines the interface for transacting with our AuditRecord data source.
+// Audit records can only be appended, never updated
+type AuditRepository interface {
+	Insert(ctx context.Context, record *AuditRecord) error
+	Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]AuditRecord, error)
+}
+
+// AuditFilter represents the criteria that can be used to narrow down a retrieval of AuditRecords.
+// Empty fields are disregarded
+type AuditFilter struct {
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+}
+
+// AuditAgent defines the behaviours for handling AuditRecords
+type AuditAgent struct {
+	ar AuditRepository
+	cl Clock
+}
+
+// Record appends a new AuditRecord to the audit log for the current realm.
+// The provided before and after values may be nil, otherwise they must be able to be marshalled to JSON
+func (a *AuditAgent) Record(ctx context.Context, actor AuditActor, action, targetType, targetID string, before, after interface{}) (AuditRecord, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return AuditRecord{}, InternalError{err}
+	}
+
+	record := AuditRecord{
+		ID:         id,
+		RealmName:  RealmFromContext(ctx).Config.Name,
+		ActorType:  actor.Type,
+		ActorID:    actor.ID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		CreatedAt:  a.cl.Now().Truncate(time.Second),
+	}
+
+	if record.Before, err = marshalAuditValue(before); err != nil {
+		return AuditRecord{}, InternalError{fmt.Errorf("cannot marshal before value: %w", err)}
+	}
+	if record.After, err = marshalAuditValue(after); err != nil {
+		return AuditRecord{}, InternalError{fmt.Errorf("cannot marshal after value: %w", err)}
+	}
+
+	if err := a.ar.Insert(ctx, &record); err != nil {
+		return AuditRecord{}, domainErrorFromRepositoryError(err)
+	}
+
+	return record, nil
+}
+
+// RetrieveRecordsByFilter retrieves the AuditRecords for the current realm that match all criteria within the provided filter,
+// most recent first
+func (a *AuditAgent) RetrieveRecordsByFilter(ctx context.Context, filter AuditFilter) ([]AuditRecord, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleSuperAdmin) {
+		return nil, UnauthorizedError{}
+	}
+
+	criteria := map[string]interface{}{
+		"realm_name": RealmFromContext(ctx).Config.Name,
+	}
+
+	for field, value := range map[string]string{
+		"actor_id":    filter.ActorID,
+		"action":      filter.Action,
+		"target_type": filter.TargetType,
+		"target_id":   filter.TargetID,
+	} {
+		if value != "" {
+			criteria[field] = value
+		}
+	}
+
+	records, err := a.ar.Select(ctx, criteria, false)
+	if err != nil {
+		if errors.As(err, &MissingDBRecordError{}) {
+			return []AuditRecord{}, nil
+		}
+		return nil, domainErrorFromRepositoryError(err)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].CreatedAt.After(records[j].CreatedAt)
+	})
+
+	return records, nil
+}
+
+// NewAuditAgent returns a new AuditAgent using the provided repository
+func NewAuditAgent(ar AuditRepository, cl Clock) (*AuditAgent, error) {
+	switch {
+	case ar == nil:
+		return nil, fmt.Errorf("audit repository: %w", ErrIsNil)
+	case cl == nil:
+		return nil, fmt.Errorf("clock: %w", ErrIsNil)
+	}
+	return &AuditAgent{ar: ar, cl: cl}, nil
+}
+
+// marshalAuditValue returns the JSON representation of the provided value, or nil if no value is provided
+func marshalAuditValue(value interface{}) (json.RawMessage, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return json.Marshal(value)
+}
@@ -0,0 +1,128 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"errors"
+	"prediction-league/service/internal/domain"
+	"testing"
+
+	"gotest.tools/assert/cmp"
+)
+
+func TestNewAuditAgent(t *testing.T) {
+	t.Run("passing invalid parameters must return expected error", func(t *testing.T) {
+		cl := &mockClock{}
+
+		tt := []struct {
+			ar      domain.AuditRepository
+			cl      domain.Clock
+			wantErr error
+		}{
+			{nil, cl, domain.ErrIsNil},
+			{ar, nil, domain.ErrIsNil},
+			{ar, cl, nil},
+		}
+		for idx, tc := range tt {
+			agent, gotErr := domain.NewAuditAgent(tc.ar, tc.cl)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("tc #%d: want error %s (%T), got %s (%T)", idx, tc.wantErr, tc.wantErr, gotErr, gotErr)
+			}
+			if tc.wantErr == nil && agent == nil {
+				t.Fatalf("tc #%d: want non-empty agent, got nil", idx)
+			}
+		}
+	})
+}
+
+func TestAuditAgent_Record(t *testing.T) {
+	t.Cleanup(truncate)
+
+	agent, err := domain.NewAuditAgent(ar, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := testContextDefault(t)
+	defer cancel()
+
+	t.Run("record action with before and after values must succeed", func(t *testing.T) {
+		actor := domain.AdminAuditActorFromContext(domain.SetAdminUserOnContext(ctx, testSuperAdmin))
+
+		gotRecord, err := agent.Record(ctx, actor, domain.AuditActionEntryApproved, domain.AuditTargetTypeEntry, "entry_id", nil, map[string]string{"status": "paid"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantRecord := domain.AuditRecord{
+			ID:         gotRecord.ID,
+			RealmName:  testRealmName,
+			ActorType:  domain.AuditActorTypeAdmin,
+			ActorID:    testSuperAdmin.Username,
+			Action:     domain.AuditActionEntryApproved,
+			TargetType: domain.AuditTargetTypeEntry,
+			TargetID:   "entry_id",
+			After:      json.RawMessage(`{"status":"paid"}`),
+			CreatedAt:  testDate,
+		}
+		cmpDiff(t, "audit record", wantRecord, gotRecord)
+	})
+
+	t.Run("record action with value that cannot be marshalled must fail", func(t *testing.T) {
+		_, err := agent.Record(ctx, domain.AuditActor{}, domain.AuditActionEntryApproved, domain.AuditTargetTypeEntry, "entry_id", nil, make(chan int))
+		if !cmp.ErrorType(err, domain.InternalError{})().Success() {
+			expectedTypeOfGot(t, domain.InternalError{}, err)
+		}
+	})
+}
+
+func TestAuditAgent_RetrieveRecordsByFilter(t *testing.T) {
+	t.Cleanup(truncate)
+
+	agent, err := domain.NewAuditAgent(ar, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := testContextDefault(t)
+	defer cancel()
+
+	entrant := domain.AuditActor{Type: domain.AuditActorTypeEntrant, ID: "entry_id"}
+	for _, action := range []string{domain.AuditActionEntryPredictionCreated, domain.AuditActionEntryApproved} {
+		if _, err := agent.Record(ctx, entrant, action, domain.AuditTargetTypeEntry, "entry_id", nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("retrieve records by action must return matching records", func(t *testing.T) {
+		records, err := agent.RetrieveRecordsByFilter(domain.SetAdminUserOnContext(ctx, testSuperAdmin), domain.AuditFilter{
+			Action: domain.AuditActionEntryApproved,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 1 {
+			expectedGot(t, 1, len(records))
+		}
+	})
+
+	t.Run("retrieve records with no matches must return no records", func(t *testing.T) {
+		records, err := agent.RetrieveRecordsByFilter(domain.SetAdminUserOnContext(ctx, testSuperAdmin), domain.AuditFilter{
+			TargetID: "not_an_entry_id",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 0 {
+			expectedGot(t, 0, len(records))
+		}
+	})
+
+	t.Run("retrieve records as approver must fail", func(t *testing.T) {
+		approver := domain.AdminUser{RealmRoles: map[string]string{testRealmName: domain.AdminRoleApprover}}
+
+		_, err := agent.RetrieveRecordsByFilter(domain.SetAdminUserOnContext(ctx, approver), domain.AuditFilter{})
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+}
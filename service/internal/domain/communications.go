@@ -12,6 +12,10 @@ const (
 	EmailSubjectRoundCompleteFormat = "Match Week %d begins!"
 	EmailSubjectFinalRoundComplete  = "Thanks for playing!"
 	EmailSubjectMagicLogin          = "Your login link"
+	EmailSubjectScoresCorrected     = "Match Week %d scores corrected"
+	EmailSubjectPaymentReminder     = "Don't forget to pay for your entry!"
+	EmailSubjectWaitlistPromotion   = "A place has opened up for you!"
+	EmailSubjectEmailVerification   = "Please verify your email address"
 )
 
 // CommunicationsAgent defines the behaviours for issuing communications
@@ -126,8 +130,57 @@ func (c *CommunicationsAgent) IssueRoundCompleteEmail(ctx context.Context, sep S
 	return nil
 }
 
-// IssueMagicLoginEmail generates a magic login email for the provided Entry and pushes it to the send queue
-func (c *CommunicationsAgent) IssueMagicLoginEmail(ctx context.Context, entry *Entry, tokenId string) error {
+// IssueScoresCorrectedEmail generates a "scores corrected" email for the provided ScoredEntryPrediction, whose score
+// has changed from the provided previous score following a correction to its Standings, and pushes it to the send queue
+func (c *CommunicationsAgent) IssueScoresCorrectedEmail(ctx context.Context, sep ScoredEntryPrediction, previousScore int) error {
+	entry, err := c.getEntryFromScoredEntryPrediction(ctx, sep)
+	if err != nil {
+		return err
+	}
+
+	standings, err := c.getStandingsFromScoredEntryPrediction(ctx, sep)
+	if err != nil {
+		return err
+	}
+
+	realm, err := c.rc.GetByName(entry.RealmName)
+	if err != nil {
+		return NotFoundError{fmt.Errorf("cannot get realm with id '%s': %w", entry.RealmName, err)}
+	}
+
+	season, err := c.sc.GetByID(entry.SeasonID)
+	if err != nil {
+		return NotFoundError{fmt.Errorf("cannot get season with id '%s': %w", entry.SeasonID, err)}
+	}
+
+	d := ScoresCorrectedEmailData{
+		MessagePayload: newMessagePayload(realm, entry.EntrantName, season.Name),
+		RoundNumber:    standings.RoundNumber,
+		PreviousScore:  previousScore,
+		CorrectedScore: sep.Score,
+		LeaderBoardURL: realm.GetFullLeaderboardURL(),
+	}
+	var emailContent bytes.Buffer
+	if err := c.tpl.ExecuteTemplate(&emailContent, "email_txt_scores_corrected", d); err != nil {
+		return err
+	}
+
+	recipient := Identity{
+		Name:    entry.EntrantName,
+		Address: entry.EntrantEmail,
+	}
+	subject := fmt.Sprintf(EmailSubjectScoresCorrected, standings.RoundNumber)
+	email := newEmail(realm, recipient, subject, emailContent.String())
+	if err := c.emlQ.Send(ctx, email); err != nil {
+		return fmt.Errorf("cannot send email to queue: %w", err)
+	}
+
+	return nil
+}
+
+// IssueMagicLoginEmail generates a magic login email for the provided Entry and pushes it to the send queue.
+// The email includes the provided login code, for entrants who cannot follow the link on the device they want to log in on
+func (c *CommunicationsAgent) IssueMagicLoginEmail(ctx context.Context, entry *Entry, tokenId, loginCode string) error {
 	if entry == nil {
 		return InternalError{errors.New("no entry provided")}
 	}
@@ -145,6 +198,8 @@ func (c *CommunicationsAgent) IssueMagicLoginEmail(ctx context.Context, entry *E
 	d := MagicLoginEmail{
 		MessagePayload: newMessagePayload(realm, entry.EntrantName, season.Name),
 		LoginURL:       realm.GetMagicLoginURL(&Token{ID: tokenId}),
+		LoginCode:      loginCode,
+		LoginCodeURL:   realm.GetLoginCodeURL(),
 	}
 	var emailContent bytes.Buffer
 	if err := c.tpl.ExecuteTemplate(&emailContent, "email_txt_magic_login", d); err != nil {
@@ -163,6 +218,102 @@ func (c *CommunicationsAgent) IssueMagicLoginEmail(ctx context.Context, entry *E
 	return nil
 }
 
+// IssueEmailVerificationEmail generates an email for the provided Entry and pushes it to the send queue.
+// The email includes a link that uses the provided token to prove that the entrant owns the Entry's email address
+func (c *CommunicationsAgent) IssueEmailVerificationEmail(ctx context.Context, entry *Entry, tokenId string) error {
+	if entry == nil {
+		return InternalError{errors.New("no entry provided")}
+	}
+
+	realm, err := c.rc.GetByName(entry.RealmName)
+	if err != nil {
+		return NotFoundError{fmt.Errorf("cannot get realm with id '%s': %w", entry.RealmName, err)}
+	}
+
+	season, err := c.sc.GetByID(entry.SeasonID)
+	if err != nil {
+		return NotFoundError{fmt.Errorf("cannot get season with id '%s': %w", entry.SeasonID, err)}
+	}
+
+	d := EmailVerificationEmail{
+		MessagePayload:  newMessagePayload(realm, entry.EntrantName, season.Name),
+		EntrantNickname: entry.EntrantNickname,
+		VerifyURL:       realm.GetEmailVerificationURL(&Token{ID: tokenId}),
+	}
+	var emailContent bytes.Buffer
+	if err := c.tpl.ExecuteTemplate(&emailContent, "email_txt_email_verification", d); err != nil {
+		return err
+	}
+
+	recipient := Identity{
+		Name:    entry.EntrantName,
+		Address: entry.EntrantEmail,
+	}
+	email := newEmail(realm, recipient, EmailSubjectEmailVerification, emailContent.String())
+	if err := c.emlQ.Send(ctx, email); err != nil {
+		return fmt.Errorf("cannot send email to queue: %w", err)
+	}
+
+	return nil
+}
+
+// IssuePaymentReminderEmail generates a payment reminder email for the provided unpaid Entry and pushes it to the send queue.
+// The provided registration token allows the entrant to return to the payment step of the join page
+func (c *CommunicationsAgent) IssuePaymentReminderEmail(ctx context.Context, entry *Entry, regToken *Token) error {
+	return c.issuePaymentURLEmail(ctx, entry, regToken, EmailSubjectPaymentReminder, "email_txt_payment_reminder")
+}
+
+// IssueWaitlistPromotionEmail generates an email for the provided Entry that has been promoted from the waitlist and pushes it
+// to the send queue. The provided registration token allows the entrant to go straight to the payment step of the join page
+func (c *CommunicationsAgent) IssueWaitlistPromotionEmail(ctx context.Context, entry *Entry, regToken *Token) error {
+	return c.issuePaymentURLEmail(ctx, entry, regToken, EmailSubjectWaitlistPromotion, "email_txt_waitlist_promotion")
+}
+
+// issuePaymentURLEmail generates an email for the provided unpaid Entry from the provided template, including a URL
+// that uses the provided registration token to return the entrant to the payment step of the join page
+func (c *CommunicationsAgent) issuePaymentURLEmail(ctx context.Context, entry *Entry, regToken *Token, subject, templateName string) error {
+	if entry == nil {
+		return InternalError{errors.New("no entry provided")}
+	}
+
+	if regToken == nil {
+		return InternalError{errors.New("no registration token provided")}
+	}
+
+	realm, err := c.rc.GetByName(entry.RealmName)
+	if err != nil {
+		return NotFoundError{fmt.Errorf("cannot get realm with id '%s': %w", entry.RealmName, err)}
+	}
+
+	season, err := c.sc.GetByID(entry.SeasonID)
+	if err != nil {
+		return NotFoundError{fmt.Errorf("cannot get season with id '%s': %w", entry.SeasonID, err)}
+	}
+
+	d := PaymentReminderEmailData{
+		MessagePayload:  newMessagePayload(realm, entry.EntrantName, season.Name),
+		EntrantNickname: entry.EntrantNickname,
+		EntryFee:        realm.EntryFee.Label,
+		PaymentURL:      realm.GetPaymentReminderURL(regToken),
+		EntriesClose:    season.EntriesAccepted.Until.Format("Monday 2 January 2006 at 3:04pm"),
+	}
+	var emailContent bytes.Buffer
+	if err := c.tpl.ExecuteTemplate(&emailContent, templateName, d); err != nil {
+		return err
+	}
+
+	recipient := Identity{
+		Name:    entry.EntrantName,
+		Address: entry.EntrantEmail,
+	}
+	email := newEmail(realm, recipient, subject, emailContent.String())
+	if err := c.emlQ.Send(ctx, email); err != nil {
+		return fmt.Errorf("cannot send email to queue: %w", err)
+	}
+
+	return nil
+}
+
 // getEntryFromScoredEntryPrediction retrieves the relationally-affiliated entry from the provided scored entry prediction
 func (c *CommunicationsAgent) getEntryFromScoredEntryPrediction(ctx context.Context, sep ScoredEntryPrediction) (*Entry, error) {
 	// retrieve entry prediction from scored entry prediction
@@ -308,10 +459,37 @@ type RoundCompleteEmailData struct {
 	PredictionsURL string
 }
 
+// ScoresCorrectedEmailData defines the fields relating to the content of a scores corrected email
+type ScoresCorrectedEmailData struct {
+	MessagePayload
+	RoundNumber    int
+	PreviousScore  int
+	CorrectedScore int
+	LeaderBoardURL string
+}
+
 // MagicLoginEmail defines the fields relating to the content of a magic login email
 type MagicLoginEmail struct {
 	MessagePayload
-	LoginURL string
+	LoginURL     string
+	LoginCode    string
+	LoginCodeURL string
+}
+
+// EmailVerificationEmail defines the fields relating to the content of an email verification email
+type EmailVerificationEmail struct {
+	MessagePayload
+	EntrantNickname string
+	VerifyURL       string
+}
+
+// PaymentReminderEmailData defines the fields relating to the content of a payment reminder or waitlist promotion email
+type PaymentReminderEmailData struct {
+	MessagePayload
+	EntrantNickname string
+	EntryFee        string
+	PaymentURL      string
+	EntriesClose    string
 }
 
 // EmailQueue defines behaviours for sending and reading Emails on a queue
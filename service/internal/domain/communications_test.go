@@ -433,6 +433,81 @@ func TestCommunicationsAgent_IssueRoundCompleteEmail(t *testing.T) {
 	})
 }
 
+func TestCommunicationsAgent_IssueScoresCorrectedEmail(t *testing.T) {
+	t.Cleanup(truncate)
+
+	entry := insertEntry(t, generateTestEntry(t,
+		"Harry Redknapp",
+		"MrHarryR",
+		"harry.redknapp@football.net",
+	))
+
+	entryPrediction := insertEntryPrediction(t, generateTestEntryPrediction(t, entry.ID))
+	standings := insertStandings(t, generateTestStandings(t))
+	scoredEntryPrediction := insertScoredEntryPrediction(t, generateTestScoredEntryPrediction(t, entryPrediction.ID, standings.ID))
+
+	t.Run("issue scores corrected email with a valid scored entry prediction must succeed", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		emlQ := domain.NewInMemEmailQueue()
+
+		agent, err := domain.NewCommunicationsAgent(er, epr, sr, emlQ, tpl, sc, tc, rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := agent.IssueScoresCorrectedEmail(ctx, scoredEntryPrediction, 130); err != nil {
+			t.Fatal(err)
+		}
+		if err := emlQ.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		emls := make([]domain.Email, 0)
+		for eml := range emlQ.Read() {
+			emls = append(emls, eml)
+		}
+
+		if len(emls) != 1 {
+			t.Fatalf("want 1 email, got %d", len(emls))
+		}
+
+		wantEmail := readCommsTestEmail(t, "scores_corrected_email_meta.json")
+		gotEmail := emls[0]
+		cmpDiff(t, "email", wantEmail, gotEmail)
+
+		wantPlainContent := readCommsTestDataFile(t, "scores_corrected_txt_content_body.txt")
+		gotPlainContent := []byte(gotEmail.PlainText)
+		cmpDiff(t, "plain content", wantPlainContent, gotPlainContent)
+	})
+
+	t.Run("issue scores corrected email with a scored entry prediction whose standings ID does not exist must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		invalidUUID, err := uuid.NewRandom()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sep := scoredEntryPrediction
+		sep.StandingsID = invalidUUID
+
+		emlQ := domain.NewInMemEmailQueue()
+
+		agent, err := domain.NewCommunicationsAgent(er, epr, sr, emlQ, tpl, sc, tc, rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = agent.IssueScoresCorrectedEmail(ctx, sep, 130)
+		if !cmp.ErrorType(err, domain.NotFoundError{})().Success() {
+			expectedTypeOfGot(t, domain.NotFoundError{}, err)
+		}
+	})
+}
+
 func TestCommunicationsAgent_IssueMagicLoginEmail(t *testing.T) {
 	t.Cleanup(truncate)
 
@@ -448,6 +523,7 @@ func TestCommunicationsAgent_IssueMagicLoginEmail(t *testing.T) {
 		)
 
 		tokenId := "MAGIC12345"
+		loginCode := "123456"
 
 		emlQ := domain.NewInMemEmailQueue()
 
@@ -456,7 +532,7 @@ func TestCommunicationsAgent_IssueMagicLoginEmail(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		if err := agent.IssueMagicLoginEmail(ctx, &entry, tokenId); err != nil {
+		if err := agent.IssueMagicLoginEmail(ctx, &entry, tokenId, loginCode); err != nil {
 			t.Fatal(err)
 		}
 
@@ -493,7 +569,7 @@ func TestCommunicationsAgent_IssueMagicLoginEmail(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		err = agent.IssueMagicLoginEmail(ctx, nil, "dat_string")
+		err = agent.IssueMagicLoginEmail(ctx, nil, "dat_string", "123456")
 		if !cmp.ErrorType(err, domain.InternalError{})().Success() {
 			expectedTypeOfGot(t, domain.InternalError{}, err)
 		}
@@ -519,7 +595,7 @@ func TestCommunicationsAgent_IssueMagicLoginEmail(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		err = agent.IssueMagicLoginEmail(ctx, &entry, "dat_string")
+		err = agent.IssueMagicLoginEmail(ctx, &entry, "dat_string", "123456")
 		if !cmp.ErrorType(err, domain.NotFoundError{})().Success() {
 			expectedTypeOfGot(t, domain.NotFoundError{}, err)
 		}
@@ -545,13 +621,279 @@ func TestCommunicationsAgent_IssueMagicLoginEmail(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		err = agent.IssueMagicLoginEmail(ctx, &entry, "dat_string")
+		err = agent.IssueMagicLoginEmail(ctx, &entry, "dat_string", "123456")
 		if !cmp.ErrorType(err, domain.NotFoundError{})().Success() {
 			expectedTypeOfGot(t, domain.NotFoundError{}, err)
 		}
 	})
 }
 
+func TestCommunicationsAgent_IssueEmailVerificationEmail(t *testing.T) {
+	t.Cleanup(truncate)
+
+	t.Run("issue email verification email with a valid entry must succeed", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		entry := generateTestEntry(
+			t,
+			"Harry Redknapp",
+			"Mr Harry R",
+			"harry.redknapp@football.net",
+		)
+
+		emlQ := domain.NewInMemEmailQueue()
+
+		agent, err := domain.NewCommunicationsAgent(er, epr, sr, emlQ, tpl, sc, tc, rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := agent.IssueEmailVerificationEmail(ctx, &entry, "VERIFY12345"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := emlQ.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		emls := make([]domain.Email, 0)
+		for eml := range emlQ.Read() {
+			emls = append(emls, eml)
+		}
+
+		if len(emls) != 1 {
+			t.Fatalf("want 1 email, got %d", len(emls))
+		}
+
+		wantEmail := readCommsTestEmail(t, "email_verification_email_meta.json")
+		gotEmail := emls[0]
+		cmpDiff(t, "email", wantEmail, gotEmail)
+
+		wantPlainContent := readCommsTestDataFile(t, "email_verification_txt_content_body.txt")
+		gotPlainContent := []byte(gotEmail.PlainText)
+		cmpDiff(t, "plain content", wantPlainContent, gotPlainContent)
+	})
+
+	t.Run("issue email verification email with no entry must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		emlQ := domain.NewInMemEmailQueue()
+
+		agent, err := domain.NewCommunicationsAgent(er, epr, sr, emlQ, tpl, sc, tc, rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = agent.IssueEmailVerificationEmail(ctx, nil, "VERIFY12345")
+		if !cmp.ErrorType(err, domain.InternalError{})().Success() {
+			expectedTypeOfGot(t, domain.InternalError{}, err)
+		}
+	})
+}
+
+func TestCommunicationsAgent_IssuePaymentReminderEmail(t *testing.T) {
+	t.Cleanup(truncate)
+
+	// entries must close at a fixed moment so that the rendered email is predictable
+	season := testSeason
+	season.EntriesAccepted.Until = testDate
+	seasons := domain.SeasonCollection{season.ID: season}
+
+	regToken := generateTestToken("REG12345")
+
+	t.Run("issue payment reminder email with a valid entry must succeed", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		entry := generateTestEntry(
+			t,
+			"Harry Redknapp",
+			"Mr Harry R",
+			"harry.redknapp@football.net",
+		)
+
+		emlQ := domain.NewInMemEmailQueue()
+
+		agent, err := domain.NewCommunicationsAgent(er, epr, sr, emlQ, tpl, seasons, tc, rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := agent.IssuePaymentReminderEmail(ctx, &entry, regToken); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := emlQ.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		emls := make([]domain.Email, 0)
+		for eml := range emlQ.Read() {
+			emls = append(emls, eml)
+		}
+
+		if len(emls) != 1 {
+			t.Fatalf("want 1 email, got %d", len(emls))
+		}
+
+		wantEmail := readCommsTestEmail(t, "payment_reminder_email_meta.json")
+		gotEmail := emls[0]
+		cmpDiff(t, "email", wantEmail, gotEmail)
+
+		wantPlainContent := readCommsTestDataFile(t, "payment_reminder_txt_content_body.txt")
+		gotPlainContent := []byte(gotEmail.PlainText)
+		cmpDiff(t, "plain content", wantPlainContent, gotPlainContent)
+	})
+
+	t.Run("issue payment reminder email with no entry must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		emlQ := domain.NewInMemEmailQueue()
+
+		agent, err := domain.NewCommunicationsAgent(er, epr, sr, emlQ, tpl, seasons, tc, rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = agent.IssuePaymentReminderEmail(ctx, nil, regToken)
+		if !cmp.ErrorType(err, domain.InternalError{})().Success() {
+			expectedTypeOfGot(t, domain.InternalError{}, err)
+		}
+	})
+
+	t.Run("issue payment reminder email with no token must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		entry := generateTestEntry(
+			t,
+			"Harry Redknapp",
+			"Mr Harry R",
+			"harry.redknapp@football.net",
+		)
+
+		emlQ := domain.NewInMemEmailQueue()
+
+		agent, err := domain.NewCommunicationsAgent(er, epr, sr, emlQ, tpl, seasons, tc, rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = agent.IssuePaymentReminderEmail(ctx, &entry, nil)
+		if !cmp.ErrorType(err, domain.InternalError{})().Success() {
+			expectedTypeOfGot(t, domain.InternalError{}, err)
+		}
+	})
+
+	t.Run("issue payment reminder email with an entry whose season does not exist must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		entry := generateTestEntry(
+			t,
+			"Harry Redknapp",
+			"Mr Harry R",
+			"harry.redknapp@football.net",
+		)
+
+		entry.SeasonID = "not_a_valid_season"
+
+		emlQ := domain.NewInMemEmailQueue()
+
+		agent, err := domain.NewCommunicationsAgent(er, epr, sr, emlQ, tpl, seasons, tc, rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = agent.IssuePaymentReminderEmail(ctx, &entry, regToken)
+		if !cmp.ErrorType(err, domain.NotFoundError{})().Success() {
+			expectedTypeOfGot(t, domain.NotFoundError{}, err)
+		}
+	})
+}
+
+func TestCommunicationsAgent_IssueWaitlistPromotionEmail(t *testing.T) {
+	t.Cleanup(truncate)
+
+	// entries must close at a fixed moment so that the rendered email is predictable
+	season := testSeason
+	season.EntriesAccepted.Until = testDate
+	seasons := domain.SeasonCollection{season.ID: season}
+
+	regToken := generateTestToken("REG12345")
+
+	t.Run("issue waitlist promotion email with a valid entry must succeed", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		entry := generateTestEntry(
+			t,
+			"Harry Redknapp",
+			"Mr Harry R",
+			"harry.redknapp@football.net",
+		)
+
+		emlQ := domain.NewInMemEmailQueue()
+
+		agent, err := domain.NewCommunicationsAgent(er, epr, sr, emlQ, tpl, seasons, tc, rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := agent.IssueWaitlistPromotionEmail(ctx, &entry, regToken); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := emlQ.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		emls := make([]domain.Email, 0)
+		for eml := range emlQ.Read() {
+			emls = append(emls, eml)
+		}
+
+		if len(emls) != 1 {
+			t.Fatalf("want 1 email, got %d", len(emls))
+		}
+
+		wantEmail := readCommsTestEmail(t, "waitlist_promotion_email_meta.json")
+		gotEmail := emls[0]
+		cmpDiff(t, "email", wantEmail, gotEmail)
+
+		wantPlainContent := readCommsTestDataFile(t, "waitlist_promotion_txt_content_body.txt")
+		gotPlainContent := []byte(gotEmail.PlainText)
+		cmpDiff(t, "plain content", wantPlainContent, gotPlainContent)
+	})
+
+	t.Run("issue waitlist promotion email with no token must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		entry := generateTestEntry(
+			t,
+			"Harry Redknapp",
+			"Mr Harry R",
+			"harry.redknapp@football.net",
+		)
+
+		emlQ := domain.NewInMemEmailQueue()
+
+		agent, err := domain.NewCommunicationsAgent(er, epr, sr, emlQ, tpl, seasons, tc, rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = agent.IssueWaitlistPromotionEmail(ctx, &entry, nil)
+		if !cmp.ErrorType(err, domain.InternalError{})().Success() {
+			expectedTypeOfGot(t, domain.InternalError{}, err)
+		}
+	})
+}
+
 func TestNewNoopEmailClient(t *testing.T) {
 	t.Run("passing invalid parameters must return expected error", func(t *testing.T) {
 		l := &mockLogger{}
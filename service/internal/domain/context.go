@@ -8,10 +8,11 @@ import (
 type ContextKey string
 
 const (
-	contextKeyTimestamp        ContextKey = "TIMESTAMP"
-	contextKeyGuard            ContextKey = "GUARD"
-	contextKeyRealm            ContextKey = "REALM"
-	contextKeyBasicAuthSuccess ContextKey = "BASIC_AUTH_SUCCESS"
+	contextKeyTimestamp  ContextKey = "TIMESTAMP"
+	contextKeyGuard      ContextKey = "GUARD"
+	contextKeyRealm      ContextKey = "REALM"
+	contextKeyAdminUser  ContextKey = "ADMIN_USER"
+	contextKeySystemRole ContextKey = "SYSTEM_ROLE"
 )
 
 // Guard represents an arbitrary guard that can be used by agent methods
@@ -42,9 +43,6 @@ func NewContext() (context.Context, context.CancelFunc) {
 	ctx = context.WithValue(ctx, contextKeyGuard, &Guard{})
 	ctx = context.WithValue(ctx, contextKeyRealm, &Realm{})
 
-	var basicAuth bool
-	ctx = context.WithValue(ctx, contextKeyBasicAuthSuccess, &basicAuth)
-
 	var time = time.Now()
 	ctx = context.WithValue(ctx, contextKeyTimestamp, &time)
 
@@ -87,19 +85,54 @@ func RealmFromContext(ctx context.Context) *Realm {
 	return r
 }
 
-// SetBasicAuthSuccessfulOnContext sets a successful Basic Auth attempt on the provided context
-func SetBasicAuthSuccessfulOnContext(ctx context.Context) context.Context {
-	return context.WithValue(ctx, contextKeyBasicAuthSuccess, true)
+// contextWithRealm returns a copy of the provided context that carries the provided Realm,
+// for work performed by the system on a realm's behalf outside of a request
+func contextWithRealm(ctx context.Context, realm Realm) context.Context {
+	return context.WithValue(ctx, contextKeyRealm, &realm)
+}
+
+// SetAdminUserOnContext sets the provided authenticated AdminUser on the provided context
+func SetAdminUserOnContext(ctx context.Context, user AdminUser) context.Context {
+	return context.WithValue(ctx, contextKeyAdminUser, user)
 }
 
-// IsBasicAuthSuccessful determines whether a successful Basic Auth attempt exists on the provided context
-func IsBasicAuthSuccessful(ctx context.Context) bool {
-	val := ctx.Value(contextKeyBasicAuthSuccess)
+// AdminUserFromContext retrieves the authenticated AdminUser from the provided context, if one exists
+func AdminUserFromContext(ctx context.Context) (AdminUser, bool) {
+	val := ctx.Value(contextKeyAdminUser)
 
 	switch val.(type) {
-	case bool:
-		return val.(bool)
+	case AdminUser:
+		return val.(AdminUser), true
+	}
+
+	return AdminUser{}, false
+}
+
+// SetSystemAdminRoleOnContext grants the system itself the provided admin role within the context's realm,
+// so that an admin operation can be performed on the system's behalf without an authenticated AdminUser
+func SetSystemAdminRoleOnContext(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, contextKeySystemRole, AdminUser{
+		RealmRoles: map[string]string{RealmFromContext(ctx).Config.Name: role},
+	})
+}
+
+// HasAdminRole determines whether the provided context holds an authenticated AdminUser
+// with at least the provided role within the context's realm
+func HasAdminRole(ctx context.Context, role string) bool {
+	return HasAdminRoleForRealm(ctx, RealmFromContext(ctx).Config.Name, role)
+}
+
+// HasAdminRoleForRealm determines whether the provided context holds an authenticated AdminUser, or a role granted to the system,
+// with at least the provided role within the provided realm
+func HasAdminRoleForRealm(ctx context.Context, realmName, role string) bool {
+	if system, ok := ctx.Value(contextKeySystemRole).(AdminUser); ok && system.HasRole(realmName, role) {
+		return true
+	}
+
+	user, ok := AdminUserFromContext(ctx)
+	if !ok {
+		return false
 	}
 
-	return false
+	return user.HasRole(realmName, role)
 }
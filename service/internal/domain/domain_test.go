@@ -23,15 +23,25 @@ import (
 )
 
 var (
+	aa         *domain.AuditAgent
+	akr        domain.APIKeyRepository
+	ar         domain.AuditRepository
+	aur        domain.AdminUserRepository
 	badDB      *sql.DB
 	db         *sql.DB
 	epr        domain.EntryPredictionRepository
 	er         domain.EntryRepository
+	per        domain.PaymentEventRepository
+	ppr        domain.PrizePayoutRepository
 	rc         domain.RealmCollection
 	realm      domain.Realm
 	sepr       domain.ScoredEntryPredictionRepository
+	sgr        domain.SignupSessionRepository
 	sr         domain.StandingsRepository
+	sqr        domain.QuarantinedStandingsRepository
+	ssr        domain.StandingsSnapshotRepository
 	sc         domain.SeasonCollection
+	scr        domain.StandingsCorrectionRepository
 	tc         domain.TeamCollection
 	testDate   time.Time
 	testSeason domain.Season
@@ -45,6 +55,15 @@ const (
 	testRealmPIN  = "1234"
 )
 
+// testSuperAdmin represents an authenticated AdminUser who is permitted to perform any admin operation
+var testSuperAdmin = domain.AdminUser{
+	Username:   "test_superadmin",
+	RealmRoles: map[string]string{domain.AdminRealmAll: domain.AdminRoleSuperAdmin},
+}
+
+// testTokenHashKey represents the key with which the IDs of test tokens are hashed
+var testTokenHashKey = []byte("test_token_hash_key")
+
 // TestMain provides a testing bootstrap
 func TestMain(m *testing.M) {
 	var err error
@@ -112,11 +131,61 @@ func TestMain(m *testing.M) {
 		log.Fatalf("cannot instantiate new standings repo: %s", err.Error())
 	}
 
+	sqr, err = mysqldb.NewStandingsQuarantineRepo(db)
+	if err != nil {
+		log.Fatalf("cannot instantiate new standings quarantine repo: %s", err.Error())
+	}
+
+	ssr, err = mysqldb.NewStandingsSnapshotRepo(db)
+	if err != nil {
+		log.Fatalf("cannot instantiate new standings snapshot repo: %s", err.Error())
+	}
+
+	scr, err = mysqldb.NewStandingsCorrectionRepo(db)
+	if err != nil {
+		log.Fatalf("cannot instantiate new standings correction repo: %s", err.Error())
+	}
+
+	aur, err = mysqldb.NewAdminUserRepo(db)
+	if err != nil {
+		log.Fatalf("cannot instantiate new admin user repo: %s", err.Error())
+	}
+
+	per, err = mysqldb.NewPaymentEventRepo(db)
+	if err != nil {
+		log.Fatalf("cannot instantiate new payment event repo: %s", err.Error())
+	}
+
+	ppr, err = mysqldb.NewPrizePayoutRepo(db)
+	if err != nil {
+		log.Fatalf("cannot instantiate new prize payout repo: %s", err.Error())
+	}
+
+	ar, err = mysqldb.NewAuditRepo(db)
+	if err != nil {
+		log.Fatalf("cannot instantiate new audit repo: %s", err.Error())
+	}
+
+	aa, err = domain.NewAuditAgent(ar, &domain.RealClock{})
+	if err != nil {
+		log.Fatalf("cannot instantiate new audit agent: %s", err.Error())
+	}
+
 	tr, err = mysqldb.NewTokenRepo(db)
 	if err != nil {
 		log.Fatalf("cannot instantiate new token repo: %s", err.Error())
 	}
 
+	sgr, err = mysqldb.NewSignupSessionRepo(db)
+	if err != nil {
+		log.Fatalf("cannot instantiate new signup session repo: %s", err.Error())
+	}
+
+	akr, err = mysqldb.NewAPIKeyRepo(db)
+	if err != nil {
+		log.Fatalf("cannot instantiate new api key repo: %s", err.Error())
+	}
+
 	// load templates
 	tpl, err = domain.ParseTemplates(projectRootDir + "/service/views")
 	if err != nil {
@@ -166,7 +235,7 @@ func mustLoadTestEnvFromPaths(paths ...string) {
 
 // truncate clears our test tables of all previous data between tests
 func truncate() {
-	for _, tableName := range []string{"mw_result_modifier", "mw_result", "mw_submission", "token", "scored_entry_prediction", "entry_prediction", "standings", "entry"} {
+	for _, tableName := range []string{"mw_result_modifier", "mw_result", "mw_submission", "token", "scored_entry_prediction", "entry_prediction", "standings_quarantine", "standings_snapshot", "standings_correction", "standings", "entry", "admin_user", "audit_record", "payment_event", "prize_payout", "signup_session", "api_key"} {
 		if _, err := db.Exec(fmt.Sprintf("DELETE FROM %s", tableName)); err != nil {
 			log.Fatalf("cannot truncate table '%s': %s", tableName, err.Error())
 		}
@@ -296,6 +365,7 @@ func generateTestEntry(t *testing.T, entrantName, entrantNickname, entrantEmail
 		Status:           domain.EntryStatusPending,
 		PaymentMethod:    &paymentMethod,
 		PaymentRef:       &paymentRef,
+		EmailVerifiedAt:  &testDate,
 		EntryPredictions: nil,
 	}
 }
@@ -403,7 +473,7 @@ func newTestRealm() domain.Realm {
 		},
 		Site: domain.RealmSite{
 			Origin: "http://test_realm.org",
-			Paths:  domain.NewRealmSitePaths(),
+			Paths:  domain.NewRealmSitePaths(""),
 		},
 	}
 }
@@ -17,6 +17,18 @@ const (
 	EntryStatusPending = "pending"
 	// EntryStatusPaid represents an Entry whose status is PAID
 	EntryStatusPaid = "paid"
+	// EntryStatusWithdrawn represents an Entry whose status is WITHDRAWN
+	EntryStatusWithdrawn = "withdrawn"
+	// EntryStatusDisqualified represents an Entry whose status is DISQUALIFIED
+	EntryStatusDisqualified = "disqualified"
+	// EntryStatusRefunded represents an Entry whose status is REFUNDED
+	EntryStatusRefunded = "refunded"
+	// EntryStatusDisputed represents an Entry whose status is DISPUTED
+	EntryStatusDisputed = "disputed"
+	// EntryStatusExpired represents an Entry whose status is EXPIRED
+	EntryStatusExpired = "expired"
+	// EntryStatusWaitlisted represents an Entry whose status is WAITLISTED
+	EntryStatusWaitlisted = "waitlisted"
 
 	// EntryPaymentMethodPayPal represents an Entry that has been paid via PAYPAL
 	EntryPaymentMethodPayPal = "paypal"
@@ -42,6 +54,8 @@ type Entry struct {
 	PaymentRef       *string   `db:"payment_ref"`
 	EntryPredictions []EntryPrediction
 	ApprovedAt       *time.Time `db:"approved_at"`
+	ReminderSentAt   *time.Time `db:"reminder_sent_at"`
+	EmailVerifiedAt  *time.Time `db:"email_verified_at"`
 	CreatedAt        time.Time  `db:"created_at"`
 	UpdatedAt        *time.Time `db:"updated_at"`
 }
@@ -50,6 +64,36 @@ func (e *Entry) IsApproved() bool {
 	return e.ApprovedAt != nil
 }
 
+// IsEmailVerified determines whether the entrant has proven that they own the email address of the Entry
+func (e *Entry) IsEmailVerified() bool {
+	return e.EmailVerifiedAt != nil
+}
+
+// IsExcluded determines whether the Entry has been withdrawn or disqualified from the competition,
+// whether its payment has been refunded or disputed, or whether it expired before being paid for
+func (e *Entry) IsExcluded() bool {
+	switch e.Status {
+	case EntryStatusWithdrawn, EntryStatusDisqualified, EntryStatusRefunded, EntryStatusDisputed, EntryStatusExpired:
+		return true
+	}
+	return false
+}
+
+// holdsPlace determines whether the Entry counts towards the entry cap of its realm
+func (e *Entry) holdsPlace() bool {
+	return !e.IsExcluded() && e.Status != EntryStatusWaitlisted
+}
+
+// EntryFilter represents the criteria that can be used to narrow down a retrieval of Entries.
+// Empty fields are disregarded
+type EntryFilter struct {
+	RealmName     string
+	SeasonID      string
+	Status        string
+	Approved      *bool
+	EmailVerified *bool
+}
+
 // EntryPrediction provides a data type for the prediction that is associated with an Entry
 type EntryPrediction struct {
 	ID        uuid.UUID         `db:"id"`
@@ -68,7 +112,10 @@ func NewEntryPrediction(ids []string) EntryPrediction {
 // EntryRepository defines the interface for transacting with our Entry data source
 type EntryRepository interface {
 	Insert(ctx context.Context, entry *Entry) error
+	InsertWithinCap(ctx context.Context, entry *Entry, entryCap int) error
 	Update(ctx context.Context, entry *Entry) error
+	PromoteWithinCap(ctx context.Context, entry *Entry, entryCap int) error
+	RestoreWithinCap(ctx context.Context, entry *Entry, fromStatus string, entryCap int) error
 	Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]Entry, error)
 	SelectBySeasonIDAndApproved(ctx context.Context, seasonID string, approved bool) ([]Entry, error)
 	ExistsByID(ctx context.Context, id string) error
@@ -88,6 +135,7 @@ type EntryAgent struct {
 	epr EntryPredictionRepository
 	sr  StandingsRepository
 	sc  SeasonCollection
+	aa  *AuditAgent
 	cl  Clock
 }
 
@@ -123,6 +171,8 @@ func (e *EntryAgent) CreateEntry(ctx context.Context, entry Entry, s *Season) (E
 	entry.PaymentRef = nil
 	entry.EntryPredictions = []EntryPrediction{}
 	entry.ApprovedAt = nil
+	entry.ReminderSentAt = nil
+	entry.EmailVerifiedAt = nil
 	entry.CreatedAt = time.Time{}
 	entry.UpdatedAt = nil
 
@@ -163,11 +213,33 @@ func (e *EntryAgent) CreateEntry(ctx context.Context, entry Entry, s *Season) (E
 		}
 	}
 
+	// an expired entry no longer holds on to its nickname
+	existingNicknameEntries = withoutExpiredEntries(existingNicknameEntries)
+
+	// entrant may be trying to sign up again for an entry they have not yet paid for
+	for _, existing := range existingEmailEntries {
+		switch existing.Status {
+		case EntryStatusPending:
+			return Entry{}, ConflictError{ErrEntryAwaitingPayment}
+		case EntryStatusWaitlisted:
+			return Entry{}, ConflictError{errors.New("entry is already on the waitlist")}
+		}
+	}
+
 	if len(existingNicknameEntries) > 0 || len(existingEmailEntries) > 0 {
 		// entry isn't unique!
 		return Entry{}, ConflictError{errors.New("entry already exists")}
 	}
 
+	// a realm with an entry cap only has room for so many entries, after which new entries join its waitlist
+	if realm.Config.EntryCap > 0 {
+		if err := e.er.InsertWithinCap(ctx, &entry, realm.Config.EntryCap); err != nil {
+			return Entry{}, domainErrorFromRepositoryError(err)
+		}
+
+		return entry, nil
+	}
+
 	// write entry to database
 	if err := e.er.Insert(ctx, &entry); err != nil {
 		return Entry{}, domainErrorFromRepositoryError(err)
@@ -365,6 +437,11 @@ func (e *EntryAgent) AddEntryPredictionToEntry(ctx context.Context, entryPredict
 		return Entry{}, domainErrorFromRepositoryError(err)
 	}
 
+	// ensure the entry is still taking part
+	if entry.IsExcluded() {
+		return Entry{}, ConflictError{fmt.Errorf("entry is %s", entry.Status)}
+	}
+
 	// retrieve the entry's Season
 	season, err := e.sc.GetByID(entry.SeasonID)
 	if err != nil {
@@ -503,8 +580,8 @@ func (e *EntryAgent) UpdateEntryPaymentDetails(ctx context.Context, entryID, pay
 
 // ApproveEntryByID provides a shortcut to approving an Entry by its ID
 func (e *EntryAgent) ApproveEntryByID(ctx context.Context, id string) (Entry, error) {
-	// ensure basic auth has been provided and matches admin credentials
-	if !IsBasicAuthSuccessful(ctx) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleApprover) {
 		return Entry{}, UnauthorizedError{}
 	}
 
@@ -545,6 +622,11 @@ func (e *EntryAgent) ApproveEntryByID(ctx context.Context, id string) (Entry, er
 		return Entry{}, ConflictError{errors.New("entry has already been approved")}
 	}
 
+	// an entrant must prove that the email address is theirs before their entry can take part
+	if !entry.IsEmailVerified() {
+		return Entry{}, ConflictError{errors.New("entry can only be approved once its email address has been verified")}
+	}
+
 	ts := e.cl.Now()
 	entry.ApprovedAt = &ts
 
@@ -553,6 +635,475 @@ func (e *EntryAgent) ApproveEntryByID(ctx context.Context, id string) (Entry, er
 		return Entry{}, domainErrorFromRepositoryError(err)
 	}
 
+	// record who approved the entry
+	if _, err := e.aa.Record(
+		ctx,
+		AdminAuditActorFromContext(ctx),
+		AuditActionEntryApproved,
+		AuditTargetTypeEntry,
+		entry.ID.String(),
+		map[string]interface{}{"status": entry.Status, "approved_at": nil},
+		map[string]interface{}{"status": entry.Status, "approved_at": entry.ApprovedAt},
+	); err != nil {
+		return Entry{}, err
+	}
+
+	return entry, nil
+}
+
+// RetrieveEntriesByFilter retrieves the Entries that match all criteria within the provided filter.
+// Entry predictions are not inflated
+func (e *EntryAgent) RetrieveEntriesByFilter(ctx context.Context, filter EntryFilter) ([]Entry, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the realm being filtered on,
+	// or for every realm if no realm filter has been provided
+	realmName := filter.RealmName
+	if realmName == "" {
+		realmName = AdminRealmAll
+	}
+	if !HasAdminRoleForRealm(ctx, realmName, AdminRoleViewer) {
+		return nil, UnauthorizedError{}
+	}
+
+	criteria := make(map[string]interface{})
+
+	if filter.RealmName != "" {
+		criteria["realm_name"] = filter.RealmName
+	}
+	if filter.SeasonID != "" {
+		criteria["season_id"] = filter.SeasonID
+	}
+	if filter.Status != "" {
+		if !isValidEntryStatus(filter.Status) {
+			return nil, ValidationError{
+				Reasons: []string{fmt.Sprintf("%s is not a valid status", filter.Status)},
+			}
+		}
+		criteria["status"] = filter.Status
+	}
+	if filter.Approved != nil {
+		operator := "IS NULL"
+		if *filter.Approved {
+			operator = "IS NOT NULL"
+		}
+		criteria["approved_at"] = DBQueryCondition{
+			Operator: operator,
+		}
+	}
+	if filter.EmailVerified != nil {
+		operator := "IS NULL"
+		if *filter.EmailVerified {
+			operator = "IS NOT NULL"
+		}
+		criteria["email_verified_at"] = DBQueryCondition{
+			Operator: operator,
+		}
+	}
+
+	entries, err := e.er.Select(ctx, criteria, false)
+	if err != nil {
+		if errors.As(err, &MissingDBRecordError{}) {
+			return []Entry{}, nil
+		}
+		return nil, domainErrorFromRepositoryError(err)
+	}
+
+	return entries, nil
+}
+
+// RetrieveEntryWithPredictionHistoryByID retrieves the Entry with the provided ID,
+// inflated with every EntryPrediction it has made in chronological order
+func (e *EntryAgent) RetrieveEntryWithPredictionHistoryByID(ctx context.Context, id string) (Entry, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleViewer) {
+		return Entry{}, UnauthorizedError{}
+	}
+
+	entry, err := e.RetrieveEntryByID(ctx, id)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	sort.SliceStable(entry.EntryPredictions, func(i, j int) bool {
+		return entry.EntryPredictions[i].CreatedAt.Before(entry.EntryPredictions[j].CreatedAt)
+	})
+
+	return entry, nil
+}
+
+// UpdateEntrantDetailsByID updates the nickname and/or email of the Entry with the provided ID.
+// A nil value leaves the existing field unchanged
+func (e *EntryAgent) UpdateEntrantDetailsByID(ctx context.Context, id string, nickname, email *string) (Entry, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleApprover) {
+		return Entry{}, UnauthorizedError{}
+	}
+
+	entry, err := e.retrieveSingleEntryByID(ctx, id)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	criteria := make(map[string]interface{})
+	if nickname != nil {
+		entry.EntrantNickname = strings.Trim(*nickname, " ")
+		criteria["entrant_nickname"] = entry.EntrantNickname
+	}
+	if email != nil {
+		newEmail := strings.Trim(*email, " ")
+		if newEmail != entry.EntrantEmail {
+			// the entrant has not yet proven that the new email address is theirs
+			entry.EmailVerifiedAt = nil
+		}
+		entry.EntrantEmail = newEmail
+		criteria["entrant_email"] = entry.EntrantEmail
+	}
+
+	if len(criteria) == 0 {
+		return Entry{}, ValidationError{Reasons: []string{"Nickname or Email must be provided"}}
+	}
+
+	// check that no other entry within the same season and realm already has either new value,
+	// so that we can return a nice error message rather than relying on the db constraint
+	existing, err := e.er.Select(ctx, criteria, true)
+	if err != nil && !errors.As(err, &MissingDBRecordError{}) {
+		return Entry{}, domainErrorFromRepositoryError(err)
+	}
+	for _, ex := range existing {
+		if ex.ID == entry.ID || ex.SeasonID != entry.SeasonID || ex.RealmName != entry.RealmName {
+			continue
+		}
+		if ex.Status == EntryStatusExpired && ex.EntrantEmail != entry.EntrantEmail {
+			// only the expired entry's nickname matches, which it no longer holds on to
+			continue
+		}
+		return Entry{}, ConflictError{errors.New("entry already exists")}
+	}
+
+	return e.UpdateEntry(ctx, entry)
+}
+
+// ApproveEntriesByIDs approves each of the Entries with the provided IDs.
+// Returns the Entries that were approved successfully, along with the error for each ID that could not be approved
+func (e *EntryAgent) ApproveEntriesByIDs(ctx context.Context, ids []string) ([]Entry, map[string]error, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleApprover) {
+		return nil, nil, UnauthorizedError{}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil, ValidationError{Reasons: []string{"Entry IDs must not be empty"}}
+	}
+
+	approved := make([]Entry, 0)
+	failed := make(map[string]error)
+
+	for _, id := range ids {
+		entry, err := e.ApproveEntryByID(ctx, id)
+		if err != nil {
+			failed[id] = err
+			continue
+		}
+		approved = append(approved, entry)
+	}
+
+	return approved, failed, nil
+}
+
+// WithdrawEntryByID marks the Entry with the provided ID as withdrawn at the entrant's request
+func (e *EntryAgent) WithdrawEntryByID(ctx context.Context, id string) (Entry, error) {
+	return e.excludeEntryByID(ctx, id, EntryStatusWithdrawn)
+}
+
+// DisqualifyEntryByID marks the Entry with the provided ID as disqualified
+func (e *EntryAgent) DisqualifyEntryByID(ctx context.Context, id string) (Entry, error) {
+	return e.excludeEntryByID(ctx, id, EntryStatusDisqualified)
+}
+
+// excludeEntryByID removes the Entry with the provided ID from the competition by setting the provided status.
+// The Entry's approval is revoked, so that it no longer appears on the leaderboard
+func (e *EntryAgent) excludeEntryByID(ctx context.Context, id string, status string) (Entry, error) {
+	// only superadmins can disqualify an entry, but approvers can withdraw one on the entrant's behalf
+	role := AdminRoleApprover
+	if status == EntryStatusDisqualified {
+		role = AdminRoleSuperAdmin
+	}
+
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, role) {
+		return Entry{}, UnauthorizedError{}
+	}
+
+	entry, err := e.retrieveSingleEntryByID(ctx, id)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if entry.IsExcluded() {
+		return Entry{}, ConflictError{fmt.Errorf("entry has already been %s", entry.Status)}
+	}
+
+	entry.Status = status
+	entry.ApprovedAt = nil
+
+	return e.UpdateEntry(ctx, entry)
+}
+
+// ExpireEntryByID marks the unpaid or waitlisted Entry with the provided ID as expired, so that it no longer holds on to its
+// nickname. An Entry can only expire once its Season has stopped accepting entries, unless it is holding a place in a realm
+// with an entry cap and its entrant has already been reminded to pay for it
+func (e *EntryAgent) ExpireEntryByID(ctx context.Context, id string, season Season) (Entry, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleApprover) {
+		return Entry{}, UnauthorizedError{}
+	}
+
+	entry, err := e.retrieveSingleEntryByID(ctx, id)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if entry.SeasonID != season.ID {
+		return Entry{}, ConflictError{errors.New("invalid season")}
+	}
+	if entry.Status != EntryStatusPending && entry.Status != EntryStatusWaitlisted {
+		return Entry{}, ConflictError{fmt.Errorf("only a pending or waitlisted entry can expire: status is %s", entry.Status)}
+	}
+
+	// releasing a capped place early gives it to someone on the waitlist
+	releasesCappedPlace := entry.Status == EntryStatusPending &&
+		RealmFromContext(ctx).Config.EntryCap > 0 &&
+		entry.ReminderSentAt != nil
+	if !season.EntriesAccepted.HasElapsedBy(e.cl.Now()) && !releasesCappedPlace {
+		return Entry{}, ConflictError{errors.New("season is still accepting entries")}
+	}
+
+	previousStatus := entry.Status
+	entry.Status = EntryStatusExpired
+
+	entry, err = e.UpdateEntry(ctx, entry)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if _, err := e.aa.Record(
+		ctx,
+		AdminAuditActorFromContext(ctx),
+		AuditActionEntryExpired,
+		AuditTargetTypeEntry,
+		entry.ID.String(),
+		map[string]interface{}{"status": previousStatus},
+		map[string]interface{}{"status": entry.Status},
+	); err != nil {
+		return Entry{}, err
+	}
+
+	return entry, nil
+}
+
+// PromoteNextWaitlistedEntry gives the longest-waiting Entry on the waitlist of the current realm a place in the provided
+// Season, if the realm's entry cap allows. The promoted Entry becomes pending so that its entrant can pay for it, and is
+// considered to have been reminded to pay. ErrNothingToPromote is returned if there is no place available or nobody waiting for one
+func (e *EntryAgent) PromoteNextWaitlistedEntry(ctx context.Context, season Season) (*Entry, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleApprover) {
+		return nil, UnauthorizedError{}
+	}
+
+	realm := RealmFromContext(ctx)
+
+	if realm.Config.EntryCap <= 0 || season.GetState(e.cl.Now()).EntriesStatus != SeasonStateActive {
+		return nil, ErrNothingToPromote
+	}
+
+	entries, err := e.er.Select(ctx, map[string]interface{}{
+		"realm_name": realm.Config.Name,
+		"season_id":  season.ID,
+	}, false)
+	if err != nil {
+		if errors.As(err, &MissingDBRecordError{}) {
+			// realm has no entries for the season at all
+			return nil, ErrNothingToPromote
+		}
+		return nil, domainErrorFromRepositoryError(err)
+	}
+
+	var next *Entry
+	var placesTaken int
+	for idx := range entries {
+		entry := &entries[idx]
+		switch {
+		case entry.holdsPlace():
+			placesTaken++
+		case entry.Status == EntryStatusWaitlisted && (next == nil || entry.CreatedAt.Before(next.CreatedAt)):
+			next = entry
+		}
+	}
+
+	if next == nil || placesTaken >= realm.Config.EntryCap {
+		return nil, ErrNothingToPromote
+	}
+
+	// the email that notifies the entrant of their promotion also reminds them to pay
+	now := e.cl.Now().Truncate(time.Second)
+	promoted := *next
+	promoted.Status = EntryStatusPending
+	promoted.ReminderSentAt = &now
+
+	// the place is only taken if it is still available by the time the entry is written, since another instance
+	// may have filled it or promoted the same entry in the meantime
+	if err := e.er.PromoteWithinCap(ctx, &promoted, realm.Config.EntryCap); err != nil {
+		if errors.Is(err, ErrEntryCapReached) || errors.Is(err, ErrEntryNotWaitlisted) {
+			return nil, ErrNothingToPromote
+		}
+		return nil, domainErrorFromRepositoryError(err)
+	}
+
+	if _, err := e.aa.Record(
+		ctx,
+		AdminAuditActorFromContext(ctx),
+		AuditActionEntryPromoted,
+		AuditTargetTypeEntry,
+		promoted.ID.String(),
+		map[string]interface{}{"status": EntryStatusWaitlisted},
+		map[string]interface{}{"status": promoted.Status},
+	); err != nil {
+		return nil, err
+	}
+
+	return &promoted, nil
+}
+
+// MarkReminderSentByID records that the entrant of the Entry with the provided ID has been reminded to pay,
+// so that they are not reminded again
+func (e *EntryAgent) MarkReminderSentByID(ctx context.Context, id string) (Entry, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleApprover) {
+		return Entry{}, UnauthorizedError{}
+	}
+
+	entry, err := e.retrieveSingleEntryByID(ctx, id)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if entry.ReminderSentAt != nil {
+		return Entry{}, ConflictError{errors.New("entrant has already been reminded")}
+	}
+
+	now := e.cl.Now().Truncate(time.Second)
+	entry.ReminderSentAt = &now
+
+	return e.UpdateEntry(ctx, entry)
+}
+
+// VerifyEntryEmailByID records that the entrant of the Entry with the provided ID has proven that they own its email address,
+// which must only be done once they have redeemed a verification Token that was sent to it.
+// See PaymentAgent.VerifyEntryEmailByID for also approving an Entry whose payment has already been verified
+func (e *EntryAgent) VerifyEntryEmailByID(ctx context.Context, id string) (Entry, error) {
+	entry, err := e.retrieveSingleEntryByID(ctx, id)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if entry.IsEmailVerified() {
+		return Entry{}, ConflictError{errors.New("email address has already been verified")}
+	}
+
+	now := e.cl.Now().Truncate(time.Second)
+	entry.EmailVerifiedAt = &now
+
+	return e.UpdateEntry(ctx, entry)
+}
+
+// UpdateEntryPaymentStatusByID changes the status of the paid Entry with the provided ID to reflect a change to its payment,
+// such as a refund or dispute raised with the payment provider. An Entry whose payment is refunded or disputed loses its approval
+// and its place, which it only regains when restored as paid if the entry cap of its realm has not been reached in the meantime
+func (e *EntryAgent) UpdateEntryPaymentStatusByID(ctx context.Context, id, status string) (Entry, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleApprover) {
+		return Entry{}, UnauthorizedError{}
+	}
+
+	if !isPaymentEntryStatus(status) {
+		return Entry{}, ValidationError{Reasons: []string{fmt.Sprintf("%s is not a valid payment status", status)}}
+	}
+
+	entry, err := e.retrieveSingleEntryByID(ctx, id)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	// check Entry status
+	if !isPaymentEntryStatus(entry.Status) {
+		return Entry{}, ConflictError{fmt.Errorf("payment status cannot be changed while entry status is %s", entry.Status)}
+	}
+	if entry.Status == status {
+		return Entry{}, ConflictError{fmt.Errorf("entry status is already %s", status)}
+	}
+
+	before := map[string]interface{}{"status": entry.Status, "approved_at": entry.ApprovedAt}
+	fromStatus := entry.Status
+	regainsPlace := status == EntryStatusPaid && !entry.holdsPlace()
+
+	entry.Status = status
+	if status != EntryStatusPaid {
+		entry.ApprovedAt = nil
+	}
+
+	// an entry that gave up its place in a realm with an entry cap can only have it back if it has not been filled since
+	realm := RealmFromContext(ctx)
+	if regainsPlace && realm.Config.EntryCap > 0 {
+		if err := e.er.RestoreWithinCap(ctx, &entry, fromStatus, realm.Config.EntryCap); err != nil {
+			if errors.Is(err, ErrEntryCapReached) {
+				return Entry{}, ConflictError{fmt.Errorf("cannot restore entry: %w", err)}
+			}
+			return Entry{}, domainErrorFromRepositoryError(err)
+		}
+	} else {
+		entry, err = e.UpdateEntry(ctx, entry)
+		if err != nil {
+			return Entry{}, err
+		}
+	}
+
+	// record the change of payment status
+	if _, err := e.aa.Record(
+		ctx,
+		AdminAuditActorFromContext(ctx),
+		AuditActionEntryPaymentStatusChanged,
+		AuditTargetTypeEntry,
+		entry.ID.String(),
+		before,
+		map[string]interface{}{"status": entry.Status, "approved_at": entry.ApprovedAt},
+	); err != nil {
+		return Entry{}, err
+	}
+
+	return entry, nil
+}
+
+// retrieveSingleEntryByID retrieves the Entry with the provided ID, without inflating its entry predictions
+func (e *EntryAgent) retrieveSingleEntryByID(ctx context.Context, id string) (Entry, error) {
+	entries, err := e.er.Select(ctx, map[string]interface{}{
+		"id": id,
+	}, false)
+	if err != nil {
+		return Entry{}, domainErrorFromRepositoryError(err)
+	}
+
+	if len(entries) != 1 {
+		return Entry{}, InternalError{fmt.Errorf("entries count other than 1: %d", len(entries))}
+	}
+
+	entry := entries[0]
+
+	realm := RealmFromContext(ctx)
+
+	// ensure that Entry realm matches current realm
+	if realm.Config.Name != entry.RealmName {
+		return Entry{}, ConflictError{errors.New("invalid realm")}
+	}
+
 	return entry, nil
 }
 
@@ -567,6 +1118,18 @@ func (e *EntryAgent) RetrieveEntryPredictionByTimestamp(ctx context.Context, ent
 	return entryPrediction, nil
 }
 
+// RetrieveEntryPredictionByID returns the entry prediction that matches the provided id
+func (e *EntryAgent) RetrieveEntryPredictionByID(ctx context.Context, id string) (EntryPrediction, error) {
+	entryPredictions, err := e.epr.Select(ctx, map[string]interface{}{
+		"id": id,
+	}, false)
+	if err != nil {
+		return EntryPrediction{}, domainErrorFromRepositoryError(err)
+	}
+
+	return entryPredictions[0], nil
+}
+
 // RetrieveEntryPredictionsForActiveSeasonByTimestamp retrieves all entry predictions active at the provided timestamp
 // for the provided active season
 func (e *EntryAgent) RetrieveEntryPredictionsForActiveSeasonByTimestamp(
@@ -588,6 +1151,11 @@ func (e *EntryAgent) RetrieveEntryPredictionsForActiveSeasonByTimestamp(
 	// get the entry prediction valid at the provided timestamp for each of the entries we've just retrieved
 	var currentEntryPredictions []EntryPrediction
 	for _, entry := range seasonEntries {
+		if entry.IsExcluded() {
+			// entry is no longer taking part, so shouldn't be scored
+			continue
+		}
+
 		es, err := getEntryPredictionValidAtTimestamp(entry.EntryPredictions, ts)
 		if err != nil {
 			// error indicates that no prediction has been found, so just ignore this entry and continue to the next
@@ -693,8 +1261,8 @@ func (e *EntryAgent) SeedEntries(ctx context.Context, entries []Entry) error {
 	return nil
 }
 
-// NewEntryAgent returns a new EntryAgent using the provided repositories
-func NewEntryAgent(er EntryRepository, epr EntryPredictionRepository, sr StandingsRepository, sc SeasonCollection, cl Clock) (*EntryAgent, error) {
+// NewEntryAgent returns a new EntryAgent using the provided repositories and audit agent
+func NewEntryAgent(er EntryRepository, epr EntryPredictionRepository, sr StandingsRepository, sc SeasonCollection, aa *AuditAgent, cl Clock) (*EntryAgent, error) {
 	switch {
 	case er == nil:
 		return nil, fmt.Errorf("entry repository: %w", ErrIsNil)
@@ -704,11 +1272,13 @@ func NewEntryAgent(er EntryRepository, epr EntryPredictionRepository, sr Standin
 		return nil, fmt.Errorf("standings repository: %w", ErrIsNil)
 	case sc == nil:
 		return nil, fmt.Errorf("season collection: %w", ErrIsNil)
+	case aa == nil:
+		return nil, fmt.Errorf("audit agent: %w", ErrIsNil)
 	case cl == nil:
 		return nil, fmt.Errorf("clock: %w", ErrIsNil)
 	}
 
-	return &EntryAgent{er, epr, sr, sc, cl}, nil
+	return &EntryAgent{er: er, epr: epr, sr: sr, sc: sc, aa: aa, cl: cl}, nil
 }
 
 // sanitiseEntry sanitises and validates an Entry
@@ -802,9 +1372,30 @@ func isValidEmail(email string) bool {
 	return true
 }
 
+// isPaymentEntryStatus determines whether the provided status can only be held by an Entry whose payment has been taken
+func isPaymentEntryStatus(status string) bool {
+	switch status {
+	case EntryStatusPaid, EntryStatusRefunded, EntryStatusDisputed:
+		return true
+	}
+
+	return false
+}
+
+// withoutExpiredEntries returns the provided Entries, less any that have expired
+func withoutExpiredEntries(entries []Entry) []Entry {
+	filtered := make([]Entry, 0)
+	for _, entry := range entries {
+		if entry.Status != EntryStatusExpired {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
 func isValidEntryStatus(status string) bool {
 	switch status {
-	case EntryStatusPending, EntryStatusPaid:
+	case EntryStatusPending, EntryStatusPaid, EntryStatusWithdrawn, EntryStatusDisqualified, EntryStatusRefunded, EntryStatusDisputed, EntryStatusExpired, EntryStatusWaitlisted:
 		return true
 	}
 
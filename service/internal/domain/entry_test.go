@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"prediction-league/service/internal/domain"
 	"sort"
+	"sync"
 	"testing"
 	"time"
 
@@ -23,19 +24,21 @@ func TestNewEntryAgent(t *testing.T) {
 			epr     domain.EntryPredictionRepository
 			sr      domain.StandingsRepository
 			sc      domain.SeasonCollection
+			aa      *domain.AuditAgent
 			cl      domain.Clock
 			wantErr error
 		}{
-			{nil, epr, sr, sc, cl, domain.ErrIsNil},
-			{er, nil, sr, sc, cl, domain.ErrIsNil},
-			{er, epr, nil, sc, cl, domain.ErrIsNil},
-			{er, epr, sr, nil, cl, domain.ErrIsNil},
-			{er, epr, sr, sc, nil, domain.ErrIsNil},
-			{er, epr, sr, sc, cl, nil},
+			{nil, epr, sr, sc, aa, cl, domain.ErrIsNil},
+			{er, nil, sr, sc, aa, cl, domain.ErrIsNil},
+			{er, epr, nil, sc, aa, cl, domain.ErrIsNil},
+			{er, epr, sr, nil, aa, cl, domain.ErrIsNil},
+			{er, epr, sr, sc, nil, cl, domain.ErrIsNil},
+			{er, epr, sr, sc, aa, nil, domain.ErrIsNil},
+			{er, epr, sr, sc, aa, cl, nil},
 		}
 
 		for idx, tc := range tt {
-			agent, gotErr := domain.NewEntryAgent(tc.er, tc.epr, tc.sr, tc.sc, tc.cl)
+			agent, gotErr := domain.NewEntryAgent(tc.er, tc.epr, tc.sr, tc.sc, tc.aa, tc.cl)
 			if !errors.Is(gotErr, tc.wantErr) {
 				t.Fatalf("tc #%d: want error %s (%T), got %s (%T)", idx, tc.wantErr, tc.wantErr, gotErr, gotErr)
 			}
@@ -49,7 +52,7 @@ func TestNewEntryAgent(t *testing.T) {
 func TestEntryAgent_CreateEntry(t *testing.T) {
 	t.Cleanup(truncate)
 
-	agent, err := domain.NewEntryAgent(er, epr, sr, sc, &mockClock{t: testDate})
+	agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{t: testDate})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -90,9 +93,10 @@ func TestEntryAgent_CreateEntry(t *testing.T) {
 		EntryPredictions: []domain.EntryPrediction{
 			domain.NewEntryPrediction([]string{"entry_team_id_1", "entry_team_id_2"}),
 		},
-		ApprovedAt: &testDate,
-		CreatedAt:  time.Time{},
-		UpdatedAt:  &testDate,
+		ApprovedAt:      &testDate,
+		EmailVerifiedAt: &testDate,
+		CreatedAt:       time.Time{},
+		UpdatedAt:       &testDate,
 	}
 
 	t.Run("create a valid entry with a valid guard value must succeed", func(t *testing.T) {
@@ -145,6 +149,9 @@ func TestEntryAgent_CreateEntry(t *testing.T) {
 		if createdEntry.ApprovedAt != nil {
 			expectedEmpty(t, "Entry.ApprovedAt", *createdEntry.ApprovedAt)
 		}
+		if createdEntry.EmailVerifiedAt != nil {
+			expectedEmpty(t, "Entry.EmailVerifiedAt", *createdEntry.EmailVerifiedAt)
+		}
 		if createdEntry.CreatedAt.Equal(time.Time{}) {
 			expectedNonEmpty(t, "Entry.CreatedAt")
 		}
@@ -157,6 +164,11 @@ func TestEntryAgent_CreateEntry(t *testing.T) {
 		if !cmp.ErrorType(err, domain.ConflictError{})().Success() {
 			expectedTypeOfGot(t, domain.ConflictError{}, err)
 		}
+
+		// existing entry has not been paid for, so the entrant must be told to complete it instead
+		if !errors.Is(err, domain.ErrEntryAwaitingPayment) {
+			expectedGot(t, domain.ErrEntryAwaitingPayment, err)
+		}
 	})
 
 	t.Run("create an entry with a nil season pointer must fail", func(t *testing.T) {
@@ -331,7 +343,7 @@ func TestEntryAgent_AddEntryPredictionToEntry(t *testing.T) {
 		// predictions are accepted
 		ts := season.PredictionsAccepted.From.Add(time.Nanosecond)
 
-		agent, err := domain.NewEntryAgent(er, epr, sr, seasonColl, &mockClock{t: ts})
+		agent, err := domain.NewEntryAgent(er, epr, sr, seasonColl, aa, &mockClock{t: ts})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -367,7 +379,7 @@ func TestEntryAgent_AddEntryPredictionToEntry(t *testing.T) {
 		// predictions are accepted
 		ts := season.PredictionsAccepted.From.Add(time.Nanosecond)
 
-		agent, err := domain.NewEntryAgent(er, epr, sr, seasonColl, &mockClock{t: ts})
+		agent, err := domain.NewEntryAgent(er, epr, sr, seasonColl, aa, &mockClock{t: ts})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -389,7 +401,7 @@ func TestEntryAgent_AddEntryPredictionToEntry(t *testing.T) {
 		// predictions are accepted
 		ts := season.PredictionsAccepted.From.Add(time.Nanosecond)
 
-		agent, err := domain.NewEntryAgent(er, epr, sr, seasonColl, &mockClock{t: ts})
+		agent, err := domain.NewEntryAgent(er, epr, sr, seasonColl, aa, &mockClock{t: ts})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -417,7 +429,7 @@ func TestEntryAgent_AddEntryPredictionToEntry(t *testing.T) {
 		// predictions are accepted
 		ts := season.PredictionsAccepted.From.Add(time.Nanosecond)
 
-		agent, err := domain.NewEntryAgent(er, epr, sr, seasonColl, &mockClock{t: ts})
+		agent, err := domain.NewEntryAgent(er, epr, sr, seasonColl, aa, &mockClock{t: ts})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -440,7 +452,7 @@ func TestEntryAgent_AddEntryPredictionToEntry(t *testing.T) {
 		// predictions are NOT accepted
 		ts := season.PredictionsAccepted.From.Add(-time.Nanosecond)
 
-		agent, err := domain.NewEntryAgent(er, epr, sr, seasonColl, &mockClock{t: ts})
+		agent, err := domain.NewEntryAgent(er, epr, sr, seasonColl, aa, &mockClock{t: ts})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -460,7 +472,7 @@ func TestEntryAgent_AddEntryPredictionToEntry(t *testing.T) {
 		// predictions are accepted
 		ts := season.PredictionsAccepted.From.Add(time.Nanosecond)
 
-		agent, err := domain.NewEntryAgent(er, epr, sr, seasonColl, &mockClock{t: ts})
+		agent, err := domain.NewEntryAgent(er, epr, sr, seasonColl, aa, &mockClock{t: ts})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -490,7 +502,7 @@ func TestEntryAgent_AddEntryPredictionToEntry(t *testing.T) {
 		// predictions are accepted
 		ts := season.PredictionsAccepted.From.Add(time.Nanosecond)
 
-		agent, err := domain.NewEntryAgent(er, epr, sr, seasonColl, &mockClock{t: ts})
+		agent, err := domain.NewEntryAgent(er, epr, sr, seasonColl, aa, &mockClock{t: ts})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -528,7 +540,7 @@ func TestEntryAgent_AddEntryPredictionToEntry(t *testing.T) {
 		// predictions are accepted
 		ts := season.PredictionsAccepted.From.Add(time.Nanosecond)
 
-		agent, err := domain.NewEntryAgent(er, epr, sr, seasonColl, &mockClock{t: ts})
+		agent, err := domain.NewEntryAgent(er, epr, sr, seasonColl, aa, &mockClock{t: ts})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -576,7 +588,7 @@ func TestEntryAgent_RetrieveEntryByID(t *testing.T) {
 		entry.EntryPredictions = append(entry.EntryPredictions, insertEntryPrediction(t, generateTestEntryPrediction(t, entry.ID)))
 	}
 
-	agent, err := domain.NewEntryAgent(er, epr, sr, sc, &mockClock{})
+	agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -664,7 +676,7 @@ func TestEntryAgent_RetrieveEntryByEntrantEmail(t *testing.T) {
 		entry.EntryPredictions = append(entry.EntryPredictions, insertEntryPrediction(t, generateTestEntryPrediction(t, entry.ID)))
 	}
 
-	agent, err := domain.NewEntryAgent(er, epr, sr, sc, &mockClock{})
+	agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -762,7 +774,7 @@ func TestEntryAgent_RetrieveEntryByEntrantNickname(t *testing.T) {
 		entry.EntryPredictions = append(entry.EntryPredictions, insertEntryPrediction(t, generateTestEntryPrediction(t, entry.ID)))
 	}
 
-	agent, err := domain.NewEntryAgent(er, epr, sr, sc, &mockClock{})
+	agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -883,7 +895,7 @@ func TestEntryAgent_RetrieveEntriesBySeasonID(t *testing.T) {
 		entries = append(entries, insertEntry(t, entry))
 	}
 
-	agent, err := domain.NewEntryAgent(er, epr, sr, sc, &mockClock{})
+	agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -954,7 +966,7 @@ func TestEntryAgent_UpdateEntry(t *testing.T) {
 		"harry.redknapp@football.net",
 	))
 
-	agent, err := domain.NewEntryAgent(er, epr, sr, sc, &mockClock{})
+	agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1143,7 +1155,7 @@ func TestEntryAgent_UpdateEntryPaymentDetails(t *testing.T) {
 		"harry.redknapp@football.net",
 	))
 
-	agent, err := domain.NewEntryAgent(er, epr, sr, sc, &mockClock{})
+	agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1282,14 +1294,14 @@ func TestEntryAgent_ApproveEntryByID(t *testing.T) {
 	entryWithPaidStatus.Status = domain.EntryStatusPaid
 	entryWithPaidStatus = insertEntry(t, entryWithPaidStatus)
 
-	agent, err := domain.NewEntryAgent(er, epr, sr, sc, &mockClock{t: testDate})
+	agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{t: testDate})
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	t.Run("approve existent entry id with valid credentials must succeed", func(t *testing.T) {
 		ctx, cancel := testContextDefault(t)
-		ctx = domain.SetBasicAuthSuccessfulOnContext(ctx)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
 		defer cancel()
 
 		// attempt to approve entry with paid status
@@ -1303,6 +1315,18 @@ func TestEntryAgent_ApproveEntryByID(t *testing.T) {
 		if approvedEntry.ApprovedAt == nil {
 			expectedNonEmpty(t, "Entry.ApprovedAt")
 		}
+
+		// approval must have been audited
+		records, err := ar.Select(ctx, map[string]interface{}{
+			"action":    domain.AuditActionEntryApproved,
+			"target_id": entryWithPaidStatus.ID.String(),
+		}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if records[0].ActorID != testSuperAdmin.Username {
+			expectedGot(t, testSuperAdmin.Username, records[0].ActorID)
+		}
 	})
 
 	t.Run("approve existent entry with invalid credentials must fail", func(t *testing.T) {
@@ -1318,7 +1342,7 @@ func TestEntryAgent_ApproveEntryByID(t *testing.T) {
 
 	t.Run("approve non-existent entry with valid credentials must fail", func(t *testing.T) {
 		ctx, cancel := testContextDefault(t)
-		ctx = domain.SetBasicAuthSuccessfulOnContext(ctx)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
 		defer cancel()
 
 		_, err := agent.ApproveEntryByID(ctx, "non_existent_id")
@@ -1329,7 +1353,7 @@ func TestEntryAgent_ApproveEntryByID(t *testing.T) {
 
 	t.Run("approve existent entry with invalid realm must fail", func(t *testing.T) {
 		ctx, cancel := testContextDefault(t)
-		ctx = domain.SetBasicAuthSuccessfulOnContext(ctx)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
 		defer cancel()
 
 		realm := domain.RealmFromContext(ctx)
@@ -1343,7 +1367,7 @@ func TestEntryAgent_ApproveEntryByID(t *testing.T) {
 
 	t.Run("approve existent entry with pending status must fail", func(t *testing.T) {
 		ctx, cancel := testContextDefault(t)
-		ctx = domain.SetBasicAuthSuccessfulOnContext(ctx)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
 		defer cancel()
 
 		// initial entry object should still have default "pending" status so just attempt to approve this
@@ -1355,7 +1379,7 @@ func TestEntryAgent_ApproveEntryByID(t *testing.T) {
 
 	t.Run("approve existent entry that has already been approved must fail", func(t *testing.T) {
 		ctx, cancel := testContextDefault(t)
-		ctx = domain.SetBasicAuthSuccessfulOnContext(ctx)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
 		defer cancel()
 
 		// just try to approve the same entry again
@@ -1364,6 +1388,615 @@ func TestEntryAgent_ApproveEntryByID(t *testing.T) {
 			expectedTypeOfGot(t, domain.ConflictError{}, err)
 		}
 	})
+
+	t.Run("approve existent entry whose email address has not been verified must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		unverifiedEntry := generateTestEntry(t,
+			"Frank Lampard",
+			"FrankieL",
+			"frank.lampard@football.net",
+		)
+		unverifiedEntry.Status = domain.EntryStatusPaid
+		unverifiedEntry.EmailVerifiedAt = nil
+		unverifiedEntry = insertEntry(t, unverifiedEntry)
+
+		_, err := agent.ApproveEntryByID(ctx, unverifiedEntry.ID.String())
+		if !cmp.ErrorType(err, domain.ConflictError{})().Success() {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+	})
+}
+
+func TestEntryAgent_VerifyEntryEmailByID(t *testing.T) {
+	t.Cleanup(truncate)
+
+	agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// generateUnverifiedEntry inserts an Entry whose email address has not been verified, with the provided payment details
+	generateUnverifiedEntry := func(t *testing.T, nickname, email, status, paymentMethod string) domain.Entry {
+		t.Helper()
+
+		entry := generateTestEntry(t, "Harry Redknapp", nickname, email)
+		entry.Status = status
+		entry.PaymentMethod = &paymentMethod
+		entry.EmailVerifiedAt = nil
+
+		return insertEntry(t, entry)
+	}
+
+	t.Run("verify email of unverified entry must succeed", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		entry := generateUnverifiedEntry(t, "MrHarryR", "harry.redknapp@football.net", domain.EntryStatusPending, domain.EntryPaymentMethodOther)
+
+		verifiedEntry, err := agent.VerifyEntryEmailByID(ctx, entry.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !verifiedEntry.IsEmailVerified() {
+			expectedGot(t, "email verified true", "email verified false")
+		}
+		if !verifiedEntry.EmailVerifiedAt.Equal(testDate) {
+			expectedGot(t, testDate, *verifiedEntry.EmailVerifiedAt)
+		}
+		if verifiedEntry.IsApproved() {
+			expectedGot(t, "approved entry false", "approved entry true")
+		}
+
+		// verifying a second time must fail
+		_, err = agent.VerifyEntryEmailByID(ctx, entry.ID.String())
+		if !cmp.ErrorType(err, domain.ConflictError{})().Success() {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+	})
+
+	t.Run("verify email of entry already paid by other means must not approve entry", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		entry := generateUnverifiedEntry(t, "FrankieL", "frank.lampard@football.net", domain.EntryStatusPaid, domain.EntryPaymentMethodOther)
+
+		verifiedEntry, err := agent.VerifyEntryEmailByID(ctx, entry.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if verifiedEntry.IsApproved() {
+			expectedGot(t, "approved entry false", "approved entry true")
+		}
+	})
+
+	t.Run("verify email of non-existent entry must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		_, err := agent.VerifyEntryEmailByID(ctx, "non_existent_id")
+		if !cmp.ErrorType(err, domain.NotFoundError{})().Success() {
+			expectedTypeOfGot(t, domain.NotFoundError{}, err)
+		}
+	})
+}
+
+func TestEntryAgent_RetrieveEntriesByFilter(t *testing.T) {
+	t.Cleanup(truncate)
+
+	pendingEntry := insertEntry(t, generateTestEntry(t,
+		"Harry Redknapp",
+		"MrHarryR",
+		"harry.redknapp@football.net",
+	))
+
+	approvedEntry := generateTestEntry(t,
+		"Jamie Redknapp",
+		"MrJamieR",
+		"jamie.redknapp@football.net",
+	)
+	approvedEntry.Status = domain.EntryStatusPaid
+	approvedEntry.ApprovedAt = &testDate
+	approvedEntry = insertEntry(t, approvedEntry)
+
+	unverifiedEntry := generateTestEntry(t,
+		"Frank Lampard",
+		"FrankieL",
+		"frank.lampard@football.net",
+	)
+	unverifiedEntry.EmailVerifiedAt = nil
+	unverifiedEntry = insertEntry(t, unverifiedEntry)
+
+	agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	approved := true
+	emailVerified := false
+
+	tt := []struct {
+		name    string
+		filter  domain.EntryFilter
+		wantIDs []string
+	}{
+		{
+			name:    "empty filter must retrieve all entries",
+			filter:  domain.EntryFilter{},
+			wantIDs: []string{pendingEntry.ID.String(), approvedEntry.ID.String(), unverifiedEntry.ID.String()},
+		},
+		{
+			name:    "filter by status must retrieve matching entries",
+			filter:  domain.EntryFilter{Status: domain.EntryStatusPending},
+			wantIDs: []string{pendingEntry.ID.String(), unverifiedEntry.ID.String()},
+		},
+		{
+			name:    "filter by approval must retrieve matching entries",
+			filter:  domain.EntryFilter{SeasonID: testSeason.ID, Approved: &approved},
+			wantIDs: []string{approvedEntry.ID.String()},
+		},
+		{
+			name:    "filter by email verification must retrieve matching entries",
+			filter:  domain.EntryFilter{EmailVerified: &emailVerified},
+			wantIDs: []string{unverifiedEntry.ID.String()},
+		},
+		{
+			name:    "filter with no matches must retrieve no entries",
+			filter:  domain.EntryFilter{RealmName: "DIFFERENT_REALM"},
+			wantIDs: []string{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := testContextDefault(t)
+			ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+			defer cancel()
+
+			entries, err := agent.RetrieveEntriesByFilter(ctx, tc.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gotIDs := make([]string, 0)
+			for _, entry := range entries {
+				gotIDs = append(gotIDs, entry.ID.String())
+			}
+			sort.Strings(gotIDs)
+			sort.Strings(tc.wantIDs)
+
+			cmpDiff(t, "entry ids", tc.wantIDs, gotIDs)
+		})
+	}
+
+	t.Run("filter by invalid status must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		_, err := agent.RetrieveEntriesByFilter(ctx, domain.EntryFilter{Status: "not_a_status"})
+		if !cmp.ErrorType(err, domain.ValidationError{})().Success() {
+			expectedTypeOfGot(t, domain.ValidationError{}, err)
+		}
+	})
+
+	t.Run("retrieve entries with invalid credentials must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		_, err := agent.RetrieveEntriesByFilter(ctx, domain.EntryFilter{})
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+}
+
+func TestEntryAgent_UpdateEntrantDetailsByID(t *testing.T) {
+	t.Cleanup(truncate)
+
+	entry := insertEntry(t, generateTestEntry(t,
+		"Harry Redknapp",
+		"MrHarryR",
+		"harry.redknapp@football.net",
+	))
+
+	otherEntry := insertEntry(t, generateTestEntry(t,
+		"Jamie Redknapp",
+		"MrJamieR",
+		"jamie.redknapp@football.net",
+	))
+
+	agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	strPtr := func(s string) *string { return &s }
+
+	t.Run("update nickname and email of existent entry must succeed", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		updated, err := agent.UpdateEntrantDetailsByID(ctx, entry.ID.String(), strPtr("HarryR"), strPtr("harry@football.net"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.EntrantNickname != "HarryR" {
+			expectedGot(t, "HarryR", updated.EntrantNickname)
+		}
+		if updated.EntrantEmail != "harry@football.net" {
+			expectedGot(t, "harry@football.net", updated.EntrantEmail)
+		}
+	})
+
+	t.Run("update nickname to one held by another entry must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		_, err := agent.UpdateEntrantDetailsByID(ctx, entry.ID.String(), strPtr(otherEntry.EntrantNickname), nil)
+		if !cmp.ErrorType(err, domain.ConflictError{})().Success() {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+	})
+
+	t.Run("update to invalid nickname must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		_, err := agent.UpdateEntrantDetailsByID(ctx, entry.ID.String(), strPtr("Not A Nickname!"), nil)
+		if !cmp.ErrorType(err, domain.ValidationError{})().Success() {
+			expectedTypeOfGot(t, domain.ValidationError{}, err)
+		}
+	})
+
+	t.Run("update with no details must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		_, err := agent.UpdateEntrantDetailsByID(ctx, entry.ID.String(), nil, nil)
+		if !cmp.ErrorType(err, domain.ValidationError{})().Success() {
+			expectedTypeOfGot(t, domain.ValidationError{}, err)
+		}
+	})
+
+	t.Run("update with invalid credentials must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		_, err := agent.UpdateEntrantDetailsByID(ctx, entry.ID.String(), strPtr("HarryR"), nil)
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+}
+
+func TestEntryAgent_ApproveEntriesByIDs(t *testing.T) {
+	t.Cleanup(truncate)
+
+	pendingEntry := insertEntry(t, generateTestEntry(t,
+		"Harry Redknapp",
+		"MrHarryR",
+		"harry.redknapp@football.net",
+	))
+
+	paidEntry := generateTestEntry(t,
+		"Jamie Redknapp",
+		"MrJamieR",
+		"jamie.redknapp@football.net",
+	)
+	paidEntry.Status = domain.EntryStatusPaid
+	paidEntry = insertEntry(t, paidEntry)
+
+	agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("approve multiple entries must approve only those that are eligible", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		approved, failed, err := agent.ApproveEntriesByIDs(ctx, []string{pendingEntry.ID.String(), paidEntry.ID.String()})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(approved) != 1 {
+			expectedGot(t, 1, len(approved))
+		}
+		if approved[0].ID != paidEntry.ID {
+			expectedGot(t, paidEntry.ID, approved[0].ID)
+		}
+
+		if len(failed) != 1 {
+			expectedGot(t, 1, len(failed))
+		}
+		if !cmp.ErrorType(failed[pendingEntry.ID.String()], domain.ConflictError{})().Success() {
+			expectedTypeOfGot(t, domain.ConflictError{}, failed[pendingEntry.ID.String()])
+		}
+	})
+
+	t.Run("approve no entries must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		_, _, err := agent.ApproveEntriesByIDs(ctx, nil)
+		if !cmp.ErrorType(err, domain.ValidationError{})().Success() {
+			expectedTypeOfGot(t, domain.ValidationError{}, err)
+		}
+	})
+
+	t.Run("approve entries with invalid credentials must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		_, _, err := agent.ApproveEntriesByIDs(ctx, []string{paidEntry.ID.String()})
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+}
+
+func TestEntryAgent_WithdrawEntryByID(t *testing.T) {
+	t.Cleanup(truncate)
+
+	entry := generateTestEntry(t,
+		"Harry Redknapp",
+		"MrHarryR",
+		"harry.redknapp@football.net",
+	)
+	entry.Status = domain.EntryStatusPaid
+	entry.ApprovedAt = &testDate
+	entry = insertEntry(t, entry)
+
+	agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("withdraw entry with invalid credentials must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		_, err := agent.WithdrawEntryByID(ctx, entry.ID.String())
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("withdraw approved entry must succeed and revoke approval", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		withdrawn, err := agent.WithdrawEntryByID(ctx, entry.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if withdrawn.Status != domain.EntryStatusWithdrawn {
+			expectedGot(t, domain.EntryStatusWithdrawn, withdrawn.Status)
+		}
+		if withdrawn.IsApproved() {
+			expectedGot(t, "approved entry false", "approved entry true")
+		}
+	})
+
+	t.Run("disqualify entry that has already been withdrawn must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		_, err := agent.DisqualifyEntryByID(ctx, entry.ID.String())
+		if !cmp.ErrorType(err, domain.ConflictError{})().Success() {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+	})
+}
+
+func TestEntryAgent_PromoteNextWaitlistedEntry(t *testing.T) {
+	t.Cleanup(truncate)
+
+	agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	season := testSeason
+	season.EntriesAccepted = domain.TimeFrame{
+		From:  testDate.Add(-12 * time.Hour),
+		Until: testDate.Add(12 * time.Hour),
+	}
+
+	// realm only has room for a single entry
+	testContextWithEntryCap := func(t *testing.T) (context.Context, context.CancelFunc) {
+		ctx, cancel := testContextDefault(t)
+		domain.RealmFromContext(ctx).Config.EntryCap = 1
+		return ctx, cancel
+	}
+
+	var firstEntry, secondEntry, thirdEntry domain.Entry
+
+	t.Run("create entries beyond the entry cap must add them to the waitlist", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryCap(t)
+		defer cancel()
+
+		firstEntry, err = agent.CreateEntry(ctx, generateTestEntry(t, "Harry Redknapp", "MrHarryR", "harry.redknapp@football.net"), &season)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if firstEntry.Status != domain.EntryStatusPending {
+			expectedGot(t, domain.EntryStatusPending, firstEntry.Status)
+		}
+
+		secondEntry, err = agent.CreateEntry(ctx, generateTestEntry(t, "Jamie Redknapp", "MrJamieR", "jamie.redknapp@football.net"), &season)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if secondEntry.Status != domain.EntryStatusWaitlisted {
+			expectedGot(t, domain.EntryStatusWaitlisted, secondEntry.Status)
+		}
+
+		thirdEntry, err = agent.CreateEntry(ctx, generateTestEntry(t, "Frank Lampard", "FrankieL", "frank.lampard@football.net"), &season)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if thirdEntry.Status != domain.EntryStatusWaitlisted {
+			expectedGot(t, domain.EntryStatusWaitlisted, thirdEntry.Status)
+		}
+	})
+
+	t.Run("create entry that is already on the waitlist must fail", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryCap(t)
+		defer cancel()
+
+		_, err := agent.CreateEntry(ctx, generateTestEntry(t, "Jamie Redknapp", "MrJamieR", "jamie.redknapp@football.net"), &season)
+		if !cmp.ErrorType(err, domain.ConflictError{})().Success() {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+	})
+
+	t.Run("promote waitlisted entry with invalid credentials must fail", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryCap(t)
+		defer cancel()
+
+		_, err := agent.PromoteNextWaitlistedEntry(ctx, season)
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("promote waitlisted entry while no place is available must promote nothing", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryCap(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		_, err := agent.PromoteNextWaitlistedEntry(ctx, season)
+		if !errors.Is(err, domain.ErrNothingToPromote) {
+			expectedGot(t, domain.ErrNothingToPromote, err)
+		}
+	})
+
+	t.Run("promote waitlisted entry once a place is available must promote the longest-waiting entry", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryCap(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		if _, err := agent.WithdrawEntryByID(ctx, firstEntry.ID.String()); err != nil {
+			t.Fatal(err)
+		}
+
+		promoted, err := agent.PromoteNextWaitlistedEntry(ctx, season)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if promoted == nil {
+			t.Fatal("want promoted entry, got nil")
+		}
+		if promoted.ID != secondEntry.ID {
+			expectedGot(t, secondEntry.ID, promoted.ID)
+		}
+		if promoted.Status != domain.EntryStatusPending {
+			expectedGot(t, domain.EntryStatusPending, promoted.Status)
+		}
+		checkTimePtrMatch(t, &testDate, promoted.ReminderSentAt)
+
+		// place has now been taken
+		_, err = agent.PromoteNextWaitlistedEntry(ctx, season)
+		if !errors.Is(err, domain.ErrNothingToPromote) {
+			expectedGot(t, domain.ErrNothingToPromote, err)
+		}
+	})
+
+	t.Run("expire reminded entry in a realm with an entry cap before entries close must succeed", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryCap(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		expired, err := agent.ExpireEntryByID(ctx, secondEntry.ID.String(), season)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expired.Status != domain.EntryStatusExpired {
+			expectedGot(t, domain.EntryStatusExpired, expired.Status)
+		}
+	})
+}
+
+func TestEntryAgent_CreateEntryWithinEntryCap(t *testing.T) {
+	t.Cleanup(truncate)
+
+	season := testSeason
+	season.EntriesAccepted = domain.TimeFrame{
+		From:  testDate.Add(-12 * time.Hour),
+		Until: testDate.Add(12 * time.Hour),
+	}
+
+	t.Run("create entries concurrently from separate agents must not exceed the entry cap", func(t *testing.T) {
+		const entryCap, attempts = 2, 6
+
+		var wg sync.WaitGroup
+		errs := make(chan error, attempts)
+		for idx := 0; idx < attempts; idx++ {
+			wg.Add(1)
+			go func(idx int) {
+				defer wg.Done()
+
+				// each agent stands in for a separate instance of the service
+				agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{t: testDate})
+				if err != nil {
+					errs <- err
+					return
+				}
+
+				ctx, cancel := testContextDefault(t)
+				defer cancel()
+				domain.RealmFromContext(ctx).Config.EntryCap = entryCap
+
+				entry := generateTestEntry(t, fmt.Sprintf("Entrant %d", idx), fmt.Sprintf("Entrant%d", idx), fmt.Sprintf("entrant.%d@football.net", idx))
+				if _, err := agent.CreateEntry(ctx, entry, &season); err != nil {
+					errs <- err
+				}
+			}(idx)
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		entries, err := er.Select(ctx, map[string]interface{}{"season_id": season.ID}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var pending, waitlisted int
+		for _, entry := range entries {
+			switch entry.Status {
+			case domain.EntryStatusPending:
+				pending++
+			case domain.EntryStatusWaitlisted:
+				waitlisted++
+			}
+		}
+		if pending != entryCap {
+			expectedGot(t, entryCap, pending)
+		}
+		if waitlisted != attempts-entryCap {
+			expectedGot(t, attempts-entryCap, waitlisted)
+		}
+	})
 }
 
 func TestEntryAgent_GetEntryPredictionByTimestamp(t *testing.T) {
@@ -1387,7 +2020,7 @@ func TestEntryAgent_GetEntryPredictionByTimestamp(t *testing.T) {
 		entryPredictions = append(entryPredictions, entryPrediction)
 	}
 
-	agent, err := domain.NewEntryAgent(er, epr, sr, sc, &mockClock{})
+	agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1559,7 +2192,7 @@ func TestEntryAgent_GetPredictionRankingLimit(t *testing.T) {
 
 		for _, tc := range tt {
 			cl := &mockClock{t: tc.ts}
-			agent, err := domain.NewEntryAgent(er, epr, sr, sc, cl)
+			agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, cl)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -1673,7 +2306,7 @@ func TestEntryAgent_CheckRankingLimit(t *testing.T) {
 	}
 
 	for _, tc := range tt {
-		agent, err := domain.NewEntryAgent(er, epr, sr, sc, cl)
+		agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, cl)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
 var (
@@ -17,6 +18,14 @@ var (
 	ErrCurrentTimeFrameIsMissing = errors.New("current timeframe is missing")
 	// ErrNoMatchingPredictionWindow defines an error representing a no matching prediction windows
 	ErrNoMatchingPredictionWindow = errors.New("no matching prediction window")
+	// ErrEntryAwaitingPayment defines an error representing an existing entry that has not yet been paid for
+	ErrEntryAwaitingPayment = errors.New("entry is awaiting payment - check your inbox for a link to complete it")
+	// ErrEntryCapReached defines an error representing a realm and season whose entry cap has no place left
+	ErrEntryCapReached = errors.New("entry cap reached")
+	// ErrEntryNotWaitlisted defines an error representing an Entry that is no longer on the waitlist
+	ErrEntryNotWaitlisted = errors.New("entry is not waitlisted")
+	// ErrNothingToPromote defines an error representing a waitlist with nobody on it who can be given a place
+	ErrNothingToPromote = errors.New("no waitlisted entry can be promoted")
 )
 
 // BadRequestError translates to a 400 Bad Request response status code
@@ -61,6 +70,16 @@ func (e ValidationError) Error() string {
 	return fmt.Sprintf("reasons: %s", strings.ToLower(reasons))
 }
 
+// RateLimitedError translates to a 429 Too Many Requests response status code
+type RateLimitedError struct {
+	error
+	RetryAfter time.Duration
+}
+
+func (e RateLimitedError) Unwrap() error {
+	return e.error
+}
+
 // InternalError translates to a 500 Internal Server Error response status code
 type InternalError struct{ error }
 
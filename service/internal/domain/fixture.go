@@ -0,0 +1,168 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// PollingIntervalActive determines how often standings are polled while matches are in progress or have just finished
+	PollingIntervalActive = 5 * time.Minute
+
+	// PollingIntervalIdle determines how often standings are polled when there are no matches in progress
+	PollingIntervalIdle = 6 * time.Hour
+
+	// fixtureDuration represents the length of time from kick-off until the final whistle, allowing for half-time and stoppages
+	fixtureDuration = 2 * time.Hour
+
+	// fixtureCooldown represents the length of time after the final whistle that standings should continue to be polled
+	// frequently, while the upstream data source catches up with the result
+	fixtureCooldown = 2 * time.Hour
+)
+
+// Fixture represents a single scheduled match within a Season
+type Fixture struct {
+	MatchWeek  int       `yaml:"match_week" json:"match_week"`
+	KickOff    time.Time `yaml:"kick_off" json:"kick_off"`
+	HomeTeamID string    `yaml:"home_team_id" json:"home_team_id"`
+	AwayTeamID string    `yaml:"away_team_id" json:"away_team_id"`
+}
+
+// FixtureDataSource defines the interface for an external data source that is able to provide a Season's fixtures
+type FixtureDataSource interface {
+	RetrieveFixturesBySeason(ctx context.Context, s Season) ([]Fixture, error)
+}
+
+// GetFixturesFromFile returns the Fixtures that have been defined within the schedule file for the provided season ID.
+// Returns a NotFoundError if no schedule file exists for the season
+func GetFixturesFromFile(seasonID string) ([]Fixture, error) {
+	filePath := filepath.Join("data", "fixtures", fmt.Sprintf("%s.yml", seasonID))
+
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, NotFoundError{fmt.Errorf("fixtures file '%s': not found", filePath)}
+		}
+		return nil, fmt.Errorf("cannot read file '%s': %w", filePath, err)
+	}
+
+	var schedule struct {
+		Fixtures []Fixture `yaml:"fixtures"`
+	}
+	if err := yaml.Unmarshal(b, &schedule); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal fixtures from file '%s': %w", filePath, err)
+	}
+
+	return schedule.Fixtures, nil
+}
+
+// PollingSchedule determines when the standings for a Season should next be polled, based on the Season's fixtures.
+// Standings are polled frequently while matches are in progress or have just finished, and infrequently otherwise.
+// PollingSchedule implements cron.Schedule
+type PollingSchedule struct {
+	windows []TimeFrame
+	until   time.Time
+	stopped bool
+	mux     sync.RWMutex
+}
+
+// Next returns the next time at which standings should be polled, after the provided time.
+// A zero time is returned once the Season has elapsed or the schedule has been stopped
+func (p *PollingSchedule) Next(t time.Time) time.Time {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+
+	if p.stopped || !t.Before(p.until) {
+		return time.Time{}
+	}
+
+	next := t.Add(PollingIntervalIdle)
+
+	for _, w := range p.windows {
+		if !w.Until.After(t) {
+			// window has already elapsed
+			continue
+		}
+
+		if !w.From.After(t) {
+			// we're within a window, so poll again soon
+			next = t.Add(PollingIntervalActive)
+		} else if w.From.Before(next) {
+			// next window begins before we'd otherwise poll
+			next = w.From
+		}
+
+		break
+	}
+
+	if next.After(p.until) {
+		// make sure we get one last look before the season ends
+		return p.until
+	}
+
+	return next
+}
+
+// Stop prevents the schedule from producing any further polling times
+func (p *PollingSchedule) Stop() {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.stopped = true
+}
+
+// SetFixtures replaces the Fixtures that the schedule is based on, so that fixtures which have since been rescheduled
+// or postponed are polled at their new kick-off times
+func (p *PollingSchedule) SetFixtures(fixtures []Fixture) {
+	windows := newPollingWindows(fixtures)
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.windows = windows
+}
+
+// NewPollingSchedule returns a new PollingSchedule for the provided Season and its Fixtures
+func NewPollingSchedule(s Season, fixtures []Fixture) *PollingSchedule {
+	return &PollingSchedule{
+		windows: newPollingWindows(fixtures),
+		until:   s.Live.Until,
+	}
+}
+
+// newPollingWindows returns the time frames during which standings should be polled frequently, based on the provided Fixtures
+func newPollingWindows(fixtures []Fixture) []TimeFrame {
+	kickOffs := make([]time.Time, 0)
+	for _, f := range fixtures {
+		kickOffs = append(kickOffs, f.KickOff)
+	}
+
+	sort.SliceStable(kickOffs, func(i, j int) bool {
+		return kickOffs[i].Before(kickOffs[j])
+	})
+
+	// merge fixtures that overlap (e.g. simultaneous kick-offs on the final day) into a single window
+	windows := make([]TimeFrame, 0)
+	for _, ko := range kickOffs {
+		w := TimeFrame{From: ko, Until: ko.Add(fixtureDuration + fixtureCooldown)}
+
+		if len(windows) > 0 && !w.From.After(windows[len(windows)-1].Until) {
+			if w.Until.After(windows[len(windows)-1].Until) {
+				windows[len(windows)-1].Until = w.Until
+			}
+			continue
+		}
+
+		windows = append(windows, w)
+	}
+
+	return windows
+}
@@ -0,0 +1,108 @@
+package domain_test
+
+import (
+	"prediction-league/service/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestPollingSchedule_Next(t *testing.T) {
+	saturday := time.Date(2018, 8, 11, 12, 30, 0, 0, time.UTC)
+
+	season := domain.Season{
+		Live: domain.TimeFrame{
+			From:  saturday.Add(-24 * time.Hour),
+			Until: saturday.Add(14 * 24 * time.Hour),
+		},
+	}
+
+	fixtures := []domain.Fixture{
+		// listed out of order, with two simultaneous kick-offs
+		{KickOff: saturday.Add(2*time.Hour + 30*time.Minute)},
+		{KickOff: saturday},
+		{KickOff: saturday.Add(2*time.Hour + 30*time.Minute)},
+		{KickOff: saturday.Add(7 * 24 * time.Hour)},
+	}
+
+	schedule := domain.NewPollingSchedule(season, fixtures)
+
+	tt := []struct {
+		name     string
+		t        time.Time
+		wantNext time.Time
+	}{
+		{
+			name:     "well before next kick-off must poll at idle interval",
+			t:        saturday.Add(-24 * time.Hour),
+			wantNext: saturday.Add(-24 * time.Hour).Add(domain.PollingIntervalIdle),
+		},
+		{
+			name:     "shortly before next kick-off must poll at kick-off",
+			t:        saturday.Add(-time.Hour),
+			wantNext: saturday,
+		},
+		{
+			name:     "during match must poll at active interval",
+			t:        saturday.Add(time.Hour),
+			wantNext: saturday.Add(time.Hour).Add(domain.PollingIntervalActive),
+		},
+		{
+			name:     "between overlapping matches must poll at active interval",
+			t:        saturday.Add(3 * time.Hour),
+			wantNext: saturday.Add(3 * time.Hour).Add(domain.PollingIntervalActive),
+		},
+		{
+			name:     "shortly after final whistle must poll at active interval",
+			t:        saturday.Add(6 * time.Hour),
+			wantNext: saturday.Add(6 * time.Hour).Add(domain.PollingIntervalActive),
+		},
+		{
+			name:     "once all matches have finished must poll at idle interval",
+			t:        saturday.Add(9 * time.Hour),
+			wantNext: saturday.Add(9 * time.Hour).Add(domain.PollingIntervalIdle),
+		},
+		{
+			name:     "close to the end of the season must poll at end of season",
+			t:        season.Live.Until.Add(-time.Hour),
+			wantNext: season.Live.Until,
+		},
+		{
+			name: "after the end of the season must not poll",
+			t:    season.Live.Until,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gotNext := schedule.Next(tc.t)
+			if !gotNext.Equal(tc.wantNext) {
+				expectedGot(t, tc.wantNext, gotNext)
+			}
+		})
+	}
+
+	t.Run("schedule with replaced fixtures must poll at their new kick-off", func(t *testing.T) {
+		schedule := domain.NewPollingSchedule(season, fixtures)
+
+		// first fixture has been postponed by a day
+		postponed := saturday.Add(24 * time.Hour)
+		schedule.SetFixtures([]domain.Fixture{{KickOff: postponed}})
+
+		if gotNext := schedule.Next(saturday.Add(time.Hour)); !gotNext.Equal(saturday.Add(time.Hour).Add(domain.PollingIntervalIdle)) {
+			expectedGot(t, saturday.Add(time.Hour).Add(domain.PollingIntervalIdle), gotNext)
+		}
+		if gotNext := schedule.Next(postponed.Add(-time.Hour)); !gotNext.Equal(postponed) {
+			expectedGot(t, postponed, gotNext)
+		}
+	})
+
+	t.Run("stopped schedule must not poll", func(t *testing.T) {
+		schedule := domain.NewPollingSchedule(season, fixtures)
+		schedule.Stop()
+
+		gotNext := schedule.Next(saturday)
+		if !gotNext.IsZero() {
+			expectedGot(t, time.Time{}, gotNext)
+		}
+	})
+}
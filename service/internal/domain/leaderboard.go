@@ -12,14 +12,16 @@ type LeaderBoard struct {
 	RoundNumber int                  `json:"round_number"`
 	Rankings    []LeaderBoardRanking `json:"rankings"`
 	LastUpdated *time.Time           `json:"last_updated"`
+	PrizePot    *PrizePot            `json:"prize_pot,omitempty"`
 }
 
 // LeaderBoardRanking represents a single ranking on the leaderboard
 type LeaderBoardRanking struct {
 	RankingWithScore
-	MaxScore   int `json:"max_score"`
-	TotalScore int `json:"total_score"`
-	Movement   int `json:"movement"`
+	MaxScore        int     `json:"max_score"`
+	TotalScore      int     `json:"total_score"`
+	Movement        int     `json:"movement"`
+	ProjectedPayout float32 `json:"projected_payout"`
 }
 
 // LeaderBoardAgent defines the behaviours for handling LeaderBoards
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// MatchWeekStrategyUpstream uses the match week number provided by the upstream data source
+	MatchWeekStrategyUpstream = "upstream"
+	// MatchWeekStrategyMinGamesPlayed infers the match week number from the fewest games played by any team
+	MatchWeekStrategyMinGamesPlayed = "min"
+	// MatchWeekStrategyModeGamesPlayed infers the match week number from the most common number of games played
+	MatchWeekStrategyModeGamesPlayed = "mode"
+)
+
+// MatchWeekInference represents the outcome of inferring a match week number from a set of Standings
+type MatchWeekInference struct {
+	Number   int
+	Strategy string
+	Reason   string
+}
+
+// isValidMatchWeekStrategy determines whether the provided strategy is recognised
+func isValidMatchWeekStrategy(strategy string) bool {
+	switch strategy {
+	case "", MatchWeekStrategyUpstream, MatchWeekStrategyMinGamesPlayed, MatchWeekStrategyModeGamesPlayed:
+		return true
+	}
+	return false
+}
+
+// InferMatchWeekNumber works out the match week number that the provided Standings represent, using the strategy
+// configured for the provided Season. Strategies based on games played attribute the table to the last match week that
+// has been completed, so a table where most teams have played 4 games is match week 4 until match week 5 is completed.
+// This keeps the final table of each match week attributed to it, so that the match week is finalised with its own rankings.
+// The inferred number is kept within the bounds of the Season's first and maximum number of rounds
+func InferMatchWeekNumber(s Season, stnd Standings) (MatchWeekInference, error) {
+	strategy := s.MatchWeekStrategy
+	if strategy == "" {
+		strategy = MatchWeekStrategyUpstream
+	}
+
+	inf := MatchWeekInference{Strategy: strategy}
+
+	if strategy == MatchWeekStrategyUpstream {
+		inf.Number = stnd.RoundNumber
+		inf.Reason = fmt.Sprintf("upstream reports match week %d", stnd.RoundNumber)
+		return inf, nil
+	}
+
+	if len(stnd.Rankings) == 0 {
+		return MatchWeekInference{}, fmt.Errorf("cannot infer match week using strategy '%s': standings have no rankings", strategy)
+	}
+
+	played := make([]int, 0, len(stnd.Rankings))
+	for _, rnk := range stnd.Rankings {
+		played = append(played, rnk.MetaData[MetaKeyPlayedGames])
+	}
+	sort.Ints(played)
+
+	var completed int
+	switch strategy {
+	case MatchWeekStrategyMinGamesPlayed:
+		completed = played[0]
+		inf.Reason = fmt.Sprintf("fewest games played by any team is %d", completed)
+	case MatchWeekStrategyModeGamesPlayed:
+		var count int
+		completed, count = modeOfSortedInts(played)
+		inf.Reason = fmt.Sprintf("%d of %d teams have played %d games", count, len(played), completed)
+	default:
+		return MatchWeekInference{}, fmt.Errorf("match week strategy '%s': %w", strategy, ErrIsInvalid)
+	}
+
+	inf.Number = completed
+	switch {
+	case inf.Number < 1:
+		// no games have been completed yet, so the first match week is still to come
+		inf.Number = 1
+	case s.MaxRounds > 0 && inf.Number > s.MaxRounds:
+		inf.Number = s.MaxRounds
+	}
+
+	inf.Reason = fmt.Sprintf("%s (upstream reports match week %d)", inf.Reason, stnd.RoundNumber)
+
+	return inf, nil
+}
+
+// modeOfSortedInts returns the most common value within the provided sorted values, along with its number of
+// occurrences. Ties are resolved in favour of the lowest value
+func modeOfSortedInts(sorted []int) (int, int) {
+	var mode, modeCount, count int
+	for idx, val := range sorted {
+		if idx > 0 && val == sorted[idx-1] {
+			count++
+		} else {
+			count = 1
+		}
+		if count > modeCount {
+			mode, modeCount = val, count
+		}
+	}
+	return mode, modeCount
+}
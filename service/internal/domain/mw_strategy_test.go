@@ -0,0 +1,135 @@
+package domain_test
+
+import (
+	"errors"
+	"prediction-league/service/internal/domain"
+	"testing"
+)
+
+func TestInferMatchWeekNumber(t *testing.T) {
+	newStandings := func(roundNumber int, played ...int) domain.Standings {
+		stnd := domain.Standings{RoundNumber: roundNumber}
+		for idx, p := range played {
+			rnk := domain.NewRankingWithMeta()
+			rnk.Position = idx + 1
+			rnk.MetaData[domain.MetaKeyPlayedGames] = p
+			stnd.Rankings = append(stnd.Rankings, rnk)
+		}
+		return stnd
+	}
+
+	tt := []struct {
+		name          string
+		season        domain.Season
+		stnd          domain.Standings
+		wantInference domain.MatchWeekInference
+		wantErr       error
+	}{
+		{
+			name:   "empty strategy must default to upstream",
+			season: domain.Season{MaxRounds: 38},
+			stnd:   newStandings(12, 10, 11, 11),
+			wantInference: domain.MatchWeekInference{
+				Number:   12,
+				Strategy: domain.MatchWeekStrategyUpstream,
+				Reason:   "upstream reports match week 12",
+			},
+		},
+		{
+			name:   "min strategy must use fewest games played",
+			season: domain.Season{MaxRounds: 38, MatchWeekStrategy: domain.MatchWeekStrategyMinGamesPlayed},
+			stnd:   newStandings(12, 11, 9, 11, 10),
+			wantInference: domain.MatchWeekInference{
+				Number:   9,
+				Strategy: domain.MatchWeekStrategyMinGamesPlayed,
+				Reason:   "fewest games played by any team is 9 (upstream reports match week 12)",
+			},
+		},
+		{
+			name:   "mode strategy must use most common games played",
+			season: domain.Season{MaxRounds: 38, MatchWeekStrategy: domain.MatchWeekStrategyModeGamesPlayed},
+			stnd:   newStandings(12, 11, 9, 10, 10, 11, 10),
+			wantInference: domain.MatchWeekInference{
+				Number:   10,
+				Strategy: domain.MatchWeekStrategyModeGamesPlayed,
+				Reason:   "3 of 6 teams have played 10 games (upstream reports match week 12)",
+			},
+		},
+		{
+			name:   "mode strategy must favour fewer games played when tied",
+			season: domain.Season{MaxRounds: 38, MatchWeekStrategy: domain.MatchWeekStrategyModeGamesPlayed},
+			stnd:   newStandings(12, 11, 10, 11, 10),
+			wantInference: domain.MatchWeekInference{
+				Number:   10,
+				Strategy: domain.MatchWeekStrategyModeGamesPlayed,
+				Reason:   "2 of 4 teams have played 10 games (upstream reports match week 12)",
+			},
+		},
+		{
+			name:   "table must remain in completed match week once its last game has finished",
+			season: domain.Season{MaxRounds: 38, MatchWeekStrategy: domain.MatchWeekStrategyMinGamesPlayed},
+			stnd:   newStandings(5, 4, 4, 4, 4),
+			wantInference: domain.MatchWeekInference{
+				Number:   4,
+				Strategy: domain.MatchWeekStrategyMinGamesPlayed,
+				Reason:   "fewest games played by any team is 4 (upstream reports match week 5)",
+			},
+		},
+		{
+			name:   "table must move to next match week once its first game has finished for every team",
+			season: domain.Season{MaxRounds: 38, MatchWeekStrategy: domain.MatchWeekStrategyMinGamesPlayed},
+			stnd:   newStandings(5, 5, 5, 5, 5),
+			wantInference: domain.MatchWeekInference{
+				Number:   5,
+				Strategy: domain.MatchWeekStrategyMinGamesPlayed,
+				Reason:   "fewest games played by any team is 5 (upstream reports match week 5)",
+			},
+		},
+		{
+			name:   "inferred match week must not precede first round",
+			season: domain.Season{MaxRounds: 38, MatchWeekStrategy: domain.MatchWeekStrategyMinGamesPlayed},
+			stnd:   newStandings(1, 0, 0),
+			wantInference: domain.MatchWeekInference{
+				Number:   1,
+				Strategy: domain.MatchWeekStrategyMinGamesPlayed,
+				Reason:   "fewest games played by any team is 0 (upstream reports match week 1)",
+			},
+		},
+		{
+			name:   "inferred match week must not exceed max rounds",
+			season: domain.Season{MaxRounds: 38, MatchWeekStrategy: domain.MatchWeekStrategyMinGamesPlayed},
+			stnd:   newStandings(38, 39, 39),
+			wantInference: domain.MatchWeekInference{
+				Number:   38,
+				Strategy: domain.MatchWeekStrategyMinGamesPlayed,
+				Reason:   "fewest games played by any team is 39 (upstream reports match week 38)",
+			},
+		},
+		{
+			name:    "strategy based on games played must fail without rankings",
+			season:  domain.Season{MaxRounds: 38, MatchWeekStrategy: domain.MatchWeekStrategyMinGamesPlayed},
+			stnd:    newStandings(12),
+			wantErr: errors.New("cannot infer match week using strategy 'min': standings have no rankings"),
+		},
+		{
+			name:    "unknown strategy must fail",
+			season:  domain.Season{MaxRounds: 38, MatchWeekStrategy: "max"},
+			stnd:    newStandings(12, 11),
+			wantErr: errors.New("match week strategy 'max': is invalid"),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gotInference, gotErr := domain.InferMatchWeekNumber(tc.season, tc.stnd)
+			if tc.wantErr != nil {
+				cmpErrorMsg(t, tc.wantErr.Error(), gotErr)
+				return
+			}
+			if gotErr != nil {
+				t.Fatal(gotErr)
+			}
+			cmpDiff(t, "match week inference", tc.wantInference, gotInference)
+		})
+	}
+}
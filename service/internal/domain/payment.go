@@ -0,0 +1,534 @@
+package domain
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentOrder represents an order that has been raised with a PaymentProvider on behalf of an Entry,
+// which must be completed by the entrant before it can be captured
+type PaymentOrder struct {
+	ID       string  `json:"id"`
+	EntryID  string  `json:"entry_id"`
+	Amount   float32 `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// providerEventTypeOrderCaptured identifies a PaymentEvent that records an order captured by the service itself,
+// rather than an event that was raised by the PaymentProvider
+const providerEventTypeOrderCaptured = "order_captured"
+
+// VerifiedPayment represents a payment whose completion has been confirmed directly with a PaymentProvider
+type VerifiedPayment struct {
+	OrderID      string
+	EntryID      string
+	Reference    string
+	Amount       float32
+	Currency     string
+	MerchantName string
+}
+
+// PaymentProvider defines the interface for our external payment provider
+type PaymentProvider interface {
+	PaymentMethod() string
+	CreateOrder(ctx context.Context, entry Entry, fee RealmEntryFee) (PaymentOrder, error)
+	RetrieveOrder(ctx context.Context, orderID string) (PaymentOrder, error)
+	CaptureOrder(ctx context.Context, orderID string) (VerifiedPayment, error)
+	VerifyWebhookEvent(ctx context.Context, header map[string][]string, body []byte) (PaymentEvent, error)
+}
+
+// PaymentAgent defines the behaviours for handling payments that are verified by a PaymentProvider
+type PaymentAgent struct {
+	ea  *EntryAgent
+	wa  *WaitlistAgent
+	pp  PaymentProvider
+	per PaymentEventRepository
+	rc  RealmCollection
+	cl  Clock
+}
+
+// CreatePaymentOrder raises a new PaymentOrder with the PaymentProvider for the entry fee of the Entry that matches the provided ID
+func (p *PaymentAgent) CreatePaymentOrder(ctx context.Context, entryID string) (PaymentOrder, error) {
+	entry, err := p.ea.retrieveSingleEntryByID(ctx, entryID)
+	if err != nil {
+		return PaymentOrder{}, err
+	}
+
+	// check Entry status
+	if entry.Status != EntryStatusPending {
+		return PaymentOrder{}, ConflictError{errors.New("payment can only be made if entry status is pending")}
+	}
+
+	fee := RealmFromContext(ctx).EntryFee
+	if fee.Amount <= 0 {
+		return PaymentOrder{}, ConflictError{errors.New("realm does not require an entry fee")}
+	}
+
+	order, err := p.pp.CreateOrder(ctx, entry, fee)
+	if err != nil {
+		return PaymentOrder{}, InternalError{fmt.Errorf("cannot create payment order: %w", err)}
+	}
+
+	return order, nil
+}
+
+// CapturePaymentOrder captures the PaymentOrder that matches the provided order ID and verifies the resulting payment
+// against the Entry that matches the provided entry ID. A verified payment is recorded against the Entry,
+// which is then approved automatically
+func (p *PaymentAgent) CapturePaymentOrder(ctx context.Context, entryID, orderID string) (Entry, VerifiedPayment, error) {
+	if orderID == "" {
+		return Entry{}, VerifiedPayment{}, ValidationError{Reasons: []string{"invalid order id"}}
+	}
+
+	entry, err := p.ea.retrieveSingleEntryByID(ctx, entryID)
+	if err != nil {
+		return Entry{}, VerifiedPayment{}, err
+	}
+
+	// check Entry status before capturing, so that an entrant is not charged for an entry that cannot be paid for
+	if entry.Status != EntryStatusPending {
+		return Entry{}, VerifiedPayment{}, ConflictError{errors.New("payment can only be made if entry status is pending")}
+	}
+
+	fee := RealmFromContext(ctx).EntryFee
+
+	// check the order before capturing it too, so that an entrant is not charged for a payment that cannot be accepted
+	order, err := p.pp.RetrieveOrder(ctx, orderID)
+	if err != nil {
+		return Entry{}, VerifiedPayment{}, ConflictError{fmt.Errorf("cannot retrieve payment order: %w", err)}
+	}
+
+	if err := verifyOrderForEntry(order, entry, fee); err != nil {
+		return Entry{}, VerifiedPayment{}, err
+	}
+
+	payment, err := p.pp.CaptureOrder(ctx, orderID)
+	if err != nil {
+		return Entry{}, VerifiedPayment{}, ConflictError{fmt.Errorf("cannot capture payment order: %w", err)}
+	}
+
+	if err := verifyPaymentForEntry(payment, entry, fee); err != nil {
+		// the entrant has been charged by now, so the payment is kept on record for an admin to refund rather than dropped
+		if recErr := p.recordCapturedPayment(ctx, entry, payment, fmt.Sprintf("flagged: %s", err.Error())); recErr != nil {
+			return Entry{}, VerifiedPayment{}, InternalError{fmt.Errorf("cannot record flagged payment: %w", recErr)}
+		}
+		return Entry{}, VerifiedPayment{}, err
+	}
+
+	entry, err = p.approveVerifiedPayment(ctx, entry, payment)
+	if err != nil {
+		return Entry{}, VerifiedPayment{}, err
+	}
+
+	outcome := paymentEventOutcomeEntryPaid
+	if entry.IsApproved() {
+		outcome = paymentEventOutcomeEntryApproved
+	}
+
+	if err := p.recordCapturedPayment(ctx, entry, payment, outcome); err != nil {
+		return Entry{}, VerifiedPayment{}, err
+	}
+
+	return entry, payment, nil
+}
+
+// recordCapturedPayment stores the provided payment, which has been captured on behalf of the provided Entry,
+// as a PaymentEvent that has already been applied with the provided outcome. This is what allows the Entry to be approved
+// once its email address is verified, since the payment details held on the Entry itself could have been provided by anyone
+func (p *PaymentAgent) recordCapturedPayment(ctx context.Context, entry Entry, payment VerifiedPayment, outcome string) error {
+	payload, err := json.Marshal(payment)
+	if err != nil {
+		return InternalError{fmt.Errorf("cannot marshal payment: %w", err)}
+	}
+
+	now := p.cl.Now().Truncate(time.Second)
+
+	event := PaymentEvent{
+		ID:                providerEventTypeOrderCaptured + ":" + payment.OrderID,
+		RealmName:         entry.RealmName,
+		Type:              PaymentEventTypeCaptureCompleted,
+		ProviderEventType: providerEventTypeOrderCaptured,
+		EntryID:           entry.ID.String(),
+		PaymentRef:        payment.Reference,
+		Amount:            payment.Amount,
+		Currency:          payment.Currency,
+		Payload:           payload,
+		Outcome:           outcome,
+		ReceivedAt:        now,
+		ProcessedAt:       &now,
+	}
+
+	if err := p.per.Insert(ctx, &event); err != nil {
+		return domainErrorFromRepositoryError(err)
+	}
+
+	return nil
+}
+
+// VerifyEntryEmailByID records that the entrant of the Entry with the provided ID has proven that they own its email address.
+// An Entry whose payment has already been verified with the PaymentProvider is then approved on the system's behalf,
+// just as it would have been if its email address had been verified when the payment was taken
+func (p *PaymentAgent) VerifyEntryEmailByID(ctx context.Context, id string) (Entry, error) {
+	entry, err := p.ea.VerifyEntryEmailByID(ctx, id)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if entry.Status != EntryStatusPaid || entry.IsApproved() || entry.PaymentRef == nil {
+		return entry, nil
+	}
+
+	verified, err := p.hasVerifiedPayment(ctx, entry)
+	if err != nil {
+		return Entry{}, err
+	}
+	if !verified {
+		// payment was made by other means, so it must still be approved by an admin
+		return entry, nil
+	}
+
+	return p.ea.ApproveEntryByID(SetSystemAdminRoleOnContext(ctx, AdminRoleApprover), entry.ID.String())
+}
+
+// hasVerifiedPayment determines whether the payment held on the provided Entry has been verified with the PaymentProvider,
+// either when its order was captured or by a webhook event that was applied to it
+func (p *PaymentAgent) hasVerifiedPayment(ctx context.Context, entry Entry) (bool, error) {
+	events, err := p.per.Select(ctx, map[string]interface{}{
+		"realm_name":  entry.RealmName,
+		"event_type":  PaymentEventTypeCaptureCompleted,
+		"payment_ref": *entry.PaymentRef,
+	}, false)
+	if err != nil {
+		if errors.As(err, &MissingDBRecordError{}) {
+			return false, nil
+		}
+		return false, domainErrorFromRepositoryError(err)
+	}
+
+	for _, event := range events {
+		if event.EntryID != "" && event.EntryID != entry.ID.String() {
+			continue
+		}
+		if event.IsProcessed() && (event.Outcome == paymentEventOutcomeEntryPaid || event.Outcome == paymentEventOutcomeEntryApproved) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// RetrievePaidAmounts retrieves the amount that has actually been paid for each of the provided Entries, keyed by Entry ID.
+// A payment captured by the PaymentProvider is reported as the amount that was captured, while an Entry that has been
+// marked as paid by other means is reported as the entry fee of its realm. Entries for which nothing is currently held,
+// including those whose payment has been refunded or disputed, are omitted
+func (p *PaymentAgent) RetrievePaidAmounts(ctx context.Context, entries []Entry) (map[string]float32, error) {
+	amounts := make(map[string]float32)
+	capturedByRealm := make(map[string]map[string]float32)
+
+	for _, entry := range entries {
+		if !HasAdminRoleForRealm(ctx, entry.RealmName, AdminRoleViewer) {
+			return nil, UnauthorizedError{}
+		}
+
+		switch entry.Status {
+		case EntryStatusPending, EntryStatusWaitlisted, EntryStatusExpired, EntryStatusRefunded, EntryStatusDisputed:
+			continue
+		}
+
+		captured, ok := capturedByRealm[entry.RealmName]
+		if !ok {
+			var err error
+			if captured, err = p.retrieveCapturedAmounts(ctx, entry.RealmName); err != nil {
+				return nil, err
+			}
+			capturedByRealm[entry.RealmName] = captured
+		}
+
+		if amount, ok := captured[entry.ID.String()]; ok {
+			amounts[entry.ID.String()] = amount
+			continue
+		}
+		if entry.PaymentRef != nil {
+			if amount, ok := captured[*entry.PaymentRef]; ok {
+				amounts[entry.ID.String()] = amount
+				continue
+			}
+		}
+
+		if entry.Status != EntryStatusPaid {
+			// entry was withdrawn or disqualified without a captured payment, so there is no record of one being made
+			continue
+		}
+
+		realm, err := p.rc.GetByName(entry.RealmName)
+		if err != nil {
+			return nil, NotFoundError{fmt.Errorf("cannot get realm with id '%s': %w", entry.RealmName, err)}
+		}
+		amounts[entry.ID.String()] = realm.EntryFee.Amount
+	}
+
+	return amounts, nil
+}
+
+// retrieveCapturedAmounts retrieves the amounts of the payments captured by the PaymentProvider that have been applied
+// to entries of the provided realm, keyed by both Entry ID and payment reference
+func (p *PaymentAgent) retrieveCapturedAmounts(ctx context.Context, realmName string) (map[string]float32, error) {
+	events, err := p.per.Select(ctx, map[string]interface{}{
+		"realm_name": realmName,
+		"event_type": PaymentEventTypeCaptureCompleted,
+	}, false)
+	if err != nil {
+		if errors.As(err, &MissingDBRecordError{}) {
+			return map[string]float32{}, nil
+		}
+		return nil, domainErrorFromRepositoryError(err)
+	}
+
+	captured := make(map[string]float32)
+	for _, event := range events {
+		if !event.IsProcessed() || (event.Outcome != paymentEventOutcomeEntryPaid && event.Outcome != paymentEventOutcomeEntryApproved) {
+			continue
+		}
+		if event.EntryID != "" {
+			captured[event.EntryID] = event.Amount
+		}
+		if event.PaymentRef != "" {
+			captured[event.PaymentRef] = event.Amount
+		}
+	}
+
+	return captured, nil
+}
+
+// approveVerifiedPayment records the provided payment against the provided Entry and approves it, as long as its email address has been verified.
+// The payment must already have been verified with the provider, so the system can approve the entry on behalf of an admin
+func (p *PaymentAgent) approveVerifiedPayment(ctx context.Context, entry Entry, payment VerifiedPayment) (Entry, error) {
+	entry, err := p.ea.UpdateEntryPaymentDetails(ctx, entry.ID.String(), p.pp.PaymentMethod(), payment.Reference, true)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	// the payment still stands, but the entry is only approved once its email address has been verified
+	if !entry.IsEmailVerified() {
+		return entry, nil
+	}
+
+	return p.ea.ApproveEntryByID(SetSystemAdminRoleOnContext(ctx, AdminRoleApprover), entry.ID.String())
+}
+
+// NewPaymentAgent returns a new PaymentAgent using the provided agents, PaymentProvider, repository and realms
+func NewPaymentAgent(ea *EntryAgent, wa *WaitlistAgent, pp PaymentProvider, per PaymentEventRepository, rc RealmCollection, cl Clock) (*PaymentAgent, error) {
+	switch {
+	case ea == nil:
+		return nil, fmt.Errorf("entry agent: %w", ErrIsNil)
+	case wa == nil:
+		return nil, fmt.Errorf("waitlist agent: %w", ErrIsNil)
+	case pp == nil:
+		return nil, fmt.Errorf("payment provider: %w", ErrIsNil)
+	case per == nil:
+		return nil, fmt.Errorf("payment event repository: %w", ErrIsNil)
+	case rc == nil:
+		return nil, fmt.Errorf("realm collection: %w", ErrIsNil)
+	case cl == nil:
+		return nil, fmt.Errorf("clock: %w", ErrIsNil)
+	}
+	return &PaymentAgent{ea: ea, wa: wa, pp: pp, per: per, rc: rc, cl: cl}, nil
+}
+
+// StubPaymentProvider implements PaymentProvider by holding orders in memory and capturing them without taking any payment.
+// It is intended for local development and testing only
+type StubPaymentProvider struct {
+	mu            sync.Mutex
+	orders        map[string]PaymentOrder
+	webhookSecret string
+	l             Logger
+}
+
+// PaymentMethod implements PaymentProvider
+func (s *StubPaymentProvider) PaymentMethod() string {
+	return EntryPaymentMethodOther
+}
+
+// CreateOrder implements PaymentProvider
+func (s *StubPaymentProvider) CreateOrder(_ context.Context, entry Entry, fee RealmEntryFee) (PaymentOrder, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return PaymentOrder{}, err
+	}
+
+	order := PaymentOrder{
+		ID:       "STUB-" + strings.ToUpper(id.String()),
+		EntryID:  entry.ID.String(),
+		Amount:   fee.Amount,
+		Currency: fee.Currency,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orders[order.ID] = order
+
+	s.l.Debugf("stub created payment order %s for entry: %s", order.ID, order.EntryID)
+
+	return order, nil
+}
+
+// RetrieveOrder implements PaymentProvider
+func (s *StubPaymentProvider) RetrieveOrder(_ context.Context, orderID string) (PaymentOrder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[orderID]
+	if !ok {
+		return PaymentOrder{}, fmt.Errorf("order id '%s': not found", orderID)
+	}
+
+	return order, nil
+}
+
+// CaptureOrder implements PaymentProvider
+func (s *StubPaymentProvider) CaptureOrder(_ context.Context, orderID string) (VerifiedPayment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[orderID]
+	if !ok {
+		return VerifiedPayment{}, fmt.Errorf("order id '%s': not found", orderID)
+	}
+
+	// an order can only be captured once
+	delete(s.orders, orderID)
+
+	s.l.Debugf("stub captured payment order %s for entry: %s", order.ID, order.EntryID)
+
+	return VerifiedPayment{
+		OrderID:      order.ID,
+		EntryID:      order.EntryID,
+		Reference:    order.ID + "-CAPTURE",
+		Amount:       order.Amount,
+		Currency:     order.Currency,
+		MerchantName: "*STUB PAYMENT PROVIDER",
+	}, nil
+}
+
+// VerifyWebhookEvent implements PaymentProvider. The provided body must be signed with the stub's webhook secret,
+// as a hex-encoded HMAC-SHA256 within the StubWebhookSignatureHeader header
+func (s *StubPaymentProvider) VerifyWebhookEvent(_ context.Context, header map[string][]string, body []byte) (PaymentEvent, error) {
+	if s.webhookSecret == "" {
+		return PaymentEvent{}, errors.New("webhook secret is empty")
+	}
+
+	sig, err := hex.DecodeString(http.Header(header).Get(StubWebhookSignatureHeader))
+	if err != nil {
+		return PaymentEvent{}, fmt.Errorf("cannot decode signature: %w", err)
+	}
+
+	if !hmac.Equal(sig, SignStubWebhookPayload(s.webhookSecret, body)) {
+		return PaymentEvent{}, errors.New("invalid signature")
+	}
+
+	var payload stubWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return PaymentEvent{}, fmt.Errorf("cannot unmarshal payload: %w", err)
+	}
+
+	if payload.ID == "" {
+		return PaymentEvent{}, errors.New("event id is empty")
+	}
+
+	s.l.Debugf("stub verified webhook event %s: %s", payload.ID, payload.EventType)
+
+	return PaymentEvent{
+		ID:                payload.ID,
+		Type:              payload.EventType,
+		ProviderEventType: payload.EventType,
+		EntryID:           payload.EntryID,
+		PaymentRef:        payload.PaymentRef,
+		Amount:            payload.Amount,
+		Currency:          payload.Currency,
+	}, nil
+}
+
+// NewStubPaymentProvider returns a new StubPaymentProvider using the provided webhook secret and logger.
+// An empty webhook secret results in every webhook event being rejected
+func NewStubPaymentProvider(webhookSecret string, l Logger) (*StubPaymentProvider, error) {
+	if l == nil {
+		return nil, fmt.Errorf("logger: %w", ErrIsNil)
+	}
+	return &StubPaymentProvider{orders: make(map[string]PaymentOrder), webhookSecret: webhookSecret, l: l}, nil
+}
+
+// StubWebhookSignatureHeader is the header that carries the signature of a webhook event sent to the StubPaymentProvider
+const StubWebhookSignatureHeader = "X-Stub-Signature"
+
+// SignStubWebhookPayload returns the signature of the provided body, as expected by a StubPaymentProvider
+// that uses the provided webhook secret
+func SignStubWebhookPayload(webhookSecret string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(webhookSecret))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// stubWebhookPayload defines the payload structure of a webhook event sent to the StubPaymentProvider
+type stubWebhookPayload struct {
+	ID         string  `json:"id"`
+	EventType  string  `json:"event_type"`
+	EntryID    string  `json:"entry_id"`
+	PaymentRef string  `json:"payment_ref"`
+	Amount     float32 `json:"amount"`
+	Currency   string  `json:"currency"`
+}
+
+// verifyOrderForEntry ensures that the provided order was raised on behalf of the provided Entry
+// and covers the provided entry fee in full
+func verifyOrderForEntry(order PaymentOrder, entry Entry, fee RealmEntryFee) error {
+	if order.EntryID != entry.ID.String() {
+		return ConflictError{fmt.Errorf("order was raised for a different entry: %s", order.EntryID)}
+	}
+
+	return verifyAmountForEntryFee(order.Amount, order.Currency, fee)
+}
+
+// verifyPaymentForEntry ensures that the provided payment was made on behalf of the provided Entry
+// and covers the provided entry fee in full
+func verifyPaymentForEntry(payment VerifiedPayment, entry Entry, fee RealmEntryFee) error {
+	if payment.EntryID != entry.ID.String() {
+		return ConflictError{fmt.Errorf("payment was made for a different entry: %s", payment.EntryID)}
+	}
+
+	if payment.Reference == "" {
+		return ConflictError{errors.New("payment has no reference")}
+	}
+
+	return verifyAmountForEntryFee(payment.Amount, payment.Currency, fee)
+}
+
+// verifyAmountForEntryFee ensures that the provided amount and currency cover the provided entry fee in full
+func verifyAmountForEntryFee(amount float32, currency string, fee RealmEntryFee) error {
+	if fee.Currency != "" && !strings.EqualFold(currency, fee.Currency) {
+		return ConflictError{fmt.Errorf("payment currency %s does not match entry fee currency %s", currency, fee.Currency)}
+	}
+
+	// compare amounts in minor units to avoid floating point inaccuracies
+	if toMinorUnits(amount) < toMinorUnits(fee.Amount) {
+		return ConflictError{fmt.Errorf("payment amount %.2f is less than entry fee %.2f", amount, fee.Amount)}
+	}
+
+	return nil
+}
+
+// toMinorUnits converts the provided amount to its equivalent in minor currency units (e.g. pence)
+func toMinorUnits(amount float32) int64 {
+	return int64(math.Round(float64(amount) * 100))
+}
@@ -0,0 +1,240 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// PaymentEventTypeCaptureCompleted represents a payment that has been captured after a delay
+	PaymentEventTypeCaptureCompleted = "capture_completed"
+	// PaymentEventTypeCaptureRefunded represents a captured payment that has been refunded or reversed
+	PaymentEventTypeCaptureRefunded = "capture_refunded"
+	// PaymentEventTypeDisputeOpened represents a dispute that has been raised against a captured payment
+	PaymentEventTypeDisputeOpened = "dispute_opened"
+	// PaymentEventTypeDisputeWon represents a dispute that has been resolved in the merchant's favour, or cancelled
+	PaymentEventTypeDisputeWon = "dispute_won"
+	// PaymentEventTypeDisputeLost represents a dispute that has been resolved in the payer's favour
+	PaymentEventTypeDisputeLost = "dispute_lost"
+	// PaymentEventTypeUnhandled represents any other event raised by a payment provider
+	PaymentEventTypeUnhandled = "unhandled"
+)
+
+const (
+	// paymentEventOutcomeEntryPaid represents a PaymentEvent whose verified payment has been recorded against an Entry
+	// that is awaiting email verification before it can be approved
+	paymentEventOutcomeEntryPaid = "entry paid: awaiting email verification"
+	// paymentEventOutcomeEntryApproved represents a PaymentEvent that has resulted in an Entry being approved
+	paymentEventOutcomeEntryApproved = "entry approved"
+)
+
+// PaymentEvent represents an asynchronous event that has been raised by a PaymentProvider
+type PaymentEvent struct {
+	ID                string          `db:"id"`
+	RealmName         string          `db:"realm_name"`
+	Type              string          `db:"event_type"`
+	ProviderEventType string          `db:"provider_event_type"`
+	EntryID           string          `db:"entry_id"`
+	PaymentRef        string          `db:"payment_ref"`
+	Amount            float32         `db:"amount"`
+	Currency          string          `db:"currency"`
+	Payload           json.RawMessage `db:"payload"`
+	Outcome           string          `db:"outcome"`
+	ReceivedAt        time.Time       `db:"received_at"`
+	ProcessedAt       *time.Time      `db:"processed_at"`
+}
+
+// IsProcessed determines whether the PaymentEvent has already been applied
+func (p *PaymentEvent) IsProcessed() bool {
+	return p.ProcessedAt != nil
+}
+
+// PaymentEventRepository defines the interface for transacting with our PaymentEvent data source
+type PaymentEventRepository interface {
+	Insert(ctx context.Context, event *PaymentEvent) error
+	Update(ctx context.Context, event *PaymentEvent) error
+	Delete(ctx context.Context, event *PaymentEvent) error
+	Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]PaymentEvent, error)
+}
+
+// ProcessWebhookEvent verifies the provided webhook request with the PaymentProvider and applies the resulting
+// PaymentEvent to the Entry that it refers to. The provider sends the events of every realm to the same webhook,
+// so each event belongs to the realm of its Entry rather than the realm that received it. Each event is stored before
+// it is applied, so that an event which is delivered more than once, even concurrently, is only applied once. An event
+// that fails to be applied is removed again and an error returned, so that the provider delivers it again
+func (p *PaymentAgent) ProcessWebhookEvent(ctx context.Context, header map[string][]string, body []byte) (PaymentEvent, error) {
+	event, err := p.pp.VerifyWebhookEvent(ctx, header, body)
+	if err != nil {
+		return PaymentEvent{}, UnauthorizedError{fmt.Errorf("cannot verify webhook event: %w", err)}
+	}
+
+	entry, err := p.retrieveEntryForPaymentEvent(ctx, event)
+	entryFound := err == nil
+	if err != nil && !errors.As(err, &NotFoundError{}) {
+		return PaymentEvent{}, err
+	}
+
+	if entryFound {
+		// an event whose realm cannot be determined fails without being stored, so that the provider delivers it again
+		realm, err := p.rc.GetByName(entry.RealmName)
+		if err != nil {
+			return PaymentEvent{}, InternalError{fmt.Errorf("cannot get realm of entry: %w", err)}
+		}
+		ctx = contextWithRealm(ctx, realm)
+	}
+
+	event.RealmName = RealmFromContext(ctx).Config.Name
+	event.Payload = body
+	event.ReceivedAt = p.cl.Now().Truncate(time.Second)
+
+	// inserting the event first means that of several concurrent deliveries of the same event, only one is stored
+	if err := p.per.Insert(ctx, &event); err != nil {
+		if !errors.As(err, &DuplicateDBRecordError{}) {
+			return PaymentEvent{}, domainErrorFromRepositoryError(err)
+		}
+
+		// event has already been applied, or is being applied by a concurrent delivery
+		existing, err := p.per.Select(ctx, map[string]interface{}{
+			"realm_name": event.RealmName,
+			"id":         event.ID,
+		}, false)
+		if err != nil {
+			return PaymentEvent{}, domainErrorFromRepositoryError(err)
+		}
+
+		return existing[0], nil
+	}
+
+	var outcome string
+	switch {
+	case event.Type == PaymentEventTypeUnhandled:
+		outcome = "ignored: unhandled event type"
+	case !entryFound:
+		outcome = "ignored: entry not found"
+	default:
+		if outcome, err = p.applyPaymentEvent(ctx, event, entry); err != nil {
+			if delErr := p.per.Delete(ctx, &event); delErr != nil {
+				return PaymentEvent{}, InternalError{fmt.Errorf("cannot remove event that failed to be applied (%s): %w", err.Error(), delErr)}
+			}
+			return PaymentEvent{}, err
+		}
+	}
+
+	ts := p.cl.Now().Truncate(time.Second)
+	event.Outcome = outcome
+	event.ProcessedAt = &ts
+
+	if err := p.per.Update(ctx, &event); err != nil {
+		return PaymentEvent{}, domainErrorFromRepositoryError(err)
+	}
+
+	return event, nil
+}
+
+// applyPaymentEvent changes the status of the provided Entry, which the provided PaymentEvent refers to, and returns
+// a description of the outcome. Events that cannot be applied to the Entry are ignored rather than returning an error,
+// so that the provider does not attempt to deliver them again
+func (p *PaymentAgent) applyPaymentEvent(ctx context.Context, event PaymentEvent, entry Entry) (string, error) {
+	// the provider has raised the event, so the system can change the entry on behalf of an admin
+	systemCtx := SetSystemAdminRoleOnContext(ctx, AdminRoleApprover)
+
+	switch event.Type {
+	case PaymentEventTypeCaptureCompleted:
+		if entry.Status != EntryStatusPending {
+			return fmt.Sprintf("ignored: entry status is %s", entry.Status), nil
+		}
+
+		payment := VerifiedPayment{
+			EntryID:   event.EntryID,
+			Reference: event.PaymentRef,
+			Amount:    event.Amount,
+			Currency:  event.Currency,
+		}
+		if payment.EntryID == "" {
+			payment.EntryID = entry.ID.String()
+		}
+
+		if err := verifyPaymentForEntry(payment, entry, RealmFromContext(ctx).EntryFee); err != nil {
+			return fmt.Sprintf("rejected: %s", err.Error()), nil
+		}
+
+		entry, err := p.approveVerifiedPayment(ctx, entry, payment)
+		if err != nil {
+			return "", err
+		}
+		if !entry.IsApproved() {
+			return paymentEventOutcomeEntryPaid, nil
+		}
+
+		return paymentEventOutcomeEntryApproved, nil
+
+	case PaymentEventTypeCaptureRefunded, PaymentEventTypeDisputeLost:
+		return p.updateEntryPaymentStatus(systemCtx, entry, EntryStatusRefunded)
+
+	case PaymentEventTypeDisputeOpened:
+		return p.updateEntryPaymentStatus(systemCtx, entry, EntryStatusDisputed)
+
+	case PaymentEventTypeDisputeWon:
+		if entry.Status != EntryStatusDisputed {
+			return fmt.Sprintf("ignored: entry status is %s", entry.Status), nil
+		}
+
+		// the place the entry gave up when the dispute was opened may have been given to a waitlisted entry since
+		if _, err := p.wa.UpdateEntryPaymentStatusByID(systemCtx, entry.ID.String(), EntryStatusPaid); err != nil {
+			if errors.Is(err, ErrEntryCapReached) {
+				return "flagged: entry cap reached, so entry cannot regain its place", nil
+			}
+			return "", err
+		}
+		if !entry.IsEmailVerified() {
+			return paymentEventOutcomeEntryPaid, nil
+		}
+		if _, err := p.ea.ApproveEntryByID(systemCtx, entry.ID.String()); err != nil {
+			return "", err
+		}
+
+		return paymentEventOutcomeEntryApproved, nil
+	}
+
+	return fmt.Sprintf("ignored: unknown event type %s", event.Type), nil
+}
+
+// updateEntryPaymentStatus sets the provided payment status on the provided Entry, unless the entry cannot hold it,
+// and gives the place it gives up to the next Entry on the waitlist
+func (p *PaymentAgent) updateEntryPaymentStatus(ctx context.Context, entry Entry, status string) (string, error) {
+	if !isPaymentEntryStatus(entry.Status) || entry.Status == status {
+		return fmt.Sprintf("ignored: entry status is %s", entry.Status), nil
+	}
+
+	if _, err := p.wa.UpdateEntryPaymentStatusByID(ctx, entry.ID.String(), status); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("entry %s", status), nil
+}
+
+// retrieveEntryForPaymentEvent retrieves the Entry referred to by the provided PaymentEvent, whichever realm it belongs to,
+// by its ID if the provider supplied one, otherwise by its payment reference
+func (p *PaymentAgent) retrieveEntryForPaymentEvent(ctx context.Context, event PaymentEvent) (Entry, error) {
+	criteria := map[string]interface{}{"id": event.EntryID}
+	if event.EntryID == "" {
+		if event.PaymentRef == "" {
+			return Entry{}, NotFoundError{errors.New("payment event does not refer to an entry")}
+		}
+		criteria = map[string]interface{}{"payment_ref": event.PaymentRef}
+	}
+
+	entries, err := p.ea.er.Select(ctx, criteria, false)
+	if err != nil {
+		return Entry{}, domainErrorFromRepositoryError(err)
+	}
+
+	if len(entries) != 1 {
+		return Entry{}, InternalError{fmt.Errorf("entries count other than 1: %d", len(entries))}
+	}
+
+	return entries[0], nil
+}
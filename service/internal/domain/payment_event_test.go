@@ -0,0 +1,442 @@
+package domain_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"prediction-league/service/internal/domain"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert/cmp"
+)
+
+func TestPaymentAgent_ProcessWebhookEvent(t *testing.T) {
+	t.Cleanup(truncate)
+
+	ea, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	agent, err := domain.NewPaymentAgent(ea, newTestWaitlistAgent(t, ea), newTestStubPaymentProvider(t), per, rc, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pendingEntry := insertEntry(t, generateTestEntry(t,
+		"Harry Redknapp",
+		"MrHarryR",
+		"harry.redknapp@football.net",
+	))
+
+	refundedEntry := insertPaidTestEntry(t, "Jamie Redknapp", "MrJamieR", "jamie.redknapp@football.net", "CAPTURE-REFUNDED")
+	disputedEntry := insertPaidTestEntry(t, "Frank Lampard", "FrankieL", "frank.lampard@football.net", "CAPTURE-DISPUTED")
+	otherRealmEntry := insertPaidTestEntry(t, "Steven Gerrard", "StevieG", "steven.gerrard@football.net", "CAPTURE-OTHER-REALM")
+
+	t.Run("event with invalid signature must fail", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		defer cancel()
+
+		hdr, body := signTestStubWebhookEvent(t, map[string]interface{}{
+			"id":          "EVT-FORGED",
+			"event_type":  domain.PaymentEventTypeCaptureRefunded,
+			"payment_ref": "CAPTURE-REFUNDED",
+		})
+		hdr.Set(domain.StubWebhookSignatureHeader, hex.EncodeToString([]byte("not_the_signature")))
+
+		_, err := agent.ProcessWebhookEvent(ctx, hdr, body)
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+
+		// forged event must not have been stored
+		if _, err := per.Select(ctx, map[string]interface{}{"id": "EVT-FORGED"}, false); err == nil {
+			expectedNonEmpty(t, "missing record error")
+		}
+	})
+
+	t.Run("refund event must mark paid entry as refunded and revoke its approval", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		defer cancel()
+
+		hdr, body := signTestStubWebhookEvent(t, map[string]interface{}{
+			"id":          "EVT-REFUND",
+			"event_type":  domain.PaymentEventTypeCaptureRefunded,
+			"payment_ref": "CAPTURE-REFUNDED",
+		})
+
+		event, err := agent.ProcessWebhookEvent(ctx, hdr, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.Outcome != "entry refunded" {
+			expectedGot(t, "entry refunded", event.Outcome)
+		}
+
+		entry := mustRetrieveEntryByID(t, ctx, refundedEntry.ID.String())
+		if entry.Status != domain.EntryStatusRefunded {
+			expectedGot(t, domain.EntryStatusRefunded, entry.Status)
+		}
+		if entry.IsApproved() {
+			expectedGot(t, "approved entry false", "approved entry true")
+		}
+	})
+
+	t.Run("event received by another realm must be applied within the realm of its entry", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		defer cancel()
+
+		// webhook is served by another realm's host
+		domain.RealmFromContext(ctx).Config.Name = "OTHER_REALM"
+
+		hdr, body := signTestStubWebhookEvent(t, map[string]interface{}{
+			"id":          "EVT-REFUND-OTHER-REALM",
+			"event_type":  domain.PaymentEventTypeCaptureRefunded,
+			"payment_ref": "CAPTURE-OTHER-REALM",
+		})
+
+		event, err := agent.ProcessWebhookEvent(ctx, hdr, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.RealmName != testRealmName {
+			expectedGot(t, testRealmName, event.RealmName)
+		}
+		if event.Outcome != "entry refunded" {
+			expectedGot(t, "entry refunded", event.Outcome)
+		}
+
+		entry := mustRetrieveEntryByID(t, ctx, otherRealmEntry.ID.String())
+		if entry.Status != domain.EntryStatusRefunded {
+			expectedGot(t, domain.EntryStatusRefunded, entry.Status)
+		}
+	})
+
+	t.Run("redelivered event must not be applied again", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		defer cancel()
+
+		hdr, body := signTestStubWebhookEvent(t, map[string]interface{}{
+			"id":          "EVT-REFUND",
+			"event_type":  domain.PaymentEventTypeCaptureRefunded,
+			"payment_ref": "CAPTURE-REFUNDED",
+		})
+
+		event, err := agent.ProcessWebhookEvent(ctx, hdr, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.Outcome != "entry refunded" {
+			expectedGot(t, "entry refunded", event.Outcome)
+		}
+
+		records, err := ar.Select(ctx, map[string]interface{}{
+			"action":    domain.AuditActionEntryPaymentStatusChanged,
+			"target_id": refundedEntry.ID.String(),
+		}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 1 {
+			expectedGot(t, 1, len(records))
+		}
+	})
+
+	t.Run("dispute events must suspend and then restore entry", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		defer cancel()
+
+		hdr, body := signTestStubWebhookEvent(t, map[string]interface{}{
+			"id":          "EVT-DISPUTE-OPENED",
+			"event_type":  domain.PaymentEventTypeDisputeOpened,
+			"payment_ref": "CAPTURE-DISPUTED",
+		})
+		if _, err := agent.ProcessWebhookEvent(ctx, hdr, body); err != nil {
+			t.Fatal(err)
+		}
+
+		entry := mustRetrieveEntryByID(t, ctx, disputedEntry.ID.String())
+		if entry.Status != domain.EntryStatusDisputed {
+			expectedGot(t, domain.EntryStatusDisputed, entry.Status)
+		}
+		if entry.IsApproved() {
+			expectedGot(t, "approved entry false", "approved entry true")
+		}
+
+		hdr, body = signTestStubWebhookEvent(t, map[string]interface{}{
+			"id":          "EVT-DISPUTE-WON",
+			"event_type":  domain.PaymentEventTypeDisputeWon,
+			"payment_ref": "CAPTURE-DISPUTED",
+		})
+		if _, err := agent.ProcessWebhookEvent(ctx, hdr, body); err != nil {
+			t.Fatal(err)
+		}
+
+		entry = mustRetrieveEntryByID(t, ctx, disputedEntry.ID.String())
+		if entry.Status != domain.EntryStatusPaid {
+			expectedGot(t, domain.EntryStatusPaid, entry.Status)
+		}
+		if !entry.IsApproved() {
+			expectedGot(t, "approved entry true", "approved entry false")
+		}
+	})
+
+	t.Run("delayed capture for less than entry fee must be rejected", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		defer cancel()
+
+		hdr, body := signTestStubWebhookEvent(t, map[string]interface{}{
+			"id":          "EVT-CAPTURE-SHORT",
+			"event_type":  domain.PaymentEventTypeCaptureCompleted,
+			"entry_id":    pendingEntry.ID.String(),
+			"payment_ref": "CAPTURE-SHORT",
+			"amount":      1.23,
+			"currency":    "GBP",
+		})
+
+		event, err := agent.ProcessWebhookEvent(ctx, hdr, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !event.IsProcessed() {
+			expectedNonEmpty(t, "PaymentEvent.ProcessedAt")
+		}
+
+		entry := mustRetrieveEntryByID(t, ctx, pendingEntry.ID.String())
+		if entry.Status != domain.EntryStatusPending {
+			expectedGot(t, domain.EntryStatusPending, entry.Status)
+		}
+	})
+
+	t.Run("delayed capture must mark pending entry as paid and approve it", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		defer cancel()
+
+		hdr, body := signTestStubWebhookEvent(t, map[string]interface{}{
+			"id":          "EVT-CAPTURE",
+			"event_type":  domain.PaymentEventTypeCaptureCompleted,
+			"entry_id":    pendingEntry.ID.String(),
+			"payment_ref": "CAPTURE-DELAYED",
+			"amount":      12.34,
+			"currency":    "GBP",
+		})
+
+		event, err := agent.ProcessWebhookEvent(ctx, hdr, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.Outcome != "entry approved" {
+			expectedGot(t, "entry approved", event.Outcome)
+		}
+
+		entry := mustRetrieveEntryByID(t, ctx, pendingEntry.ID.String())
+		if entry.Status != domain.EntryStatusPaid {
+			expectedGot(t, domain.EntryStatusPaid, entry.Status)
+		}
+		wantPaymentRef := "CAPTURE-DELAYED"
+		checkStringPtrMatch(t, &wantPaymentRef, entry.PaymentRef)
+		if !entry.IsApproved() {
+			expectedGot(t, "approved entry true", "approved entry false")
+		}
+	})
+
+	t.Run("event for unknown entry must be ignored", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		defer cancel()
+
+		hdr, body := signTestStubWebhookEvent(t, map[string]interface{}{
+			"id":          "EVT-UNKNOWN",
+			"event_type":  domain.PaymentEventTypeCaptureRefunded,
+			"payment_ref": "CAPTURE-UNKNOWN",
+		})
+
+		event, err := agent.ProcessWebhookEvent(ctx, hdr, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.Outcome != "ignored: entry not found" {
+			expectedGot(t, "ignored: entry not found", event.Outcome)
+		}
+	})
+}
+
+func TestPaymentAgent_ProcessWebhookEvent_EntryCap(t *testing.T) {
+	t.Cleanup(truncate)
+
+	now := time.Now().Truncate(time.Second)
+
+	season := testSeason
+	season.EntriesAccepted.From = now.Add(-24 * time.Hour)
+	season.EntriesAccepted.Until = now.Add(24 * time.Hour)
+
+	cl := &mockClock{t: now}
+
+	ea, err := domain.NewEntryAgent(er, epr, sr, domain.SeasonCollection{season.ID: season}, aa, cl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ta, err := domain.NewTokenAgent(tr, aa, cl, &mockLogger{}, testTokenHashKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ei := &mockWaitlistPromotionEmailIssuer{}
+
+	wa, err := domain.NewWaitlistAgent(ea, ta, ei, newMockLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// realm only has room for the disputed entry until its dispute is opened
+	cappedRealm := realm
+	cappedRealm.Config.EntryCap = 1
+
+	agent, err := domain.NewPaymentAgent(ea, wa, newTestStubPaymentProvider(t), per, domain.RealmCollection{cappedRealm}, cl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	disputedEntry := insertPaidTestEntry(t, "Frank Lampard", "FrankieL", "frank.lampard@football.net", "CAPTURE-DISPUTED")
+
+	waitlistedEntry := generateTestEntry(t, "Jamie Redknapp", "MrJamieR", "jamie.redknapp@football.net")
+	waitlistedEntry.Status = domain.EntryStatusWaitlisted
+	waitlistedEntry = insertEntry(t, waitlistedEntry)
+
+	t.Run("dispute opened must give the place of the disputed entry to the next waitlisted entry", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		hdr, body := signTestStubWebhookEvent(t, map[string]interface{}{
+			"id":          "EVT-CAP-DISPUTE-OPENED",
+			"event_type":  domain.PaymentEventTypeDisputeOpened,
+			"payment_ref": "CAPTURE-DISPUTED",
+		})
+		if _, err := agent.ProcessWebhookEvent(ctx, hdr, body); err != nil {
+			t.Fatal(err)
+		}
+
+		entry := mustRetrieveEntryByID(t, ctx, waitlistedEntry.ID.String())
+		if entry.Status != domain.EntryStatusPending {
+			expectedGot(t, domain.EntryStatusPending, entry.Status)
+		}
+		if diff := cmp.DeepEqual([]string{waitlistedEntry.ID.String()}, ei.promoted)(); !diff.Success() {
+			expectedGot(t, []string{waitlistedEntry.ID.String()}, ei.promoted)
+		}
+	})
+
+	t.Run("dispute won after the place has been filled must be flagged and leave entry disputed", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		hdr, body := signTestStubWebhookEvent(t, map[string]interface{}{
+			"id":          "EVT-CAP-DISPUTE-WON",
+			"event_type":  domain.PaymentEventTypeDisputeWon,
+			"payment_ref": "CAPTURE-DISPUTED",
+		})
+		event, err := agent.ProcessWebhookEvent(ctx, hdr, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(event.Outcome, "flagged: ") {
+			expectedGot(t, "flagged outcome", event.Outcome)
+		}
+
+		entry := mustRetrieveEntryByID(t, ctx, disputedEntry.ID.String())
+		if entry.Status != domain.EntryStatusDisputed {
+			expectedGot(t, domain.EntryStatusDisputed, entry.Status)
+		}
+		if entry.IsApproved() {
+			expectedGot(t, "approved entry false", "approved entry true")
+		}
+	})
+}
+
+func TestStubPaymentProvider_VerifyWebhookEvent(t *testing.T) {
+	pp := newTestStubPaymentProvider(t)
+
+	payload := map[string]interface{}{
+		"id":          "EVT-1",
+		"event_type":  domain.PaymentEventTypeCaptureRefunded,
+		"payment_ref": "CAPTURE-1",
+	}
+
+	t.Run("signed payload must produce the expected event", func(t *testing.T) {
+		hdr, body := signTestStubWebhookEvent(t, payload)
+
+		event, err := pp.VerifyWebhookEvent(context.Background(), hdr, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantEvent := domain.PaymentEvent{
+			ID:                "EVT-1",
+			Type:              domain.PaymentEventTypeCaptureRefunded,
+			ProviderEventType: domain.PaymentEventTypeCaptureRefunded,
+			PaymentRef:        "CAPTURE-1",
+		}
+		cmpDiff(t, "payment event", wantEvent, event)
+	})
+
+	t.Run("payload signed with another secret must fail", func(t *testing.T) {
+		_, body := signTestStubWebhookEvent(t, payload)
+
+		hdr := http.Header{}
+		hdr.Set(domain.StubWebhookSignatureHeader, hex.EncodeToString(domain.SignStubWebhookPayload("not_the_secret", body)))
+
+		if _, err := pp.VerifyWebhookEvent(context.Background(), hdr, body); err == nil {
+			expectedNonEmpty(t, "error")
+		}
+	})
+
+	t.Run("unsigned payload must fail", func(t *testing.T) {
+		_, body := signTestStubWebhookEvent(t, payload)
+
+		if _, err := pp.VerifyWebhookEvent(context.Background(), http.Header{}, body); err == nil {
+			expectedNonEmpty(t, "error")
+		}
+	})
+}
+
+// signTestStubWebhookEvent marshals the provided payload and returns it alongside the headers
+// that carry its signature for the StubPaymentProvider used within the testsuite
+func signTestStubWebhookEvent(t *testing.T, payload map[string]interface{}) (http.Header, []byte) {
+	t.Helper()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hdr := http.Header{}
+	hdr.Set(domain.StubWebhookSignatureHeader, hex.EncodeToString(domain.SignStubWebhookPayload(testStubWebhookSecret, body)))
+
+	return hdr, body
+}
+
+// insertPaidTestEntry inserts a generated Entry that has been paid for with the provided reference and approved
+func insertPaidTestEntry(t *testing.T, entrantName, entrantNickname, entrantEmail, paymentRef string) domain.Entry {
+	t.Helper()
+
+	entry := generateTestEntry(t, entrantName, entrantNickname, entrantEmail)
+	entry.Status = domain.EntryStatusPaid
+	entry.PaymentRef = &paymentRef
+	entry.ApprovedAt = &testDate
+
+	return insertEntry(t, entry)
+}
+
+// mustRetrieveEntryByID retrieves the Entry with the provided ID directly from the repository
+func mustRetrieveEntryByID(t *testing.T, ctx context.Context, id string) domain.Entry {
+	t.Helper()
+
+	entries, err := er.Select(ctx, map[string]interface{}{"id": id}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return entries[0]
+}
@@ -0,0 +1,472 @@
+package domain_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"prediction-league/service/internal/adapters/logger"
+	"prediction-league/service/internal/domain"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert/cmp"
+)
+
+func TestNewPaymentAgent(t *testing.T) {
+	t.Run("passing invalid parameters must return expected error", func(t *testing.T) {
+		ea, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		wa := newTestWaitlistAgent(t, ea)
+		pp := newTestStubPaymentProvider(t)
+
+		cl := &mockClock{}
+
+		tt := []struct {
+			ea      *domain.EntryAgent
+			wa      *domain.WaitlistAgent
+			pp      domain.PaymentProvider
+			per     domain.PaymentEventRepository
+			rc      domain.RealmCollection
+			cl      domain.Clock
+			wantErr error
+		}{
+			{nil, wa, pp, per, rc, cl, domain.ErrIsNil},
+			{ea, nil, pp, per, rc, cl, domain.ErrIsNil},
+			{ea, wa, nil, per, rc, cl, domain.ErrIsNil},
+			{ea, wa, pp, nil, rc, cl, domain.ErrIsNil},
+			{ea, wa, pp, per, nil, cl, domain.ErrIsNil},
+			{ea, wa, pp, per, rc, nil, domain.ErrIsNil},
+			{ea, wa, pp, per, rc, cl, nil},
+		}
+		for idx, tc := range tt {
+			agent, gotErr := domain.NewPaymentAgent(tc.ea, tc.wa, tc.pp, tc.per, tc.rc, tc.cl)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("tc #%d: want error %s (%T), got %s (%T)", idx, tc.wantErr, tc.wantErr, gotErr, gotErr)
+			}
+			if tc.wantErr == nil && agent == nil {
+				t.Fatalf("tc #%d: want non-empty agent, got nil", idx)
+			}
+		}
+	})
+}
+
+func TestPaymentAgent_CapturePaymentOrder(t *testing.T) {
+	t.Cleanup(truncate)
+
+	ea, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pp := newTestStubPaymentProvider(t)
+
+	agent, err := domain.NewPaymentAgent(ea, newTestWaitlistAgent(t, ea), pp, per, rc, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := insertEntry(t, generateTestEntry(t,
+		"Harry Redknapp",
+		"MrHarryR",
+		"harry.redknapp@football.net",
+	))
+
+	otherEntry := insertEntry(t, generateTestEntry(t,
+		"Jamie Redknapp",
+		"MrJamieR",
+		"jamie.redknapp@football.net",
+	))
+
+	t.Run("capture order for another entry must fail", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		defer cancel()
+
+		order, err := agent.CreatePaymentOrder(ctx, otherEntry.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, _, err = agent.CapturePaymentOrder(ctx, entry.ID.String(), order.ID)
+		if !cmp.ErrorType(err, domain.ConflictError{})().Success() {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+
+		// order must not have been captured, so that nobody has been charged
+		if _, err := pp.RetrieveOrder(ctx, order.ID); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("capture order for less than entry fee must fail", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		defer cancel()
+
+		order, err := agent.CreatePaymentOrder(ctx, entry.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// entry fee has increased since order was created
+		ctx, cancel = testContextWithEntryFee(t, 20)
+		defer cancel()
+
+		_, _, err = agent.CapturePaymentOrder(ctx, entry.ID.String(), order.ID)
+		if !cmp.ErrorType(err, domain.ConflictError{})().Success() {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+	})
+
+	t.Run("capture order whose captured payment does not cover entry fee must record flagged payment", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		defer cancel()
+
+		flaggedEntry := insertEntry(t, generateTestEntry(t,
+			"Frank Lampard",
+			"FrankieL",
+			"frank.lampard@football.net",
+		))
+
+		order, err := agent.CreatePaymentOrder(ctx, flaggedEntry.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		shortAgent, err := domain.NewPaymentAgent(ea, newTestWaitlistAgent(t, ea), &shortCapturePaymentProvider{pp}, per, rc, &mockClock{t: testDate})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, _, err = shortAgent.CapturePaymentOrder(ctx, flaggedEntry.ID.String(), order.ID)
+		if !cmp.ErrorType(err, domain.ConflictError{})().Success() {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+
+		// captured payment must have been kept on record, without being applied to the entry
+		events, err := per.Select(ctx, map[string]interface{}{"entry_id": flaggedEntry.ID.String()}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != 1 {
+			expectedGot(t, 1, len(events))
+		}
+		if !strings.HasPrefix(events[0].Outcome, "flagged: ") {
+			expectedGot(t, "flagged outcome", events[0].Outcome)
+		}
+
+		gotEntry, err := ea.RetrieveEntryByID(ctx, flaggedEntry.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotEntry.Status != domain.EntryStatusPending {
+			expectedGot(t, domain.EntryStatusPending, gotEntry.Status)
+		}
+	})
+
+	t.Run("capture non-existent order must fail", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		defer cancel()
+
+		_, _, err := agent.CapturePaymentOrder(ctx, entry.ID.String(), "not_an_order")
+		if !cmp.ErrorType(err, domain.ConflictError{})().Success() {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+	})
+
+	t.Run("capture verified order must mark entry as paid and approve it", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		defer cancel()
+
+		order, err := agent.CreatePaymentOrder(ctx, entry.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		gotEntry, payment, err := agent.CapturePaymentOrder(ctx, entry.ID.String(), order.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if gotEntry.Status != domain.EntryStatusPaid {
+			expectedGot(t, domain.EntryStatusPaid, gotEntry.Status)
+		}
+		if gotEntry.PaymentRef == nil || *gotEntry.PaymentRef != payment.Reference {
+			expectedGot(t, payment.Reference, gotEntry.PaymentRef)
+		}
+		if !gotEntry.IsApproved() {
+			expectedGot(t, "approved entry true", "approved entry false")
+		}
+
+		// approval must have been audited as having been performed by the system
+		records, err := ar.Select(ctx, map[string]interface{}{
+			"action":    domain.AuditActionEntryApproved,
+			"target_id": entry.ID.String(),
+		}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if records[0].ActorType != domain.AuditActorTypeSystem {
+			expectedGot(t, domain.AuditActorTypeSystem, records[0].ActorType)
+		}
+
+		// system role must not leak onto the original context
+		if domain.HasAdminRole(ctx, domain.AdminRoleApprover) {
+			expectedGot(t, false, true)
+		}
+	})
+
+	t.Run("create order for entry that has already been paid must fail", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		defer cancel()
+
+		_, err := agent.CreatePaymentOrder(ctx, entry.ID.String())
+		if !cmp.ErrorType(err, domain.ConflictError{})().Success() {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+	})
+}
+
+func TestPaymentAgent_VerifyEntryEmailByID(t *testing.T) {
+	t.Cleanup(truncate)
+
+	ea, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	agent, err := domain.NewPaymentAgent(ea, newTestWaitlistAgent(t, ea), newTestStubPaymentProvider(t), per, rc, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("verify email of entry with captured payment must approve entry", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		defer cancel()
+
+		entry := generateUnverifiedTestEntry(t, "MrHarryR", "harry.redknapp@football.net")
+
+		order, err := agent.CreatePaymentOrder(ctx, entry.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		paidEntry, _, err := agent.CapturePaymentOrder(ctx, entry.ID.String(), order.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if paidEntry.IsApproved() {
+			expectedGot(t, "approved entry false", "approved entry true")
+		}
+
+		verifiedEntry, err := agent.VerifyEntryEmailByID(ctx, entry.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !verifiedEntry.IsEmailVerified() {
+			expectedGot(t, "email verified true", "email verified false")
+		}
+		if !verifiedEntry.IsApproved() {
+			expectedGot(t, "approved entry true", "approved entry false")
+		}
+	})
+
+	t.Run("verify email of entry with unverified paypal payment details must not approve entry", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		defer cancel()
+
+		entry := generateUnverifiedTestEntry(t, "MrJamieR", "jamie.redknapp@football.net")
+
+		// payment details provided by the entrant themselves
+		if _, err := ea.UpdateEntryPaymentDetails(ctx, entry.ID.String(), domain.EntryPaymentMethodPayPal, "NOT-A-REAL-CAPTURE", false); err != nil {
+			t.Fatal(err)
+		}
+
+		verifiedEntry, err := agent.VerifyEntryEmailByID(ctx, entry.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !verifiedEntry.IsEmailVerified() {
+			expectedGot(t, "email verified true", "email verified false")
+		}
+		if verifiedEntry.IsApproved() {
+			expectedGot(t, "approved entry false", "approved entry true")
+		}
+	})
+}
+
+func TestPaymentAgent_RetrievePaidAmounts(t *testing.T) {
+	t.Cleanup(truncate)
+
+	ea, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	agent, err := domain.NewPaymentAgent(ea, newTestWaitlistAgent(t, ea), newTestStubPaymentProvider(t), per, rc, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// generateEntryWithStatus inserts an Entry with the provided status
+	generateEntryWithStatus := func(t *testing.T, nickname, email, status string) domain.Entry {
+		t.Helper()
+
+		entry := generateTestEntry(t, "Harry Redknapp", nickname, email)
+		entry.Status = status
+
+		return insertEntry(t, entry)
+	}
+
+	ctx, cancel := testContextWithEntryFee(t, 12.34)
+	defer cancel()
+
+	capturedEntry := generateUnverifiedTestEntry(t, "MrHarryR", "harry.redknapp@football.net")
+	order, err := agent.CreatePaymentOrder(ctx, capturedEntry.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := agent.CapturePaymentOrder(ctx, capturedEntry.ID.String(), order.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	paidEntry := generateEntryWithStatus(t, "MrJamieR", "jamie.redknapp@football.net", domain.EntryStatusPaid)
+	pendingEntry := generateEntryWithStatus(t, "MrFrankL", "frank.lampard@football.net", domain.EntryStatusPending)
+	refundedEntry := generateEntryWithStatus(t, "MrFrankL2", "frank.lampard2@football.net", domain.EntryStatusRefunded)
+
+	entries := []domain.Entry{capturedEntry, paidEntry, pendingEntry, refundedEntry}
+
+	t.Run("retrieve paid amounts must return only the amounts that have been paid", func(t *testing.T) {
+		adminCtx := domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+
+		amounts, err := agent.RetrievePaidAmounts(adminCtx, entries)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantAmounts := map[string]float32{
+			capturedEntry.ID.String(): 12.34,
+			paidEntry.ID.String():     12.34,
+		}
+		cmpDiff(t, "paid amounts", wantAmounts, amounts)
+	})
+
+	t.Run("retrieve paid amounts with no admin user must fail", func(t *testing.T) {
+		_, err := agent.RetrievePaidAmounts(ctx, entries)
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+}
+
+func TestStubPaymentProvider_RetrieveOrder(t *testing.T) {
+	pp := newTestStubPaymentProvider(t)
+	entry := generateTestEntry(t, "Harry Redknapp", "MrHarryR", "harry.redknapp@football.net")
+
+	order, err := pp.CreateOrder(context.Background(), entry, domain.RealmEntryFee{Amount: 12.34, Currency: "GBP"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("retrieve created order must succeed", func(t *testing.T) {
+		gotOrder, err := pp.RetrieveOrder(context.Background(), order.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cmpDiff(t, "order", order, gotOrder)
+	})
+
+	t.Run("retrieve non-existent order must fail", func(t *testing.T) {
+		if _, err := pp.RetrieveOrder(context.Background(), "not_an_order"); err == nil {
+			expectedNonEmpty(t, "error")
+		}
+	})
+}
+
+func TestStubPaymentProvider_CaptureOrder(t *testing.T) {
+	pp := newTestStubPaymentProvider(t)
+	entry := generateTestEntry(t, "Harry Redknapp", "MrHarryR", "harry.redknapp@football.net")
+
+	order, err := pp.CreateOrder(context.Background(), entry, domain.RealmEntryFee{Amount: 12.34, Currency: "GBP"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("capture created order must succeed", func(t *testing.T) {
+		payment, err := pp.CaptureOrder(context.Background(), order.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantPayment := domain.VerifiedPayment{
+			OrderID:      order.ID,
+			EntryID:      entry.ID.String(),
+			Reference:    order.ID + "-CAPTURE",
+			Amount:       12.34,
+			Currency:     "GBP",
+			MerchantName: "*STUB PAYMENT PROVIDER",
+		}
+		cmpDiff(t, "verified payment", wantPayment, payment)
+	})
+
+	t.Run("capture order a second time must fail", func(t *testing.T) {
+		if _, err := pp.CaptureOrder(context.Background(), order.ID); err == nil {
+			expectedNonEmpty(t, "error")
+		}
+	})
+}
+
+// shortCapturePaymentProvider is a StubPaymentProvider whose captured payments fall short of the amount of their order
+type shortCapturePaymentProvider struct {
+	*domain.StubPaymentProvider
+}
+
+// CaptureOrder implements PaymentProvider
+func (s *shortCapturePaymentProvider) CaptureOrder(ctx context.Context, orderID string) (domain.VerifiedPayment, error) {
+	payment, err := s.StubPaymentProvider.CaptureOrder(ctx, orderID)
+	payment.Amount = payment.Amount / 2
+	return payment, err
+}
+
+// testStubWebhookSecret is the secret used to sign webhook events sent to the StubPaymentProvider within the testsuite
+const testStubWebhookSecret = "stub_webhook_secret"
+
+// newTestStubPaymentProvider returns a new StubPaymentProvider for use within the testsuite
+func newTestStubPaymentProvider(t *testing.T) *domain.StubPaymentProvider {
+	t.Helper()
+
+	l, err := logger.NewLogger("DEBUG", &bytes.Buffer{}, &domain.RealClock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pp, err := domain.NewStubPaymentProvider(testStubWebhookSecret, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pp
+}
+
+// generateUnverifiedTestEntry inserts a pending Entry whose email address has not been verified
+func generateUnverifiedTestEntry(t *testing.T, nickname, email string) domain.Entry {
+	t.Helper()
+
+	entry := generateTestEntry(t, "Harry Redknapp", nickname, email)
+	entry.Status = domain.EntryStatusPending
+	entry.PaymentMethod = nil
+	entry.PaymentRef = nil
+	entry.EmailVerifiedAt = nil
+
+	return insertEntry(t, entry)
+}
+
+// testContextWithEntryFee returns a new testContextDefault whose realm requires the provided entry fee
+func testContextWithEntryFee(t *testing.T, amount float32) (context.Context, context.CancelFunc) {
+	t.Helper()
+
+	ctx, cancel := testContextDefault(t)
+	domain.RealmFromContext(ctx).EntryFee = domain.RealmEntryFee{Amount: amount, Currency: "GBP"}
+
+	return ctx, cancel
+}
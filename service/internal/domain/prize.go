@@ -0,0 +1,494 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// PrizeCategoryFirstPlace represents the prize awarded to the entry that tops the leaderboard at the end of the season
+	PrizeCategoryFirstPlace = "first_place"
+	// PrizeCategorySecondPlace represents the prize awarded to the runner-up at the end of the season
+	PrizeCategorySecondPlace = "second_place"
+	// PrizeCategoryThirdPlace represents the prize awarded to the entry in third place at the end of the season
+	PrizeCategoryThirdPlace = "third_place"
+	// PrizeCategoryWeekly represents the prize awarded to the highest scorer of a single round
+	PrizeCategoryWeekly = "weekly"
+	// PrizeCategoryCharity represents the share of the prize pot that is donated to charity
+	PrizeCategoryCharity = "charity"
+)
+
+// PrizePot represents the prize money generated by the paid entries of a realm's season, and how it is shared out
+type PrizePot struct {
+	SeasonID       string  `json:"season_id"`
+	EntryCount     int     `json:"entry_count"`
+	Currency       string  `json:"currency"`
+	Total          float32 `json:"total"`
+	FirstPlace     float32 `json:"first_place"`
+	SecondPlace    float32 `json:"second_place"`
+	ThirdPlace     float32 `json:"third_place"`
+	WeeklyPerRound float32 `json:"weekly_per_round"`
+	Charity        float32 `json:"charity"`
+	CharityName    string  `json:"charity_name,omitempty"`
+	Unallocated    float32 `json:"unallocated"`
+	Finalised      bool    `json:"finalised"`
+}
+
+// PrizePayout represents an amount of prize money that has been locked in once a realm's season has been finalised
+type PrizePayout struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	RealmName   string    `db:"realm_name" json:"-"`
+	SeasonID    string    `db:"season_id" json:"season_id"`
+	EntryID     string    `db:"entry_id" json:"entry_id,omitempty"`         // empty if payout is not awarded to an entry (i.e. charity)
+	Category    string    `db:"category" json:"category"`                   // one of the PrizeCategory constants
+	RoundNumber int       `db:"round_number" json:"round_number,omitempty"` // round that a weekly prize was won in
+	Amount      float32   `db:"amount" json:"amount"`
+	Currency    string    `db:"currency" json:"currency"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+// PrizePayoutRepository defines the interface for transacting with our PrizePayout data source
+type PrizePayoutRepository interface {
+	Insert(ctx context.Context, payouts ...*PrizePayout) error
+	Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]PrizePayout, error)
+}
+
+// prizeShares represents the shares of a prize pot in minor currency units
+type prizeShares struct {
+	entryCount     int
+	total          int64
+	places         [3]int64 // first, second and third place
+	weeklyPerRound int64
+	charity        int64
+}
+
+// unallocated returns the amount of the prize pot that is not covered by any share, including that lost to rounding
+func (p prizeShares) unallocated(rounds int) int64 {
+	return p.total - p.places[0] - p.places[1] - p.places[2] - p.weeklyPerRound*int64(rounds) - p.charity
+}
+
+// PrizeAgent defines the behaviours for handling PrizePots and PrizePayouts
+type PrizeAgent struct {
+	er   EntryRepository
+	sepr ScoredEntryPredictionRepository
+	ppr  PrizePayoutRepository
+	sc   SeasonCollection
+	rc   RealmCollection
+	cl   Clock
+}
+
+// RetrievePrizePot calculates the PrizePot for the provided season ID within the current realm
+func (p *PrizeAgent) RetrievePrizePot(ctx context.Context, seasonID string) (PrizePot, error) {
+	season, err := p.sc.GetByID(seasonID)
+	if err != nil {
+		return PrizePot{}, NotFoundError{fmt.Errorf("season id %s: not found", seasonID)}
+	}
+
+	realm := RealmFromContext(ctx)
+
+	shares, err := p.calculatePrizeShares(ctx, *realm, season)
+	if err != nil {
+		return PrizePot{}, err
+	}
+
+	finalised, err := p.hasLockedInPayouts(ctx, realm.Config.Name, seasonID)
+	if err != nil {
+		return PrizePot{}, err
+	}
+
+	return PrizePot{
+		SeasonID:       seasonID,
+		EntryCount:     shares.entryCount,
+		Currency:       realm.EntryFee.Currency,
+		Total:          fromMinorUnits(shares.total),
+		FirstPlace:     fromMinorUnits(shares.places[0]),
+		SecondPlace:    fromMinorUnits(shares.places[1]),
+		ThirdPlace:     fromMinorUnits(shares.places[2]),
+		WeeklyPerRound: fromMinorUnits(shares.weeklyPerRound),
+		Charity:        fromMinorUnits(shares.charity),
+		CharityName:    realm.Prizes.CharityName,
+		Unallocated:    fromMinorUnits(shares.unallocated(season.MaxRounds)),
+		Finalised:      finalised,
+	}, nil
+}
+
+// RetrievePrizePayouts retrieves the PrizePayouts that have been locked in for the provided season ID within the current
+// realm. An empty slice is returned if the season has not yet been finalised
+func (p *PrizeAgent) RetrievePrizePayouts(ctx context.Context, seasonID string) ([]PrizePayout, error) {
+	if _, err := p.sc.GetByID(seasonID); err != nil {
+		return nil, NotFoundError{fmt.Errorf("season id %s: not found", seasonID)}
+	}
+
+	realm := RealmFromContext(ctx)
+
+	payouts, err := p.ppr.Select(ctx, map[string]interface{}{
+		"realm_name": realm.Config.Name,
+		"season_id":  seasonID,
+	}, false)
+	if err != nil {
+		if errors.As(err, &MissingDBRecordError{}) {
+			return []PrizePayout{}, nil
+		}
+		return nil, domainErrorFromRepositoryError(err)
+	}
+
+	sortPrizePayouts(payouts)
+
+	return payouts, nil
+}
+
+// ApplyProjectedPayouts populates the provided LeaderBoard with the current realm's PrizePot, and each of its rankings
+// with the prize money they would receive if the season were to finish with the same positions. A ranking's projected
+// payout includes the weekly prize for the LeaderBoard's round if it is the round's highest scorer
+func (p *PrizeAgent) ApplyProjectedPayouts(ctx context.Context, seasonID string, lb *LeaderBoard) error {
+	if lb == nil {
+		return fmt.Errorf("leaderboard: %w", ErrIsNil)
+	}
+
+	pot, err := p.RetrievePrizePot(ctx, seasonID)
+	if err != nil {
+		return err
+	}
+	lb.PrizePot = &pot
+
+	if lb.LastUpdated == nil {
+		// leaderboard has not been generated from any standings yet, so rankings are not meaningful
+		return nil
+	}
+
+	realm := RealmFromContext(ctx)
+	season, err := p.sc.GetByID(seasonID)
+	if err != nil {
+		return NotFoundError{fmt.Errorf("season id %s: not found", seasonID)}
+	}
+
+	shares, err := p.calculatePrizeShares(ctx, *realm, season)
+	if err != nil {
+		return err
+	}
+
+	projected := make(map[string]int64)
+	for entryID, amount := range placePrizesByEntryID(shares, lb.Rankings) {
+		projected[entryID] += amount
+	}
+	for entryID, amount := range weeklyPrizesByEntryID(shares, lb.Rankings) {
+		projected[entryID] += amount
+	}
+
+	for idx := range lb.Rankings {
+		lb.Rankings[idx].ProjectedPayout = fromMinorUnits(projected[lb.Rankings[idx].ID])
+	}
+
+	return nil
+}
+
+// LockInSeasonPayouts stores the final PrizePayouts for each realm that plays the provided Season.
+// Payouts are only ever locked in once per realm, so realms whose payouts already exist are skipped
+func (p *PrizeAgent) LockInSeasonPayouts(ctx context.Context, season Season) error {
+	for _, realm := range p.rc {
+		if realm.Config.SeasonID != season.ID {
+			continue
+		}
+
+		if err := p.lockInRealmPayouts(ctx, realm, season); err != nil {
+			return fmt.Errorf("realm %s: %w", realm.Config.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// RetrieveRealmNamesWithLockedInPayouts returns the names of the realms playing the provided Season whose PrizePayouts
+// have already been locked in
+func (p *PrizeAgent) RetrieveRealmNamesWithLockedInPayouts(ctx context.Context, season Season) ([]string, error) {
+	var realmNames []string
+
+	for _, realm := range p.rc {
+		if realm.Config.SeasonID != season.ID {
+			continue
+		}
+
+		locked, err := p.hasLockedInPayouts(ctx, realm.Config.Name, season.ID)
+		if err != nil {
+			return nil, fmt.Errorf("realm %s: %w", realm.Config.Name, err)
+		}
+		if locked {
+			realmNames = append(realmNames, realm.Config.Name)
+		}
+	}
+
+	return realmNames, nil
+}
+
+// lockInRealmPayouts stores the final PrizePayouts for the provided Realm and Season
+func (p *PrizeAgent) lockInRealmPayouts(ctx context.Context, realm Realm, season Season) error {
+	locked, err := p.hasLockedInPayouts(ctx, realm.Config.Name, season.ID)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return nil
+	}
+
+	shares, err := p.calculatePrizeShares(ctx, realm, season)
+	if err != nil {
+		return err
+	}
+
+	payouts := make([]PrizePayout, 0)
+	newPayout := func(entryID, category string, roundNumber int, amount int64) {
+		payouts = append(payouts, PrizePayout{
+			RealmName:   realm.Config.Name,
+			SeasonID:    season.ID,
+			EntryID:     entryID,
+			Category:    category,
+			RoundNumber: roundNumber,
+			Amount:      fromMinorUnits(amount),
+			Currency:    realm.EntryFee.Currency,
+		})
+	}
+
+	finalRankings, err := p.retrieveRoundRankings(ctx, realm.Config.Name, season.ID, season.MaxRounds)
+	if err != nil {
+		return err
+	}
+
+	categories := []string{PrizeCategoryFirstPlace, PrizeCategorySecondPlace, PrizeCategoryThirdPlace}
+	for idx, rnk := range topRankings(finalRankings, len(categories)) {
+		if shares.places[idx] > 0 {
+			newPayout(rnk.ID, categories[idx], 0, shares.places[idx])
+		}
+	}
+
+	if shares.weeklyPerRound > 0 {
+		for roundNumber := 1; roundNumber <= season.MaxRounds; roundNumber++ {
+			rankings, err := p.retrieveRoundRankings(ctx, realm.Config.Name, season.ID, roundNumber)
+			if err != nil {
+				return err
+			}
+
+			for entryID, amount := range weeklyPrizesByEntryID(shares, rankings) {
+				newPayout(entryID, PrizeCategoryWeekly, roundNumber, amount)
+			}
+		}
+	}
+
+	if shares.charity > 0 {
+		newPayout("", PrizeCategoryCharity, 0, shares.charity)
+	}
+
+	now := p.cl.Now().Truncate(time.Second)
+	toInsert := make([]*PrizePayout, 0, len(payouts))
+	for idx := range payouts {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return InternalError{err}
+		}
+
+		payouts[idx].ID = id
+		payouts[idx].CreatedAt = now
+
+		toInsert = append(toInsert, &payouts[idx])
+	}
+
+	// payouts are inserted together so that a failure part way through can't leave a partial set behind,
+	// which would otherwise be treated as already locked in
+	if err := p.ppr.Insert(ctx, toInsert...); err != nil {
+		return domainErrorFromRepositoryError(err)
+	}
+
+	return nil
+}
+
+// calculatePrizeShares calculates how the prize pot generated by the approved paid entries of the provided Realm and
+// Season is shared out, according to the realm's prize rules
+func (p *PrizeAgent) calculatePrizeShares(ctx context.Context, realm Realm, season Season) (prizeShares, error) {
+	if err := realm.Prizes.Validate(); err != nil {
+		return prizeShares{}, ConflictError{fmt.Errorf("invalid prize rules: %w", err)}
+	}
+
+	entries, err := p.er.Select(ctx, map[string]interface{}{
+		"realm_name": realm.Config.Name,
+		"season_id":  season.ID,
+		"status":     EntryStatusPaid,
+	}, false)
+	if err != nil && !errors.As(err, &MissingDBRecordError{}) {
+		return prizeShares{}, domainErrorFromRepositoryError(err)
+	}
+
+	var count int
+	for _, entry := range entries {
+		if entry.IsApproved() {
+			count++
+		}
+	}
+
+	total := toMinorUnits(realm.EntryFee.Amount) * int64(count)
+
+	shares := prizeShares{
+		entryCount: count,
+		total:      total,
+		places: [3]int64{
+			percentageOf(total, realm.Prizes.FirstPlace),
+			percentageOf(total, realm.Prizes.SecondPlace),
+			percentageOf(total, realm.Prizes.ThirdPlace),
+		},
+		charity: percentageOf(total, realm.Prizes.Charity),
+	}
+
+	if season.MaxRounds > 0 {
+		shares.weeklyPerRound = percentageOf(total, realm.Prizes.Weekly) / int64(season.MaxRounds)
+	}
+
+	return shares, nil
+}
+
+// hasLockedInPayouts returns true if PrizePayouts have already been stored for the provided realm and season
+func (p *PrizeAgent) hasLockedInPayouts(ctx context.Context, realmName, seasonID string) (bool, error) {
+	if _, err := p.ppr.Select(ctx, map[string]interface{}{
+		"realm_name": realmName,
+		"season_id":  seasonID,
+	}, false); err != nil {
+		if errors.As(err, &MissingDBRecordError{}) {
+			return false, nil
+		}
+		return false, domainErrorFromRepositoryError(err)
+	}
+
+	return true, nil
+}
+
+// retrieveRoundRankings retrieves the cumulative rankings of the provided realm and season for the provided round number,
+// returning an empty slice if nobody has been scored for the round
+func (p *PrizeAgent) retrieveRoundRankings(ctx context.Context, realmName, seasonID string, roundNumber int) ([]LeaderBoardRanking, error) {
+	rankings, err := p.sepr.SelectEntryCumulativeScoresByRealm(ctx, realmName, seasonID, roundNumber)
+	if err != nil {
+		if errors.As(err, &MissingDBRecordError{}) {
+			return []LeaderBoardRanking{}, nil
+		}
+		return nil, domainErrorFromRepositoryError(err)
+	}
+
+	return rankings, nil
+}
+
+// placePrizesByEntryID returns the first, second and third place prizes from the provided shares, keyed by the ID of
+// the entry that holds each position within the provided rankings
+func placePrizesByEntryID(shares prizeShares, rankings []LeaderBoardRanking) map[string]int64 {
+	prizes := make(map[string]int64)
+
+	for idx, rnk := range topRankings(rankings, len(shares.places)) {
+		if shares.places[idx] > 0 {
+			prizes[rnk.ID] += shares.places[idx]
+		}
+	}
+
+	return prizes
+}
+
+// weeklyPrizesByEntryID returns the weekly prize from the provided shares, keyed by the ID of the entry with the highest
+// round score within the provided rankings. The prize is split equally between entries that tie for the highest score
+func weeklyPrizesByEntryID(shares prizeShares, rankings []LeaderBoardRanking) map[string]int64 {
+	prizes := make(map[string]int64)
+
+	if shares.weeklyPerRound == 0 || len(rankings) == 0 {
+		return prizes
+	}
+
+	highest := rankings[0].Score
+	for _, rnk := range rankings {
+		if rnk.Score > highest {
+			highest = rnk.Score
+		}
+	}
+
+	winners := make([]string, 0)
+	for _, rnk := range rankings {
+		if rnk.Score == highest {
+			winners = append(winners, rnk.ID)
+		}
+	}
+
+	for _, entryID := range winners {
+		prizes[entryID] = shares.weeklyPerRound / int64(len(winners))
+	}
+
+	return prizes
+}
+
+// topRankings returns up to the provided number of rankings with the lowest positions
+func topRankings(rankings []LeaderBoardRanking, n int) []LeaderBoardRanking {
+	sorted := make([]LeaderBoardRanking, len(rankings))
+	copy(sorted, rankings)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Position < sorted[j].Position
+	})
+
+	if len(sorted) > n {
+		return sorted[:n]
+	}
+
+	return sorted
+}
+
+// sortPrizePayouts sorts the provided PrizePayouts by category, then by round number
+func sortPrizePayouts(payouts []PrizePayout) {
+	order := map[string]int{
+		PrizeCategoryFirstPlace:  0,
+		PrizeCategorySecondPlace: 1,
+		PrizeCategoryThirdPlace:  2,
+		PrizeCategoryWeekly:      3,
+		PrizeCategoryCharity:     4,
+	}
+
+	sort.SliceStable(payouts, func(i, j int) bool {
+		if order[payouts[i].Category] != order[payouts[j].Category] {
+			return order[payouts[i].Category] < order[payouts[j].Category]
+		}
+		return payouts[i].RoundNumber < payouts[j].RoundNumber
+	})
+}
+
+// percentageOf returns the provided percentage of the provided amount of minor units, rounded down
+func percentageOf(amount int64, percentage float32) int64 {
+	return int64(math.Floor(float64(amount) * float64(percentage) / 100))
+}
+
+// fromMinorUnits converts the provided amount of minor currency units back to a major currency amount
+func fromMinorUnits(amount int64) float32 {
+	return float32(amount) / 100
+}
+
+// NewPrizeAgent returns a new PrizeAgent using the provided repositories and collections
+func NewPrizeAgent(er EntryRepository, sepr ScoredEntryPredictionRepository, ppr PrizePayoutRepository, sc SeasonCollection, rc RealmCollection, cl Clock) (*PrizeAgent, error) {
+	switch {
+	case er == nil:
+		return nil, fmt.Errorf("entry repository: %w", ErrIsNil)
+	case sepr == nil:
+		return nil, fmt.Errorf("scored entry prediction repository: %w", ErrIsNil)
+	case ppr == nil:
+		return nil, fmt.Errorf("prize payout repository: %w", ErrIsNil)
+	case sc == nil:
+		return nil, fmt.Errorf("season collection: %w", ErrIsNil)
+	case rc == nil:
+		return nil, fmt.Errorf("realm collection: %w", ErrIsNil)
+	case cl == nil:
+		return nil, fmt.Errorf("clock: %w", ErrIsNil)
+	}
+
+	return &PrizeAgent{
+		er:   er,
+		sepr: sepr,
+		ppr:  ppr,
+		sc:   sc,
+		rc:   rc,
+		cl:   cl,
+	}, nil
+}
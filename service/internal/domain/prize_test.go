@@ -0,0 +1,293 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"prediction-league/service/internal/domain"
+	"testing"
+	"time"
+
+	"gotest.tools/assert/cmp"
+)
+
+func TestNewPrizeAgent(t *testing.T) {
+	t.Run("passing invalid parameters must return expected error", func(t *testing.T) {
+		cl := &mockClock{}
+
+		tt := []struct {
+			er      domain.EntryRepository
+			sepr    domain.ScoredEntryPredictionRepository
+			ppr     domain.PrizePayoutRepository
+			sc      domain.SeasonCollection
+			rc      domain.RealmCollection
+			cl      domain.Clock
+			wantErr error
+		}{
+			{nil, sepr, ppr, sc, rc, cl, domain.ErrIsNil},
+			{er, nil, ppr, sc, rc, cl, domain.ErrIsNil},
+			{er, sepr, nil, sc, rc, cl, domain.ErrIsNil},
+			{er, sepr, ppr, nil, rc, cl, domain.ErrIsNil},
+			{er, sepr, ppr, sc, nil, cl, domain.ErrIsNil},
+			{er, sepr, ppr, sc, rc, nil, domain.ErrIsNil},
+			{er, sepr, ppr, sc, rc, cl, nil},
+		}
+
+		for idx, tc := range tt {
+			agent, gotErr := domain.NewPrizeAgent(tc.er, tc.sepr, tc.ppr, tc.sc, tc.rc, tc.cl)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("tc #%d: want error %s (%T), got %s (%T)", idx, tc.wantErr, tc.wantErr, gotErr, gotErr)
+			}
+			if tc.wantErr == nil && agent == nil {
+				t.Fatalf("tc #%d: want non-empty agent, got nil", idx)
+			}
+		}
+	})
+}
+
+func TestPrizeAgent(t *testing.T) {
+	t.Cleanup(truncate)
+
+	now := time.Now().Truncate(time.Second)
+
+	// two-round season, so that every round is eligible for a weekly prize
+	season := testSeason
+	season.MaxRounds = 2
+
+	prizeRealm := domain.Realm{
+		Config:   domain.RealmConfig{Name: testRealmName, SeasonID: season.ID},
+		EntryFee: domain.RealmEntryFee{Amount: 10, Currency: "GBP"},
+		Prizes: domain.RealmPrizes{
+			FirstPlace:  50,
+			SecondPlace: 20,
+			ThirdPlace:  10,
+			Weekly:      10,
+			Charity:     10,
+			CharityName: "Charity FC",
+		},
+	}
+
+	agent, err := domain.NewPrizeAgent(
+		er,
+		sepr,
+		ppr,
+		domain.SeasonCollection{season.ID: season},
+		domain.RealmCollection{prizeRealm},
+		&mockClock{t: testDate},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testContextWithPrizes := func(t *testing.T) (context.Context, context.CancelFunc) {
+		t.Helper()
+
+		ctx, cancel := testContextDefault(t)
+		realm := domain.RealmFromContext(ctx)
+		realm.Config.SeasonID = season.ID
+		realm.EntryFee = prizeRealm.EntryFee
+		realm.Prizes = prizeRealm.Prizes
+
+		return ctx, cancel
+	}
+
+	// <-- seed entries, only those that are paid and approved should contribute to the prize pot -->
+
+	harryEntry := insertPaidTestEntry(t, "Harry Redknapp", "MrHarryR", "harry.redknapp@football.net", "HARRY-PAID")
+	jamieEntry := insertPaidTestEntry(t, "Jamie Redknapp", "MrJamieR", "jamie.redknapp@football.net", "JAMIE-PAID")
+	frankEntry := insertPaidTestEntry(t, "Frank Lampard", "FrankieL", "frank.lampard@football.net", "FRANK-PAID")
+
+	// pending entry
+	insertEntry(t, generateTestEntry(t, "Eric Cantona", "MonsieurEric", "eric.cantona@football.net"))
+
+	// refunded entry
+	refundedEntry := generateTestEntry(t, "Joey Barton", "MrJoeyB", "joey.barton@football.net")
+	refundedEntry.Status = domain.EntryStatusRefunded
+	insertEntry(t, refundedEntry)
+
+	// <-- seed scores for each round -->
+
+	roundScores := map[int]map[string]int{
+		1: {harryEntry.ID.String(): 10, jamieEntry.ID.String(): 12, frankEntry.ID.String(): 8},
+		2: {harryEntry.ID.String(): 20, jamieEntry.ID.String(): 5, frankEntry.ID.String(): 20},
+	}
+
+	entryPredictions := make(map[string]domain.EntryPrediction)
+	for _, entry := range []domain.Entry{harryEntry, jamieEntry, frankEntry} {
+		entryPredictions[entry.ID.String()] = insertEntryPrediction(t, generateTestEntryPrediction(t, entry.ID))
+	}
+
+	for roundNumber := 1; roundNumber <= season.MaxRounds; roundNumber++ {
+		stnd := generateTestStandings(t)
+		stnd.SeasonID = season.ID
+		stnd.RoundNumber = roundNumber
+		stnd.CreatedAt = now.Add(time.Duration(roundNumber) * time.Hour)
+		stnd = insertStandings(t, stnd)
+
+		for entryID, score := range roundScores[roundNumber] {
+			sep := generateTestScoredEntryPrediction(t, entryPredictions[entryID].ID, stnd.ID)
+			sep.Score = score
+			insertScoredEntryPrediction(t, sep)
+		}
+	}
+
+	t.Run("retrieve prize pot must calculate shares from paid and approved entries", func(t *testing.T) {
+		ctx, cancel := testContextWithPrizes(t)
+		defer cancel()
+
+		pot, err := agent.RetrievePrizePot(ctx, season.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantPot := domain.PrizePot{
+			SeasonID:       season.ID,
+			EntryCount:     3,
+			Currency:       "GBP",
+			Total:          30,
+			FirstPlace:     15,
+			SecondPlace:    6,
+			ThirdPlace:     3,
+			WeeklyPerRound: 1.5,
+			Charity:        3,
+			CharityName:    "Charity FC",
+			Unallocated:    0,
+			Finalised:      false,
+		}
+		cmpDiff(t, "prize pot", wantPot, pot)
+	})
+
+	t.Run("retrieve prize pot with invalid prize rules must fail", func(t *testing.T) {
+		ctx, cancel := testContextWithPrizes(t)
+		defer cancel()
+
+		domain.RealmFromContext(ctx).Prizes.FirstPlace = 95
+
+		_, err := agent.RetrievePrizePot(ctx, season.ID)
+		if !cmp.ErrorType(err, domain.ConflictError{})().Success() {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+	})
+
+	t.Run("retrieve prize pot for non-existent season must fail", func(t *testing.T) {
+		ctx, cancel := testContextWithPrizes(t)
+		defer cancel()
+
+		_, err := agent.RetrievePrizePot(ctx, "not_a_season")
+		if !cmp.ErrorType(err, domain.NotFoundError{})().Success() {
+			expectedTypeOfGot(t, domain.NotFoundError{}, err)
+		}
+	})
+
+	t.Run("apply projected payouts must award place prizes and the round's weekly prize", func(t *testing.T) {
+		ctx, cancel := testContextWithPrizes(t)
+		defer cancel()
+
+		lb := &domain.LeaderBoard{
+			RoundNumber: 2,
+			Rankings: []domain.LeaderBoardRanking{
+				generateTestLeaderBoardRanking(1, 0, harryEntry.ID.String(), 20, 20, 30),
+				generateTestLeaderBoardRanking(2, 0, frankEntry.ID.String(), 20, 20, 28),
+				generateTestLeaderBoardRanking(3, 0, jamieEntry.ID.String(), 5, 17, 12),
+			},
+			LastUpdated: &now,
+		}
+
+		if err := agent.ApplyProjectedPayouts(ctx, season.ID, lb); err != nil {
+			t.Fatal(err)
+		}
+
+		if lb.PrizePot == nil {
+			t.Fatal("want non-empty prize pot, got nil")
+		}
+
+		gotPayouts := make(map[string]float32)
+		for _, rnk := range lb.Rankings {
+			gotPayouts[rnk.ID] = rnk.ProjectedPayout
+		}
+
+		wantPayouts := map[string]float32{
+			harryEntry.ID.String(): 15.75, // first place, shared weekly prize
+			frankEntry.ID.String(): 6.75,  // second place, shared weekly prize
+			jamieEntry.ID.String(): 3,     // third place
+		}
+		cmpDiff(t, "projected payouts", wantPayouts, gotPayouts)
+	})
+
+	t.Run("retrieve payouts before season is finalised must return empty payouts", func(t *testing.T) {
+		ctx, cancel := testContextWithPrizes(t)
+		defer cancel()
+
+		payouts, err := agent.RetrievePrizePayouts(ctx, season.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(payouts) != 0 {
+			expectedEmpty(t, "payouts", payouts)
+		}
+
+		realmNames, err := agent.RetrieveRealmNamesWithLockedInPayouts(ctx, season)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(realmNames) != 0 {
+			expectedEmpty(t, "realm names", realmNames)
+		}
+	})
+
+	t.Run("lock in season payouts must store final payouts once", func(t *testing.T) {
+		ctx, cancel := testContextWithPrizes(t)
+		defer cancel()
+
+		// locking in a second time must have no effect
+		for i := 0; i < 2; i++ {
+			if err := agent.LockInSeasonPayouts(ctx, season); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		payouts, err := agent.RetrievePrizePayouts(ctx, season.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		type payoutKey struct {
+			entryID     string
+			category    string
+			roundNumber int
+		}
+
+		gotPayouts := make(map[payoutKey]float32)
+		for _, payout := range payouts {
+			gotPayouts[payoutKey{payout.EntryID, payout.Category, payout.RoundNumber}] = payout.Amount
+		}
+
+		wantPayouts := map[payoutKey]float32{
+			{harryEntry.ID.String(), domain.PrizeCategoryFirstPlace, 0}:  15,
+			{frankEntry.ID.String(), domain.PrizeCategorySecondPlace, 0}: 6,
+			{jamieEntry.ID.String(), domain.PrizeCategoryThirdPlace, 0}:  3,
+			{jamieEntry.ID.String(), domain.PrizeCategoryWeekly, 1}:      1.5,
+			{harryEntry.ID.String(), domain.PrizeCategoryWeekly, 2}:      0.75,
+			{frankEntry.ID.String(), domain.PrizeCategoryWeekly, 2}:      0.75,
+			{"", domain.PrizeCategoryCharity, 0}:                         3,
+		}
+		cmpDiff(t, "payouts", wantPayouts, gotPayouts)
+
+		if len(payouts) != len(wantPayouts) {
+			expectedGot(t, len(wantPayouts), len(payouts))
+		}
+
+		pot, err := agent.RetrievePrizePot(ctx, season.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pot.Finalised {
+			expectedGot(t, true, pot.Finalised)
+		}
+
+		realmNames, err := agent.RetrieveRealmNamesWithLockedInPayouts(ctx, season)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cmpDiff(t, "realm names", []string{testRealmName}, realmNames)
+	})
+}
@@ -0,0 +1,219 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	RateLimitActionMagicLogin = "magic_login"
+	RateLimitActionRealmPIN   = "realm_pin"
+	RateLimitActionAPIKey     = "api_key"
+	RateLimitActionAdminLogin = "admin_login"
+	RateLimitActionLoginCode  = "login_code"
+)
+
+// RateLimit defines the maximum number of attempts at an action that can be made by a single subject within a window.
+// Exceeding an alert-only RateLimit is logged rather than blocked, so that a subject shared by every client cannot be used
+// to lock everyone else out
+type RateLimit struct {
+	Action    string
+	Subject   string
+	Max       int
+	Window    time.Duration
+	AlertOnly bool
+}
+
+// key returns the key that attempts are counted against, which is unique to the provided realm
+func (r RateLimit) key(realmName string) string {
+	return strings.Join([]string{realmName, r.Action, r.Subject}, "|")
+}
+
+// MagicLoginRateLimits returns the RateLimits that apply to requesting a magic login email for the provided email address
+// from the provided IP address, so that an inbox cannot be flooded with emails. An unusual volume of requests across
+// the realm as a whole is alerted on
+func MagicLoginRateLimits(ip, email string) []RateLimit {
+	return []RateLimit{
+		{Action: RateLimitActionMagicLogin, Subject: "ip:" + ip, Max: 10, Window: time.Hour},
+		{Action: RateLimitActionMagicLogin, Subject: "email:" + strings.ToLower(strings.TrimSpace(email)), Max: 3, Window: 15 * time.Minute},
+		{Action: RateLimitActionMagicLogin, Subject: "realm", Max: 200, Window: time.Hour, AlertOnly: true},
+	}
+}
+
+// RealmPINRateLimits returns the RateLimits that apply to failed attempts at the realm PIN from the provided IP address,
+// so that the PIN cannot be brute-forced by a single client. Many clients acting together are alerted on
+func RealmPINRateLimits(ip string) []RateLimit {
+	return []RateLimit{
+		{Action: RateLimitActionRealmPIN, Subject: "ip:" + ip, Max: 5, Window: 15 * time.Minute},
+		{Action: RateLimitActionRealmPIN, Subject: "realm", Max: 50, Window: time.Hour, AlertOnly: true},
+	}
+}
+
+// APIKeyRateLimits returns the RateLimits that apply to requests made with the APIKey that has the provided ID,
+// so that a single misbehaving client cannot overwhelm the API
+func APIKeyRateLimits(apiKeyID string) []RateLimit {
+	return []RateLimit{
+		{Action: RateLimitActionAPIKey, Subject: "key:" + apiKeyID, Max: 60, Window: time.Minute},
+	}
+}
+
+// AdminLoginRateLimits returns the RateLimits that apply to failed attempts at admin credentials from the provided IP address,
+// so that a single client can neither guess admin passwords nor tie up the service verifying them
+func AdminLoginRateLimits(ip string) []RateLimit {
+	return []RateLimit{
+		{Action: RateLimitActionAdminLogin, Subject: "ip:" + ip, Max: 10, Window: 15 * time.Minute},
+	}
+}
+
+// LoginCodeRateLimits returns the RateLimits that apply to failed attempts at login codes from the provided IP address,
+// so that a single client cannot guess at the login codes of many entrants. Many clients acting together are alerted on
+func LoginCodeRateLimits(ip string) []RateLimit {
+	return []RateLimit{
+		{Action: RateLimitActionLoginCode, Subject: "ip:" + ip, Max: 10, Window: 15 * time.Minute},
+		{Action: RateLimitActionLoginCode, Subject: "realm", Max: 100, Window: time.Hour, AlertOnly: true},
+	}
+}
+
+// RateLimitStore defines the interface for counting attempts made against a RateLimit within fixed windows.
+// Increment must return the number of attempts that have been made within the window once its own has been counted,
+// so that concurrent attempts can never all see the same count
+type RateLimitStore interface {
+	Increment(ctx context.Context, key string, windowStart, expiresAt time.Time) (int, error)
+	Decrement(ctx context.Context, key string, windowStart time.Time) error
+}
+
+// RateLimitAgent defines the behaviours for limiting the number of attempts that can be made at an action
+type RateLimitAgent struct {
+	rs RateLimitStore
+	cl Clock
+	l  Logger
+}
+
+// Allow counts an attempt against each of the provided RateLimits within the current realm, and returns a RateLimitedError
+// if doing so has taken any of them beyond its maximum. Attempts are counted before they are decided on, so that a burst
+// of concurrent attempts cannot all be allowed before any of them has been counted
+func (r *RateLimitAgent) Allow(ctx context.Context, limits ...RateLimit) error {
+	realmName := RealmFromContext(ctx).Config.Name
+	now := r.cl.Now()
+
+	var limited error
+	for _, limit := range limits {
+		windowStart := now.Truncate(limit.Window)
+
+		cnt, err := r.rs.Increment(ctx, limit.key(realmName), windowStart, windowStart.Add(limit.Window))
+		if err != nil {
+			return domainErrorFromRepositoryError(err)
+		}
+
+		if cnt <= limit.Max {
+			continue
+		}
+
+		if limit.AlertOnly {
+			// only alert once per window, rather than for every attempt beyond the maximum
+			if cnt == limit.Max+1 {
+				r.l.Errorf("rate limit: %s attempts by %s in realm '%s' have exceeded %d since %s", limit.Action, limit.Subject, realmName, limit.Max, windowStart.Format(time.RFC3339))
+			}
+			continue
+		}
+
+		r.l.Infof("rate limit: blocked %s attempt by %s in realm '%s': %d attempts since %s", limit.Action, limit.Subject, realmName, cnt, windowStart.Format(time.RFC3339))
+
+		// keep counting against the remaining limits, so that every limit sees the same attempts
+		if limited == nil {
+			limited = RateLimitedError{
+				error:      fmt.Errorf("too many %s attempts", strings.ReplaceAll(limit.Action, "_", " ")),
+				RetryAfter: windowStart.Add(limit.Window).Sub(now),
+			}
+		}
+	}
+
+	return limited
+}
+
+// Release gives back an attempt that was counted by Allow against each of the provided RateLimits within the current realm,
+// for an attempt that turned out to succeed, so that only failed attempts count towards them
+func (r *RateLimitAgent) Release(ctx context.Context, limits ...RateLimit) error {
+	realmName := RealmFromContext(ctx).Config.Name
+	now := r.cl.Now()
+
+	for _, limit := range limits {
+		if err := r.rs.Decrement(ctx, limit.key(realmName), now.Truncate(limit.Window)); err != nil {
+			return domainErrorFromRepositoryError(err)
+		}
+	}
+
+	return nil
+}
+
+// NewRateLimitAgent returns a new RateLimitAgent using the provided store
+func NewRateLimitAgent(rs RateLimitStore, cl Clock, l Logger) (*RateLimitAgent, error) {
+	switch {
+	case rs == nil:
+		return nil, fmt.Errorf("rate limit store: %w", ErrIsNil)
+	case cl == nil:
+		return nil, fmt.Errorf("clock: %w", ErrIsNil)
+	case l == nil:
+		return nil, fmt.Errorf("logger: %w", ErrIsNil)
+	}
+	return &RateLimitAgent{rs, cl, l}, nil
+}
+
+// rateLimitWindow represents the attempts counted against a single key within its current window
+type rateLimitWindow struct {
+	start     time.Time
+	expiresAt time.Time
+	attempts  int
+}
+
+// InMemoryRateLimitStore implements RateLimitStore by holding attempts in memory.
+// Attempts are not shared between instances of the service, so it is only suitable when running a single instance
+type InMemoryRateLimitStore struct {
+	mu      sync.Mutex
+	windows map[string]rateLimitWindow
+}
+
+// Increment implements RateLimitStore
+func (i *InMemoryRateLimitStore) Increment(_ context.Context, key string, windowStart, expiresAt time.Time) (int, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	// discard windows that ended before this one began, so that memory usage does not grow indefinitely
+	for k, w := range i.windows {
+		if w.expiresAt.Before(windowStart) {
+			delete(i.windows, k)
+		}
+	}
+
+	w, ok := i.windows[key]
+	if !ok || !w.start.Equal(windowStart) {
+		w = rateLimitWindow{start: windowStart, expiresAt: expiresAt}
+	}
+	w.attempts++
+	i.windows[key] = w
+
+	return w.attempts, nil
+}
+
+// Decrement implements RateLimitStore
+func (i *InMemoryRateLimitStore) Decrement(_ context.Context, key string, windowStart time.Time) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	w, ok := i.windows[key]
+	if !ok || !w.start.Equal(windowStart) || w.attempts == 0 {
+		return nil
+	}
+	w.attempts--
+	i.windows[key] = w
+
+	return nil
+}
+
+// NewInMemoryRateLimitStore returns a new InMemoryRateLimitStore that holds no attempts
+func NewInMemoryRateLimitStore() *InMemoryRateLimitStore {
+	return &InMemoryRateLimitStore{windows: make(map[string]rateLimitWindow)}
+}
@@ -0,0 +1,241 @@
+package domain_test
+
+import (
+	"errors"
+	"fmt"
+	"prediction-league/service/internal/domain"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/assert/cmp"
+)
+
+func TestNewRateLimitAgent(t *testing.T) {
+	t.Run("passing invalid parameters must return expected error", func(t *testing.T) {
+		rs := domain.NewInMemoryRateLimitStore()
+		cl := &mockClock{}
+		l := &mockLogger{}
+
+		tt := []struct {
+			rs      domain.RateLimitStore
+			cl      domain.Clock
+			l       domain.Logger
+			wantErr error
+		}{
+			{nil, cl, l, domain.ErrIsNil},
+			{rs, nil, l, domain.ErrIsNil},
+			{rs, cl, nil, domain.ErrIsNil},
+			{rs, cl, l, nil},
+		}
+
+		for idx, tc := range tt {
+			agent, gotErr := domain.NewRateLimitAgent(tc.rs, tc.cl, tc.l)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("tc #%d: want error %s (%T), got %s (%T)", idx, tc.wantErr, tc.wantErr, gotErr, gotErr)
+			}
+			if tc.wantErr == nil && agent == nil {
+				t.Fatalf("tc #%d: want non-empty agent, got nil", idx)
+			}
+		}
+	})
+}
+
+func TestRateLimitAgent_Allow(t *testing.T) {
+	// align with the start of a window, so that every attempt falls within it
+	windowStart := time.Date(2018, 5, 26, 14, 0, 0, 0, time.UTC)
+
+	limit := domain.RateLimit{
+		Action:  domain.RateLimitActionMagicLogin,
+		Subject: "ip:127.0.0.1",
+		Max:     2,
+		Window:  15 * time.Minute,
+	}
+
+	t.Run("allow attempts until the limit is reached must succeed and then fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		cl := &mockClock{t: windowStart.Add(5 * time.Minute)}
+		l := newMockLogger()
+
+		agent, err := domain.NewRateLimitAgent(domain.NewInMemoryRateLimitStore(), cl, l)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < limit.Max; i++ {
+			if err := agent.Allow(ctx, limit); err != nil {
+				t.Fatalf("attempt #%d: %s", i, err)
+			}
+		}
+
+		err = agent.Allow(ctx, limit)
+		if !cmp.ErrorType(err, domain.RateLimitedError{})().Success() {
+			expectedTypeOfGot(t, domain.RateLimitedError{}, err)
+		}
+
+		var rlErr domain.RateLimitedError
+		errors.As(err, &rlErr)
+		if rlErr.RetryAfter != 10*time.Minute {
+			expectedGot(t, 10*time.Minute, rlErr.RetryAfter)
+		}
+
+		// blocked attempt must be logged
+		if !strings.Contains(l.buf.String(), "blocked magic_login attempt by ip:127.0.0.1") {
+			t.Fatalf("want blocked attempt to be logged, got '%s'", l.buf.String())
+		}
+
+		// attempts must be allowed again once the window has passed
+		cl.t = windowStart.Add(limit.Window)
+		if err := agent.Allow(ctx, limit); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("attempts in one realm must not count towards another", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		agent, err := domain.NewRateLimitAgent(domain.NewInMemoryRateLimitStore(), &mockClock{t: windowStart}, &mockLogger{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < limit.Max; i++ {
+			if err := agent.Allow(ctx, limit); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		otherCtx, otherCancel := testContextDefault(t)
+		defer otherCancel()
+		domain.RealmFromContext(otherCtx).Config.Name = "not_the_test_realm"
+
+		if err := agent.Allow(otherCtx, limit); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("allow must fail if any of the provided limits has been reached", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		agent, err := domain.NewRateLimitAgent(domain.NewInMemoryRateLimitStore(), &mockClock{t: windowStart}, newMockLogger())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		limits := domain.MagicLoginRateLimits("127.0.0.1", "Harry.Redknapp@football.net")
+
+		// the email limit is the strictest, and must apply whatever case the address is provided in
+		for i := 0; i < 3; i++ {
+			if err := agent.Allow(ctx, limits...); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		err = agent.Allow(ctx, domain.MagicLoginRateLimits("192.168.0.1", " harry.redknapp@football.net")...)
+		if !cmp.ErrorType(err, domain.RateLimitedError{})().Success() {
+			expectedTypeOfGot(t, domain.RateLimitedError{}, err)
+		}
+	})
+}
+
+func TestRateLimitAgent_Allow_Concurrent(t *testing.T) {
+	ctx, cancel := testContextDefault(t)
+	defer cancel()
+
+	agent, err := domain.NewRateLimitAgent(domain.NewInMemoryRateLimitStore(), &mockClock{t: testDate}, newMockLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limits := domain.RealmPINRateLimits("127.0.0.1")
+
+	t.Run("allow a burst of concurrent attempts must only allow up to the limit", func(t *testing.T) {
+		const attempts = 20
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var allowed int
+		for i := 0; i < attempts; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := agent.Allow(ctx, limits...); err == nil {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if allowed != 5 {
+			expectedGot(t, 5, allowed)
+		}
+	})
+}
+
+func TestRateLimitAgent_Release(t *testing.T) {
+	ctx, cancel := testContextDefault(t)
+	defer cancel()
+
+	agent, err := domain.NewRateLimitAgent(domain.NewInMemoryRateLimitStore(), &mockClock{t: testDate}, newMockLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limits := domain.RealmPINRateLimits("127.0.0.1")
+
+	t.Run("released attempts must not count towards the limit", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			if err := agent.Allow(ctx, limits...); err != nil {
+				t.Fatal(err)
+			}
+			if err := agent.Release(ctx, limits...); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+
+	t.Run("allow must fail once enough attempts have not been released", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			if err := agent.Allow(ctx, limits...); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		err := agent.Allow(ctx, limits...)
+		if !cmp.ErrorType(err, domain.RateLimitedError{})().Success() {
+			expectedTypeOfGot(t, domain.RateLimitedError{}, err)
+		}
+	})
+}
+
+func TestRateLimitAgent_AlertOnly(t *testing.T) {
+	ctx, cancel := testContextDefault(t)
+	defer cancel()
+
+	l := newMockLogger()
+
+	agent, err := domain.NewRateLimitAgent(domain.NewInMemoryRateLimitStore(), &mockClock{t: testDate}, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("exceeding a realm-wide limit must alert but never block other clients", func(t *testing.T) {
+		// each attempt comes from a different client, so only the realm-wide limit is ever exceeded
+		for i := 0; i < 60; i++ {
+			if err := agent.Allow(ctx, domain.RealmPINRateLimits(fmt.Sprintf("10.0.0.%d", i))...); err != nil {
+				t.Fatalf("attempt #%d: %s", i, err)
+			}
+		}
+
+		if got := strings.Count(l.buf.String(), "realm_pin attempts by realm"); got != 1 {
+			t.Fatalf("want realm-wide limit to be alerted on once, got %d alerts in '%s'", got, l.buf.String())
+		}
+	})
+}
@@ -4,19 +4,27 @@ import (
 	"fmt"
 	"html/template"
 	"io/ioutil"
+	"net/url"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/gomarkdown/markdown"
 	"gopkg.in/yaml.v2"
 )
 
+// realmPathPrefixRoot is the path under which each realm that is served by path prefix can be found
+const realmPathPrefixRoot = "/r/"
+
 // Realm represents an instance of the game, often pertaining to the domain on which the server is accessible
 type Realm struct {
-	Config   RealmConfig   `yaml:"config"`
-	Contact  RealmContact  `yaml:"contact"`
-	EntryFee RealmEntryFee `yaml:"entry_fee"`
-	FAQs     []RealmFAQ    `yaml:"faqs"`
-	Site     RealmSite     `yaml:"site"`
+	Config          RealmConfig          `yaml:"config"`
+	Contact         RealmContact         `yaml:"contact"`
+	EntryFee        RealmEntryFee        `yaml:"entry_fee"`
+	FAQs            []RealmFAQ           `yaml:"faqs"`
+	Prizes          RealmPrizes          `yaml:"prizes"`
+	SecurityHeaders RealmSecurityHeaders `yaml:"security_headers"`
+	Site            RealmSite            `yaml:"site"`
 }
 
 // GetFullHomeURL returns the home page path appended to the realm origin
@@ -43,12 +51,57 @@ func (r Realm) GetMagicLoginURL(t *Token) string {
 	return r.Site.Origin + r.Site.Paths.Login + tID
 }
 
+// GetLoginCodeURL returns the path of the page for entering a login code appended to the realm origin
+func (r Realm) GetLoginCodeURL() string {
+	return r.Site.Origin + r.Site.Paths.Login + "/code"
+}
+
+// GetEmailVerificationURL generates a URL for verifying an entrant's email address using the provided Token
+func (r Realm) GetEmailVerificationURL(t *Token) string {
+	tID := ""
+	if t != nil {
+		tID = "/" + t.ID
+	}
+	return r.Site.Origin + r.Site.Paths.VerifyEmail + tID
+}
+
+// GetPaymentReminderURL generates a URL that returns an entrant to the payment step of the join page,
+// using the provided registration Token to identify their Entry
+func (r Realm) GetPaymentReminderURL(t *Token) string {
+	if t == nil {
+		return r.Site.Origin + r.Site.Paths.Join
+	}
+	return r.Site.Origin + r.Site.Paths.Join + "?" + url.Values{"token": {t.ID}}.Encode()
+}
+
 // RealmConfig represents the core configuration of a Realm
 type RealmConfig struct {
 	Name     string `yaml:"name"`      // realm id stored in database for entries
 	GameName string `yaml:"game_name"` // name of the game, referenced in transactional emails and html titles
 	PIN      string `yaml:"pin"`       // pin to enter the game
 	SeasonID string `yaml:"season_id"` // id of season to associate with the realm
+	EntryCap int    `yaml:"entry_cap"` // maximum number of entries that can hold a place in the game, or 0 for no limit
+
+	MaxSessionLifetime time.Duration `yaml:"max_session_lifetime"` // maximum duration that a login can be renewed for, or 0 for the default of 12 hours
+
+	Aliases            []string `yaml:"aliases"`              // additional hostnames that the realm is served on, e.g. both the www. and apex domains
+	TrustForwardedHost bool     `yaml:"trust_forwarded_host"` // whether the realm can be resolved from the X-Forwarded-Host header set by a proxy
+	PathPrefix         bool     `yaml:"path_prefix"`          // whether the realm is served under /r/{name} on any host, instead of at the root of its own
+}
+
+// MatchesHost determines whether the provided hostname belongs to the realm, either as its name or as one of its aliases
+func (r RealmConfig) MatchesHost(host string) bool {
+	if strings.EqualFold(host, r.Name) {
+		return true
+	}
+
+	for _, alias := range r.Aliases {
+		if strings.EqualFold(host, alias) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // RealmContact represents the contact details of a realm
@@ -65,15 +118,59 @@ type RealmContact struct {
 type RealmEntryFee struct {
 	Amount    float32  `yaml:"amount"`    // entry payment numerical amount
 	Breakdown []string `yaml:"breakdown"` // breakdown of entry fee to display on entry page
+	Currency  string   `yaml:"currency"`  // iso 4217 currency code of entry payment amount
 	Label     string   `yaml:"label"`     // entry payment formatted/display amount
 }
 
+// RealmPrizes represents how the prize pot of a realm is shared out, with each share as a percentage of the pot.
+// Any percentage of the pot that is not covered by a share is left unallocated (e.g. to cover running costs)
+type RealmPrizes struct {
+	FirstPlace  float32 `yaml:"first_place"`  // percentage awarded to the winner at the end of the season
+	SecondPlace float32 `yaml:"second_place"` // percentage awarded to the runner-up at the end of the season
+	ThirdPlace  float32 `yaml:"third_place"`  // percentage awarded to third place at the end of the season
+	Weekly      float32 `yaml:"weekly"`       // percentage shared equally between the highest scorers of each round
+	Charity     float32 `yaml:"charity"`      // percentage donated to charity
+	CharityName string  `yaml:"charity_name"` // name of the charity that receives the charity share
+}
+
+// Validate returns an error if the prize percentages are negative or exceed the whole prize pot
+func (r RealmPrizes) Validate() error {
+	var total float32
+
+	for name, pct := range map[string]float32{
+		"first place":  r.FirstPlace,
+		"second place": r.SecondPlace,
+		"third place":  r.ThirdPlace,
+		"weekly":       r.Weekly,
+		"charity":      r.Charity,
+	} {
+		if pct < 0 {
+			return fmt.Errorf("%s percentage %v: must not be negative", name, pct)
+		}
+		total += pct
+	}
+
+	if total > 100 {
+		return fmt.Errorf("total percentage %v: must not exceed 100", total)
+	}
+
+	return nil
+}
+
 // RealmFAQ defines the structure of a frequently-asked question
 type RealmFAQ struct {
 	Question string        `yaml:"question"`
 	Answer   template.HTML `yaml:"answer"`
 }
 
+// RealmSecurityHeaders defines the security headers that are sent with every response served for a realm
+type RealmSecurityHeaders struct {
+	ContentSecurityPolicy   string `yaml:"content_security_policy"`   // value of the content-security-policy header, or empty to omit it
+	StrictTransportSecurity string `yaml:"strict_transport_security"` // value of the strict-transport-security header, or empty to omit it
+	FrameOptions            string `yaml:"frame_options"`             // value of the x-frame-options header, or empty for the default of DENY
+	ReferrerPolicy          string `yaml:"referrer_policy"`           // value of the referrer-policy header, or empty for the default of strict-origin-when-cross-origin
+}
+
 // RealmSite defines data points that are rendered as markup
 type RealmSite struct {
 	AnalyticsCode          string        `yaml:"analytics_code"`             // google analytics code
@@ -95,12 +192,14 @@ type RealmSite struct {
 
 // RealmSitePaths store the paths to each page
 type RealmSitePaths struct {
+	Prefix      string // path that every other path begins with, or empty if the realm is served at the root of its host
 	FAQ         string
 	Home        string
 	Join        string
 	Leaderboard string
 	Login       string
 	MyTable     string
+	VerifyEmail string
 }
 
 // RealmCollection is slice of Realm
@@ -117,6 +216,29 @@ func (rc RealmCollection) GetByName(name string) (Realm, error) {
 	return Realm{}, NotFoundError{fmt.Errorf("realm name '%s': not found", name)}
 }
 
+// GetByHost returns the Realm that is served on the provided hostname
+func (rc RealmCollection) GetByHost(host string) (Realm, error) {
+	for _, r := range rc {
+		if r.Config.MatchesHost(host) {
+			return r, nil
+		}
+	}
+
+	return Realm{}, NotFoundError{fmt.Errorf("realm host '%s': not found", host)}
+}
+
+// GetByPath returns the Realm whose path prefix the provided request path begins with
+func (rc RealmCollection) GetByPath(path string) (Realm, error) {
+	for _, r := range rc {
+		prefix := r.Site.Paths.Prefix
+		if prefix != "" && (path == prefix || strings.HasPrefix(path, prefix+"/")) {
+			return r, nil
+		}
+	}
+
+	return Realm{}, NotFoundError{fmt.Errorf("realm path '%s': not found", path)}
+}
+
 // GetRealmCollection returns the required RealmCollection
 func GetRealmCollection() (RealmCollection, error) {
 	dirPath := filepath.Join("data", "realms")
@@ -174,18 +296,25 @@ func parseRealmFromDir(dirPath string) (Realm, error) {
 	}
 
 	// populate site paths
-	realm.Site.Paths = NewRealmSitePaths()
+	var prefix string
+	if realm.Config.PathPrefix {
+		prefix = realmPathPrefixRoot + realm.Config.Name
+	}
+	realm.Site.Paths = NewRealmSitePaths(prefix)
 
 	return realm, nil
 }
 
-func NewRealmSitePaths() RealmSitePaths {
+// NewRealmSitePaths returns the paths to each page, beginning with the provided prefix
+func NewRealmSitePaths(prefix string) RealmSitePaths {
 	return RealmSitePaths{
-		FAQ:         "/faq",
-		Home:        "/",
-		Join:        "/join",
-		Leaderboard: "/leaderboard",
-		Login:       "/login",
-		MyTable:     "/prediction",
+		Prefix:      prefix,
+		FAQ:         prefix + "/faq",
+		Home:        prefix + "/",
+		Join:        prefix + "/join",
+		Leaderboard: prefix + "/leaderboard",
+		Login:       prefix + "/login",
+		MyTable:     prefix + "/prediction",
+		VerifyEmail: prefix + "/verify-email",
 	}
 }
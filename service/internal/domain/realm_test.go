@@ -85,6 +85,63 @@ func TestRealm_GetMagicLoginURL(t *testing.T) {
 	}
 }
 
+func TestRealm_GetPaymentReminderURL(t *testing.T) {
+	realm := domain.Realm{Site: domain.RealmSite{
+		Origin: "http://localhost",
+		Paths: domain.RealmSitePaths{
+			Join: "/join",
+		},
+	}}
+
+	tt := []struct {
+		name    string
+		token   *domain.Token
+		wantURL string
+	}{
+		{
+			name:    "no token",
+			wantURL: "http://localhost/join",
+		},
+		{
+			name:    "valid token",
+			token:   &domain.Token{ID: "abc123"},
+			wantURL: "http://localhost/join?token=abc123",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gotURL := realm.GetPaymentReminderURL(tc.token)
+			cmpDiff(t, "payment reminder url", tc.wantURL, gotURL)
+		})
+	}
+}
+
+func TestRealmPrizes_Validate(t *testing.T) {
+	tt := []struct {
+		name    string
+		prizes  domain.RealmPrizes
+		wantErr bool
+	}{
+		{"no prizes", domain.RealmPrizes{}, false},
+		{"shares covering whole pot", domain.RealmPrizes{FirstPlace: 50, SecondPlace: 20, ThirdPlace: 10, Weekly: 10, Charity: 10}, false},
+		{"shares covering part of pot", domain.RealmPrizes{FirstPlace: 60, Charity: 12.5}, false},
+		{"shares exceeding whole pot", domain.RealmPrizes{FirstPlace: 60, SecondPlace: 30, ThirdPlace: 20}, true},
+		{"negative share", domain.RealmPrizes{FirstPlace: 60, Weekly: -10}, true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gotErr := tc.prizes.Validate()
+			if tc.wantErr && gotErr == nil {
+				expectedNonEmpty(t, "error")
+			}
+			if !tc.wantErr && gotErr != nil {
+				t.Fatal(gotErr)
+			}
+		})
+	}
+}
+
 func TestRealmCollection_GetByName(t *testing.T) {
 	collection := domain.RealmCollection{
 		domain.Realm{Config: domain.RealmConfig{Name: "realm_1"}},
@@ -111,3 +168,84 @@ func TestRealmCollection_GetByName(t *testing.T) {
 		}
 	})
 }
+
+func TestRealmCollection_GetByHost(t *testing.T) {
+	collection := domain.RealmCollection{
+		domain.Realm{Config: domain.RealmConfig{Name: "realm1.com"}},
+		domain.Realm{Config: domain.RealmConfig{Name: "realm2.com", Aliases: []string{"www.realm2.com", "realm2.net"}}},
+	}
+
+	tt := []struct {
+		host     string
+		wantName string
+	}{
+		{"realm1.com", "realm1.com"},
+		{"realm2.com", "realm2.com"},
+		{"www.realm2.com", "realm2.com"},
+		{"REALM2.NET", "realm2.com"},
+	}
+	for _, tc := range tt {
+		t.Run("retrieving a realm by host "+tc.host+" must succeed", func(t *testing.T) {
+			r, err := collection.GetByHost(tc.host)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Config.Name != tc.wantName {
+				expectedGot(t, tc.wantName, r.Config.Name)
+			}
+		})
+	}
+
+	t.Run("retrieving a realm by unknown host must fail", func(t *testing.T) {
+		if _, err := collection.GetByHost("www.realm1.com"); err == nil {
+			expectedNonEmpty(t, "realm collection getbyhost error")
+		}
+	})
+}
+
+func TestRealmCollection_GetByPath(t *testing.T) {
+	collection := domain.RealmCollection{
+		domain.Realm{Config: domain.RealmConfig{Name: "realm_1"}, Site: domain.RealmSite{Paths: domain.NewRealmSitePaths("")}},
+		domain.Realm{Config: domain.RealmConfig{Name: "realm_2"}, Site: domain.RealmSite{Paths: domain.NewRealmSitePaths("/r/realm_2")}},
+	}
+
+	for _, path := range []string{"/r/realm_2", "/r/realm_2/", "/r/realm_2/api/season/latest"} {
+		t.Run("retrieving a realm by path "+path+" must succeed", func(t *testing.T) {
+			r, err := collection.GetByPath(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Config.Name != "realm_2" {
+				expectedGot(t, "realm_2", r.Config.Name)
+			}
+		})
+	}
+
+	for _, path := range []string{"/", "/leaderboard", "/r/realm_1/leaderboard", "/r/realm_22"} {
+		t.Run("retrieving a realm by path "+path+" must fail", func(t *testing.T) {
+			if _, err := collection.GetByPath(path); err == nil {
+				expectedNonEmpty(t, "realm collection getbypath error")
+			}
+		})
+	}
+}
+
+func TestNewRealmSitePaths(t *testing.T) {
+	t.Run("site paths with a prefix must begin with it", func(t *testing.T) {
+		realm := domain.Realm{Site: domain.RealmSite{
+			Origin: "http://localhost",
+			Paths:  domain.NewRealmSitePaths("/r/realm_1"),
+		}}
+
+		cmpDiff(t, "full home url", "http://localhost/r/realm_1/", realm.GetFullHomeURL())
+		cmpDiff(t, "full my table url", "http://localhost/r/realm_1/prediction", realm.GetFullMyTableURL())
+		cmpDiff(t, "magic login url", "http://localhost/r/realm_1/login/abc123", realm.GetMagicLoginURL(&domain.Token{ID: "abc123"}))
+	})
+
+	t.Run("site paths without a prefix must begin at the root", func(t *testing.T) {
+		paths := domain.NewRealmSitePaths("")
+
+		cmpDiff(t, "home path", "/", paths.Home)
+		cmpDiff(t, "join path", "/join", paths.Join)
+	})
+}
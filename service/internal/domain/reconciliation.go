@@ -0,0 +1,224 @@
+package domain
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// PaymentStatementLine represents a single line of a statement issued by a payment provider
+type PaymentStatementLine struct {
+	LineNumber int     `json:"line_number"`
+	PaymentRef string  `json:"payment_ref"`
+	Amount     float32 `json:"amount"`
+	Currency   string  `json:"currency,omitempty"`
+}
+
+// ReconciledPayment represents a PaymentStatementLine that has been matched to an Entry by its payment reference
+type ReconciledPayment struct {
+	Line           PaymentStatementLine `json:"line"`
+	EntryID        string               `json:"entry_id"`
+	EntryStatus    string               `json:"entry_status"`
+	ExpectedAmount float32              `json:"expected_amount"`
+	Approved       bool                 `json:"approved"`
+	ApprovalError  string               `json:"approval_error,omitempty"`
+}
+
+// PaymentReconciliation represents the outcome of matching a set of PaymentStatementLines against the Entries of a realm
+type PaymentReconciliation struct {
+	Matched    []ReconciledPayment    `json:"matched"`
+	Mismatched []ReconciledPayment    `json:"mismatched"` // matched to an entry, but not for the realm's entry fee
+	Unmatched  []PaymentStatementLine `json:"unmatched"`
+}
+
+// ReconcilePayments matches the provided PaymentStatementLines against the Entries of the current realm and season
+// by their payment reference. Lines that match an Entry for the realm's entry fee can optionally be used to approve
+// that Entry. Lines whose amount or currency differ from the entry fee are reported, but never approved
+func (e *EntryAgent) ReconcilePayments(ctx context.Context, lines []PaymentStatementLine, approve bool) (PaymentReconciliation, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	role := AdminRoleViewer
+	if approve {
+		role = AdminRoleApprover
+	}
+	if !HasAdminRole(ctx, role) {
+		return PaymentReconciliation{}, UnauthorizedError{}
+	}
+
+	if len(lines) == 0 {
+		return PaymentReconciliation{}, ValidationError{Reasons: []string{"Statement must contain at least one line"}}
+	}
+
+	realm := RealmFromContext(ctx)
+
+	entries, err := e.er.Select(ctx, map[string]interface{}{
+		"realm_name": realm.Config.Name,
+		"season_id":  realm.Config.SeasonID,
+	}, false)
+	if err != nil && !errors.As(err, &MissingDBRecordError{}) {
+		return PaymentReconciliation{}, domainErrorFromRepositoryError(err)
+	}
+
+	entriesByRef := make(map[string]Entry)
+	for _, entry := range entries {
+		if entry.PaymentRef != nil && *entry.PaymentRef != "" {
+			entriesByRef[*entry.PaymentRef] = entry
+		}
+	}
+
+	rec := PaymentReconciliation{
+		Matched:    make([]ReconciledPayment, 0),
+		Mismatched: make([]ReconciledPayment, 0),
+		Unmatched:  make([]PaymentStatementLine, 0),
+	}
+
+	for _, line := range lines {
+		entry, ok := entriesByRef[line.PaymentRef]
+		if !ok {
+			rec.Unmatched = append(rec.Unmatched, line)
+			continue
+		}
+
+		payment := ReconciledPayment{
+			Line:           line,
+			EntryID:        entry.ID.String(),
+			EntryStatus:    entry.Status,
+			ExpectedAmount: realm.EntryFee.Amount,
+			Approved:       entry.IsApproved(),
+		}
+
+		if !statementLineMatchesEntryFee(line, realm.EntryFee) {
+			rec.Mismatched = append(rec.Mismatched, payment)
+			continue
+		}
+
+		if approve && !payment.Approved {
+			approved, err := e.ApproveEntryByID(ctx, payment.EntryID)
+			if err != nil {
+				payment.ApprovalError = err.Error()
+			} else {
+				payment.EntryStatus = approved.Status
+				payment.Approved = true
+				// ensure a repeated line for the same entry is not approved twice
+				entriesByRef[line.PaymentRef] = approved
+			}
+		}
+
+		rec.Matched = append(rec.Matched, payment)
+	}
+
+	return rec, nil
+}
+
+// statementLineMatchesEntryFee determines whether the provided PaymentStatementLine is for the provided entry fee.
+// The currency is only compared if both the line and the entry fee specify one
+func statementLineMatchesEntryFee(line PaymentStatementLine, fee RealmEntryFee) bool {
+	if toMinorUnits(line.Amount) != toMinorUnits(fee.Amount) {
+		return false
+	}
+
+	if line.Currency != "" && fee.Currency != "" && !strings.EqualFold(line.Currency, fee.Currency) {
+		return false
+	}
+
+	return true
+}
+
+// paymentStatementColumnAliases maps each column of a PaymentStatementLine to the header names
+// that payment providers are known to use for it within their statement exports
+var paymentStatementColumnAliases = map[string][]string{
+	"payment_ref": {"payment_ref", "reference", "transaction id", "transaction_id"},
+	"amount":      {"amount", "gross"},
+	"currency":    {"currency"},
+}
+
+// ParsePaymentStatementCSV parses the provided CSV statement into PaymentStatementLines.
+// The first row must be a header row identifying the payment reference and amount columns,
+// while the currency column is optional
+func ParsePaymentStatementCSV(r io.Reader) ([]PaymentStatementLine, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ValidationError{Reasons: []string{"Statement must contain a header row"}}
+		}
+		return nil, BadRequestError{Err: err}
+	}
+
+	columns := make(map[string]int)
+	for idx, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for column, aliases := range paymentStatementColumnAliases {
+			if _, ok := columns[column]; ok {
+				continue
+			}
+			for _, alias := range aliases {
+				if name == alias {
+					columns[column] = idx
+				}
+			}
+		}
+	}
+
+	var reasons []string
+	for _, column := range []string{"payment_ref", "amount"} {
+		if _, ok := columns[column]; !ok {
+			reasons = append(reasons, fmt.Sprintf("Statement header is missing column: %s", column))
+		}
+	}
+	if len(reasons) > 0 {
+		return nil, ValidationError{Reasons: reasons}
+	}
+
+	// field returns the value of the provided column for the provided record, or an empty string if it is absent
+	field := func(record []string, column string) string {
+		idx, ok := columns[column]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	lines := make([]PaymentStatementLine, 0)
+	for lineNumber := 2; ; lineNumber++ {
+		record, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, BadRequestError{Err: err}
+		}
+
+		ref := field(record, "payment_ref")
+		if ref == "" {
+			// skip blank lines and provider subtotals that carry no reference
+			continue
+		}
+
+		rawAmount := strings.ReplaceAll(field(record, "amount"), ",", "")
+		amount, err := strconv.ParseFloat(rawAmount, 32)
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("Line %d has invalid amount: %s", lineNumber, rawAmount))
+			continue
+		}
+
+		lines = append(lines, PaymentStatementLine{
+			LineNumber: lineNumber,
+			PaymentRef: ref,
+			Amount:     float32(amount),
+			Currency:   strings.ToUpper(field(record, "currency")),
+		})
+	}
+
+	if len(reasons) > 0 {
+		return nil, ValidationError{Reasons: reasons}
+	}
+
+	return lines, nil
+}
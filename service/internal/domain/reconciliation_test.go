@@ -0,0 +1,196 @@
+package domain_test
+
+import (
+	"prediction-league/service/internal/domain"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert/cmp"
+)
+
+func TestEntryAgent_ReconcilePayments(t *testing.T) {
+	t.Cleanup(truncate)
+
+	agent, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	approvedEntry := insertPaidTestEntry(t, "Harry Redknapp", "MrHarryR", "harry.redknapp@football.net", "REF-APPROVED")
+
+	unapprovedEntry := generateTestEntry(t, "Jamie Redknapp", "MrJamieR", "jamie.redknapp@football.net")
+	unapprovedEntry.Status = domain.EntryStatusPaid
+	unapprovedRef := "REF-UNAPPROVED"
+	unapprovedEntry.PaymentRef = &unapprovedRef
+	unapprovedEntry = insertEntry(t, unapprovedEntry)
+
+	underpaidEntry := generateTestEntry(t, "Frank Lampard", "FrankieL", "frank.lampard@football.net")
+	underpaidEntry.Status = domain.EntryStatusPaid
+	underpaidRef := "REF-UNDERPAID"
+	underpaidEntry.PaymentRef = &underpaidRef
+	underpaidEntry = insertEntry(t, underpaidEntry)
+
+	lines := []domain.PaymentStatementLine{
+		{LineNumber: 2, PaymentRef: "REF-APPROVED", Amount: 12.34, Currency: "GBP"},
+		{LineNumber: 3, PaymentRef: "REF-UNAPPROVED", Amount: 12.34, Currency: "GBP"},
+		{LineNumber: 4, PaymentRef: "REF-UNDERPAID", Amount: 1.23, Currency: "GBP"},
+		{LineNumber: 5, PaymentRef: "REF-UNKNOWN", Amount: 12.34, Currency: "GBP"},
+	}
+
+	t.Run("reconcile without admin user must fail", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		defer cancel()
+
+		_, err := agent.ReconcilePayments(ctx, lines, false)
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("reconcile without lines must fail", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		_, err := agent.ReconcilePayments(ctx, nil, false)
+		if !cmp.ErrorType(err, domain.ValidationError{})().Success() {
+			expectedTypeOfGot(t, domain.ValidationError{}, err)
+		}
+	})
+
+	t.Run("reconcile without approval must report matches and leave entries untouched", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		rec, err := agent.ReconcilePayments(ctx, lines, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantRec := domain.PaymentReconciliation{
+			Matched: []domain.ReconciledPayment{
+				{
+					Line:           lines[0],
+					EntryID:        approvedEntry.ID.String(),
+					EntryStatus:    domain.EntryStatusPaid,
+					ExpectedAmount: 12.34,
+					Approved:       true,
+				},
+				{
+					Line:           lines[1],
+					EntryID:        unapprovedEntry.ID.String(),
+					EntryStatus:    domain.EntryStatusPaid,
+					ExpectedAmount: 12.34,
+					Approved:       false,
+				},
+			},
+			Mismatched: []domain.ReconciledPayment{
+				{
+					Line:           lines[2],
+					EntryID:        underpaidEntry.ID.String(),
+					EntryStatus:    domain.EntryStatusPaid,
+					ExpectedAmount: 12.34,
+					Approved:       false,
+				},
+			},
+			Unmatched: []domain.PaymentStatementLine{lines[3]},
+		}
+		cmpDiff(t, "payment reconciliation", wantRec, rec)
+
+		entry := mustRetrieveEntryByID(t, ctx, unapprovedEntry.ID.String())
+		if entry.IsApproved() {
+			expectedGot(t, "approved entry false", "approved entry true")
+		}
+	})
+
+	t.Run("reconcile with approval must approve matched entries only", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryFee(t, 12.34)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		rec, err := agent.ReconcilePayments(ctx, lines, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, payment := range rec.Matched {
+			if !payment.Approved {
+				expectedGot(t, "approved payment true", "approved payment false")
+			}
+			if payment.ApprovalError != "" {
+				expectedEmpty(t, "approval error", payment.ApprovalError)
+			}
+		}
+
+		entry := mustRetrieveEntryByID(t, ctx, unapprovedEntry.ID.String())
+		if !entry.IsApproved() {
+			expectedGot(t, "approved entry true", "approved entry false")
+		}
+		entry = mustRetrieveEntryByID(t, ctx, underpaidEntry.ID.String())
+		if entry.IsApproved() {
+			expectedGot(t, "approved entry false", "approved entry true")
+		}
+	})
+}
+
+func TestParsePaymentStatementCSV(t *testing.T) {
+	t.Run("statement with provider column names must be parsed", func(t *testing.T) {
+		statement := strings.Join([]string{
+			`Date,Name,Gross,Currency,Transaction ID`,
+			`26/05/2018,Harry Redknapp,12.34,gbp,REF-1`,
+			`26/05/2018,Jamie Redknapp,"1,012.50",GBP,REF-2`,
+			`,Subtotal,1024.84,GBP,`,
+		}, "\n")
+
+		lines, err := domain.ParsePaymentStatementCSV(strings.NewReader(statement))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantLines := []domain.PaymentStatementLine{
+			{LineNumber: 2, PaymentRef: "REF-1", Amount: 12.34, Currency: "GBP"},
+			{LineNumber: 3, PaymentRef: "REF-2", Amount: 1012.5, Currency: "GBP"},
+		}
+		cmpDiff(t, "statement lines", wantLines, lines)
+	})
+
+	t.Run("statement without currency column must be parsed", func(t *testing.T) {
+		statement := "payment_ref,amount\nREF-1,12.34\n"
+
+		lines, err := domain.ParsePaymentStatementCSV(strings.NewReader(statement))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantLines := []domain.PaymentStatementLine{
+			{LineNumber: 2, PaymentRef: "REF-1", Amount: 12.34},
+		}
+		cmpDiff(t, "statement lines", wantLines, lines)
+	})
+
+	t.Run("statement with missing columns must fail", func(t *testing.T) {
+		statement := "name,currency\nHarry Redknapp,GBP\n"
+
+		_, err := domain.ParsePaymentStatementCSV(strings.NewReader(statement))
+		if !cmp.ErrorType(err, domain.ValidationError{})().Success() {
+			expectedTypeOfGot(t, domain.ValidationError{}, err)
+		}
+	})
+
+	t.Run("statement with invalid amount must fail", func(t *testing.T) {
+		statement := "reference,amount\nREF-1,twelve\n"
+
+		_, err := domain.ParsePaymentStatementCSV(strings.NewReader(statement))
+		if !cmp.ErrorType(err, domain.ValidationError{})().Success() {
+			expectedTypeOfGot(t, domain.ValidationError{}, err)
+		}
+	})
+
+	t.Run("empty statement must fail", func(t *testing.T) {
+		_, err := domain.ParsePaymentStatementCSV(strings.NewReader(""))
+		if !cmp.ErrorType(err, domain.ValidationError{})().Success() {
+			expectedTypeOfGot(t, domain.ValidationError{}, err)
+		}
+	})
+}
@@ -87,6 +87,19 @@ func (s *ScoredEntryPredictionAgent) RetrieveScoredEntryPredictionByIDs(ctx cont
 	return retrievedScoredEntryPredictions[0], nil
 }
 
+// RetrieveScoredEntryPredictionsByStandingsID handles the retrieval of all existing ScoredEntryPredictions
+// that have been scored against the Standings matching the provided ID
+func (s *ScoredEntryPredictionAgent) RetrieveScoredEntryPredictionsByStandingsID(ctx context.Context, standingsID string) ([]ScoredEntryPrediction, error) {
+	retrievedScoredEntryPredictions, err := s.sepr.Select(ctx, map[string]interface{}{
+		"standings_id": standingsID,
+	}, false)
+	if err != nil {
+		return nil, domainErrorFromRepositoryError(err)
+	}
+
+	return retrievedScoredEntryPredictions, nil
+}
+
 // RetrieveLatestScoredEntryPredictionByEntryIDAndRoundNumber handles the retrieval of
 // the most recently created ScoredEntryPrediction by the provided entry ID and round number
 func (s *ScoredEntryPredictionAgent) RetrieveLatestScoredEntryPredictionByEntryIDAndRoundNumber(ctx context.Context, entryID string, roundNumber int) (*ScoredEntryPrediction, error) {
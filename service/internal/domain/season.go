@@ -29,6 +29,7 @@ type Season struct {
 	TeamIDs             []string           // slice of strings representing valid team IDs that exist within TeamsCollection
 	BasePoints          int64              // score that each player begins each match week with (before any hits are applied)
 	MaxRounds           int                // number of rounds after which season is considered completed (maximum number of games to be played by each team)
+	MatchWeekStrategy   string             // strategy used to infer the current match week number from retrieved standings, defaults to upstream
 }
 
 // GetState determines a Season's state based on a supplied timestamp
@@ -186,6 +187,10 @@ func ValidateSeason(s Season, tc TeamCollection) error {
 		return errors.New("predictions must be accepted for a longer duration than entries")
 	}
 
+	if !isValidMatchWeekStrategy(s.MatchWeekStrategy) {
+		return fmt.Errorf("match week strategy '%s' must be valid", s.MatchWeekStrategy)
+	}
+
 	// verify that each team exists and is not duplicated
 	if _, err := FilterTeamsByIDs(s.TeamIDs, tc); err != nil {
 		return err
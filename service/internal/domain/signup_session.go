@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// SignupStepPayment represents the step of the sign-up workflow at which an entrant pays for their newly-created Entry
+	SignupStepPayment = "payment"
+	// SignupStepComplete represents the end of the sign-up workflow, once an entrant has provided their payment details
+	SignupStepComplete = "complete"
+)
+
+// SignupSession represents the progress of a single entrant through the sign-up workflow, so that they can
+// return to the step they reached if they leave the workflow before it is complete
+type SignupSession struct {
+	ID        string     `db:"id"`
+	RealmName string     `db:"realm_name"`
+	SeasonID  string     `db:"season_id"`
+	EntryID   string     `db:"entry_id"`
+	Step      string     `db:"step"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt *time.Time `db:"updated_at"`
+	ExpiresAt time.Time  `db:"expires_at"`
+}
+
+// SignupSessionRepository defines the interface for transacting with our SignupSession data source
+type SignupSessionRepository interface {
+	Insert(ctx context.Context, session *SignupSession) error
+	Update(ctx context.Context, session *SignupSession) error
+	Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]SignupSession, error)
+	GenerateUniqueSignupSessionID(ctx context.Context) (string, error)
+}
+
+// SignupSessionAgent defines the behaviours for handling SignupSessions
+type SignupSessionAgent struct {
+	ssr SignupSessionRepository
+	cl  Clock
+}
+
+// StartSignupSession starts a new SignupSession for the provided newly-created Entry.
+// The SignupSession lasts for as long as the provided Season is accepting entries
+func (s *SignupSessionAgent) StartSignupSession(ctx context.Context, entry Entry, season Season) (SignupSession, error) {
+	realm := RealmFromContext(ctx)
+
+	if entry.RealmName != realm.Config.Name {
+		return SignupSession{}, ConflictError{errors.New("invalid realm")}
+	}
+	if entry.SeasonID != season.ID {
+		return SignupSession{}, ConflictError{errors.New("invalid season")}
+	}
+
+	now := s.cl.Now().Truncate(time.Second)
+	if !season.EntriesAccepted.Until.After(now) {
+		return SignupSession{}, ConflictError{errors.New("season is not currently accepting entries")}
+	}
+
+	id, err := s.ssr.GenerateUniqueSignupSessionID(ctx)
+	if err != nil {
+		return SignupSession{}, domainErrorFromRepositoryError(err)
+	}
+
+	session := SignupSession{
+		ID:        id,
+		RealmName: entry.RealmName,
+		SeasonID:  entry.SeasonID,
+		EntryID:   entry.ID.String(),
+		Step:      SignupStepPayment,
+		CreatedAt: now,
+		ExpiresAt: season.EntriesAccepted.Until,
+	}
+
+	if err := s.ssr.Insert(ctx, &session); err != nil {
+		return SignupSession{}, domainErrorFromRepositoryError(err)
+	}
+
+	return session, nil
+}
+
+// RetrieveSignupSessionByID retrieves the unexpired SignupSession with the provided ID that belongs to the current realm
+func (s *SignupSessionAgent) RetrieveSignupSessionByID(ctx context.Context, id string) (SignupSession, error) {
+	realm := RealmFromContext(ctx)
+
+	sessions, err := s.ssr.Select(ctx, map[string]interface{}{
+		"id":         id,
+		"realm_name": realm.Config.Name,
+	}, false)
+	if err != nil {
+		return SignupSession{}, domainErrorFromRepositoryError(err)
+	}
+
+	if len(sessions) != 1 {
+		return SignupSession{}, NotFoundError{fmt.Errorf("signup session id: %s not found", id)}
+	}
+
+	session := sessions[0]
+
+	if s.cl.Now().After(session.ExpiresAt) {
+		return SignupSession{}, NotFoundError{fmt.Errorf("signup session id: %s has expired", id)}
+	}
+
+	return session, nil
+}
+
+// UpdateSignupSessionStep moves the SignupSession with the provided ID on to the provided step of the sign-up workflow
+func (s *SignupSessionAgent) UpdateSignupSessionStep(ctx context.Context, id, step string) (SignupSession, error) {
+	if !isValidSignupStep(step) {
+		return SignupSession{}, ValidationError{Reasons: []string{fmt.Sprintf("%s is not a valid sign-up step", step)}}
+	}
+
+	session, err := s.RetrieveSignupSessionByID(ctx, id)
+	if err != nil {
+		return SignupSession{}, err
+	}
+
+	// a completed sign-up cannot be resumed
+	if session.Step == SignupStepComplete {
+		return SignupSession{}, ConflictError{errors.New("sign-up is already complete")}
+	}
+
+	now := s.cl.Now().Truncate(time.Second)
+	session.Step = step
+	session.UpdatedAt = &now
+
+	if err := s.ssr.Update(ctx, &session); err != nil {
+		return SignupSession{}, domainErrorFromRepositoryError(err)
+	}
+
+	return session, nil
+}
+
+// isValidSignupStep determines whether the provided step is a valid step of the sign-up workflow
+func isValidSignupStep(step string) bool {
+	switch step {
+	case SignupStepPayment, SignupStepComplete:
+		return true
+	}
+	return false
+}
+
+// NewSignupSessionAgent returns a new SignupSessionAgent using the provided repository
+func NewSignupSessionAgent(ssr SignupSessionRepository, cl Clock) (*SignupSessionAgent, error) {
+	switch {
+	case ssr == nil:
+		return nil, fmt.Errorf("signup session repository: %w", ErrIsNil)
+	case cl == nil:
+		return nil, fmt.Errorf("clock: %w", ErrIsNil)
+	}
+	return &SignupSessionAgent{ssr, cl}, nil
+}
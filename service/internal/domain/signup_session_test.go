@@ -0,0 +1,175 @@
+package domain_test
+
+import (
+	"errors"
+	"prediction-league/service/internal/domain"
+	"testing"
+	"time"
+
+	"gotest.tools/assert/cmp"
+)
+
+func TestNewSignupSessionAgent(t *testing.T) {
+	t.Run("passing invalid parameters must return expected error", func(t *testing.T) {
+		cl := &mockClock{}
+
+		tt := []struct {
+			sgr     domain.SignupSessionRepository
+			cl      domain.Clock
+			wantErr error
+		}{
+			{nil, cl, domain.ErrIsNil},
+			{sgr, nil, domain.ErrIsNil},
+			{sgr, cl, nil},
+		}
+
+		for idx, tc := range tt {
+			agent, gotErr := domain.NewSignupSessionAgent(tc.sgr, tc.cl)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("tc #%d: want error %s (%T), got %s (%T)", idx, tc.wantErr, tc.wantErr, gotErr, gotErr)
+			}
+			if tc.wantErr == nil && agent == nil {
+				t.Fatalf("tc #%d: want non-empty agent, got nil", idx)
+			}
+		}
+	})
+}
+
+func TestSignupSessionAgent(t *testing.T) {
+	t.Cleanup(truncate)
+
+	now := time.Now().Truncate(time.Second)
+
+	season := testSeason
+	season.EntriesAccepted.From = now.Add(-24 * time.Hour)
+	season.EntriesAccepted.Until = now.Add(24 * time.Hour)
+
+	agent, err := domain.NewSignupSessionAgent(sgr, &mockClock{t: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := insertEntry(t, generateTestEntry(t, "Harry Redknapp", "MrHarryR", "harry.redknapp@football.net"))
+
+	var session domain.SignupSession
+
+	t.Run("start signup session for a new entry must succeed", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		session, err = agent.StartSignupSession(ctx, entry, season)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if session.ID == "" {
+			expectedNonEmpty(t, "SignupSession.ID")
+		}
+
+		wantSession := domain.SignupSession{
+			ID:        session.ID,
+			RealmName: testRealmName,
+			SeasonID:  season.ID,
+			EntryID:   entry.ID.String(),
+			Step:      domain.SignupStepPayment,
+			CreatedAt: now,
+			ExpiresAt: season.EntriesAccepted.Until,
+		}
+		cmpDiff(t, "signup session", wantSession, session)
+	})
+
+	t.Run("start signup session for an entry of another season must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		otherSeason := season
+		otherSeason.ID = "not_the_entry_season"
+
+		_, err := agent.StartSignupSession(ctx, entry, otherSeason)
+		if !cmp.ErrorType(err, domain.ConflictError{})().Success() {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+	})
+
+	t.Run("start signup session after entries have closed must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		closedSeason := season
+		closedSeason.EntriesAccepted.Until = now.Add(-time.Hour)
+
+		_, err := agent.StartSignupSession(ctx, entry, closedSeason)
+		if !cmp.ErrorType(err, domain.ConflictError{})().Success() {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+	})
+
+	t.Run("retrieve signup session must succeed", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		retrieved, err := agent.RetrieveSignupSessionByID(ctx, session.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cmpDiff(t, "signup session", session, retrieved)
+	})
+
+	t.Run("retrieve signup session from another realm must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		domain.RealmFromContext(ctx).Config.Name = "not_the_test_realm"
+
+		_, err := agent.RetrieveSignupSessionByID(ctx, session.ID)
+		if !cmp.ErrorType(err, domain.NotFoundError{})().Success() {
+			expectedTypeOfGot(t, domain.NotFoundError{}, err)
+		}
+	})
+
+	t.Run("retrieve expired signup session must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		expiredAgent, err := domain.NewSignupSessionAgent(sgr, &mockClock{t: season.EntriesAccepted.Until.Add(time.Second)})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = expiredAgent.RetrieveSignupSessionByID(ctx, session.ID)
+		if !cmp.ErrorType(err, domain.NotFoundError{})().Success() {
+			expectedTypeOfGot(t, domain.NotFoundError{}, err)
+		}
+	})
+
+	t.Run("update signup session with an invalid step must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		_, err := agent.UpdateSignupSessionStep(ctx, session.ID, "not_a_step")
+		if !cmp.ErrorType(err, domain.ValidationError{})().Success() {
+			expectedTypeOfGot(t, domain.ValidationError{}, err)
+		}
+	})
+
+	t.Run("complete signup session must succeed only once", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		completed, err := agent.UpdateSignupSessionStep(ctx, session.ID, domain.SignupStepComplete)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantSession := session
+		wantSession.Step = domain.SignupStepComplete
+		wantSession.UpdatedAt = &now
+		cmpDiff(t, "signup session", wantSession, completed)
+
+		// a completed sign-up cannot return to an earlier step
+		_, err = agent.UpdateSignupSessionStep(ctx, session.ID, domain.SignupStepPayment)
+		if !cmp.ErrorType(err, domain.ConflictError{})().Success() {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+	})
+}
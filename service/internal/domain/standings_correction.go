@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StandingsCorrection represents a change to the rankings of a Standings that had already been finalised,
+// such as a points deduction or an upheld appeal that is applied upstream after the round has completed
+type StandingsCorrection struct {
+	ID                uuid.UUID         `db:"id" json:"id"`
+	StandingsID       uuid.UUID         `db:"standings_id" json:"standings_id"`
+	SeasonID          string            `db:"season_id" json:"season_id"`
+	RoundNumber       int               `db:"round_number" json:"round_number"`
+	PreviousRankings  []RankingWithMeta `db:"previous_rankings" json:"previous_rankings"`
+	CorrectedRankings []RankingWithMeta `db:"corrected_rankings" json:"corrected_rankings"`
+	CreatedAt         time.Time         `db:"created_at" json:"created_at"`
+}
+
+// StandingsCorrectionRepository defines the interface for transacting with our StandingsCorrection data source
+type StandingsCorrectionRepository interface {
+	Insert(ctx context.Context, correction *StandingsCorrection) error
+	Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]StandingsCorrection, error)
+}
+
+// StandingsCorrectionAgent defines the behaviours for handling StandingsCorrections
+type StandingsCorrectionAgent struct {
+	scr StandingsCorrectionRepository
+	cl  Clock
+}
+
+// RecordCorrection stores a new StandingsCorrection representing the change from the provided finalised Standings
+// to the provided corrected rankings
+func (s *StandingsCorrectionAgent) RecordCorrection(ctx context.Context, stnd Standings, corrected []RankingWithMeta) (StandingsCorrection, error) {
+	if !stnd.Finalised {
+		return StandingsCorrection{}, ConflictError{errors.New("cannot correct standings that have not been finalised")}
+	}
+
+	if rankingsWithMetaMatch(stnd.Rankings, corrected) {
+		return StandingsCorrection{}, ConflictError{errors.New("corrected rankings do not differ from finalised rankings")}
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return StandingsCorrection{}, InternalError{err}
+	}
+
+	correction := StandingsCorrection{
+		ID:                id,
+		StandingsID:       stnd.ID,
+		SeasonID:          stnd.SeasonID,
+		RoundNumber:       stnd.RoundNumber,
+		PreviousRankings:  stnd.Rankings,
+		CorrectedRankings: corrected,
+		CreatedAt:         s.cl.Now().Truncate(time.Second),
+	}
+
+	if err := s.scr.Insert(ctx, &correction); err != nil {
+		return StandingsCorrection{}, domainErrorFromRepositoryError(err)
+	}
+
+	return correction, nil
+}
+
+// RetrieveCorrectionsBySeasonID retrieves all StandingsCorrections for the provided season ID, most recent first
+func (s *StandingsCorrectionAgent) RetrieveCorrectionsBySeasonID(ctx context.Context, seasonID string) ([]StandingsCorrection, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleViewer) {
+		return nil, UnauthorizedError{}
+	}
+
+	corrections, err := s.scr.Select(ctx, map[string]interface{}{
+		"season_id": seasonID,
+	}, false)
+	if err != nil {
+		return nil, domainErrorFromRepositoryError(err)
+	}
+
+	sort.SliceStable(corrections, func(i, j int) bool {
+		return corrections[i].CreatedAt.After(corrections[j].CreatedAt)
+	})
+
+	return corrections, nil
+}
+
+// NewStandingsCorrectionAgent returns a new StandingsCorrectionAgent using the provided repository
+func NewStandingsCorrectionAgent(scr StandingsCorrectionRepository, cl Clock) (*StandingsCorrectionAgent, error) {
+	switch {
+	case scr == nil:
+		return nil, fmt.Errorf("standings correction repository: %w", ErrIsNil)
+	case cl == nil:
+		return nil, fmt.Errorf("clock: %w", ErrIsNil)
+	}
+	return &StandingsCorrectionAgent{scr: scr, cl: cl}, nil
+}
@@ -0,0 +1,235 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"prediction-league/service/internal/domain"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewStandingsCorrectionAgent(t *testing.T) {
+	t.Run("passing invalid parameters must return expected error", func(t *testing.T) {
+		cl := &mockClock{}
+
+		tt := []struct {
+			scr     domain.StandingsCorrectionRepository
+			cl      domain.Clock
+			wantErr error
+		}{
+			{nil, cl, domain.ErrIsNil},
+			{scr, nil, domain.ErrIsNil},
+			{scr, cl, nil},
+		}
+		for idx, tc := range tt {
+			agent, gotErr := domain.NewStandingsCorrectionAgent(tc.scr, tc.cl)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("tc #%d: want error %s (%T), got %s (%T)", idx, tc.wantErr, tc.wantErr, gotErr, gotErr)
+			}
+			if tc.wantErr == nil && agent == nil {
+				t.Fatalf("tc #%d: want non-empty agent, got nil", idx)
+			}
+		}
+	})
+}
+
+func TestStandingsCorrectionAgent_RecordCorrection(t *testing.T) {
+	t.Cleanup(truncate)
+
+	agent, err := domain.NewStandingsCorrectionAgent(scr, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stnd := insertStandings(t, generateTestCorrectableStandings(t))
+	corrected := swapTopTwoRankings(stnd.Rankings)
+
+	ctx, cancel := testContextDefault(t)
+	defer cancel()
+
+	t.Run("record correction for standings that have not been finalised must fail", func(t *testing.T) {
+		notFinalised := stnd
+		notFinalised.Finalised = false
+
+		_, err := agent.RecordCorrection(ctx, notFinalised, corrected)
+		if !errors.As(err, &domain.ConflictError{}) {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+	})
+
+	t.Run("record correction with unchanged rankings must fail", func(t *testing.T) {
+		_, err := agent.RecordCorrection(ctx, stnd, stnd.Rankings)
+		if !errors.As(err, &domain.ConflictError{}) {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+	})
+
+	t.Run("record correction with changed rankings must succeed", func(t *testing.T) {
+		gotCorrection, err := agent.RecordCorrection(ctx, stnd, corrected)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantCorrection := domain.StandingsCorrection{
+			ID:                gotCorrection.ID,
+			StandingsID:       stnd.ID,
+			SeasonID:          stnd.SeasonID,
+			RoundNumber:       stnd.RoundNumber,
+			PreviousRankings:  stnd.Rankings,
+			CorrectedRankings: corrected,
+			CreatedAt:         testDate,
+		}
+		cmpDiff(t, "standings correction", wantCorrection, gotCorrection)
+
+		retrieved, err := agent.RetrieveCorrectionsBySeasonID(domain.SetAdminUserOnContext(ctx, testSuperAdmin), stnd.SeasonID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(retrieved) != 1 {
+			expectedGot(t, 1, len(retrieved))
+		}
+	})
+}
+
+func TestRetrieveLatestStandingsWorker_ProcessCorrectedStandings(t *testing.T) {
+	t.Cleanup(truncate)
+
+	ctx, cancel := testContextDefault(t)
+	defer cancel()
+
+	entry := insertEntry(t, generateTestEntry(t,
+		"Harry Redknapp",
+		"MrHarryR",
+		"harry.redknapp@football.net",
+	))
+	entryPrediction := insertEntryPrediction(t, generateTestEntryPrediction(t, entry.ID))
+	stnd := insertStandings(t, generateTestCorrectableStandings(t))
+	sep := insertScoredEntryPrediction(t, generateTestScoredEntryPrediction(t, entryPrediction.ID, stnd.ID))
+
+	ea, err := domain.NewEntryAgent(er, epr, sr, sc, aa, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sa, err := domain.NewStandingsAgent(sr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ssa, err := domain.NewStandingsSnapshotAgent(ssr, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sca, err := domain.NewStandingsCorrectionAgent(scr, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sepa, err := domain.NewScoredEntryPredictionAgent(er, epr, sr, sepr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	emailIssuer := &mockScoresCorrectedEmailIssuer{
+		mux:        &sync.Mutex{},
+		prevScores: make(map[string]int),
+	}
+
+	worker := newTestRetrieveLatestStandingsWorker(t, domain.RetrieveLatestStandingsWorkerParams{
+		Season:                     testSeason,
+		EntryAgent:                 ea,
+		StandingsAgent:             sa,
+		SnapshotAgent:              ssa,
+		CorrectionAgent:            sca,
+		ScoredEntryPredictionAgent: sepa,
+		MatchWeekSubmissionAgent:   newMatchWeekSubmissionAgent(t, newMatchWeekSubmissionRepo(t, newUUID(t), testDate)),
+		MatchWeekResultAgent:       newMatchWeekResultAgent(t, newMatchWeekResultRepo(t, testDate)),
+		EmailIssuer:                emailIssuer,
+	})
+
+	t.Run("unchanged finalised standings must not be corrected", func(t *testing.T) {
+		if err := worker.ProcessCorrectedStandings(ctx, stnd, stnd); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := scr.Select(ctx, map[string]interface{}{"standings_id": stnd.ID}, false); !errors.As(err, &domain.MissingDBRecordError{}) {
+			expectedTypeOfGot(t, domain.MissingDBRecordError{}, err)
+		}
+		if len(emailIssuer.prevScores) != 0 {
+			expectedGot(t, 0, len(emailIssuer.prevScores))
+		}
+	})
+
+	t.Run("corrected finalised standings must rescore and notify entrants whose score has changed", func(t *testing.T) {
+		clientStnd := stnd
+		clientStnd.Rankings = swapTopTwoRankings(stnd.Rankings)
+
+		if err := worker.ProcessCorrectedStandings(ctx, stnd, clientStnd); err != nil {
+			t.Fatal(err)
+		}
+
+		corrections, err := scr.Select(ctx, map[string]interface{}{"standings_id": stnd.ID}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(corrections) != 1 {
+			expectedGot(t, 1, len(corrections))
+		}
+
+		gotStnd, err := sa.RetrieveStandingsByID(ctx, stnd.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !gotStnd.Finalised {
+			expectedGot(t, true, gotStnd.Finalised)
+		}
+		cmpDiff(t, "corrected rankings", clientStnd.Rankings, gotStnd.Rankings)
+
+		gotSep, err := sepa.RetrieveScoredEntryPredictionByIDs(ctx, entryPrediction.ID.String(), stnd.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotSep.Score == sep.Score {
+			t.Fatalf("want rescored entry prediction score to differ from %d", sep.Score)
+		}
+
+		wantPrevScores := map[string]int{entryPrediction.ID.String(): sep.Score}
+		cmpDiff(t, "previous scores", wantPrevScores, emailIssuer.prevScores)
+	})
+}
+
+type mockScoresCorrectedEmailIssuer struct {
+	domain.RoundCompleteEmailIssuer
+	mux        *sync.Mutex
+	prevScores map[string]int
+}
+
+func (m *mockScoresCorrectedEmailIssuer) IssueScoresCorrectedEmail(ctx context.Context, sep domain.ScoredEntryPrediction, previousScore int) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.prevScores[sep.EntryPredictionID.String()] = previousScore
+	return nil
+}
+
+// generateTestCorrectableStandings generates a finalised Standings entity whose rankings have distinct positions
+func generateTestCorrectableStandings(t *testing.T) domain.Standings {
+	t.Helper()
+
+	stnd := generateTestStandings(t)
+	for idx := range stnd.Rankings {
+		stnd.Rankings[idx].Position = idx + 1
+	}
+	stnd.CreatedAt = testDate.Add(-time.Hour)
+
+	return stnd
+}
+
+// swapTopTwoRankings returns a copy of the provided rankings with the top two teams switched
+func swapTopTwoRankings(rankings []domain.RankingWithMeta) []domain.RankingWithMeta {
+	swapped := make([]domain.RankingWithMeta, len(rankings))
+	copy(swapped, rankings)
+	swapped[0].ID, swapped[1].ID = rankings[1].ID, rankings[0].ID
+	return swapped
+}
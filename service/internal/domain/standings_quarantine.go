@@ -0,0 +1,278 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// QuarantineStatusPending represents quarantined standings that are awaiting review by an admin
+	QuarantineStatusPending = "pending"
+	// QuarantineStatusAccepted represents quarantined standings whose anomalies have been accepted by an admin
+	QuarantineStatusAccepted = "accepted"
+	// QuarantineStatusRejected represents quarantined standings that have been rejected by an admin
+	QuarantineStatusRejected = "rejected"
+)
+
+const (
+	// StandingsAnomalyRoundNumberDecreased represents a round number that is lower than the one previously stored
+	StandingsAnomalyRoundNumberDecreased = "round_number_decreased"
+	// StandingsAnomalyGamesPlayedDecreased represents a team that has played fewer games than previously stored
+	StandingsAnomalyGamesPlayedDecreased = "games_played_decreased"
+	// StandingsAnomalyGamesPlayedJumped represents a team that has played more games than the change in round number allows
+	StandingsAnomalyGamesPlayedJumped = "games_played_jumped"
+)
+
+// StandingsAnomaly represents a single discrepancy between the latest Standings and those previously stored
+type StandingsAnomaly struct {
+	Type   string `json:"type"`
+	TeamID string `json:"team_id,omitempty"` // empty for anomalies that do not pertain to a single team
+	From   int    `json:"from"`              // previously stored round number or games played
+	To     int    `json:"to"`                // latest round number or games played
+	Rounds int    `json:"rounds,omitempty"`  // number of rounds elapsed between the previous and latest standings
+}
+
+// String returns a description of the StandingsAnomaly
+func (a StandingsAnomaly) String() string {
+	switch a.Type {
+	case StandingsAnomalyRoundNumberDecreased:
+		return fmt.Sprintf("round number moved backwards from %d to %d", a.From, a.To)
+	case StandingsAnomalyGamesPlayedDecreased:
+		return fmt.Sprintf("team %s: games played decreased from %d to %d", a.TeamID, a.From, a.To)
+	case StandingsAnomalyGamesPlayedJumped:
+		return fmt.Sprintf("team %s: games played increased from %d to %d across %d round(s)", a.TeamID, a.From, a.To, a.Rounds)
+	}
+	return fmt.Sprintf("%s: %d to %d", a.Type, a.From, a.To)
+}
+
+// QuarantinedStandings represents a Standings update from an external data source that has been held back from
+// processing, due to one or more anomalies being detected when compared against the Standings we have already stored
+type QuarantinedStandings struct {
+	ID          uuid.UUID          `db:"id" json:"id"`
+	SeasonID    string             `db:"season_id" json:"season_id"`
+	RoundNumber int                `db:"round_number" json:"round_number"`
+	Rankings    []RankingWithMeta  `db:"rankings" json:"rankings"`
+	Anomalies   []StandingsAnomaly `db:"anomalies" json:"anomalies"`
+	Status      string             `db:"status" json:"status"`
+	CreatedAt   time.Time          `db:"created_at" json:"created_at"`
+	ReviewedAt  *time.Time         `db:"reviewed_at" json:"reviewed_at"`
+}
+
+// hasAnomalies determines whether the provided anomalies are all present within the QuarantinedStandings' own anomalies
+func (q QuarantinedStandings) hasAnomalies(anomalies []StandingsAnomaly) bool {
+	known := make(map[StandingsAnomaly]struct{})
+	for _, a := range q.Anomalies {
+		known[a] = struct{}{}
+	}
+	for _, a := range anomalies {
+		if _, ok := known[a]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// QuarantinedStandingsRepository defines the interface for transacting with our QuarantinedStandings data source
+type QuarantinedStandingsRepository interface {
+	Insert(ctx context.Context, qs *QuarantinedStandings) error
+	Update(ctx context.Context, qs *QuarantinedStandings) error
+	Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]QuarantinedStandings, error)
+}
+
+// StandingsQuarantineAgent defines the behaviours for handling QuarantinedStandings
+type StandingsQuarantineAgent struct {
+	qr QuarantinedStandingsRepository
+	cl Clock
+}
+
+// QuarantineStandings holds back the provided Standings for review, along with the anomalies that were detected.
+// If the same anomalies are already pending review for the Standings' season and round, the existing
+// QuarantinedStandings are returned instead so that repeated runs against the same upstream data do not pile up
+func (s *StandingsQuarantineAgent) QuarantineStandings(ctx context.Context, stnd Standings, anomalies []StandingsAnomaly) (QuarantinedStandings, error) {
+	if len(anomalies) == 0 {
+		return QuarantinedStandings{}, ConflictError{fmt.Errorf("standings have no anomalies to quarantine")}
+	}
+
+	existing, err := s.qr.Select(ctx, map[string]interface{}{
+		"season_id":    stnd.SeasonID,
+		"round_number": stnd.RoundNumber,
+		"status":       QuarantineStatusPending,
+	}, false)
+	if err != nil && !errors.As(err, &MissingDBRecordError{}) {
+		return QuarantinedStandings{}, domainErrorFromRepositoryError(err)
+	}
+
+	for _, qs := range existing {
+		if qs.hasAnomalies(anomalies) && len(qs.Anomalies) == len(anomalies) {
+			// already awaiting review
+			return qs, nil
+		}
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return QuarantinedStandings{}, InternalError{err}
+	}
+
+	qs := QuarantinedStandings{
+		ID:          id,
+		SeasonID:    stnd.SeasonID,
+		RoundNumber: stnd.RoundNumber,
+		Rankings:    stnd.Rankings,
+		Anomalies:   anomalies,
+		Status:      QuarantineStatusPending,
+		CreatedAt:   s.cl.Now().Truncate(time.Second),
+	}
+
+	if err := s.qr.Insert(ctx, &qs); err != nil {
+		return QuarantinedStandings{}, domainErrorFromRepositoryError(err)
+	}
+
+	return qs, nil
+}
+
+// IsAccepted determines whether the provided anomalies have already been accepted by an admin
+// for the provided Standings' season and round
+func (s *StandingsQuarantineAgent) IsAccepted(ctx context.Context, stnd Standings, anomalies []StandingsAnomaly) (bool, error) {
+	accepted, err := s.qr.Select(ctx, map[string]interface{}{
+		"season_id":    stnd.SeasonID,
+		"round_number": stnd.RoundNumber,
+		"status":       QuarantineStatusAccepted,
+	}, false)
+	if err != nil {
+		if errors.As(err, &MissingDBRecordError{}) {
+			return false, nil
+		}
+		return false, domainErrorFromRepositoryError(err)
+	}
+
+	for _, qs := range accepted {
+		if qs.hasAnomalies(anomalies) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// RetrieveQuarantinedStandingsBySeasonID retrieves all QuarantinedStandings for the provided season ID
+func (s *StandingsQuarantineAgent) RetrieveQuarantinedStandingsBySeasonID(ctx context.Context, seasonID string) ([]QuarantinedStandings, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleViewer) {
+		return nil, UnauthorizedError{}
+	}
+
+	retrieved, err := s.qr.Select(ctx, map[string]interface{}{
+		"season_id": seasonID,
+	}, false)
+	if err != nil {
+		return nil, domainErrorFromRepositoryError(err)
+	}
+
+	sort.SliceStable(retrieved, func(i, j int) bool {
+		return retrieved[i].CreatedAt.After(retrieved[j].CreatedAt)
+	})
+
+	return retrieved, nil
+}
+
+// ReviewQuarantinedStandings sets the provided status on the pending QuarantinedStandings that matches the provided ID.
+// Accepting allows the next standings retrieval for the same season and round to proceed in spite of these anomalies
+func (s *StandingsQuarantineAgent) ReviewQuarantinedStandings(ctx context.Context, id string, status string) (QuarantinedStandings, error) {
+	// standings are shared by every realm, so reviewing them requires a superadmin of all realms
+	if !HasAdminRoleForRealm(ctx, AdminRealmAll, AdminRoleSuperAdmin) {
+		return QuarantinedStandings{}, UnauthorizedError{}
+	}
+
+	if status != QuarantineStatusAccepted && status != QuarantineStatusRejected {
+		return QuarantinedStandings{}, ValidationError{Reasons: []string{"invalid review status"}}
+	}
+
+	retrieved, err := s.qr.Select(ctx, map[string]interface{}{
+		"id": id,
+	}, false)
+	if err != nil {
+		return QuarantinedStandings{}, domainErrorFromRepositoryError(err)
+	}
+
+	qs := retrieved[0]
+	if qs.Status != QuarantineStatusPending {
+		return QuarantinedStandings{}, ConflictError{fmt.Errorf("quarantined standings have already been %s", qs.Status)}
+	}
+
+	now := s.cl.Now().Truncate(time.Second)
+	qs.Status = status
+	qs.ReviewedAt = &now
+
+	if err := s.qr.Update(ctx, &qs); err != nil {
+		return QuarantinedStandings{}, domainErrorFromRepositoryError(err)
+	}
+
+	return qs, nil
+}
+
+// NewStandingsQuarantineAgent returns a new StandingsQuarantineAgent using the provided repository
+func NewStandingsQuarantineAgent(qr QuarantinedStandingsRepository, cl Clock) (*StandingsQuarantineAgent, error) {
+	switch {
+	case qr == nil:
+		return nil, fmt.Errorf("quarantined standings repository: %w", ErrIsNil)
+	case cl == nil:
+		return nil, fmt.Errorf("clock: %w", ErrIsNil)
+	}
+	return &StandingsQuarantineAgent{qr: qr, cl: cl}, nil
+}
+
+// DetectStandingsAnomalies compares the provided latest Standings against the previous Standings that have been stored
+// for the same Season, and returns every anomaly that suggests the latest Standings are not to be trusted
+func DetectStandingsAnomalies(latest, previous Standings) []StandingsAnomaly {
+	var anomalies []StandingsAnomaly
+
+	roundDiff := latest.RoundNumber - previous.RoundNumber
+	if roundDiff < 0 {
+		anomalies = append(anomalies, StandingsAnomaly{
+			Type: StandingsAnomalyRoundNumberDecreased,
+			From: previous.RoundNumber,
+			To:   latest.RoundNumber,
+		})
+	}
+
+	// each team can play at most one game per round, plus one more to allow for rearranged fixtures
+	maxGamesIncrease := roundDiff + 1
+
+	prevPlayed := make(map[string]int)
+	for _, rnk := range previous.Rankings {
+		prevPlayed[rnk.ID] = rnk.MetaData[MetaKeyPlayedGames]
+	}
+
+	for _, rnk := range latest.Rankings {
+		prev, ok := prevPlayed[rnk.ID]
+		if !ok {
+			continue
+		}
+		played := rnk.MetaData[MetaKeyPlayedGames]
+		switch {
+		case played < prev:
+			anomalies = append(anomalies, StandingsAnomaly{
+				Type:   StandingsAnomalyGamesPlayedDecreased,
+				TeamID: rnk.ID,
+				From:   prev,
+				To:     played,
+			})
+		case roundDiff >= 0 && played-prev > maxGamesIncrease:
+			anomalies = append(anomalies, StandingsAnomaly{
+				Type:   StandingsAnomalyGamesPlayedJumped,
+				TeamID: rnk.ID,
+				From:   prev,
+				To:     played,
+				Rounds: roundDiff,
+			})
+		}
+	}
+
+	return anomalies
+}
@@ -0,0 +1,315 @@
+package domain_test
+
+import (
+	"errors"
+	"prediction-league/service/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewStandingsQuarantineAgent(t *testing.T) {
+	t.Run("passing invalid parameters must return expected error", func(t *testing.T) {
+		cl := &mockClock{}
+
+		tt := []struct {
+			sqr     domain.QuarantinedStandingsRepository
+			cl      domain.Clock
+			wantErr error
+		}{
+			{nil, cl, domain.ErrIsNil},
+			{sqr, nil, domain.ErrIsNil},
+			{sqr, cl, nil},
+		}
+		for idx, tc := range tt {
+			agent, gotErr := domain.NewStandingsQuarantineAgent(tc.sqr, tc.cl)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("tc #%d: want error %s (%T), got %s (%T)", idx, tc.wantErr, tc.wantErr, gotErr, gotErr)
+			}
+			if tc.wantErr == nil && agent == nil {
+				t.Fatalf("tc #%d: want non-empty agent, got nil", idx)
+			}
+		}
+	})
+}
+
+func TestStandingsAnomaly_String(t *testing.T) {
+	tt := []struct {
+		anomaly domain.StandingsAnomaly
+		want    string
+	}{
+		{domain.StandingsAnomaly{Type: domain.StandingsAnomalyRoundNumberDecreased, From: 5, To: 4}, "round number moved backwards from 5 to 4"},
+		{domain.StandingsAnomaly{Type: domain.StandingsAnomalyGamesPlayedDecreased, TeamID: "team_b", From: 4, To: 3}, "team team_b: games played decreased from 4 to 3"},
+		{domain.StandingsAnomaly{Type: domain.StandingsAnomalyGamesPlayedJumped, TeamID: "team_a", From: 5, To: 9, Rounds: 1}, "team team_a: games played increased from 5 to 9 across 1 round(s)"},
+	}
+	for _, tc := range tt {
+		cmpDiff(t, "anomaly description", tc.want, tc.anomaly.String())
+	}
+}
+
+func TestDetectStandingsAnomalies(t *testing.T) {
+	newStandings := func(roundNumber int, played ...int) domain.Standings {
+		stnd := domain.Standings{RoundNumber: roundNumber}
+		for idx, p := range played {
+			rnk := domain.NewRankingWithMeta()
+			rnk.ID = []string{"team_a", "team_b"}[idx]
+			rnk.Position = idx + 1
+			rnk.MetaData[domain.MetaKeyPlayedGames] = p
+			stnd.Rankings = append(stnd.Rankings, rnk)
+		}
+		return stnd
+	}
+
+	tt := []struct {
+		name          string
+		latest        domain.Standings
+		previous      domain.Standings
+		wantAnomalies []domain.StandingsAnomaly
+	}{
+		{
+			name:     "same round with one more game played must not produce anomalies",
+			latest:   newStandings(5, 5, 5),
+			previous: newStandings(5, 4, 5),
+		},
+		{
+			name:     "next round with rearranged fixture must not produce anomalies",
+			latest:   newStandings(6, 6, 7),
+			previous: newStandings(5, 5, 5),
+		},
+		{
+			name:          "round number moving backwards must produce anomaly",
+			latest:        newStandings(4, 5, 5),
+			previous:      newStandings(5, 5, 5),
+			wantAnomalies: []domain.StandingsAnomaly{{Type: domain.StandingsAnomalyRoundNumberDecreased, From: 5, To: 4}},
+		},
+		{
+			name:          "games played decreasing must produce anomaly",
+			latest:        newStandings(5, 5, 3),
+			previous:      newStandings(5, 5, 4),
+			wantAnomalies: []domain.StandingsAnomaly{{Type: domain.StandingsAnomalyGamesPlayedDecreased, TeamID: "team_b", From: 4, To: 3}},
+		},
+		{
+			name:          "games played jumping further than round change allows must produce anomaly",
+			latest:        newStandings(6, 9, 6),
+			previous:      newStandings(5, 5, 5),
+			wantAnomalies: []domain.StandingsAnomaly{{Type: domain.StandingsAnomalyGamesPlayedJumped, TeamID: "team_a", From: 5, To: 9, Rounds: 1}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gotAnomalies := domain.DetectStandingsAnomalies(tc.latest, tc.previous)
+			cmpDiff(t, "anomalies", tc.wantAnomalies, gotAnomalies)
+		})
+	}
+}
+
+func TestStandingsQuarantineAgent_QuarantineStandings(t *testing.T) {
+	t.Cleanup(truncate)
+
+	agent, err := domain.NewStandingsQuarantineAgent(sqr, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stnd := generateTestStandings(t)
+	anomalies := []domain.StandingsAnomaly{{Type: domain.StandingsAnomalyRoundNumberDecreased, From: 2, To: 1}}
+
+	t.Run("quarantine standings with anomalies must succeed", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		gotQS, err := agent.QuarantineStandings(ctx, stnd, anomalies)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantQS := domain.QuarantinedStandings{
+			ID:          gotQS.ID,
+			SeasonID:    stnd.SeasonID,
+			RoundNumber: stnd.RoundNumber,
+			Rankings:    stnd.Rankings,
+			Anomalies:   anomalies,
+			Status:      domain.QuarantineStatusPending,
+			CreatedAt:   testDate,
+		}
+		cmpDiff(t, "quarantined standings", wantQS, gotQS)
+
+		// quarantining the same anomalies again must return the existing record
+		againQS, err := agent.QuarantineStandings(ctx, stnd, anomalies)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if againQS.ID != gotQS.ID {
+			expectedGot(t, gotQS.ID, againQS.ID)
+		}
+	})
+
+	t.Run("quarantine standings without anomalies must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		_, err := agent.QuarantineStandings(ctx, stnd, nil)
+		if !errors.As(err, &domain.ConflictError{}) {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+	})
+}
+
+func TestStandingsQuarantineAgent_ReviewQuarantinedStandings(t *testing.T) {
+	t.Cleanup(truncate)
+
+	agent, err := domain.NewStandingsQuarantineAgent(sqr, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stnd := generateTestStandings(t)
+	anomalies := []domain.StandingsAnomaly{{Type: domain.StandingsAnomalyGamesPlayedDecreased, TeamID: "team_a", From: 4, To: 3}}
+
+	ctx, cancel := testContextDefault(t)
+	defer cancel()
+
+	qs, err := agent.QuarantineStandings(ctx, stnd, anomalies)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("review quarantined standings without admin credentials must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		_, err := agent.ReviewQuarantinedStandings(ctx, qs.ID.String(), domain.QuarantineStatusAccepted)
+		if !errors.As(err, &domain.UnauthorizedError{}) {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("review quarantined standings with invalid status must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		_, err := agent.ReviewQuarantinedStandings(ctx, qs.ID.String(), domain.QuarantineStatusPending)
+		if !errors.As(err, &domain.ValidationError{}) {
+			expectedTypeOfGot(t, domain.ValidationError{}, err)
+		}
+	})
+
+	t.Run("review non-existent quarantined standings must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		_, err := agent.ReviewQuarantinedStandings(ctx, uuid.New().String(), domain.QuarantineStatusAccepted)
+		if !errors.As(err, &domain.NotFoundError{}) {
+			expectedTypeOfGot(t, domain.NotFoundError{}, err)
+		}
+	})
+
+	t.Run("accept quarantined standings must succeed and mark anomalies as accepted", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		accepted, err := agent.IsAccepted(ctx, stnd, anomalies)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if accepted {
+			expectedGot(t, false, accepted)
+		}
+
+		gotQS, err := agent.ReviewQuarantinedStandings(ctx, qs.ID.String(), domain.QuarantineStatusAccepted)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantQS := qs
+		wantQS.Status = domain.QuarantineStatusAccepted
+		wantQS.ReviewedAt = &testDate
+		cmpDiff(t, "reviewed quarantined standings", wantQS, gotQS)
+
+		accepted, err = agent.IsAccepted(ctx, stnd, anomalies)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !accepted {
+			expectedGot(t, true, accepted)
+		}
+
+		// reviewing an already reviewed record must fail
+		_, err = agent.ReviewQuarantinedStandings(ctx, qs.ID.String(), domain.QuarantineStatusRejected)
+		if !errors.As(err, &domain.ConflictError{}) {
+			expectedTypeOfGot(t, domain.ConflictError{}, err)
+		}
+	})
+}
+
+func TestRetrieveLatestStandingsWorker_QuarantineIfAnomalous(t *testing.T) {
+	t.Cleanup(truncate)
+
+	sa, err := domain.NewStandingsAgent(sr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qa, err := domain.NewStandingsQuarantineAgent(sqr, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored := generateTestStandings(t)
+	stored.RoundNumber = 5
+	stored.CreatedAt = testDate.Add(-time.Hour)
+	for idx := range stored.Rankings {
+		stored.Rankings[idx].MetaData[domain.MetaKeyPlayedGames] = 5
+	}
+	insertStandings(t, stored)
+
+	worker := newTestRetrieveLatestStandingsWorker(t, domain.RetrieveLatestStandingsWorkerParams{
+		Season:          testSeason,
+		Clock:           &mockClock{t: testDate},
+		Logger:          newMockLogger(),
+		StandingsAgent:  sa,
+		QuarantineAgent: qa,
+	})
+
+	ctx, cancel := testContextDefault(t)
+	defer cancel()
+
+	t.Run("standings without anomalies must not be quarantined", func(t *testing.T) {
+		latest := stored
+		latest.RoundNumber = 6
+
+		quarantined, err := worker.QuarantineIfAnomalous(ctx, latest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if quarantined {
+			expectedGot(t, false, quarantined)
+		}
+	})
+
+	t.Run("standings with a round number moving backwards must be quarantined", func(t *testing.T) {
+		latest := stored
+		latest.RoundNumber = 4
+
+		quarantined, err := worker.QuarantineIfAnomalous(ctx, latest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !quarantined {
+			expectedGot(t, true, quarantined)
+		}
+
+		retrieved, err := sqr.Select(ctx, map[string]interface{}{"round_number": 4}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(retrieved) != 1 {
+			expectedGot(t, 1, len(retrieved))
+		}
+	})
+}
@@ -0,0 +1,226 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StandingsSnapshot represents an immutable copy of a round's Standings rankings at a particular point in time
+type StandingsSnapshot struct {
+	ID          uuid.UUID         `db:"id" json:"id"`
+	StandingsID uuid.UUID         `db:"standings_id" json:"standings_id"`
+	SeasonID    string            `db:"season_id" json:"season_id"`
+	RoundNumber int               `db:"round_number" json:"round_number"`
+	Rankings    []RankingWithMeta `db:"rankings" json:"rankings"`
+	CreatedAt   time.Time         `db:"created_at" json:"created_at"`
+}
+
+// StandingsSnapshotDiff represents the differences between two StandingsSnapshots of the same round
+type StandingsSnapshotDiff struct {
+	FromSnapshotID uuid.UUID                 `json:"from_snapshot_id"`
+	ToSnapshotID   uuid.UUID                 `json:"to_snapshot_id"`
+	FromCreatedAt  time.Time                 `json:"from_created_at"`
+	ToCreatedAt    time.Time                 `json:"to_created_at"`
+	Changes        []StandingsSnapshotChange `json:"changes"`
+}
+
+// StandingsSnapshotChange represents the change in a single team's ranking between two StandingsSnapshots
+type StandingsSnapshotChange struct {
+	TeamID       string `json:"team_id"`
+	FromPosition int    `json:"from_position"`
+	ToPosition   int    `json:"to_position"`
+	FromPlayed   int    `json:"from_played"`
+	ToPlayed     int    `json:"to_played"`
+}
+
+// StandingsSnapshotRepository defines the interface for transacting with our StandingsSnapshot data source.
+// Snapshots are append-only, so no update or delete operations are provided
+type StandingsSnapshotRepository interface {
+	Insert(ctx context.Context, snapshot *StandingsSnapshot) error
+	Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]StandingsSnapshot, error)
+}
+
+// StandingsSnapshotAgent defines the behaviours for handling StandingsSnapshots
+type StandingsSnapshotAgent struct {
+	ssr StandingsSnapshotRepository
+	cl  Clock
+}
+
+// RecordSnapshot stores the rankings of the provided Standings as a new StandingsSnapshot, provided that they differ
+// from the most recent snapshot for the same Standings. Returns true if a new snapshot has been stored, otherwise false
+func (s *StandingsSnapshotAgent) RecordSnapshot(ctx context.Context, stnd Standings) (bool, error) {
+	existing, err := s.ssr.Select(ctx, map[string]interface{}{
+		"standings_id": stnd.ID,
+	}, false)
+	if err != nil && !errors.As(err, &MissingDBRecordError{}) {
+		return false, domainErrorFromRepositoryError(err)
+	}
+
+	if len(existing) > 0 {
+		sortSnapshotsByCreatedAt(existing)
+		if rankingsWithMetaMatch(existing[len(existing)-1].Rankings, stnd.Rankings) {
+			// table hasn't moved since the last snapshot
+			return false, nil
+		}
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return false, InternalError{err}
+	}
+
+	snapshot := StandingsSnapshot{
+		ID:          id,
+		StandingsID: stnd.ID,
+		SeasonID:    stnd.SeasonID,
+		RoundNumber: stnd.RoundNumber,
+		Rankings:    stnd.Rankings,
+		CreatedAt:   s.cl.Now().Truncate(time.Second),
+	}
+
+	if err := s.ssr.Insert(ctx, &snapshot); err != nil {
+		return false, domainErrorFromRepositoryError(err)
+	}
+
+	return true, nil
+}
+
+// RetrieveSnapshotsBySeasonAndRoundNumber retrieves all StandingsSnapshots for the provided season and round,
+// ordered from oldest to newest
+func (s *StandingsSnapshotAgent) RetrieveSnapshotsBySeasonAndRoundNumber(ctx context.Context, seasonID string, roundNumber int) ([]StandingsSnapshot, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleViewer) {
+		return nil, UnauthorizedError{}
+	}
+
+	snapshots, err := s.ssr.Select(ctx, map[string]interface{}{
+		"season_id":    seasonID,
+		"round_number": roundNumber,
+	}, false)
+	if err != nil {
+		return nil, domainErrorFromRepositoryError(err)
+	}
+
+	sortSnapshotsByCreatedAt(snapshots)
+
+	return snapshots, nil
+}
+
+// DiffSnapshots compares the StandingsSnapshots that match the provided IDs and returns the teams whose position
+// or games played has changed between them
+func (s *StandingsSnapshotAgent) DiffSnapshots(ctx context.Context, fromID, toID string) (StandingsSnapshotDiff, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleViewer) {
+		return StandingsSnapshotDiff{}, UnauthorizedError{}
+	}
+
+	from, err := s.retrieveSnapshotByID(ctx, fromID)
+	if err != nil {
+		return StandingsSnapshotDiff{}, err
+	}
+
+	to, err := s.retrieveSnapshotByID(ctx, toID)
+	if err != nil {
+		return StandingsSnapshotDiff{}, err
+	}
+
+	if from.StandingsID != to.StandingsID {
+		return StandingsSnapshotDiff{}, ConflictError{errors.New("snapshots must belong to the same round")}
+	}
+
+	return DiffStandingsSnapshots(from, to), nil
+}
+
+// retrieveSnapshotByID retrieves the StandingsSnapshot that matches the provided ID
+func (s *StandingsSnapshotAgent) retrieveSnapshotByID(ctx context.Context, id string) (StandingsSnapshot, error) {
+	snapshots, err := s.ssr.Select(ctx, map[string]interface{}{
+		"id": id,
+	}, false)
+	if err != nil {
+		return StandingsSnapshot{}, domainErrorFromRepositoryError(err)
+	}
+
+	return snapshots[0], nil
+}
+
+// NewStandingsSnapshotAgent returns a new StandingsSnapshotAgent using the provided repository
+func NewStandingsSnapshotAgent(ssr StandingsSnapshotRepository, cl Clock) (*StandingsSnapshotAgent, error) {
+	switch {
+	case ssr == nil:
+		return nil, fmt.Errorf("standings snapshot repository: %w", ErrIsNil)
+	case cl == nil:
+		return nil, fmt.Errorf("clock: %w", ErrIsNil)
+	}
+	return &StandingsSnapshotAgent{ssr: ssr, cl: cl}, nil
+}
+
+// DiffStandingsSnapshots returns the differences between the provided StandingsSnapshots, ordered by the
+// position of each team within the later snapshot
+func DiffStandingsSnapshots(from, to StandingsSnapshot) StandingsSnapshotDiff {
+	diff := StandingsSnapshotDiff{
+		FromSnapshotID: from.ID,
+		ToSnapshotID:   to.ID,
+		FromCreatedAt:  from.CreatedAt,
+		ToCreatedAt:    to.CreatedAt,
+		Changes:        make([]StandingsSnapshotChange, 0),
+	}
+
+	fromRankings := make(map[string]RankingWithMeta)
+	for _, rnk := range from.Rankings {
+		fromRankings[rnk.ID] = rnk
+	}
+
+	for _, toRnk := range to.Rankings {
+		fromRnk := fromRankings[toRnk.ID]
+		change := StandingsSnapshotChange{
+			TeamID:       toRnk.ID,
+			FromPosition: fromRnk.Position,
+			ToPosition:   toRnk.Position,
+			FromPlayed:   fromRnk.MetaData[MetaKeyPlayedGames],
+			ToPlayed:     toRnk.MetaData[MetaKeyPlayedGames],
+		}
+		if change.FromPosition == change.ToPosition && change.FromPlayed == change.ToPlayed {
+			continue
+		}
+		diff.Changes = append(diff.Changes, change)
+	}
+
+	sort.SliceStable(diff.Changes, func(i, j int) bool {
+		return diff.Changes[i].ToPosition < diff.Changes[j].ToPosition
+	})
+
+	return diff
+}
+
+// sortSnapshotsByCreatedAt sorts the provided StandingsSnapshots from oldest to newest
+func sortSnapshotsByCreatedAt(snapshots []StandingsSnapshot) {
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt)
+	})
+}
+
+// rankingsWithMetaMatch determines whether the provided rankings are identical
+func rankingsWithMetaMatch(a, b []RankingWithMeta) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := range a {
+		if a[idx].ID != b[idx].ID || a[idx].Position != b[idx].Position {
+			return false
+		}
+		if len(a[idx].MetaData) != len(b[idx].MetaData) {
+			return false
+		}
+		for key, val := range a[idx].MetaData {
+			if bVal, ok := b[idx].MetaData[key]; !ok || bVal != val {
+				return false
+			}
+		}
+	}
+	return true
+}
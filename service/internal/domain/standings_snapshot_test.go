@@ -0,0 +1,199 @@
+package domain_test
+
+import (
+	"errors"
+	"prediction-league/service/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewStandingsSnapshotAgent(t *testing.T) {
+	t.Run("passing invalid parameters must return expected error", func(t *testing.T) {
+		cl := &mockClock{}
+
+		tt := []struct {
+			ssr     domain.StandingsSnapshotRepository
+			cl      domain.Clock
+			wantErr error
+		}{
+			{nil, cl, domain.ErrIsNil},
+			{ssr, nil, domain.ErrIsNil},
+			{ssr, cl, nil},
+		}
+		for idx, tc := range tt {
+			agent, gotErr := domain.NewStandingsSnapshotAgent(tc.ssr, tc.cl)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("tc #%d: want error %s (%T), got %s (%T)", idx, tc.wantErr, tc.wantErr, gotErr, gotErr)
+			}
+			if tc.wantErr == nil && agent == nil {
+				t.Fatalf("tc #%d: want non-empty agent, got nil", idx)
+			}
+		}
+	})
+}
+
+func TestStandingsSnapshotAgent_RecordSnapshot(t *testing.T) {
+	t.Cleanup(truncate)
+
+	stnd := insertStandings(t, generateTestStandings(t))
+
+	ctx, cancel := testContextDefault(t)
+	defer cancel()
+
+	t.Run("recording first snapshot for standings must succeed", func(t *testing.T) {
+		agent, err := domain.NewStandingsSnapshotAgent(ssr, &mockClock{t: testDate})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		created, err := agent.RecordSnapshot(ctx, stnd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !created {
+			expectedGot(t, true, created)
+		}
+	})
+
+	t.Run("recording unchanged standings must not create a new snapshot", func(t *testing.T) {
+		agent, err := domain.NewStandingsSnapshotAgent(ssr, &mockClock{t: testDate.Add(15 * time.Minute)})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		created, err := agent.RecordSnapshot(ctx, stnd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created {
+			expectedGot(t, false, created)
+		}
+	})
+
+	t.Run("recording changed standings must create a new snapshot", func(t *testing.T) {
+		agent, err := domain.NewStandingsSnapshotAgent(ssr, &mockClock{t: testDate.Add(30 * time.Minute)})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		changed := stnd
+		changed.Rankings = append([]domain.RankingWithMeta{}, stnd.Rankings...)
+		changed.Rankings[0], changed.Rankings[1] = changed.Rankings[1], changed.Rankings[0]
+
+		created, err := agent.RecordSnapshot(ctx, changed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !created {
+			expectedGot(t, true, created)
+		}
+
+		snapshots, err := ssr.Select(ctx, map[string]interface{}{"standings_id": stnd.ID}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(snapshots) != 2 {
+			expectedGot(t, 2, len(snapshots))
+		}
+	})
+}
+
+func TestStandingsSnapshotAgent_DiffSnapshots(t *testing.T) {
+	t.Cleanup(truncate)
+
+	agent, err := domain.NewStandingsSnapshotAgent(ssr, &mockClock{t: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stnd := insertStandings(t, generateTestStandings(t))
+
+	ctx, cancel := testContextDefault(t)
+	defer cancel()
+
+	if _, err := agent.RecordSnapshot(ctx, stnd); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("diff snapshots without admin credentials must fail", func(t *testing.T) {
+		_, err := agent.DiffSnapshots(ctx, uuid.New().String(), uuid.New().String())
+		if !errors.As(err, &domain.UnauthorizedError{}) {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("diff non-existent snapshots must fail", func(t *testing.T) {
+		ctx := domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+
+		_, err := agent.DiffSnapshots(ctx, uuid.New().String(), uuid.New().String())
+		if !errors.As(err, &domain.NotFoundError{}) {
+			expectedTypeOfGot(t, domain.NotFoundError{}, err)
+		}
+	})
+
+	t.Run("diff existing snapshots of the same round must succeed", func(t *testing.T) {
+		ctx := domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+
+		snapshots, err := agent.RetrieveSnapshotsBySeasonAndRoundNumber(ctx, stnd.SeasonID, stnd.RoundNumber)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(snapshots) != 1 {
+			expectedGot(t, 1, len(snapshots))
+		}
+
+		diff, err := agent.DiffSnapshots(ctx, snapshots[0].ID.String(), snapshots[0].ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(diff.Changes) != 0 {
+			expectedGot(t, 0, len(diff.Changes))
+		}
+	})
+}
+
+func TestDiffStandingsSnapshots(t *testing.T) {
+	newRanking := func(id string, pos, played int) domain.RankingWithMeta {
+		rnk := domain.NewRankingWithMeta()
+		rnk.ID = id
+		rnk.Position = pos
+		rnk.MetaData[domain.MetaKeyPlayedGames] = played
+		return rnk
+	}
+
+	from := domain.StandingsSnapshot{
+		ID:        uuid.New(),
+		CreatedAt: testDate,
+		Rankings: []domain.RankingWithMeta{
+			newRanking("team_a", 1, 3),
+			newRanking("team_b", 2, 3),
+			newRanking("team_c", 3, 3),
+		},
+	}
+
+	to := domain.StandingsSnapshot{
+		ID:        uuid.New(),
+		CreatedAt: testDate.Add(time.Hour),
+		Rankings: []domain.RankingWithMeta{
+			newRanking("team_b", 1, 4),
+			newRanking("team_a", 2, 3),
+			newRanking("team_c", 3, 4),
+		},
+	}
+
+	want := domain.StandingsSnapshotDiff{
+		FromSnapshotID: from.ID,
+		ToSnapshotID:   to.ID,
+		FromCreatedAt:  from.CreatedAt,
+		ToCreatedAt:    to.CreatedAt,
+		Changes: []domain.StandingsSnapshotChange{
+			{TeamID: "team_b", FromPosition: 2, ToPosition: 1, FromPlayed: 3, ToPlayed: 4},
+			{TeamID: "team_a", FromPosition: 1, ToPosition: 2, FromPlayed: 3, ToPlayed: 3},
+			{TeamID: "team_c", FromPosition: 3, ToPosition: 3, FromPlayed: 3, ToPlayed: 4},
+		},
+	}
+
+	cmpDiff(t, "snapshot diff", want, domain.DiffStandingsSnapshots(from, to))
+}
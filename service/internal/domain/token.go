@@ -2,8 +2,14 @@ package domain
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"math/big"
 	"time"
 )
 
@@ -12,19 +18,47 @@ const (
 	TokenTypeEntryRegistration
 	TokenTypeMagicLogin
 	TokenTypePrediction
+	TokenTypeLoginCode
+	TokenTypeEmailVerification
 	TokenLength = 32
 )
 
 var TokenValidityDuration = map[int]time.Duration{
-	TokenTypeAuth:              time.Minute * 60, // duration for which user's auth token remains valid
-	TokenTypeEntryRegistration: time.Minute * 10, // duration for which user's registration form submission token remains valid
-	TokenTypeMagicLogin:        time.Minute * 10, // duration for which magic login link sent to a user by email remains valid
-	TokenTypePrediction:        time.Minute * 60, // duration for which user's edit prediction form submission token remains valid
+	TokenTypeAuth:              time.Minute * 60,   // duration for which user's auth token remains valid
+	TokenTypeEntryRegistration: time.Minute * 10,   // duration for which user's registration form submission token remains valid
+	TokenTypeMagicLogin:        time.Minute * 10,   // duration for which magic login link sent to a user by email remains valid
+	TokenTypePrediction:        time.Minute * 60,   // duration for which user's edit prediction form submission token remains valid
+	TokenTypeLoginCode:         time.Minute * 10,   // duration for which login code sent to a user by email remains valid
+	TokenTypeEmailVerification: time.Hour * 24 * 7, // duration for which email verification link sent to a user by email remains valid
+}
+
+// TokenTypeNames provides a human-readable name for each token type
+var TokenTypeNames = map[int]string{
+	TokenTypeAuth:              "auth",
+	TokenTypeEntryRegistration: "entry_registration",
+	TokenTypeMagicLogin:        "magic_login",
+	TokenTypePrediction:        "prediction",
+	TokenTypeLoginCode:         "login_code",
+	TokenTypeEmailVerification: "email_verification",
 }
 
 var extendedTokenDur = 6 * time.Hour
 
-// Token defines a token model
+// authTokenRenewalWindow is how close to its expiry an Auth Token must be before its session is renewed
+var authTokenRenewalWindow = 15 * time.Minute
+
+// authTokenRenewalGracePeriod is how long an Auth Token remains valid once it has been replaced by a renewed one, so that
+// requests which were already in flight with it do not fail
+var authTokenRenewalGracePeriod = time.Minute
+
+// defaultMaxSessionLifetime is how long a session can be renewed for after logging in, if its realm does not specify
+var defaultMaxSessionLifetime = 12 * time.Hour
+
+// loginCodeMaxAttempts is how many attempts can be made at a login code before it is discarded
+var loginCodeMaxAttempts = 5
+
+// Token defines a token model.
+// The ID of a Token is only ever known to whoever it was issued to, as the data store only retains a keyed hash of it
 type Token struct {
 	ID         string     `db:"id"`
 	Type       int        `db:"type"`
@@ -32,6 +66,7 @@ type Token struct {
 	IssuedAt   time.Time  `db:"issued_at"`
 	RedeemedAt *time.Time `db:"redeemed_at"`
 	ExpiresAt  time.Time  `db:"expires_at"`
+	Attempts   int        `db:"attempts"`
 }
 
 // TokenRepository defines the interface for transacting with our Token data source
@@ -39,16 +74,19 @@ type TokenRepository interface {
 	Insert(ctx context.Context, token *Token) error
 	Select(ctx context.Context, criteria map[string]interface{}, matchAny bool) ([]Token, error)
 	Update(ctx context.Context, token *Token) error
+	Redeem(ctx context.Context, token *Token) error
+	IncrementAttempts(ctx context.Context, id string, maxAttempts int) error
 	Delete(ctx context.Context, criteria map[string]interface{}, matchAny bool) (int64, error)
 	ExistsByID(ctx context.Context, id string) error
-	GenerateUniqueTokenID(ctx context.Context) (string, error)
 }
 
 // TokenAgent defines the behaviours for handling Tokens
 type TokenAgent struct {
 	tr TokenRepository
+	aa *AuditAgent
 	cl Clock
 	l  Logger
+	hk []byte
 }
 
 // GenerateToken generates a new unique token for the provided type and value
@@ -60,14 +98,14 @@ func (t *TokenAgent) GenerateToken(ctx context.Context, typ int, value string) (
 	}
 
 	// create new token
-	expires := t.cl.Now().Add(tokenDur)
-	return t.createToken(ctx, typ, value, expires)
+	now := t.cl.Now()
+	return t.createToken(ctx, typ, value, now, now.Add(tokenDur))
 }
 
 // GenerateExtendedToken generates a token with an extended expiry
 func (t *TokenAgent) GenerateExtendedToken(ctx context.Context, typ int, value string) (*Token, error) {
-	// ensure basic auth has been provided and matches admin credentials
-	if !IsBasicAuthSuccessful(ctx) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleApprover) {
 		return nil, UnauthorizedError{}
 	}
 
@@ -77,38 +115,253 @@ func (t *TokenAgent) GenerateExtendedToken(ctx context.Context, typ int, value s
 	}
 
 	// create new token
-	expires := t.cl.Now().Add(extendedTokenDur)
-	return t.createToken(ctx, typ, value, expires)
+	now := t.cl.Now()
+	tkn, err := t.createToken(ctx, typ, value, now, now.Add(extendedTokenDur))
+	if err != nil {
+		return nil, err
+	}
+
+	// record who issued the token, but never the token itself
+	if _, err := t.aa.Record(
+		ctx,
+		AdminAuditActorFromContext(ctx),
+		AuditActionExtendedTokenGenerated,
+		AuditTargetTypeEntry,
+		value,
+		nil,
+		map[string]interface{}{"token_type": typ, "expires_at": tkn.ExpiresAt},
+	); err != nil {
+		return nil, err
+	}
+
+	return tkn, nil
 }
 
-// createToken creates a new unique token
-func (t *TokenAgent) createToken(ctx context.Context, typ int, value string, expires time.Time) (*Token, error) {
-	id, err := t.tr.GenerateUniqueTokenID(ctx)
+// GenerateTokenExpiringAt generates a new unique token for the provided type and value that remains valid until the provided time,
+// for links sent by the system that must outlive the usual validity duration of the token type
+func (t *TokenAgent) GenerateTokenExpiringAt(ctx context.Context, typ int, value string, expires time.Time) (*Token, error) {
+	// ensure token type is valid
+	if _, ok := TokenValidityDuration[typ]; !ok {
+		return nil, NotFoundError{fmt.Errorf("token type %d is not valid", typ)}
+	}
+
+	now := t.cl.Now()
+	if !expires.After(now) {
+		return nil, ValidationError{Reasons: []string{"token expiry must be in the future"}}
+	}
+
+	return t.createToken(ctx, typ, value, now, expires)
+}
+
+// RenewAuthToken replaces the unexpired Auth Token with the provided ID with a new one once it is close to expiry, so that
+// an entrant who remains active is not logged out. A session can only be renewed until the maximum session lifetime of the
+// current realm has elapsed since the entrant logged in. The replaced Token remains valid for a short grace period, so that
+// other requests made with it at the same time still succeed. The new Token is returned, or nil if no renewal was required,
+// including when the Token has already been renewed by another request
+func (t *TokenAgent) RenewAuthToken(ctx context.Context, id string) (*Token, error) {
+	tkn, err := t.RetrieveTokenByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
 	now := t.cl.Now()
 
+	if tkn.Type != TokenTypeAuth || now.After(tkn.ExpiresAt) {
+		return nil, UnauthorizedError{errors.New("invalid auth token")}
+	}
+
+	if tkn.RedeemedAt != nil {
+		// token has already been renewed, so it only remains valid until its grace period is up
+		return nil, nil
+	}
+
+	if tkn.ExpiresAt.Sub(now) > authTokenRenewalWindow {
+		// not close enough to expiry to be worth renewing yet
+		return nil, nil
+	}
+
+	maxLifetime := RealmFromContext(ctx).Config.MaxSessionLifetime
+	if maxLifetime <= 0 {
+		maxLifetime = defaultMaxSessionLifetime
+	}
+
+	// a renewed token keeps the issue date of the token it replaces, so that it always reflects when the entrant logged in
+	sessionEnds := tkn.IssuedAt.Add(maxLifetime)
+	if !sessionEnds.After(tkn.ExpiresAt) {
+		// session cannot be extended any further, so the entrant must log in again once it expires
+		return nil, nil
+	}
+
+	expires := now.Add(TokenValidityDuration[TokenTypeAuth])
+	if expires.After(sessionEnds) {
+		expires = sessionEnds
+	}
+
+	// mark the original token as renewed before issuing its replacement, so that only one of several concurrent
+	// requests ever renews it, and the original token is then cleared up by the token janitor once it expires
+	superseded := *tkn
+	superseded.ID = t.hashTokenID(id)
+	superseded.RedeemedAt = &now
+	if graceEnds := now.Add(authTokenRenewalGracePeriod); graceEnds.Before(superseded.ExpiresAt) {
+		superseded.ExpiresAt = graceEnds
+	}
+
+	if err := t.tr.Redeem(ctx, &superseded); err != nil {
+		if errors.As(err, &MissingDBRecordError{}) {
+			// another request has renewed the token in the meantime
+			return nil, nil
+		}
+		return nil, domainErrorFromRepositoryError(err)
+	}
+
+	return t.createToken(ctx, TokenTypeAuth, tkn.Value, tkn.IssuedAt, expires)
+}
+
+// createToken creates a new unique token
+func (t *TokenAgent) createToken(ctx context.Context, typ int, value string, issued, expires time.Time) (*Token, error) {
+	id, err := t.generateUniqueTokenID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	token := Token{
 		ID:        id,
 		Type:      typ,
 		Value:     value,
-		IssuedAt:  now,
+		IssuedAt:  issued,
 		ExpiresAt: expires,
 	}
 
-	if err := t.tr.Insert(ctx, &token); err != nil {
+	// only the hashed id is stored, so the token that is returned is the sole copy of the id itself
+	stored := token
+	stored.ID = t.hashTokenID(id)
+
+	if err := t.tr.Insert(ctx, &stored); err != nil {
 		return nil, domainErrorFromRepositoryError(err)
 	}
 
 	return &token, nil
 }
 
+// generateUniqueTokenID returns a new random token ID whose hash is not already in use
+func (t *TokenAgent) generateUniqueTokenID(ctx context.Context) (string, error) {
+	for {
+		id, err := generateTokenID()
+		if err != nil {
+			return "", InternalError{fmt.Errorf("cannot generate token id: %w", err)}
+		}
+
+		if err := t.tr.ExistsByID(ctx, t.hashTokenID(id)); err != nil {
+			if errors.As(err, &MissingDBRecordError{}) {
+				return id, nil
+			}
+			return "", domainErrorFromRepositoryError(err)
+		}
+	}
+}
+
+// hashTokenID returns the keyed hash of the provided token ID, which is what identifies the token in the data store
+func (t *TokenAgent) hashTokenID(id string) string {
+	mac := hmac.New(sha256.New, t.hk)
+	mac.Write([]byte(id))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateLoginCode generates a new one-time login code for the Entry with the provided ID, which replaces any previous login
+// code that the Entry has not yet redeemed. The code itself is returned, as only a keyed hash of it is stored
+func (t *TokenAgent) GenerateLoginCode(ctx context.Context, entryID string) (string, error) {
+	if _, err := t.DeleteInFlightTokens(ctx, TokenTypeLoginCode, entryID); err != nil {
+		return "", err
+	}
+
+	code, err := generateLoginCode()
+	if err != nil {
+		return "", InternalError{fmt.Errorf("cannot generate login code: %w", err)}
+	}
+
+	// a login code is far too short to be unique by itself, so it is hashed alongside the entry id that it belongs to
+	now := t.cl.Now()
+	tkn := Token{
+		ID:        t.hashLoginCode(entryID, code),
+		Type:      TokenTypeLoginCode,
+		Value:     entryID,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(TokenValidityDuration[TokenTypeLoginCode]),
+	}
+
+	if err := t.tr.Insert(ctx, &tkn); err != nil {
+		return "", domainErrorFromRepositoryError(err)
+	}
+
+	return code, nil
+}
+
+// RedeemLoginCode redeems the unexpired login code that was most recently generated for the Entry with the provided ID,
+// as long as it matches the provided code. Every code provided counts as an attempt, and the login code is discarded
+// once too many attempts have been made, so that it cannot be guessed
+func (t *TokenAgent) RedeemLoginCode(ctx context.Context, entryID, code string) error {
+	tokens, err := t.tr.Select(ctx, map[string]interface{}{
+		"type":        TokenTypeLoginCode,
+		"value":       entryID,
+		"redeemed_at": DBQueryCondition{"IS NULL", nil},
+	}, false)
+	if err != nil {
+		if errors.As(err, &MissingDBRecordError{}) {
+			return UnauthorizedError{errors.New("no login code has been issued")}
+		}
+		return domainErrorFromRepositoryError(err)
+	}
+
+	if len(tokens) != 1 {
+		return InternalError{fmt.Errorf("found %d in-flight login codes for entry id '%s'", len(tokens), entryID)}
+	}
+
+	// the retrieved token is identified by the hash of its code, which is what it must be updated by
+	tkn := tokens[0]
+	now := t.cl.Now()
+
+	if now.After(tkn.ExpiresAt) {
+		return UnauthorizedError{errors.New("login code has expired")}
+	}
+
+	// each attempt is counted before the code is compared, so that concurrent guesses cannot all be checked against it
+	if err := t.tr.IncrementAttempts(ctx, tkn.ID, loginCodeMaxAttempts); err != nil {
+		if !errors.As(err, &MissingDBRecordError{}) {
+			return domainErrorFromRepositoryError(err)
+		}
+
+		t.l.Infof("login code for entry id '%s' discarded after %d attempts", entryID, loginCodeMaxAttempts)
+		if _, err := t.tr.Delete(ctx, map[string]interface{}{"id": tkn.ID}, false); err != nil {
+			return domainErrorFromRepositoryError(err)
+		}
+		return UnauthorizedError{errors.New("too many incorrect login code attempts")}
+	}
+
+	if !hmac.Equal([]byte(tkn.ID), []byte(t.hashLoginCode(entryID, code))) {
+		return UnauthorizedError{errors.New("incorrect login code")}
+	}
+
+	// only one of several concurrent attempts with the right code can redeem it
+	tkn.RedeemedAt = &now
+	if err := t.tr.Redeem(ctx, &tkn); err != nil {
+		if errors.As(err, &MissingDBRecordError{}) {
+			return UnauthorizedError{errors.New("login code has already been redeemed")}
+		}
+		return domainErrorFromRepositoryError(err)
+	}
+
+	return nil
+}
+
+// hashLoginCode returns the keyed hash of the provided login code for the Entry with the provided ID
+func (t *TokenAgent) hashLoginCode(entryID, code string) string {
+	return t.hashTokenID(entryID + ":" + code)
+}
+
 // RetrieveTokenByID retrieves an existing token by the provided ID
 func (t *TokenAgent) RetrieveTokenByID(ctx context.Context, id string) (*Token, error) {
 	tokens, err := t.tr.Select(ctx, map[string]interface{}{
-		"id": id,
+		"id": t.hashTokenID(id),
 	}, false)
 	if err != nil {
 		return nil, domainErrorFromRepositoryError(err)
@@ -118,7 +371,11 @@ func (t *TokenAgent) RetrieveTokenByID(ctx context.Context, id string) (*Token,
 		return nil, NotFoundError{fmt.Errorf("token id: %s not found", id)}
 	}
 
-	return &tokens[0], nil
+	// the caller already holds the id, so hand it back in place of its hash
+	tkn := tokens[0]
+	tkn.ID = id
+
+	return &tkn, nil
 }
 
 // RedeemToken sets a RedeemedAt value on the provided Token and updates it
@@ -129,6 +386,7 @@ func (t *TokenAgent) RedeemToken(ctx context.Context, token Token) error {
 
 	redeemed := t.cl.Now()
 	token.RedeemedAt = &redeemed
+	token.ID = t.hashTokenID(token.ID)
 
 	err := t.tr.Update(ctx, &token)
 	if err != nil {
@@ -141,7 +399,7 @@ func (t *TokenAgent) RedeemToken(ctx context.Context, token Token) error {
 // DeleteToken removes the provided token
 func (t *TokenAgent) DeleteToken(ctx context.Context, token Token) error {
 	cnt, err := t.tr.Delete(ctx, map[string]interface{}{
-		"id": token.ID,
+		"id": t.hashTokenID(token.ID),
 	}, false)
 	if err != nil {
 		return domainErrorFromRepositoryError(err)
@@ -152,7 +410,7 @@ func (t *TokenAgent) DeleteToken(ctx context.Context, token Token) error {
 	}
 
 	if cnt > 1 {
-		return InternalError{fmt.Errorf("deleted %d tokens by id '%s'", cnt, token.ID)}
+		return InternalError{fmt.Errorf("deleted %d tokens by hashed id '%s'", cnt, t.hashTokenID(token.ID))}
 	}
 
 	return nil
@@ -173,6 +431,38 @@ func (t *TokenAgent) DeleteTokensExpiredAfter(ctx context.Context, timestamp tim
 	return cnt, nil
 }
 
+// DeleteTokensByTypeExpiredBefore removes tokens of the provided type that had expired by the provided timestamp
+func (t *TokenAgent) DeleteTokensByTypeExpiredBefore(ctx context.Context, typ int, timestamp time.Time) (int64, error) {
+	cnt, err := t.tr.Delete(ctx, map[string]interface{}{
+		"type": typ,
+		"expires_at": DBQueryCondition{
+			Operator: "<=",
+			Operand:  timestamp,
+		},
+	}, false)
+	if err != nil {
+		return 0, domainErrorFromRepositoryError(err)
+	}
+
+	return cnt, nil
+}
+
+// DeleteTokensByTypeRedeemedBefore removes tokens of the provided type that had been redeemed by the provided timestamp
+func (t *TokenAgent) DeleteTokensByTypeRedeemedBefore(ctx context.Context, typ int, timestamp time.Time) (int64, error) {
+	cnt, err := t.tr.Delete(ctx, map[string]interface{}{
+		"type": typ,
+		"redeemed_at": DBQueryCondition{
+			Operator: "<=",
+			Operand:  timestamp,
+		},
+	}, false)
+	if err != nil {
+		return 0, domainErrorFromRepositoryError(err)
+	}
+
+	return cnt, nil
+}
+
 // DeleteInFlightTokens removes tokens that meet the provided criteria and have not yet been redeemed
 func (t *TokenAgent) DeleteInFlightTokens(ctx context.Context, typ int, val string) (int64, error) {
 	cnt, err := t.tr.Delete(ctx, map[string]interface{}{
@@ -187,32 +477,158 @@ func (t *TokenAgent) DeleteInFlightTokens(ctx context.Context, typ int, val stri
 	return cnt, nil
 }
 
+// RevokeAuthToken removes the Auth Token with the provided ID, so that the session it represents can no longer be used.
+// Renewing a session replaces its token with one that keeps the same issue date, and the token it replaces remains valid
+// until its grace period is up, so each renewed Auth Token of the same Entry and session is removed too. The number of
+// Tokens removed is returned
+func (t *TokenAgent) RevokeAuthToken(ctx context.Context, id string) (int64, error) {
+	tkn, err := t.RetrieveTokenByID(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	if tkn.Type != TokenTypeAuth {
+		return 0, NotFoundError{errors.New("auth token not found")}
+	}
+
+	cnt, err := t.tr.Delete(ctx, map[string]interface{}{
+		"id":   t.hashTokenID(id),
+		"type": TokenTypeAuth,
+	}, false)
+	if err != nil {
+		return 0, domainErrorFromRepositoryError(err)
+	}
+
+	if cnt == 0 {
+		return 0, NotFoundError{errors.New("auth token not found")}
+	}
+
+	renewed, err := t.tr.Delete(ctx, map[string]interface{}{
+		"type":        TokenTypeAuth,
+		"value":       tkn.Value,
+		"issued_at":   tkn.IssuedAt,
+		"redeemed_at": DBQueryCondition{Operator: "IS NOT NULL"},
+	}, false)
+	if err != nil {
+		return 0, domainErrorFromRepositoryError(err)
+	}
+
+	return cnt + renewed, nil
+}
+
+// RevokeAllAuthTokensByAuthToken removes every Auth Token that belongs to the same Entry as the unexpired Auth Token with
+// the provided ID, signing the entrant out of every session they have open. The number of Tokens removed is returned
+func (t *TokenAgent) RevokeAllAuthTokensByAuthToken(ctx context.Context, id string) (int64, error) {
+	tkn, err := t.RetrieveTokenByID(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	// an expired token must not be able to revoke the sessions of whoever it once belonged to
+	if tkn.Type != TokenTypeAuth || t.cl.Now().After(tkn.ExpiresAt) {
+		return 0, UnauthorizedError{errors.New("invalid auth token")}
+	}
+
+	return t.deleteAuthTokensByEntryID(ctx, tkn.Value)
+}
+
+// RevokeAllAuthTokensByEntryID removes every Auth Token that belongs to the Entry with the provided ID on behalf of an admin,
+// signing the entrant out of every session they have open. The number of Tokens removed is returned
+func (t *TokenAgent) RevokeAllAuthTokensByEntryID(ctx context.Context, entryID string) (int64, error) {
+	// ensure an admin user has been authenticated with sufficient permissions for the current realm
+	if !HasAdminRole(ctx, AdminRoleApprover) {
+		return 0, UnauthorizedError{}
+	}
+
+	cnt, err := t.deleteAuthTokensByEntryID(ctx, entryID)
+	if err != nil {
+		return 0, err
+	}
+
+	// record who revoked the sessions, but never the tokens themselves
+	if _, err := t.aa.Record(
+		ctx,
+		AdminAuditActorFromContext(ctx),
+		AuditActionEntrySessionsRevoked,
+		AuditTargetTypeEntry,
+		entryID,
+		nil,
+		map[string]interface{}{"revoked": cnt},
+	); err != nil {
+		return 0, err
+	}
+
+	return cnt, nil
+}
+
+// deleteAuthTokensByEntryID removes every Auth Token whose value is the provided Entry ID
+func (t *TokenAgent) deleteAuthTokensByEntryID(ctx context.Context, entryID string) (int64, error) {
+	cnt, err := t.tr.Delete(ctx, map[string]interface{}{
+		"type":  TokenTypeAuth,
+		"value": entryID,
+	}, false)
+	if err != nil {
+		return 0, domainErrorFromRepositoryError(err)
+	}
+
+	return cnt, nil
+}
+
 // IsTokenValid determines whether the provided Token is valid
 func (t *TokenAgent) IsTokenValid(tkn *Token, typ int, val string) bool {
+	// identify the token by its hash, so that a usable token never ends up in the logs
+	hashedID := t.hashTokenID(tkn.ID)
+
 	now := t.cl.Now()
 	switch {
 	case tkn.Type != typ:
-		t.l.Errorf("token id '%s': token type %d is not %d", tkn.ID, tkn.Type, typ)
+		t.l.Errorf("hashed token id '%s': token type %d is not %d", hashedID, tkn.Type, typ)
 		return false
 	case tkn.Value != val:
-		t.l.Errorf("token id '%s': token value '%s' is not '%s'", tkn.ID, tkn.Value, val)
+		t.l.Errorf("hashed token id '%s': token value '%s' is not '%s'", hashedID, tkn.Value, val)
 		return false
 	case now.After(tkn.ExpiresAt):
-		t.l.Errorf("token id '%s': expired", tkn.ID)
+		t.l.Errorf("hashed token id '%s': expired", hashedID)
 		return false
 	}
 	return true
 }
 
-// NewTokenAgent returns a new TokenAgent using the provided repository
-func NewTokenAgent(tr TokenRepository, cl Clock, l Logger) (*TokenAgent, error) {
+// generateTokenID generates a cryptographically random token ID of TokenLength characters
+func generateTokenID() (string, error) {
+	// every 3 random bytes are encoded as 4 url-safe characters
+	b := make([]byte, TokenLength*3/4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// generateLoginCode generates a cryptographically random login code of 6 digits
+func generateLoginCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// NewTokenAgent returns a new TokenAgent using the provided repository and audit agent,
+// which stores the IDs of the Tokens it generates as a hash keyed with the provided hash key
+func NewTokenAgent(tr TokenRepository, aa *AuditAgent, cl Clock, l Logger, hashKey []byte) (*TokenAgent, error) {
 	switch {
 	case tr == nil:
 		return nil, fmt.Errorf("token repository: %w", ErrIsNil)
+	case aa == nil:
+		return nil, fmt.Errorf("audit agent: %w", ErrIsNil)
 	case cl == nil:
 		return nil, fmt.Errorf("clock: %w", ErrIsNil)
 	case l == nil:
 		return nil, fmt.Errorf("logger: %w", ErrIsNil)
+	case len(hashKey) == 0:
+		return nil, fmt.Errorf("hash key: %w", ErrIsNil)
 	}
-	return &TokenAgent{tr, cl, l}, nil
+	return &TokenAgent{tr, aa, cl, l, hashKey}, nil
 }
@@ -2,8 +2,14 @@ package domain_test
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"prediction-league/service/internal/domain"
+	"regexp"
+	"sync"
 	"testing"
 	"time"
 
@@ -18,17 +24,21 @@ func TestNewTokenAgent(t *testing.T) {
 
 		tt := []struct {
 			tr      domain.TokenRepository
+			aa      *domain.AuditAgent
 			cl      domain.Clock
 			l       domain.Logger
+			hk      []byte
 			wantErr error
 		}{
-			{nil, cl, l, domain.ErrIsNil},
-			{tr, nil, l, domain.ErrIsNil},
-			{tr, cl, nil, domain.ErrIsNil},
-			{tr, cl, l, nil},
+			{nil, aa, cl, l, testTokenHashKey, domain.ErrIsNil},
+			{tr, nil, cl, l, testTokenHashKey, domain.ErrIsNil},
+			{tr, aa, nil, l, testTokenHashKey, domain.ErrIsNil},
+			{tr, aa, cl, nil, testTokenHashKey, domain.ErrIsNil},
+			{tr, aa, cl, l, nil, domain.ErrIsNil},
+			{tr, aa, cl, l, testTokenHashKey, nil},
 		}
 		for idx, tc := range tt {
-			agent, gotErr := domain.NewTokenAgent(tc.tr, tc.cl, tc.l)
+			agent, gotErr := domain.NewTokenAgent(tc.tr, tc.aa, tc.cl, tc.l, tc.hk)
 			if !errors.Is(gotErr, tc.wantErr) {
 				t.Fatalf("tc #%d: want error %s (%T), got %s (%T)", idx, tc.wantErr, tc.wantErr, gotErr, gotErr)
 			}
@@ -42,7 +52,7 @@ func TestNewTokenAgent(t *testing.T) {
 func TestTokenAgent_GenerateToken(t *testing.T) {
 	t.Cleanup(truncate)
 
-	agent, err := domain.NewTokenAgent(tr, &mockClock{t: testDate}, &mockLogger{})
+	agent, err := domain.NewTokenAgent(tr, aa, &mockClock{t: testDate}, &mockLogger{}, testTokenHashKey)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -102,9 +112,12 @@ func TestTokenAgent_GenerateToken(t *testing.T) {
 				t.Fatalf("want token expires at %+v, got %+v", wantExp, token.ExpiresAt)
 			}
 
-			// inserting same token a second time must fail
-			if gotErr := tr.Insert(ctx, token); !errors.As(gotErr, &domain.DuplicateDBRecordError{}) {
-				t.Fatalf("want DuplicateDBRecordError, got %s (%T)", gotErr, gotErr)
+			// token must only be stored by its hashed id
+			if gotErr := tr.ExistsByID(ctx, token.ID); !errors.As(gotErr, &domain.MissingDBRecordError{}) {
+				t.Fatalf("want MissingDBRecordError, got %s (%T)", gotErr, gotErr)
+			}
+			if err := tr.ExistsByID(ctx, hashTestTokenID(token.ID)); err != nil {
+				t.Fatal(err)
 			}
 		})
 	}
@@ -124,7 +137,7 @@ func TestTokenAgent_GenerateToken(t *testing.T) {
 func TestTokenAgent_GenerateExtendedToken(t *testing.T) {
 	t.Cleanup(truncate)
 
-	agent, err := domain.NewTokenAgent(tr, &mockClock{t: testDate}, &mockLogger{})
+	agent, err := domain.NewTokenAgent(tr, aa, &mockClock{t: testDate}, &mockLogger{}, testTokenHashKey)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -154,7 +167,7 @@ func TestTokenAgent_GenerateExtendedToken(t *testing.T) {
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
 			ctx, cancel := testContextDefault(t)
-			ctx = domain.SetBasicAuthSuccessfulOnContext(ctx)
+			ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
 			defer cancel()
 
 			typ := tc.typ
@@ -185,16 +198,19 @@ func TestTokenAgent_GenerateExtendedToken(t *testing.T) {
 				t.Fatalf("want token expires at %+v, got %+v", wantExp, token.ExpiresAt)
 			}
 
-			// inserting same token a second time must fail
-			if gotErr := tr.Insert(ctx, token); !errors.As(gotErr, &domain.DuplicateDBRecordError{}) {
-				t.Fatalf("want DuplicateDBRecordError, got %s (%T)", gotErr, gotErr)
+			// token must only be stored by its hashed id
+			if gotErr := tr.ExistsByID(ctx, token.ID); !errors.As(gotErr, &domain.MissingDBRecordError{}) {
+				t.Fatalf("want MissingDBRecordError, got %s (%T)", gotErr, gotErr)
+			}
+			if err := tr.ExistsByID(ctx, hashTestTokenID(token.ID)); err != nil {
+				t.Fatal(err)
 			}
 		})
 	}
 
 	t.Run("generate a token of a non-existent token type must fail", func(t *testing.T) {
 		ctx, cancel := testContextDefault(t)
-		ctx = domain.SetBasicAuthSuccessfulOnContext(ctx)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
 		defer cancel()
 
 		nonExistentTokenType := 123456
@@ -208,15 +224,13 @@ func TestTokenAgent_GenerateExtendedToken(t *testing.T) {
 func TestTokenAgent_RetrieveTokenByID(t *testing.T) {
 	t.Cleanup(truncate)
 
-	agent, err := domain.NewTokenAgent(tr, &mockClock{}, &mockLogger{})
+	agent, err := domain.NewTokenAgent(tr, aa, &mockClock{}, &mockLogger{}, testTokenHashKey)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	token := generateTestToken("token_id")
-	if err := tr.Insert(context.Background(), token); err != nil {
-		t.Fatal(err)
-	}
+	insertToken(t, token)
 
 	t.Run("retrieve an existing token must succeed", func(t *testing.T) {
 		ctx, cancel := testContextDefault(t)
@@ -253,6 +267,16 @@ func TestTokenAgent_RetrieveTokenByID(t *testing.T) {
 			expectedTypeOfGot(t, domain.NotFoundError{}, err)
 		}
 	})
+
+	t.Run("retrieve a token by its hashed id must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		_, err := agent.RetrieveTokenByID(ctx, hashTestTokenID(token.ID))
+		if !cmp.ErrorType(err, domain.NotFoundError{})().Success() {
+			expectedTypeOfGot(t, domain.NotFoundError{}, err)
+		}
+	})
 }
 
 func TestTokenAgent_RedeemToken(t *testing.T) {
@@ -275,16 +299,14 @@ func TestTokenAgent_RedeemToken(t *testing.T) {
 		return res[0]
 	}
 
-	agent, err := domain.NewTokenAgent(tr, &mockClock{t: testDate}, &mockLogger{})
+	agent, err := domain.NewTokenAgent(tr, aa, &mockClock{t: testDate}, &mockLogger{}, testTokenHashKey)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	t.Run("redeem an existing token that has not been redeemed must succeed", func(t *testing.T) {
 		token := generateTestToken("tkn-1")
-		if err := tr.Insert(ctx, token); err != nil {
-			t.Fatal(err)
-		}
+		insertToken(t, token)
 
 		if err := agent.RedeemToken(context.Background(), *token); err != nil {
 			t.Fatal(err)
@@ -292,8 +314,9 @@ func TestTokenAgent_RedeemToken(t *testing.T) {
 
 		// token must be redeemed
 		wantTkn := *token
+		wantTkn.ID = hashTestTokenID(token.ID)
 		wantTkn.RedeemedAt = &testDate
-		gotTkn := getTokenByID(token.ID)
+		gotTkn := getTokenByID(wantTkn.ID)
 
 		if diff := gocmp.Diff(wantTkn, gotTkn); diff != "" {
 			t.Fatalf("want token %+v, got %+v, diff: %s", wantTkn, gotTkn, diff)
@@ -303,9 +326,7 @@ func TestTokenAgent_RedeemToken(t *testing.T) {
 	t.Run("redeem an existing token that has already been redeemed must fail", func(t *testing.T) {
 		token := generateTestToken("tkn-2")
 		token.RedeemedAt = &testDate
-		if err := tr.Insert(ctx, token); err != nil {
-			t.Fatal(err)
-		}
+		insertToken(t, token)
 
 		if gotErr := agent.RedeemToken(context.Background(), *token); !errors.As(gotErr, &domain.ConflictError{}) {
 			t.Fatalf("want conflict error, got %s (%T)", gotErr, gotErr)
@@ -323,16 +344,14 @@ func TestTokenAgent_RedeemToken(t *testing.T) {
 func TestTokenAgent_DeleteToken(t *testing.T) {
 	t.Cleanup(truncate)
 
-	agent, err := domain.NewTokenAgent(tr, &mockClock{}, &mockLogger{})
+	agent, err := domain.NewTokenAgent(tr, aa, &mockClock{}, &mockLogger{}, testTokenHashKey)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	t.Run("delete an existing token must succeed", func(t *testing.T) {
 		tkn := generateTestToken("tkn-1")
-		if err := tr.Insert(context.Background(), tkn); err != nil {
-			t.Fatal(err)
-		}
+		insertToken(t, tkn)
 
 		ctx, cancel := testContextDefault(t)
 		defer cancel()
@@ -342,16 +361,14 @@ func TestTokenAgent_DeleteToken(t *testing.T) {
 		}
 
 		// token must have been deleted
-		if gotErr := tr.ExistsByID(ctx, tkn.ID); !errors.As(gotErr, &domain.MissingDBRecordError{}) {
+		if gotErr := tr.ExistsByID(ctx, hashTestTokenID(tkn.ID)); !errors.As(gotErr, &domain.MissingDBRecordError{}) {
 			t.Fatalf("want MissingDBRecordError, got %s (%T)", gotErr, gotErr)
 		}
 	})
 
 	t.Run("delete a non-existent token must succeed", func(t *testing.T) {
 		tkn := generateTestToken("tkn-2")
-		if err := tr.Insert(context.Background(), tkn); err != nil {
-			t.Fatal(err)
-		}
+		insertToken(t, tkn)
 
 		ctx, cancel := testContextDefault(t)
 		defer cancel()
@@ -367,7 +384,7 @@ func TestTokenAgent_DeleteToken(t *testing.T) {
 func TestTokenAgent_DeleteTokensExpiredAfter(t *testing.T) {
 	t.Cleanup(truncate)
 
-	agent, err := domain.NewTokenAgent(tr, &mockClock{}, &mockLogger{})
+	agent, err := domain.NewTokenAgent(tr, aa, &mockClock{}, &mockLogger{}, testTokenHashKey)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -432,7 +449,7 @@ func TestTokenAgent_DeleteTokensExpiredAfter(t *testing.T) {
 func TestTokenAgent_DeleteInFlightTokens(t *testing.T) {
 	t.Cleanup(truncate)
 
-	agent, err := domain.NewTokenAgent(tr, &mockClock{}, &mockLogger{})
+	agent, err := domain.NewTokenAgent(tr, aa, &mockClock{}, &mockLogger{}, testTokenHashKey)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -521,6 +538,569 @@ func TestTokenAgent_DeleteInFlightTokens(t *testing.T) {
 	})
 }
 
+func TestTokenAgent_RevokeAuthTokens(t *testing.T) {
+	t.Cleanup(truncate)
+
+	now := time.Now().Truncate(time.Second)
+
+	agent, err := domain.NewTokenAgent(tr, aa, &mockClock{t: now}, &mockLogger{}, testTokenHashKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entryID := "entry-with-sessions"
+
+	// insertAuthToken inserts an auth token for the provided entry ID that expires at the provided time
+	insertAuthToken := func(t *testing.T, id, value string, expiresAt time.Time) *domain.Token {
+		t.Helper()
+
+		token := generateTestToken(id)
+		token.Type = domain.TokenTypeAuth
+		token.Value = value
+		token.ExpiresAt = expiresAt
+		insertToken(t, token)
+
+		return token
+	}
+
+	t.Run("revoke auth token must delete only that token", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		token1 := insertAuthToken(t, "auth-1", entryID, now.Add(time.Hour))
+		token2 := insertAuthToken(t, "auth-2", entryID, now.Add(time.Hour))
+
+		revoked, err := agent.RevokeAuthToken(ctx, token1.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if revoked != 1 {
+			expectedGot(t, 1, revoked)
+		}
+
+		if err := tr.ExistsByID(ctx, hashTestTokenID(token1.ID)); !errors.As(err, &domain.MissingDBRecordError{}) {
+			t.Fatalf("want token %s to have been deleted, but got err %s (%T)", token1.ID, err, err)
+		}
+		if err := tr.ExistsByID(ctx, hashTestTokenID(token2.ID)); err != nil {
+			t.Fatal(err)
+		}
+
+		// revoking the same token again must fail
+		_, err = agent.RevokeAuthToken(ctx, token1.ID)
+		if !cmp.ErrorType(err, domain.NotFoundError{})().Success() {
+			expectedTypeOfGot(t, domain.NotFoundError{}, err)
+		}
+	})
+
+	t.Run("revoke auth token that is not an auth token must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		token := generateTestToken("not-auth")
+		token.Type = domain.TokenTypeMagicLogin
+		insertToken(t, token)
+
+		_, err := agent.RevokeAuthToken(ctx, token.ID)
+		if !cmp.ErrorType(err, domain.NotFoundError{})().Success() {
+			expectedTypeOfGot(t, domain.NotFoundError{}, err)
+		}
+	})
+
+	t.Run("revoke auth token must also delete the tokens that it has renewed", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		renewingEntryID := "entry-with-renewed-session"
+
+		// renewed token remains valid for its grace period, and shares the issue date of the token that replaced it
+		renewed := generateTestToken("auth-renewed")
+		renewed.Type = domain.TokenTypeAuth
+		renewed.Value = renewingEntryID
+		renewed.RedeemedAt = &now
+		renewed.ExpiresAt = now.Add(time.Minute)
+		insertToken(t, renewed)
+
+		current := insertAuthToken(t, "auth-current", renewingEntryID, now.Add(time.Hour))
+
+		// renewed token of another session must be left alone
+		otherSession := generateTestToken("auth-other-session")
+		otherSession.Type = domain.TokenTypeAuth
+		otherSession.Value = renewingEntryID
+		otherSession.IssuedAt = otherSession.IssuedAt.Add(-time.Hour)
+		otherSession.RedeemedAt = &now
+		otherSession.ExpiresAt = now.Add(time.Minute)
+		insertToken(t, otherSession)
+
+		revoked, err := agent.RevokeAuthToken(ctx, current.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if revoked != 2 {
+			expectedGot(t, 2, revoked)
+		}
+
+		for _, id := range []string{current.ID, renewed.ID} {
+			if err := tr.ExistsByID(ctx, hashTestTokenID(id)); !errors.As(err, &domain.MissingDBRecordError{}) {
+				t.Fatalf("want token %s to have been deleted, but got err %s (%T)", id, err, err)
+			}
+		}
+		if err := tr.ExistsByID(ctx, hashTestTokenID(otherSession.ID)); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("revoke all auth tokens by an expired auth token must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		token := insertAuthToken(t, "auth-expired", entryID, now.Add(-time.Second))
+
+		_, err := agent.RevokeAllAuthTokensByAuthToken(ctx, token.ID)
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("revoke all auth tokens by a valid auth token must delete every auth token of its entry", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		token := insertAuthToken(t, "auth-3", entryID, now.Add(time.Hour))
+		otherEntryToken := insertAuthToken(t, "auth-other", "another-entry", now.Add(time.Hour))
+
+		gotCnt, err := agent.RevokeAllAuthTokensByAuthToken(ctx, token.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// auth-2, auth-3 and auth-expired
+		wantCnt := int64(3)
+		if gotCnt != wantCnt {
+			t.Fatalf("want %d deleted tokens, got %d", wantCnt, gotCnt)
+		}
+
+		if err := tr.ExistsByID(ctx, hashTestTokenID(otherEntryToken.ID)); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("revoke all auth tokens by entry id with invalid credentials must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		_, err := agent.RevokeAllAuthTokensByEntryID(ctx, "another-entry")
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("revoke all auth tokens by entry id must delete every auth token of the entry", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+		defer cancel()
+
+		gotCnt, err := agent.RevokeAllAuthTokensByEntryID(ctx, "another-entry")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantCnt := int64(1)
+		if gotCnt != wantCnt {
+			t.Fatalf("want %d deleted tokens, got %d", wantCnt, gotCnt)
+		}
+	})
+}
+
+func TestTokenAgent_RenewAuthToken(t *testing.T) {
+	t.Cleanup(truncate)
+
+	now := time.Now().Truncate(time.Second)
+
+	agent, err := domain.NewTokenAgent(tr, aa, &mockClock{t: now}, &mockLogger{}, testTokenHashKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entryID := "entry-with-session"
+
+	// insertAuthToken inserts an auth token for the entry that was issued and expires at the provided times
+	insertAuthToken := func(t *testing.T, id string, issuedAt, expiresAt time.Time) *domain.Token {
+		t.Helper()
+
+		token := generateTestToken(id)
+		token.Type = domain.TokenTypeAuth
+		token.Value = entryID
+		token.IssuedAt = issuedAt
+		token.ExpiresAt = expiresAt
+		insertToken(t, token)
+
+		return token
+	}
+
+	t.Run("renew auth token that is not close to expiry must not renew it", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		token := insertAuthToken(t, "auth-fresh", now.Add(-time.Minute), now.Add(59*time.Minute))
+
+		renewed, err := agent.RenewAuthToken(ctx, token.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if renewed != nil {
+			t.Fatalf("want nil renewed token, got %+v", renewed)
+		}
+
+		if _, err := agent.RetrieveTokenByID(ctx, token.ID); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("renew auth token that is close to expiry must replace it", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		loggedInAt := now.Add(-50 * time.Minute)
+		token := insertAuthToken(t, "auth-expiring", loggedInAt, now.Add(10*time.Minute))
+
+		renewed, err := agent.RenewAuthToken(ctx, token.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantRenewed := &domain.Token{
+			ID:        renewed.ID,
+			Type:      domain.TokenTypeAuth,
+			Value:     entryID,
+			IssuedAt:  loggedInAt,
+			ExpiresAt: now.Add(domain.TokenValidityDuration[domain.TokenTypeAuth]),
+		}
+		cmpDiff(t, "renewed token", wantRenewed, renewed)
+
+		if renewed.ID == token.ID {
+			t.Fatalf("want renewed token id to differ from %s", token.ID)
+		}
+
+		if _, err := agent.RetrieveTokenByID(ctx, renewed.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		// original token must remain valid for a short grace period only
+		original, err := agent.RetrieveTokenByID(ctx, token.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !original.ExpiresAt.Equal(now.Add(time.Minute)) {
+			expectedGot(t, now.Add(time.Minute), original.ExpiresAt)
+		}
+		if !agent.IsTokenValid(original, domain.TokenTypeAuth, entryID) {
+			t.Fatal("want original token to remain valid, got invalid")
+		}
+
+		// a concurrent request with the original token must not renew it again
+		renewedAgain, err := agent.RenewAuthToken(ctx, token.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if renewedAgain != nil {
+			t.Fatalf("want nil renewed token, got %+v", renewedAgain)
+		}
+	})
+
+	t.Run("renew auth token must not extend the session beyond the realm's maximum session lifetime", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		domain.RealmFromContext(ctx).Config.MaxSessionLifetime = 2 * time.Hour
+
+		loggedInAt := now.Add(-90 * time.Minute)
+		token := insertAuthToken(t, "auth-capped", loggedInAt, now.Add(10*time.Minute))
+
+		renewed, err := agent.RenewAuthToken(ctx, token.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantExpiresAt := loggedInAt.Add(2 * time.Hour)
+		if !renewed.ExpiresAt.Equal(wantExpiresAt) {
+			expectedGot(t, wantExpiresAt, renewed.ExpiresAt)
+		}
+	})
+
+	t.Run("renew auth token that has reached the maximum session lifetime must not renew it", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		domain.RealmFromContext(ctx).Config.MaxSessionLifetime = 2 * time.Hour
+
+		token := insertAuthToken(t, "auth-ending", now.Add(-110*time.Minute), now.Add(10*time.Minute))
+
+		renewed, err := agent.RenewAuthToken(ctx, token.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if renewed != nil {
+			t.Fatalf("want nil renewed token, got %+v", renewed)
+		}
+	})
+
+	t.Run("renew auth token that has expired must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		token := insertAuthToken(t, "auth-expired", now.Add(-time.Hour), now.Add(-time.Second))
+
+		_, err := agent.RenewAuthToken(ctx, token.ID)
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("renew a token that is not an auth token must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		token := generateTestToken("not-auth")
+		token.Type = domain.TokenTypeMagicLogin
+		token.ExpiresAt = now.Add(time.Minute)
+		insertToken(t, token)
+
+		_, err := agent.RenewAuthToken(ctx, token.ID)
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+}
+
+func TestTokenAgent_LoginCode(t *testing.T) {
+	t.Cleanup(truncate)
+
+	now := time.Now().Truncate(time.Second)
+	cl := &mockClock{t: now}
+
+	agent, err := domain.NewTokenAgent(tr, aa, cl, newMockLogger(), testTokenHashKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("generate login code must produce a code of 6 digits", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		code, err := agent.GenerateLoginCode(ctx, "entry-code-format")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !regexp.MustCompile(`^[0-9]{6}$`).MatchString(code) {
+			t.Fatalf("want code of 6 digits, got '%s'", code)
+		}
+	})
+
+	t.Run("redeem login code that matches must succeed only once", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		entryID := "entry-code-redeem"
+
+		code, err := agent.GenerateLoginCode(ctx, entryID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := agent.RedeemLoginCode(ctx, entryID, code); err != nil {
+			t.Fatal(err)
+		}
+
+		err = agent.RedeemLoginCode(ctx, entryID, code)
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("redeem login code that matches concurrently must succeed only once", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		entryID := "entry-code-concurrent"
+
+		code, err := agent.GenerateLoginCode(ctx, entryID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var redeemed int
+
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				err := agent.RedeemLoginCode(ctx, entryID, code)
+				if err != nil {
+					if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+						t.Errorf("want error type %T, got %T: %s", domain.UnauthorizedError{}, err, err)
+					}
+					return
+				}
+
+				mu.Lock()
+				redeemed++
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+
+		if redeemed != 1 {
+			t.Fatalf("want 1 redemption, got %d", redeemed)
+		}
+	})
+
+	t.Run("redeem login code with concurrent incorrect attempts must not allow more attempts than the maximum", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		entryID := "entry-code-concurrent-guesses"
+
+		code, err := agent.GenerateLoginCode(ctx, entryID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wrongCode := "000000"
+		if code == wrongCode {
+			wrongCode = "999999"
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				err := agent.RedeemLoginCode(ctx, entryID, wrongCode)
+				if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+					t.Errorf("want error type %T, got %T: %s", domain.UnauthorizedError{}, err, err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		err = agent.RedeemLoginCode(ctx, entryID, code)
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("redeem login code that belongs to another entry must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		code, err := agent.GenerateLoginCode(ctx, "entry-code-owner")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := agent.GenerateLoginCode(ctx, "entry-code-other"); err != nil {
+			t.Fatal(err)
+		}
+
+		err = agent.RedeemLoginCode(ctx, "entry-code-other", code)
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("generate login code must replace a previous code that has not been redeemed", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		entryID := "entry-code-replaced"
+
+		oldCode, err := agent.GenerateLoginCode(ctx, entryID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		newCode, err := agent.GenerateLoginCode(ctx, entryID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if oldCode != newCode {
+			err = agent.RedeemLoginCode(ctx, entryID, oldCode)
+			if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+				expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+			}
+		}
+
+		if err := agent.RedeemLoginCode(ctx, entryID, newCode); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("redeem login code after too many incorrect attempts must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		entryID := "entry-code-guessed"
+
+		code, err := agent.GenerateLoginCode(ctx, entryID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wrongCode := "000000"
+		if code == wrongCode {
+			wrongCode = "999999"
+		}
+
+		for i := 0; i < 5; i++ {
+			err := agent.RedeemLoginCode(ctx, entryID, wrongCode)
+			if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+				expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+			}
+		}
+
+		err = agent.RedeemLoginCode(ctx, entryID, code)
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("redeem login code that has expired must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		entryID := "entry-code-expired"
+
+		code, err := agent.GenerateLoginCode(ctx, entryID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cl.t = now.Add(domain.TokenValidityDuration[domain.TokenTypeLoginCode] + time.Second)
+		defer func() { cl.t = now }()
+
+		err = agent.RedeemLoginCode(ctx, entryID, code)
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("redeem login code that has not been generated must fail", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+
+		err := agent.RedeemLoginCode(ctx, "entry-code-none", "123456")
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+}
+
 func TestTokenAgent_IsTokenValid(t *testing.T) {
 	tkn := &domain.Token{
 		ID:        "tkn-id",
@@ -529,6 +1109,9 @@ func TestTokenAgent_IsTokenValid(t *testing.T) {
 		ExpiresAt: testDate,
 	}
 
+	// a usable token id must never be logged
+	hashedID := hashTestTokenID(tkn.ID)
+
 	tt := []struct {
 		name       string
 		typ        int
@@ -557,7 +1140,7 @@ func TestTokenAgent_IsTokenValid(t *testing.T) {
 			val:        "abcdef",
 			now:        testDate,
 			wantRes:    false,
-			wantLogMsg: "token id 'tkn-id': token type 0 is not 1",
+			wantLogMsg: fmt.Sprintf("hashed token id '%s': token type 0 is not 1", hashedID),
 		},
 		{
 			name:       "token with alt value must not be valid",
@@ -565,7 +1148,7 @@ func TestTokenAgent_IsTokenValid(t *testing.T) {
 			val:        "ghijkl",
 			now:        testDate,
 			wantRes:    false,
-			wantLogMsg: "token id 'tkn-id': token value 'abcdef' is not 'ghijkl'",
+			wantLogMsg: fmt.Sprintf("hashed token id '%s': token value 'abcdef' is not 'ghijkl'", hashedID),
 		},
 		{
 			name:       "token that has expired must not be valid",
@@ -573,7 +1156,7 @@ func TestTokenAgent_IsTokenValid(t *testing.T) {
 			val:        "abcdef",
 			now:        testDate.Add(time.Second),
 			wantRes:    false,
-			wantLogMsg: "token id 'tkn-id': expired",
+			wantLogMsg: fmt.Sprintf("hashed token id '%s': expired", hashedID),
 		},
 	}
 
@@ -582,7 +1165,7 @@ func TestTokenAgent_IsTokenValid(t *testing.T) {
 			l := newMockLogger()
 			cl := &mockClock{t: tc.now}
 
-			ta, err := domain.NewTokenAgent(tr, cl, l)
+			ta, err := domain.NewTokenAgent(tr, aa, cl, l, testTokenHashKey)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -611,3 +1194,22 @@ func generateTestToken(id string) *domain.Token {
 		ExpiresAt: ts.Add(time.Minute),
 	}
 }
+
+// hashTestTokenID returns the keyed hash of the provided token ID, by which the token is stored
+func hashTestTokenID(id string) string {
+	mac := hmac.New(sha256.New, testTokenHashKey)
+	mac.Write([]byte(id))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// insertToken inserts the provided token identified by the hash of its ID, as a TokenAgent would
+func insertToken(t *testing.T, token *domain.Token) {
+	t.Helper()
+
+	stored := *token
+	stored.ID = hashTestTokenID(token.ID)
+
+	if err := tr.Insert(context.Background(), &stored); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// WaitlistPromotionEmailIssuer defines behaviours required to issue a Waitlist Promotion email
+type WaitlistPromotionEmailIssuer interface {
+	IssueWaitlistPromotionEmail(ctx context.Context, entry *Entry, regToken *Token) error
+}
+
+// WaitlistAgent defines the behaviours for handling the waitlist of a realm with an entry cap
+type WaitlistAgent struct {
+	ea *EntryAgent
+	ta *TokenAgent
+	ei WaitlistPromotionEmailIssuer
+	l  Logger
+}
+
+// PromoteWaitlistedEntries gives each available place in the provided Season to the next Entry on the waitlist of the
+// current realm, and emails its entrant a link to pay for it that remains valid for as long as the Season is accepting
+// entries. The Entries that have been promoted are returned
+func (w *WaitlistAgent) PromoteWaitlistedEntries(ctx context.Context, season Season) ([]Entry, error) {
+	var promoted []Entry
+
+	for {
+		entry, err := w.ea.PromoteNextWaitlistedEntry(ctx, season)
+		if err != nil {
+			if errors.Is(err, ErrNothingToPromote) {
+				// no more places or nobody left waiting
+				return promoted, nil
+			}
+			return promoted, err
+		}
+
+		promoted = append(promoted, *entry)
+
+		regToken, err := w.ta.GenerateTokenExpiringAt(
+			ctx,
+			TokenTypeEntryRegistration,
+			entry.ID.String(),
+			season.EntriesAccepted.Until,
+		)
+		if err != nil {
+			return promoted, fmt.Errorf("cannot generate registration token for entry %s: %w", entry.ID, err)
+		}
+
+		if err := w.ei.IssueWaitlistPromotionEmail(ctx, entry, regToken); err != nil {
+			return promoted, fmt.Errorf("cannot issue waitlist promotion email for entry %s: %w", entry.ID, err)
+		}
+	}
+}
+
+// WithdrawEntryByID marks the Entry with the provided ID as withdrawn at the entrant's request, and gives any place it
+// held to the next Entry on the waitlist
+func (w *WaitlistAgent) WithdrawEntryByID(ctx context.Context, id string) (Entry, error) {
+	return w.excludeEntryByID(ctx, id, w.ea.WithdrawEntryByID)
+}
+
+// DisqualifyEntryByID marks the Entry with the provided ID as disqualified, and gives any place it held to the next
+// Entry on the waitlist
+func (w *WaitlistAgent) DisqualifyEntryByID(ctx context.Context, id string) (Entry, error) {
+	return w.excludeEntryByID(ctx, id, w.ea.DisqualifyEntryByID)
+}
+
+// UpdateEntryPaymentStatusByID changes the payment status of the Entry with the provided ID, and gives any place it gave up
+// as a result to the next Entry on the waitlist
+func (w *WaitlistAgent) UpdateEntryPaymentStatusByID(ctx context.Context, id, status string) (Entry, error) {
+	return w.excludeEntryByID(ctx, id, func(ctx context.Context, id string) (Entry, error) {
+		return w.ea.UpdateEntryPaymentStatusByID(ctx, id, status)
+	})
+}
+
+// excludeEntryByID excludes the Entry with the provided ID using the provided function, then promotes waitlisted
+// Entries into the place it may have given up
+func (w *WaitlistAgent) excludeEntryByID(ctx context.Context, id string, exclude func(context.Context, string) (Entry, error)) (Entry, error) {
+	entry, err := exclude(ctx, id)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	season, err := w.ea.sc.GetByID(entry.SeasonID)
+	if err != nil {
+		return Entry{}, NotFoundError{fmt.Errorf("cannot get season with id '%s': %w", entry.SeasonID, err)}
+	}
+
+	// the entry has already been excluded at this point, so a failure to fill the place it gave up is no reason to fail,
+	// since that place is filled by the next run of the payment reminder worker instead
+	if _, err := w.PromoteWaitlistedEntries(ctx, season); err != nil {
+		w.l.Errorf("entry %s excluded, but cannot promote waitlisted entries: %s", entry.ID, err.Error())
+	}
+
+	return entry, nil
+}
+
+// NewWaitlistAgent returns a new WaitlistAgent using the provided agents, email issuer and logger
+func NewWaitlistAgent(ea *EntryAgent, ta *TokenAgent, ei WaitlistPromotionEmailIssuer, l Logger) (*WaitlistAgent, error) {
+	switch {
+	case ea == nil:
+		return nil, fmt.Errorf("entry agent: %w", ErrIsNil)
+	case ta == nil:
+		return nil, fmt.Errorf("token agent: %w", ErrIsNil)
+	case ei == nil:
+		return nil, fmt.Errorf("email issuer: %w", ErrIsNil)
+	case l == nil:
+		return nil, fmt.Errorf("logger: %w", ErrIsNil)
+	}
+	return &WaitlistAgent{ea, ta, ei, l}, nil
+}
@@ -0,0 +1,269 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"prediction-league/service/internal/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewWaitlistAgent(t *testing.T) {
+	t.Run("passing invalid parameters must return expected error", func(t *testing.T) {
+		ea := &domain.EntryAgent{}
+		ta := &domain.TokenAgent{}
+		ei := &mockWaitlistPromotionEmailIssuer{}
+		l := newMockLogger()
+
+		tt := []struct {
+			ea      *domain.EntryAgent
+			ta      *domain.TokenAgent
+			ei      domain.WaitlistPromotionEmailIssuer
+			l       domain.Logger
+			wantErr error
+		}{
+			{nil, ta, ei, l, domain.ErrIsNil},
+			{ea, nil, ei, l, domain.ErrIsNil},
+			{ea, ta, nil, l, domain.ErrIsNil},
+			{ea, ta, ei, nil, domain.ErrIsNil},
+			{ea, ta, ei, l, nil},
+		}
+
+		for idx, tc := range tt {
+			agent, gotErr := domain.NewWaitlistAgent(tc.ea, tc.ta, tc.ei, tc.l)
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Fatalf("tc #%d: want error %s (%T), got %s (%T)", idx, tc.wantErr, tc.wantErr, gotErr, gotErr)
+			}
+			if tc.wantErr == nil && agent == nil {
+				t.Fatalf("tc #%d: want non-empty agent, got nil", idx)
+			}
+		}
+	})
+}
+
+func TestWaitlistAgent_PromoteWaitlistedEntries(t *testing.T) {
+	t.Cleanup(truncate)
+
+	now := time.Now().Truncate(time.Second)
+
+	season := testSeason
+	season.EntriesAccepted.From = now.Add(-24 * time.Hour)
+	season.EntriesAccepted.Until = now.Add(24 * time.Hour)
+
+	cl := &mockClock{t: now}
+
+	ea, err := domain.NewEntryAgent(er, epr, sr, domain.SeasonCollection{season.ID: season}, aa, cl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ta, err := domain.NewTokenAgent(tr, aa, cl, &mockLogger{}, testTokenHashKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paidEntry := insertPaidTestEntry(t, "Harry Redknapp", "MrHarryR", "harry.redknapp@football.net", "HARRY-PAID")
+
+	waitlistedEntry := generateTestEntry(t, "Jamie Redknapp", "MrJamieR", "jamie.redknapp@football.net")
+	waitlistedEntry.Status = domain.EntryStatusWaitlisted
+	waitlistedEntry = insertEntry(t, waitlistedEntry)
+
+	// realm only has room for the paid entry until it is withdrawn
+	testContextWithEntryCap := func(t *testing.T) (context.Context, context.CancelFunc) {
+		ctx, cancel := testContextDefault(t)
+		domain.RealmFromContext(ctx).Config.EntryCap = 1
+		return domain.SetAdminUserOnContext(ctx, testSuperAdmin), cancel
+	}
+
+	t.Run("promote waitlisted entries while the realm is full must promote nothing", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryCap(t)
+		defer cancel()
+
+		ei := &mockWaitlistPromotionEmailIssuer{}
+
+		agent, err := domain.NewWaitlistAgent(ea, ta, ei, newMockLogger())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		promoted, err := agent.PromoteWaitlistedEntries(ctx, season)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(promoted) != 0 {
+			expectedEmpty(t, "promoted entries", promoted)
+		}
+		if len(ei.promoted) != 0 {
+			expectedEmpty(t, "promotion emails", ei.promoted)
+		}
+	})
+
+	t.Run("promote waitlisted entries once a place is available must promote and notify entrant", func(t *testing.T) {
+		ctx, cancel := testContextWithEntryCap(t)
+		defer cancel()
+
+		if _, err := ea.WithdrawEntryByID(ctx, paidEntry.ID.String()); err != nil {
+			t.Fatal(err)
+		}
+
+		ei := &mockWaitlistPromotionEmailIssuer{}
+
+		agent, err := domain.NewWaitlistAgent(ea, ta, ei, newMockLogger())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		promoted, err := agent.PromoteWaitlistedEntries(ctx, season)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(promoted) != 1 {
+			t.Fatalf("want 1 promoted entry, got %d", len(promoted))
+		}
+		if promoted[0].ID != waitlistedEntry.ID {
+			expectedGot(t, waitlistedEntry.ID, promoted[0].ID)
+		}
+
+		wantPromoted := []string{waitlistedEntry.ID.String()}
+		cmpDiff(t, "promotion emails", wantPromoted, ei.promoted)
+
+		tkn := ei.tokens[0]
+		if tkn.Type != domain.TokenTypeEntryRegistration {
+			expectedGot(t, domain.TokenTypeEntryRegistration, tkn.Type)
+		}
+		if tkn.Value != waitlistedEntry.ID.String() {
+			expectedGot(t, waitlistedEntry.ID.String(), tkn.Value)
+		}
+		if !tkn.ExpiresAt.Equal(season.EntriesAccepted.Until) {
+			expectedGot(t, season.EntriesAccepted.Until, tkn.ExpiresAt)
+		}
+
+		entry := mustRetrieveEntryByID(t, ctx, waitlistedEntry.ID.String())
+		if entry.Status != domain.EntryStatusPending {
+			expectedGot(t, domain.EntryStatusPending, entry.Status)
+		}
+	})
+}
+
+func TestWaitlistAgent_WithdrawEntryByID(t *testing.T) {
+	t.Cleanup(truncate)
+
+	now := time.Now().Truncate(time.Second)
+
+	season := testSeason
+	season.EntriesAccepted.From = now.Add(-24 * time.Hour)
+	season.EntriesAccepted.Until = now.Add(24 * time.Hour)
+
+	cl := &mockClock{t: now}
+
+	ea, err := domain.NewEntryAgent(er, epr, sr, domain.SeasonCollection{season.ID: season}, aa, cl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ta, err := domain.NewTokenAgent(tr, aa, cl, &mockLogger{}, testTokenHashKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ei := &mockWaitlistPromotionEmailIssuer{}
+
+	agent, err := domain.NewWaitlistAgent(ea, ta, ei, newMockLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paidEntry := insertPaidTestEntry(t, "Harry Redknapp", "MrHarryR", "harry.redknapp@football.net", "HARRY-PAID")
+
+	waitlistedEntry := generateTestEntry(t, "Jamie Redknapp", "MrJamieR", "jamie.redknapp@football.net")
+	waitlistedEntry.Status = domain.EntryStatusWaitlisted
+	waitlistedEntry = insertEntry(t, waitlistedEntry)
+
+	t.Run("withdraw entry holding a place must promote the next waitlisted entry", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+		domain.RealmFromContext(ctx).Config.EntryCap = 1
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+
+		withdrawn, err := agent.WithdrawEntryByID(ctx, paidEntry.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if withdrawn.Status != domain.EntryStatusWithdrawn {
+			expectedGot(t, domain.EntryStatusWithdrawn, withdrawn.Status)
+		}
+
+		wantPromoted := []string{waitlistedEntry.ID.String()}
+		cmpDiff(t, "promotion emails", wantPromoted, ei.promoted)
+
+		entry := mustRetrieveEntryByID(t, ctx, waitlistedEntry.ID.String())
+		if entry.Status != domain.EntryStatusPending {
+			expectedGot(t, domain.EntryStatusPending, entry.Status)
+		}
+	})
+
+	t.Run("withdraw entry whose place cannot be given to the next waitlisted entry must still succeed", func(t *testing.T) {
+		ctx, cancel := testContextDefault(t)
+		defer cancel()
+		domain.RealmFromContext(ctx).Config.EntryCap = 1
+		ctx = domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+
+		heldEntry := mustRetrieveEntryByID(t, ctx, waitlistedEntry.ID.String())
+
+		otherWaitlistedEntry := generateTestEntry(t, "Frank Lampard", "FrankieL", "frank.lampard@football.net")
+		otherWaitlistedEntry.Status = domain.EntryStatusWaitlisted
+		otherWaitlistedEntry = insertEntry(t, otherWaitlistedEntry)
+
+		l := newMockLogger()
+		failingAgent, err := domain.NewWaitlistAgent(ea, ta, &mockWaitlistPromotionEmailIssuer{err: errors.New("sad times :'(")}, l)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		withdrawn, err := failingAgent.WithdrawEntryByID(ctx, heldEntry.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if withdrawn.Status != domain.EntryStatusWithdrawn {
+			expectedGot(t, domain.EntryStatusWithdrawn, withdrawn.Status)
+		}
+
+		if !strings.Contains(l.buf.String(), "cannot promote waitlisted entries") {
+			expectedGot(t, "logged promotion failure", l.buf.String())
+		}
+	})
+}
+
+type mockWaitlistPromotionEmailIssuer struct {
+	promoted []string
+	tokens   []domain.Token
+	err      error
+}
+
+func (m *mockWaitlistPromotionEmailIssuer) IssueWaitlistPromotionEmail(_ context.Context, entry *domain.Entry, regToken *domain.Token) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.promoted = append(m.promoted, entry.ID.String())
+	m.tokens = append(m.tokens, *regToken)
+	return nil
+}
+
+// newTestWaitlistAgent returns a new WaitlistAgent using the provided EntryAgent, whose promotion emails are discarded
+func newTestWaitlistAgent(t *testing.T, ea *domain.EntryAgent) *domain.WaitlistAgent {
+	t.Helper()
+
+	ta, err := domain.NewTokenAgent(tr, aa, &mockClock{t: testDate}, &mockLogger{}, testTokenHashKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wa, err := domain.NewWaitlistAgent(ea, ta, &mockWaitlistPromotionEmailIssuer{}, newMockLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return wa
+}
@@ -0,0 +1,212 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PaymentReminderEmailIssuer defines behaviours required to issue a Payment Reminder email
+type PaymentReminderEmailIssuer interface {
+	IssuePaymentReminderEmail(ctx context.Context, entry *Entry, regToken *Token) error
+}
+
+// PaymentReminderWorker performs the work required to chase the unpaid Entries of a provided Season.
+// While the Season is accepting entries, each entrant who has not paid within the reminder delay is sent a single
+// reminder. Once the Season has stopped accepting entries, any Entry that remains unpaid or waitlisted is expired.
+// In a realm with an entry cap, an unpaid Entry that is still unpaid once the reminder delay has passed again since its
+// entrant was reminded gives up its place to the next Entry on the waitlist
+type PaymentReminderWorker struct {
+	season          Season
+	realmCollection RealmCollection
+	reminderDelay   time.Duration
+	clock           Clock
+	logger          Logger
+	entryAgent      *EntryAgent
+	tokenAgent      *TokenAgent
+	waitlistAgent   *WaitlistAgent
+	emailIssuer     PaymentReminderEmailIssuer
+}
+
+// DoWork implements domain.Worker
+func (p *PaymentReminderWorker) DoWork(ctx context.Context) error {
+	now := p.clock.Now()
+
+	if !p.season.EntriesAccepted.HasBegunBy(now) {
+		// nobody can have entered yet
+		return nil
+	}
+
+	var errs []error
+
+	for _, realm := range p.realmCollection {
+		if realm.Config.SeasonID != p.season.ID {
+			continue
+		}
+
+		if err := p.processRealm(ctx, realm, now); err != nil {
+			errs = append(errs, fmt.Errorf("realm %s: %w", realm.Config.Name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return MultiError{Errs: errs}
+	}
+
+	return nil
+}
+
+// processRealm reminds or expires each of the unpaid Entries that belong to the provided Realm,
+// and promotes waitlisted Entries into any places that become available as a result
+func (p *PaymentReminderWorker) processRealm(ctx context.Context, realm Realm, now time.Time) error {
+	// the system acts on behalf of the realm's admins
+	ctx = SetSystemAdminRoleOnContext(contextWithRealm(ctx, realm), AdminRoleApprover)
+
+	entries, err := p.entryAgent.RetrieveEntriesByFilter(ctx, EntryFilter{
+		RealmName: realm.Config.Name,
+		SeasonID:  p.season.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot retrieve entries: %w", err)
+	}
+
+	var hasWaitlist bool
+	for _, entry := range entries {
+		if entry.Status == EntryStatusWaitlisted {
+			hasWaitlist = true
+			break
+		}
+	}
+
+	var errs []error
+
+	for _, entry := range entries {
+		if entry.Status != EntryStatusPending && entry.Status != EntryStatusWaitlisted {
+			continue
+		}
+
+		if p.season.EntriesAccepted.HasElapsedBy(now) {
+			if _, err := p.entryAgent.ExpireEntryByID(ctx, entry.ID.String(), p.season); err != nil {
+				errs = append(errs, fmt.Errorf("cannot expire entry %s: %w", entry.ID, err))
+				continue
+			}
+			p.logger.Infof("season %s: expired %s entry %s (%s)", p.season.ID, entry.Status, entry.ID, entry.EntrantNickname)
+			continue
+		}
+
+		if entry.Status == EntryStatusWaitlisted {
+			// entrant is waiting for a place and has nothing to pay for yet
+			continue
+		}
+
+		if entry.ReminderSentAt != nil {
+			// only give up the entry's place if somebody is waiting for it
+			if !hasWaitlist || now.Before(entry.ReminderSentAt.Add(p.reminderDelay)) {
+				continue
+			}
+			if _, err := p.entryAgent.ExpireEntryByID(ctx, entry.ID.String(), p.season); err != nil {
+				errs = append(errs, fmt.Errorf("cannot expire entry %s: %w", entry.ID, err))
+				continue
+			}
+			p.logger.Infof("season %s: expired unpaid entry %s (%s) to make room for the waitlist", p.season.ID, entry.ID, entry.EntrantNickname)
+			continue
+		}
+
+		if now.Before(entry.CreatedAt.Add(p.reminderDelay)) {
+			continue
+		}
+
+		if err := p.remindEntrant(ctx, entry); err != nil {
+			errs = append(errs, fmt.Errorf("cannot remind entrant of entry %s: %w", entry.ID, err))
+		}
+	}
+
+	if hasWaitlist && !p.season.EntriesAccepted.HasElapsedBy(now) {
+		promoted, err := p.waitlistAgent.PromoteWaitlistedEntries(ctx, p.season)
+		for _, entry := range promoted {
+			p.logger.Infof("season %s: promoted waitlisted entry %s (%s)", p.season.ID, entry.ID, entry.EntrantNickname)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot promote waitlisted entries: %w", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return MultiError{Errs: errs}
+	}
+
+	return nil
+}
+
+// remindEntrant issues a payment reminder to the entrant of the provided Entry, with a registration token
+// that remains valid for as long as the Season is accepting entries
+func (p *PaymentReminderWorker) remindEntrant(ctx context.Context, entry Entry) error {
+	regToken, err := p.tokenAgent.GenerateTokenExpiringAt(
+		ctx,
+		TokenTypeEntryRegistration,
+		entry.ID.String(),
+		p.season.EntriesAccepted.Until,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot generate registration token: %w", err)
+	}
+
+	if err := p.emailIssuer.IssuePaymentReminderEmail(ctx, &entry, regToken); err != nil {
+		return fmt.Errorf("cannot issue payment reminder email: %w", err)
+	}
+
+	// a failure from here on means the entrant may be reminded again, which is preferable to them never being reminded
+	if _, err := p.entryAgent.MarkReminderSentByID(ctx, entry.ID.String()); err != nil {
+		return fmt.Errorf("cannot mark reminder as sent: %w", err)
+	}
+
+	return nil
+}
+
+// PaymentReminderWorkerParams defines the parameters required by NewPaymentReminderWorker
+type PaymentReminderWorkerParams struct {
+	Season          Season
+	RealmCollection RealmCollection
+	ReminderDelay   time.Duration
+	Clock           Clock
+	Logger          Logger
+	EntryAgent      *EntryAgent
+	TokenAgent      *TokenAgent
+	WaitlistAgent   *WaitlistAgent
+	EmailIssuer     PaymentReminderEmailIssuer
+}
+
+func NewPaymentReminderWorker(params PaymentReminderWorkerParams) (*PaymentReminderWorker, error) {
+	if params.RealmCollection == nil {
+		return nil, fmt.Errorf("realm collection: %w", ErrIsNil)
+	}
+	if params.Clock == nil {
+		return nil, fmt.Errorf("clock: %w", ErrIsNil)
+	}
+	if params.Logger == nil {
+		return nil, fmt.Errorf("logger: %w", ErrIsNil)
+	}
+	if params.EntryAgent == nil {
+		return nil, fmt.Errorf("entry agent: %w", ErrIsNil)
+	}
+	if params.TokenAgent == nil {
+		return nil, fmt.Errorf("token agent: %w", ErrIsNil)
+	}
+	if params.WaitlistAgent == nil {
+		return nil, fmt.Errorf("waitlist agent: %w", ErrIsNil)
+	}
+	if params.EmailIssuer == nil {
+		return nil, fmt.Errorf("email issuer: %w", ErrIsNil)
+	}
+	return &PaymentReminderWorker{
+		season:          params.Season,
+		realmCollection: params.RealmCollection,
+		reminderDelay:   params.ReminderDelay,
+		clock:           params.Clock,
+		logger:          params.Logger,
+		entryAgent:      params.EntryAgent,
+		tokenAgent:      params.TokenAgent,
+		waitlistAgent:   params.WaitlistAgent,
+		emailIssuer:     params.EmailIssuer,
+	}, nil
+}
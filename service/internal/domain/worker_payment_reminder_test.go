@@ -0,0 +1,293 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"prediction-league/service/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestNewPaymentReminderWorker(t *testing.T) {
+	cl := &mockClock{}
+	l := &mockLogger{}
+	ea := emptyEntryAgent
+	ta := &domain.TokenAgent{}
+	wla := &domain.WaitlistAgent{}
+	ei := &mockPaymentReminderEmailIssuer{}
+
+	tt := []struct {
+		name        string
+		rc          domain.RealmCollection
+		cl          domain.Clock
+		l           domain.Logger
+		ea          *domain.EntryAgent
+		ta          *domain.TokenAgent
+		wla         *domain.WaitlistAgent
+		emailIssuer domain.PaymentReminderEmailIssuer
+		wantErr     bool
+	}{
+		{"missing realm collection", nil, cl, l, ea, ta, wla, ei, true},
+		{"missing clock", rc, nil, l, ea, ta, wla, ei, true},
+		{"missing logger", rc, cl, nil, ea, ta, wla, ei, true},
+		{"missing entry agent", rc, cl, l, nil, ta, wla, ei, true},
+		{"missing token agent", rc, cl, l, ea, nil, wla, ei, true},
+		{"missing waitlist agent", rc, cl, l, ea, ta, nil, ei, true},
+		{"missing email issuer", rc, cl, l, ea, ta, wla, nil, true},
+		{"no missing dependencies", rc, cl, l, ea, ta, wla, ei, false},
+	}
+	for idx, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			params := domain.PaymentReminderWorkerParams{
+				RealmCollection: tc.rc,
+				Clock:           tc.cl,
+				Logger:          tc.l,
+				EntryAgent:      tc.ea,
+				TokenAgent:      tc.ta,
+				WaitlistAgent:   tc.wla,
+				EmailIssuer:     tc.emailIssuer,
+			}
+
+			w, gotErr := domain.NewPaymentReminderWorker(params)
+			if tc.wantErr && !errors.Is(gotErr, domain.ErrIsNil) {
+				t.Fatalf("tc #%d: want ErrIsNil, got %s (%T)", idx, gotErr, gotErr)
+			}
+			if !tc.wantErr && w == nil {
+				t.Fatalf("tc #%d: want non-empty worker, got nil", idx)
+			}
+		})
+	}
+}
+
+func TestPaymentReminderWorker_DoWork(t *testing.T) {
+	t.Cleanup(truncate)
+
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	reminderDelay := 48 * time.Hour
+
+	unpaidEntry := insertEntry(t, generateTestEntry(t, "Harry Redknapp", "MrHarryR", "harry.redknapp@football.net"))
+	paidEntry := insertPaidTestEntry(t, "Jamie Redknapp", "MrJamieR", "jamie.redknapp@football.net", "JAMIE-PAID")
+
+	season := testSeason
+	season.EntriesAccepted.From = now.Add(-24 * time.Hour)
+	season.EntriesAccepted.Until = now.Add(7 * 24 * time.Hour)
+
+	newWorker := func(t *testing.T, season domain.Season, ts time.Time, emailIssuer domain.PaymentReminderEmailIssuer) *domain.PaymentReminderWorker {
+		t.Helper()
+
+		cl := &mockClock{t: ts}
+
+		ea, err := domain.NewEntryAgent(er, epr, sr, domain.SeasonCollection{season.ID: season}, aa, cl)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ta, err := domain.NewTokenAgent(tr, aa, cl, &mockLogger{}, testTokenHashKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wla, err := domain.NewWaitlistAgent(ea, ta, &mockWaitlistPromotionEmailIssuer{}, newMockLogger())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w, err := domain.NewPaymentReminderWorker(domain.PaymentReminderWorkerParams{
+			Season:          season,
+			RealmCollection: rc,
+			ReminderDelay:   reminderDelay,
+			Clock:           cl,
+			Logger:          newMockLogger(),
+			EntryAgent:      ea,
+			TokenAgent:      ta,
+			WaitlistAgent:   wla,
+			EmailIssuer:     emailIssuer,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return w
+	}
+
+	t.Run("unpaid entry within reminder delay must not be reminded", func(t *testing.T) {
+		ei := &mockPaymentReminderEmailIssuer{}
+
+		if err := newWorker(t, season, now.Add(time.Hour), ei).DoWork(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(ei.reminded) != 0 {
+			expectedEmpty(t, "reminded entries", ei.reminded)
+		}
+	})
+
+	t.Run("unpaid entry beyond reminder delay must be reminded once", func(t *testing.T) {
+		ei := &mockPaymentReminderEmailIssuer{}
+		ts := now.Add(reminderDelay + time.Hour)
+
+		// a second run must not remind the entrant again
+		for i := 0; i < 2; i++ {
+			if err := newWorker(t, season, ts, ei).DoWork(ctx); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		wantReminded := []string{unpaidEntry.ID.String()}
+		cmpDiff(t, "reminded entries", wantReminded, ei.reminded)
+
+		tkn := ei.tokens[0]
+		if tkn.Type != domain.TokenTypeEntryRegistration {
+			expectedGot(t, domain.TokenTypeEntryRegistration, tkn.Type)
+		}
+		if tkn.Value != unpaidEntry.ID.String() {
+			expectedGot(t, unpaidEntry.ID.String(), tkn.Value)
+		}
+		if !tkn.ExpiresAt.Equal(season.EntriesAccepted.Until) {
+			expectedGot(t, season.EntriesAccepted.Until, tkn.ExpiresAt)
+		}
+
+		entry := mustRetrieveEntryByID(t, ctx, unpaidEntry.ID.String())
+		wantReminderSentAt := ts
+		checkTimePtrMatch(t, &wantReminderSentAt, entry.ReminderSentAt)
+	})
+
+	t.Run("failure to issue reminder must not mark entry as reminded", func(t *testing.T) {
+		entry := insertEntry(t, generateTestEntry(t, "Frank Lampard", "FrankieL", "frank.lampard@football.net"))
+
+		ei := &mockPaymentReminderEmailIssuer{err: errors.New("sad times :'(")}
+
+		err := newWorker(t, season, now.Add(reminderDelay+time.Hour), ei).DoWork(ctx)
+		if !errors.As(err, &domain.MultiError{}) {
+			expectedTypeOfGot(t, domain.MultiError{}, err)
+		}
+
+		entry = mustRetrieveEntryByID(t, ctx, entry.ID.String())
+		if entry.ReminderSentAt != nil {
+			expectedEmpty(t, "reminder sent at", entry.ReminderSentAt)
+		}
+	})
+
+	t.Run("unpaid entries must expire once entries have closed", func(t *testing.T) {
+		closedSeason := season
+		closedSeason.EntriesAccepted.Until = now.Add(time.Hour)
+
+		ei := &mockPaymentReminderEmailIssuer{}
+
+		if err := newWorker(t, closedSeason, now.Add(2*time.Hour), ei).DoWork(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(ei.reminded) != 0 {
+			expectedEmpty(t, "reminded entries", ei.reminded)
+		}
+
+		entry := mustRetrieveEntryByID(t, ctx, unpaidEntry.ID.String())
+		if entry.Status != domain.EntryStatusExpired {
+			expectedGot(t, domain.EntryStatusExpired, entry.Status)
+		}
+
+		entry = mustRetrieveEntryByID(t, ctx, paidEntry.ID.String())
+		if entry.Status != domain.EntryStatusPaid {
+			expectedGot(t, domain.EntryStatusPaid, entry.Status)
+		}
+
+		// nickname of expired entry must be available to a new entrant
+		insertEntry(t, generateTestEntry(t, "Harry Kane", "MrHarryR", "harry.kane@football.net"))
+	})
+}
+
+func TestPaymentReminderWorker_DoWork_EntryCap(t *testing.T) {
+	t.Cleanup(truncate)
+
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	reminderDelay := 48 * time.Hour
+
+	season := testSeason
+	season.EntriesAccepted.From = now.Add(-24 * time.Hour)
+	season.EntriesAccepted.Until = now.Add(7 * 24 * time.Hour)
+
+	// realm only has room for a single entry
+	realm := newTestRealm()
+	realm.Config.EntryCap = 1
+	realms := domain.RealmCollection{realm}
+
+	remindedAt := now.Add(-reminderDelay - time.Hour)
+	unpaidEntry := generateTestEntry(t, "Harry Redknapp", "MrHarryR", "harry.redknapp@football.net")
+	unpaidEntry.ReminderSentAt = &remindedAt
+	unpaidEntry = insertEntry(t, unpaidEntry)
+
+	waitlistedEntry := generateTestEntry(t, "Jamie Redknapp", "MrJamieR", "jamie.redknapp@football.net")
+	waitlistedEntry.Status = domain.EntryStatusWaitlisted
+	waitlistedEntry = insertEntry(t, waitlistedEntry)
+
+	cl := &mockClock{t: now}
+
+	ea, err := domain.NewEntryAgent(er, epr, sr, domain.SeasonCollection{season.ID: season}, aa, cl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ta, err := domain.NewTokenAgent(tr, aa, cl, &mockLogger{}, testTokenHashKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	promotionIssuer := &mockWaitlistPromotionEmailIssuer{}
+
+	wla, err := domain.NewWaitlistAgent(ea, ta, promotionIssuer, newMockLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := domain.NewPaymentReminderWorker(domain.PaymentReminderWorkerParams{
+		Season:          season,
+		RealmCollection: realms,
+		ReminderDelay:   reminderDelay,
+		Clock:           cl,
+		Logger:          newMockLogger(),
+		EntryAgent:      ea,
+		TokenAgent:      ta,
+		WaitlistAgent:   wla,
+		EmailIssuer:     &mockPaymentReminderEmailIssuer{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("reminded entry that remains unpaid must give up its place to the waitlist", func(t *testing.T) {
+		if err := w.DoWork(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		entry := mustRetrieveEntryByID(t, ctx, unpaidEntry.ID.String())
+		if entry.Status != domain.EntryStatusExpired {
+			expectedGot(t, domain.EntryStatusExpired, entry.Status)
+		}
+
+		entry = mustRetrieveEntryByID(t, ctx, waitlistedEntry.ID.String())
+		if entry.Status != domain.EntryStatusPending {
+			expectedGot(t, domain.EntryStatusPending, entry.Status)
+		}
+
+		wantPromoted := []string{waitlistedEntry.ID.String()}
+		cmpDiff(t, "promotion emails", wantPromoted, promotionIssuer.promoted)
+	})
+}
+
+type mockPaymentReminderEmailIssuer struct {
+	reminded []string
+	tokens   []domain.Token
+	err      error
+}
+
+func (m *mockPaymentReminderEmailIssuer) IssuePaymentReminderEmail(_ context.Context, entry *domain.Entry, regToken *domain.Token) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.reminded = append(m.reminded, entry.ID.String())
+	m.tokens = append(m.tokens, *regToken)
+	return nil
+}
@@ -5,12 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 )
 
-// RoundCompleteEmailIssuer defines behaviours required to issue a Round Complete email
+// RoundCompleteEmailIssuer defines behaviours required to issue a Round Complete email,
+// along with any subsequent Scores Corrected emails for the same round
 type RoundCompleteEmailIssuer interface {
 	IssueRoundCompleteEmail(ctx context.Context, sep ScoredEntryPrediction, isFinalRound bool) error
+	IssueScoresCorrectedEmail(ctx context.Context, sep ScoredEntryPrediction, previousScore int) error
 }
 
 // RetrieveLatestStandingsWorker performs the work required to retrieve the latest standings for a provided Season
@@ -21,9 +24,13 @@ type RetrieveLatestStandingsWorker struct {
 	logger                     Logger
 	entryAgent                 *EntryAgent
 	standingsAgent             *StandingsAgent
+	quarantineAgent            *StandingsQuarantineAgent
+	snapshotAgent              *StandingsSnapshotAgent
+	correctionAgent            *StandingsCorrectionAgent
 	scoredEntryPredictionAgent *ScoredEntryPredictionAgent
 	matchWeekSubmissionAgent   *MatchWeekSubmissionAgent
 	matchWeekResultAgent       *MatchWeekResultAgent
+	prizeAgent                 *PrizeAgent
 	emailIssuer                RoundCompleteEmailIssuer
 	footballClient             FootballDataSource
 }
@@ -58,6 +65,20 @@ func (r *RetrieveLatestStandingsWorker) DoWork(ctx context.Context) error {
 		return fmt.Errorf("cannot validate and sort client standings: %w", err)
 	}
 
+	// work out which match week the standings represent, as the upstream matchday cannot always be relied upon
+	inference, err := InferMatchWeekNumber(r.season, latestStandings)
+	if err != nil {
+		return fmt.Errorf("cannot infer match week number: %w", err)
+	}
+	r.logger.Infof(
+		"season %s: using match week %d from strategy '%s': %s",
+		r.season.ID,
+		inference.Number,
+		inference.Strategy,
+		inference.Reason,
+	)
+	latestStandings.RoundNumber = inference.Number
+
 	// if standings retrieved from client represents a completed season, ensure that round number reflects the season's
 	// max rounds - standings data from upstream client was stuck on round 37 for a 38-round PL season in 2019/20
 	// so this check safeguards against that
@@ -65,10 +86,32 @@ func (r *RetrieveLatestStandingsWorker) DoWork(ctx context.Context) error {
 		latestStandings.RoundNumber = r.season.MaxRounds
 	}
 
+	quarantined, err := r.QuarantineIfAnomalous(ctx, latestStandings)
+	if err != nil {
+		return fmt.Errorf("cannot check standings for anomalies: %w", err)
+	}
+	if quarantined {
+		// leave our stored standings untouched until an admin has reviewed the update
+		return nil
+	}
+
 	var jobStandings Standings
 
 	existingStandings, err := r.standingsAgent.RetrieveStandingsBySeasonAndRoundNumber(ctx, r.season.ID, latestStandings.RoundNumber)
 	switch {
+	case err == nil && existingStandings.Finalised:
+		// we have existing standings that have already been finalised, so the only change we can accept is a correction
+		if err := r.ProcessCorrectedStandings(ctx, existingStandings, latestStandings); err != nil {
+			return fmt.Errorf("cannot process corrected standings: %w", err)
+		}
+
+		if r.season.IsCompletedByStandings(existingStandings) {
+			// payouts for any realm that has none yet are locked in now, in case a previous attempt failed
+			if err := r.prizeAgent.LockInSeasonPayouts(ctx, r.season); err != nil {
+				return fmt.Errorf("cannot lock in prize payouts: %w", err)
+			}
+		}
+		return nil
 	case err == nil:
 		// we have existing standings
 		jobStandings, err = r.ProcessExistingStandings(ctx, existingStandings, latestStandings)
@@ -86,6 +129,11 @@ func (r *RetrieveLatestStandingsWorker) DoWork(ctx context.Context) error {
 		return fmt.Errorf("cannot retrieve standings by round number %d: %w", latestStandings.RoundNumber, err)
 	}
 
+	// keep a record of how the table has moved during the round
+	if _, err := r.snapshotAgent.RecordSnapshot(ctx, jobStandings); err != nil {
+		return fmt.Errorf("cannot record standings snapshot: %w", err)
+	}
+
 	if r.HasFinalisedLastRound(jobStandings) {
 		// we've already finalised the last round of our season so just exit early
 		return nil
@@ -106,7 +154,12 @@ func (r *RetrieveLatestStandingsWorker) DoWork(ctx context.Context) error {
 	}
 
 	if r.season.IsCompletedByStandings(jobStandings) {
-		// last round of season!
+		// last round of season! the final positions are now known, so prize money can be awarded.
+		// payouts are locked in before the standings are finalised, so that a failure is retried by the next run
+		if err := r.prizeAgent.LockInSeasonPayouts(ctx, r.season); err != nil {
+			return fmt.Errorf("cannot lock in prize payouts: %w", err)
+		}
+
 		jobStandings.Finalised = true
 		if _, err := r.standingsAgent.UpdateStandings(ctx, jobStandings); err != nil {
 			return fmt.Errorf("cannot update finalised standings: %w", err)
@@ -127,6 +180,149 @@ func (r *RetrieveLatestStandingsWorker) ProcessExistingStandings(
 	return r.standingsAgent.UpdateStandings(ctx, existStnd)
 }
 
+// ProcessCorrectedStandings handles the provided finalised Standings having changed upstream since they were finalised,
+// e.g. following a points deduction. The correction is recorded, every ScoredEntryPrediction for the round is rescored
+// against the corrected rankings, and only those entrants whose score has changed as a result are notified
+func (r *RetrieveLatestStandingsWorker) ProcessCorrectedStandings(
+	ctx context.Context,
+	finalStnd Standings,
+	clientStnd Standings,
+) error {
+	if rankingsWithMetaMatch(finalStnd.Rankings, clientStnd.Rankings) {
+		// nothing has changed since the round was finalised
+		return nil
+	}
+
+	correction, err := r.correctionAgent.RecordCorrection(ctx, finalStnd, clientStnd.Rankings)
+	if err != nil {
+		return fmt.Errorf("cannot record correction: %w", err)
+	}
+
+	r.logger.Infof(
+		"season %s: round %d: finalised standings have been corrected (id %s)",
+		r.season.ID,
+		finalStnd.RoundNumber,
+		correction.ID,
+	)
+
+	// payouts that have already been locked in are never recalculated, so a correction to any round needs reviewing by hand
+	lockedRealmNames, err := r.prizeAgent.RetrieveRealmNamesWithLockedInPayouts(ctx, r.season)
+	if err != nil {
+		return fmt.Errorf("cannot retrieve realms with locked in payouts: %w", err)
+	}
+	if len(lockedRealmNames) > 0 {
+		r.logger.Errorf(
+			"season %s: round %d: standings corrected after prize payouts were locked in for realms %s (correction id %s): payouts must be reviewed manually",
+			r.season.ID,
+			finalStnd.RoundNumber,
+			strings.Join(lockedRealmNames, ", "),
+			correction.ID,
+		)
+	}
+
+	// standings remain finalised, only their rankings are corrected
+	finalStnd.Rankings = clientStnd.Rankings
+	corrStnd, err := r.standingsAgent.UpdateStandings(ctx, finalStnd)
+	if err != nil {
+		return fmt.Errorf("cannot update corrected standings: %w", err)
+	}
+
+	if _, err := r.snapshotAgent.RecordSnapshot(ctx, corrStnd); err != nil {
+		return fmt.Errorf("cannot record standings snapshot: %w", err)
+	}
+
+	existSeps, err := r.scoredEntryPredictionAgent.RetrieveScoredEntryPredictionsByStandingsID(ctx, corrStnd.ID.String())
+	if err != nil {
+		if errors.As(err, &NotFoundError{}) {
+			// nobody was scored against the original standings, so there's nobody to rescore
+			return nil
+		}
+		return fmt.Errorf("cannot retrieve scored entry predictions: %w", err)
+	}
+
+	changedSeps := make([]ScoredEntryPrediction, 0)
+	prevScores := make(map[string]int)
+
+	for _, existSep := range existSeps {
+		// rescore against the same entry prediction that the original score was based on
+		ep, err := r.entryAgent.RetrieveEntryPredictionByID(ctx, existSep.EntryPredictionID.String())
+		if err != nil {
+			return fmt.Errorf("cannot retrieve entry prediction: %w", err)
+		}
+
+		sep, err := r.GenerateScoredEntryPrediction(ctx, ep, corrStnd)
+		if err != nil {
+			return fmt.Errorf("cannot generate scored entry prediction: %w", err)
+		}
+		if err := r.upsertScoredEntryPrediction(ctx, sep); err != nil {
+			return fmt.Errorf("cannot upsert scored entry prediction: %w", err)
+		}
+
+		if sep.Score != existSep.Score {
+			changedSeps = append(changedSeps, *sep)
+			prevScores[sep.EntryPredictionID.String()] = existSep.Score
+		}
+	}
+
+	r.logger.Infof(
+		"season %s: round %d: rescored %d entries, %d changed",
+		r.season.ID,
+		corrStnd.RoundNumber,
+		len(existSeps),
+		len(changedSeps),
+	)
+
+	return r.IssueScoresCorrectedEmails(ctx, changedSeps, prevScores)
+}
+
+// QuarantineIfAnomalous compares the provided Standings against the latest Standings stored for the worker's Season and
+// quarantines them for admin review if any anomalies are found that have not already been accepted.
+// Returns true if the provided Standings have been quarantined, otherwise false
+func (r *RetrieveLatestStandingsWorker) QuarantineIfAnomalous(ctx context.Context, stnd Standings) (bool, error) {
+	prevStnd, err := r.standingsAgent.RetrieveLatestStandingsBySeasonIDAndTimestamp(ctx, r.season.ID, r.clock.Now())
+	if err != nil {
+		if errors.As(err, &NotFoundError{}) {
+			// nothing to compare against yet
+			return false, nil
+		}
+		return false, fmt.Errorf("cannot retrieve previous standings: %w", err)
+	}
+
+	anomalies := DetectStandingsAnomalies(stnd, prevStnd)
+	if len(anomalies) == 0 {
+		return false, nil
+	}
+
+	accepted, err := r.quarantineAgent.IsAccepted(ctx, stnd, anomalies)
+	if err != nil {
+		return false, fmt.Errorf("cannot check accepted anomalies: %w", err)
+	}
+	if accepted {
+		r.logger.Infof("season %s: round %d: proceeding with previously accepted anomalies", r.season.ID, stnd.RoundNumber)
+		return false, nil
+	}
+
+	qs, err := r.quarantineAgent.QuarantineStandings(ctx, stnd, anomalies)
+	if err != nil {
+		return false, fmt.Errorf("cannot quarantine standings: %w", err)
+	}
+
+	descriptions := make([]string, 0, len(anomalies))
+	for _, a := range anomalies {
+		descriptions = append(descriptions, a.String())
+	}
+
+	r.logger.Errorf(
+		"season %s: round %d: standings quarantined for review (id %s): %s",
+		r.season.ID,
+		stnd.RoundNumber,
+		qs.ID,
+		strings.Join(descriptions, "; "),
+	)
+
+	return true, nil
+}
+
 // ProcessNewStandings processes the provided Standings as a new entity
 func (r *RetrieveLatestStandingsWorker) ProcessNewStandings(
 	ctx context.Context,
@@ -160,6 +356,21 @@ func (r *RetrieveLatestStandingsWorker) HasFinalisedLastRound(stnd Standings) bo
 	return r.season.IsCompletedByStandings(stnd) && stnd.Finalised
 }
 
+// HasFinalisedSeason returns true if the stored Standings for the last round of the worker's Season have been finalised,
+// at which point there is nothing left for the worker to retrieve
+func (r *RetrieveLatestStandingsWorker) HasFinalisedSeason(ctx context.Context) (bool, error) {
+	stnd, err := r.standingsAgent.RetrieveStandingsBySeasonAndRoundNumber(ctx, r.season.ID, r.season.MaxRounds)
+	if err != nil {
+		if errors.As(err, &NotFoundError{}) {
+			// we haven't reached the last round yet
+			return false, nil
+		}
+		return false, err
+	}
+
+	return r.HasFinalisedLastRound(stnd), nil
+}
+
 // GenerateScoredEntryPrediction generates a scored entry prediction from the provided entry prediction and standings
 func (r *RetrieveLatestStandingsWorker) GenerateScoredEntryPrediction(ctx context.Context, ep EntryPrediction, s Standings) (*ScoredEntryPrediction, error) {
 	// TODO: migrate to MatchWeekSubmission entity + deprecate EntryPrediction
@@ -237,6 +448,40 @@ func (r *RetrieveLatestStandingsWorker) IssueEmails(ctx context.Context, stnd St
 	}
 }
 
+// IssueScoresCorrectedEmails issues a scores corrected email for each of the provided ScoredEntryPredictions,
+// using the provided previous scores, keyed by entry prediction ID
+func (r *RetrieveLatestStandingsWorker) IssueScoresCorrectedEmails(ctx context.Context, seps []ScoredEntryPrediction, prevScores map[string]int) error {
+	sem := make(chan struct{}, 10) // send a maximum of 10 concurrent emails
+	wg := &sync.WaitGroup{}
+	mu := &sync.Mutex{}
+	errs := make([]error, 0)
+
+	for _, sep := range seps {
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(sep ScoredEntryPrediction) {
+			defer func() {
+				wg.Done()
+				<-sem
+			}()
+
+			if err := r.emailIssuer.IssueScoresCorrectedEmail(ctx, sep, prevScores[sep.EntryPredictionID.String()]); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(sep)
+	}
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return MultiError{Errs: errs}
+	}
+	return nil
+}
+
 // upsertScoredEntryPrediction creates or updates the provided ScoredEntryPrediction depending on whether or not it already exists
 func (r *RetrieveLatestStandingsWorker) upsertScoredEntryPrediction(ctx context.Context, sep *ScoredEntryPrediction) error {
 	// see if we have an existing scored entry prediction that matches our provided sep
@@ -312,9 +557,13 @@ type RetrieveLatestStandingsWorkerParams struct {
 	Logger                     Logger
 	EntryAgent                 *EntryAgent
 	StandingsAgent             *StandingsAgent
+	QuarantineAgent            *StandingsQuarantineAgent
+	SnapshotAgent              *StandingsSnapshotAgent
+	CorrectionAgent            *StandingsCorrectionAgent
 	ScoredEntryPredictionAgent *ScoredEntryPredictionAgent
 	MatchWeekSubmissionAgent   *MatchWeekSubmissionAgent
 	MatchWeekResultAgent       *MatchWeekResultAgent
+	PrizeAgent                 *PrizeAgent
 	EmailIssuer                RoundCompleteEmailIssuer
 	FootballClient             FootballDataSource
 }
@@ -335,6 +584,15 @@ func NewRetrieveLatestStandingsWorker(params RetrieveLatestStandingsWorkerParams
 	if params.StandingsAgent == nil {
 		return nil, fmt.Errorf("standings agent: %w", ErrIsNil)
 	}
+	if params.QuarantineAgent == nil {
+		return nil, fmt.Errorf("standings quarantine agent: %w", ErrIsNil)
+	}
+	if params.SnapshotAgent == nil {
+		return nil, fmt.Errorf("standings snapshot agent: %w", ErrIsNil)
+	}
+	if params.CorrectionAgent == nil {
+		return nil, fmt.Errorf("standings correction agent: %w", ErrIsNil)
+	}
 	if params.ScoredEntryPredictionAgent == nil {
 		return nil, fmt.Errorf("scored entry predictions agent: %w", ErrIsNil)
 	}
@@ -344,6 +602,9 @@ func NewRetrieveLatestStandingsWorker(params RetrieveLatestStandingsWorkerParams
 	if params.MatchWeekResultAgent == nil {
 		return nil, fmt.Errorf("match week result agent: %w", ErrIsNil)
 	}
+	if params.PrizeAgent == nil {
+		return nil, fmt.Errorf("prize agent: %w", ErrIsNil)
+	}
 	if params.EmailIssuer == nil {
 		return nil, fmt.Errorf("email issuer: %w", ErrIsNil)
 	}
@@ -357,9 +618,13 @@ func NewRetrieveLatestStandingsWorker(params RetrieveLatestStandingsWorkerParams
 		logger:                     params.Logger,
 		entryAgent:                 params.EntryAgent,
 		standingsAgent:             params.StandingsAgent,
+		quarantineAgent:            params.QuarantineAgent,
+		snapshotAgent:              params.SnapshotAgent,
+		correctionAgent:            params.CorrectionAgent,
 		scoredEntryPredictionAgent: params.ScoredEntryPredictionAgent,
 		matchWeekSubmissionAgent:   params.MatchWeekSubmissionAgent,
 		matchWeekResultAgent:       params.MatchWeekResultAgent,
+		prizeAgent:                 params.PrizeAgent,
 		emailIssuer:                params.EmailIssuer,
 		footballClient:             params.FootballClient,
 	}, nil
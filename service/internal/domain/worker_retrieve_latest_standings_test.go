@@ -18,8 +18,12 @@ var (
 	emptyEntryAgent                 = &domain.EntryAgent{}
 	emptyMatchWeekResultAgent       = &domain.MatchWeekResultAgent{}
 	emptyMatchWeekSubmissionAgent   = &domain.MatchWeekSubmissionAgent{}
+	emptyPrizeAgent                 = &domain.PrizeAgent{}
 	emptyScoredEntryPredictionAgent = &domain.ScoredEntryPredictionAgent{}
 	emptyStandingsAgent             = &domain.StandingsAgent{}
+	emptyStandingsCorrectionAgent   = &domain.StandingsCorrectionAgent{}
+	emptyStandingsQuarantineAgent   = &domain.StandingsQuarantineAgent{}
+	emptyStandingsSnapshotAgent     = &domain.StandingsSnapshotAgent{}
 	noopFootballDataClient          = &domain.NoopFootballDataSource{}
 )
 
@@ -29,9 +33,13 @@ func TestNewRetrieveLatestStandingsWorker(t *testing.T) {
 	l := &mockLogger{}
 	ea := emptyEntryAgent
 	sa := emptyStandingsAgent
+	qa := emptyStandingsQuarantineAgent
+	ssa := emptyStandingsSnapshotAgent
+	sca := emptyStandingsCorrectionAgent
 	sepa := emptyScoredEntryPredictionAgent
 	mwsa := emptyMatchWeekSubmissionAgent
 	mwra := emptyMatchWeekResultAgent
+	pa := emptyPrizeAgent
 	ca := emptyCommunicationsAgent
 	fcl := noopFootballDataClient
 
@@ -42,24 +50,32 @@ func TestNewRetrieveLatestStandingsWorker(t *testing.T) {
 		l           domain.Logger
 		ea          *domain.EntryAgent
 		sa          *domain.StandingsAgent
+		qa          *domain.StandingsQuarantineAgent
+		ssa         *domain.StandingsSnapshotAgent
+		sca         *domain.StandingsCorrectionAgent
 		sepa        *domain.ScoredEntryPredictionAgent
 		mwsa        *domain.MatchWeekSubmissionAgent
 		mwra        *domain.MatchWeekResultAgent
+		pa          *domain.PrizeAgent
 		emailIssuer domain.RoundCompleteEmailIssuer
 		fcl         domain.FootballDataSource
 		wantErr     bool
 	}{
-		{"missing team collection", nil, cl, l, ea, sa, sepa, mwsa, mwra, ca, fcl, true},
-		{"missing clock", tColl, nil, l, ea, sa, sepa, mwsa, mwra, ca, fcl, true},
-		{"missing logger", tColl, cl, nil, ea, sa, sepa, mwsa, mwra, ca, fcl, true},
-		{"missing entry agent", tColl, cl, l, nil, sa, sepa, mwsa, mwra, ca, fcl, true},
-		{"missing standings agent", tColl, cl, l, ea, nil, sepa, mwsa, mwra, ca, fcl, true},
-		{"missing scored entry predictions agent", tColl, cl, l, ea, sa, nil, mwsa, mwra, ca, fcl, true},
-		{"missing match week submission agent", tColl, cl, l, ea, sa, sepa, nil, mwra, ca, fcl, true},
-		{"missing match week result agent", tColl, cl, l, ea, sa, sepa, mwsa, nil, ca, fcl, true},
-		{"missing communications agent", tColl, cl, l, ea, sa, sepa, mwsa, mwra, nil, fcl, true},
-		{"missing football client", tColl, cl, l, ea, sa, sepa, mwsa, mwra, ca, nil, true},
-		{"no missing dependencies", tColl, cl, l, ea, sa, sepa, mwsa, mwra, ca, fcl, false},
+		{"missing team collection", nil, cl, l, ea, sa, qa, ssa, sca, sepa, mwsa, mwra, pa, ca, fcl, true},
+		{"missing clock", tColl, nil, l, ea, sa, qa, ssa, sca, sepa, mwsa, mwra, pa, ca, fcl, true},
+		{"missing logger", tColl, cl, nil, ea, sa, qa, ssa, sca, sepa, mwsa, mwra, pa, ca, fcl, true},
+		{"missing entry agent", tColl, cl, l, nil, sa, qa, ssa, sca, sepa, mwsa, mwra, pa, ca, fcl, true},
+		{"missing standings agent", tColl, cl, l, ea, nil, qa, ssa, sca, sepa, mwsa, mwra, pa, ca, fcl, true},
+		{"missing standings quarantine agent", tColl, cl, l, ea, sa, nil, ssa, sca, sepa, mwsa, mwra, pa, ca, fcl, true},
+		{"missing standings snapshot agent", tColl, cl, l, ea, sa, qa, nil, sca, sepa, mwsa, mwra, pa, ca, fcl, true},
+		{"missing standings correction agent", tColl, cl, l, ea, sa, qa, ssa, nil, sepa, mwsa, mwra, pa, ca, fcl, true},
+		{"missing scored entry predictions agent", tColl, cl, l, ea, sa, qa, ssa, sca, nil, mwsa, mwra, pa, ca, fcl, true},
+		{"missing match week submission agent", tColl, cl, l, ea, sa, qa, ssa, sca, sepa, nil, mwra, pa, ca, fcl, true},
+		{"missing match week result agent", tColl, cl, l, ea, sa, qa, ssa, sca, sepa, mwsa, nil, pa, ca, fcl, true},
+		{"missing prize agent", tColl, cl, l, ea, sa, qa, ssa, sca, sepa, mwsa, mwra, nil, ca, fcl, true},
+		{"missing communications agent", tColl, cl, l, ea, sa, qa, ssa, sca, sepa, mwsa, mwra, pa, nil, fcl, true},
+		{"missing football client", tColl, cl, l, ea, sa, qa, ssa, sca, sepa, mwsa, mwra, pa, ca, nil, true},
+		{"no missing dependencies", tColl, cl, l, ea, sa, qa, ssa, sca, sepa, mwsa, mwra, pa, ca, fcl, false},
 	}
 	for idx, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
@@ -69,9 +85,13 @@ func TestNewRetrieveLatestStandingsWorker(t *testing.T) {
 				Logger:                     tc.l,
 				EntryAgent:                 tc.ea,
 				StandingsAgent:             tc.sa,
+				QuarantineAgent:            tc.qa,
+				SnapshotAgent:              tc.ssa,
+				CorrectionAgent:            tc.sca,
 				ScoredEntryPredictionAgent: tc.sepa,
 				MatchWeekSubmissionAgent:   tc.mwsa,
 				MatchWeekResultAgent:       tc.mwra,
+				PrizeAgent:                 tc.pa,
 				EmailIssuer:                tc.emailIssuer,
 				FootballClient:             tc.fcl,
 			}
@@ -792,6 +812,15 @@ func newTestRetrieveLatestStandingsWorker(
 	if params.StandingsAgent == nil {
 		params.StandingsAgent = emptyStandingsAgent
 	}
+	if params.QuarantineAgent == nil {
+		params.QuarantineAgent = emptyStandingsQuarantineAgent
+	}
+	if params.SnapshotAgent == nil {
+		params.SnapshotAgent = emptyStandingsSnapshotAgent
+	}
+	if params.CorrectionAgent == nil {
+		params.CorrectionAgent = emptyStandingsCorrectionAgent
+	}
 	if params.ScoredEntryPredictionAgent == nil {
 		params.ScoredEntryPredictionAgent = emptyScoredEntryPredictionAgent
 	}
@@ -801,6 +830,9 @@ func newTestRetrieveLatestStandingsWorker(
 	if params.MatchWeekResultAgent == nil {
 		params.MatchWeekResultAgent = emptyMatchWeekResultAgent
 	}
+	if params.PrizeAgent == nil {
+		params.PrizeAgent = emptyPrizeAgent
+	}
 	if params.EmailIssuer == nil {
 		params.EmailIssuer = emptyCommunicationsAgent
 	}
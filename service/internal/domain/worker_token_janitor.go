@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TokenJanitorCount represents the number of Tokens of a single type that were deleted by a TokenJanitorWorker run
+type TokenJanitorCount struct {
+	Expired  int64 `json:"expired"`
+	Redeemed int64 `json:"redeemed"`
+}
+
+// TokenJanitorRun represents the outcome of a single TokenJanitorWorker run
+type TokenJanitorRun struct {
+	StartedAt  time.Time                    `json:"started_at"`
+	FinishedAt time.Time                    `json:"finished_at"`
+	Deleted    map[string]TokenJanitorCount `json:"deleted"`
+	Errors     []string                     `json:"errors"`
+}
+
+// TokenJanitorStats retains the outcome of the most recent TokenJanitorWorker run, so that it can be reviewed by admins
+type TokenJanitorStats struct {
+	mu      sync.RWMutex
+	lastRun *TokenJanitorRun
+}
+
+// RetrieveLastRun retrieves the outcome of the most recent TokenJanitorWorker run
+func (t *TokenJanitorStats) RetrieveLastRun(ctx context.Context) (TokenJanitorRun, error) {
+	// tokens are not bound to a realm, so only admins with permissions for every realm can view the janitor's work
+	if !HasAdminRoleForRealm(ctx, AdminRealmAll, AdminRoleViewer) {
+		return TokenJanitorRun{}, UnauthorizedError{}
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.lastRun == nil {
+		return TokenJanitorRun{}, NotFoundError{errors.New("token janitor has not run yet")}
+	}
+
+	return *t.lastRun, nil
+}
+
+// record retains the provided run as the most recent
+func (t *TokenJanitorStats) record(run TokenJanitorRun) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastRun = &run
+}
+
+// NewTokenJanitorStats returns a new TokenJanitorStats that has not yet recorded a run
+func NewTokenJanitorStats() *TokenJanitorStats {
+	return &TokenJanitorStats{}
+}
+
+// TokenJanitorWorker performs the work required to stop the Token table from growing indefinitely.
+// Each run deletes every Token that has expired, along with every redeemed Token whose retention period has elapsed
+type TokenJanitorWorker struct {
+	retentionPeriod time.Duration
+	clock           Clock
+	logger          Logger
+	tokenAgent      *TokenAgent
+	stats           *TokenJanitorStats
+}
+
+// DoWork implements domain.Worker
+func (t *TokenJanitorWorker) DoWork(ctx context.Context) error {
+	now := t.clock.Now()
+
+	run := TokenJanitorRun{
+		StartedAt: now,
+		Deleted:   make(map[string]TokenJanitorCount),
+	}
+
+	var errs []error
+
+	for _, typ := range sortedTokenTypes() {
+		name := TokenTypeNames[typ]
+
+		var cnt TokenJanitorCount
+		var err error
+
+		cnt.Expired, err = t.tokenAgent.DeleteTokensByTypeExpiredBefore(ctx, typ, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot delete expired %s tokens: %w", name, err))
+		}
+
+		cnt.Redeemed, err = t.tokenAgent.DeleteTokensByTypeRedeemedBefore(ctx, typ, now.Add(-t.retentionPeriod))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot delete redeemed %s tokens: %w", name, err))
+		}
+
+		run.Deleted[name] = cnt
+		t.logger.Infof("token janitor: deleted %d expired and %d redeemed %s tokens", cnt.Expired, cnt.Redeemed, name)
+	}
+
+	for _, err := range errs {
+		run.Errors = append(run.Errors, err.Error())
+	}
+	run.FinishedAt = t.clock.Now()
+	t.stats.record(run)
+
+	if len(errs) > 0 {
+		return MultiError{Errs: errs}
+	}
+
+	return nil
+}
+
+// sortedTokenTypes returns each token type in a consistent order
+func sortedTokenTypes() []int {
+	types := make([]int, 0, len(TokenTypeNames))
+	for typ := range TokenTypeNames {
+		types = append(types, typ)
+	}
+	sort.Ints(types)
+	return types
+}
+
+// TokenJanitorWorkerParams defines the parameters required by NewTokenJanitorWorker
+type TokenJanitorWorkerParams struct {
+	RetentionPeriod time.Duration
+	Clock           Clock
+	Logger          Logger
+	TokenAgent      *TokenAgent
+	Stats           *TokenJanitorStats
+}
+
+func NewTokenJanitorWorker(params TokenJanitorWorkerParams) (*TokenJanitorWorker, error) {
+	if params.Clock == nil {
+		return nil, fmt.Errorf("clock: %w", ErrIsNil)
+	}
+	if params.Logger == nil {
+		return nil, fmt.Errorf("logger: %w", ErrIsNil)
+	}
+	if params.TokenAgent == nil {
+		return nil, fmt.Errorf("token agent: %w", ErrIsNil)
+	}
+	if params.Stats == nil {
+		return nil, fmt.Errorf("stats: %w", ErrIsNil)
+	}
+	return &TokenJanitorWorker{
+		retentionPeriod: params.RetentionPeriod,
+		clock:           params.Clock,
+		logger:          params.Logger,
+		tokenAgent:      params.TokenAgent,
+		stats:           params.Stats,
+	}, nil
+}
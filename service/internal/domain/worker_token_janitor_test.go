@@ -0,0 +1,165 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"prediction-league/service/internal/domain"
+	"testing"
+	"time"
+
+	"gotest.tools/assert/cmp"
+)
+
+func TestNewTokenJanitorWorker(t *testing.T) {
+	cl := &mockClock{}
+	l := &mockLogger{}
+	ta := &domain.TokenAgent{}
+	stats := domain.NewTokenJanitorStats()
+
+	tt := []struct {
+		name    string
+		cl      domain.Clock
+		l       domain.Logger
+		ta      *domain.TokenAgent
+		stats   *domain.TokenJanitorStats
+		wantErr bool
+	}{
+		{"missing clock", nil, l, ta, stats, true},
+		{"missing logger", cl, nil, ta, stats, true},
+		{"missing token agent", cl, l, nil, stats, true},
+		{"missing stats", cl, l, ta, nil, true},
+		{"no missing dependencies", cl, l, ta, stats, false},
+	}
+	for idx, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			params := domain.TokenJanitorWorkerParams{
+				Clock:      tc.cl,
+				Logger:     tc.l,
+				TokenAgent: tc.ta,
+				Stats:      tc.stats,
+			}
+
+			w, gotErr := domain.NewTokenJanitorWorker(params)
+			if tc.wantErr && !errors.Is(gotErr, domain.ErrIsNil) {
+				t.Fatalf("tc #%d: want ErrIsNil, got %s (%T)", idx, gotErr, gotErr)
+			}
+			if !tc.wantErr && w == nil {
+				t.Fatalf("tc #%d: want non-empty worker, got nil", idx)
+			}
+		})
+	}
+}
+
+func TestTokenJanitorWorker_DoWork(t *testing.T) {
+	t.Cleanup(truncate)
+
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	retentionPeriod := 7 * 24 * time.Hour
+
+	cl := &mockClock{t: now}
+
+	ta, err := domain.NewTokenAgent(tr, aa, cl, &mockLogger{}, testTokenHashKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redeemedLongAgo := now.Add(-retentionPeriod - time.Second)
+	redeemedRecently := now.Add(-time.Hour)
+
+	// expired auth token
+	expiredToken := generateTestToken("tkn-expired")
+	expiredToken.Type = domain.TokenTypeAuth
+	expiredToken.ExpiresAt = now.Add(-time.Second)
+
+	// magic login token that remains valid but was redeemed before the retention period
+	oldRedeemedToken := generateTestToken("tkn-old-redeemed")
+	oldRedeemedToken.Type = domain.TokenTypeMagicLogin
+	oldRedeemedToken.RedeemedAt = &redeemedLongAgo
+	oldRedeemedToken.ExpiresAt = now.Add(time.Hour)
+
+	// magic login token that remains valid and was redeemed within the retention period
+	recentRedeemedToken := generateTestToken("tkn-recent-redeemed")
+	recentRedeemedToken.Type = domain.TokenTypeMagicLogin
+	recentRedeemedToken.RedeemedAt = &redeemedRecently
+	recentRedeemedToken.ExpiresAt = now.Add(time.Hour)
+
+	// auth token that remains valid and has not been redeemed
+	activeToken := generateTestToken("tkn-active")
+	activeToken.Type = domain.TokenTypeAuth
+	activeToken.ExpiresAt = now.Add(time.Hour)
+
+	for _, token := range []*domain.Token{expiredToken, oldRedeemedToken, recentRedeemedToken, activeToken} {
+		insertToken(t, token)
+	}
+
+	stats := domain.NewTokenJanitorStats()
+
+	w, err := domain.NewTokenJanitorWorker(domain.TokenJanitorWorkerParams{
+		RetentionPeriod: retentionPeriod,
+		Clock:           cl,
+		Logger:          newMockLogger(),
+		TokenAgent:      ta,
+		Stats:           stats,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("retrieve last run before the worker has run must fail", func(t *testing.T) {
+		adminCtx := domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+
+		_, err := stats.RetrieveLastRun(adminCtx)
+		if !cmp.ErrorType(err, domain.NotFoundError{})().Success() {
+			expectedTypeOfGot(t, domain.NotFoundError{}, err)
+		}
+	})
+
+	t.Run("expired tokens and tokens redeemed before the retention period must be deleted", func(t *testing.T) {
+		if err := w.DoWork(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, id := range []string{expiredToken.ID, oldRedeemedToken.ID} {
+			if _, err := ta.RetrieveTokenByID(ctx, id); !cmp.ErrorType(err, domain.NotFoundError{})().Success() {
+				expectedTypeOfGot(t, domain.NotFoundError{}, err)
+			}
+		}
+
+		for _, id := range []string{recentRedeemedToken.ID, activeToken.ID} {
+			if _, err := ta.RetrieveTokenByID(ctx, id); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+
+	t.Run("retrieve last run with invalid credentials must fail", func(t *testing.T) {
+		_, err := stats.RetrieveLastRun(ctx)
+		if !cmp.ErrorType(err, domain.UnauthorizedError{})().Success() {
+			expectedTypeOfGot(t, domain.UnauthorizedError{}, err)
+		}
+	})
+
+	t.Run("retrieve last run must report deleted token counts by type", func(t *testing.T) {
+		adminCtx := domain.SetAdminUserOnContext(ctx, testSuperAdmin)
+
+		run, err := stats.RetrieveLastRun(adminCtx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantRun := domain.TokenJanitorRun{
+			StartedAt:  now,
+			FinishedAt: now,
+			Deleted: map[string]domain.TokenJanitorCount{
+				"auth":               {Expired: 1},
+				"entry_registration": {},
+				"magic_login":        {Redeemed: 1},
+				"prediction":         {},
+				"login_code":         {},
+				"email_verification": {},
+			},
+		}
+		cmpDiff(t, "token janitor run", wantRun, run)
+	})
+}
@@ -11,6 +11,7 @@ type Base struct {
 	BannerTitle    template.HTML
 	ActivePage     string
 	IsLoggedIn     bool
+	CSRFToken      string
 	Realm          *domain.Realm
 	SeasonName     string
 	BuildVersion   string
@@ -49,6 +50,7 @@ type LeaderBoardPageData struct {
 		RawRankings string
 	}
 	LastUpdated time.Time
+	PrizePot    *domain.PrizePot
 }
 
 type JoinPageData struct {
@@ -59,6 +61,15 @@ type JoinPageData struct {
 	SeasonName      string
 	PayPalClientID  string
 	EntryFee        domain.RealmEntryFee
+	ResumedEntry    *ResumedEntry
+}
+
+// ResumedEntry represents an unpaid Entry whose entrant has returned to the join page in order to pay for it
+type ResumedEntry struct {
+	ID           string
+	Email        string
+	RegToken     string
+	NeedsPayment bool
 }
 
 type FAQPageData struct {
@@ -70,3 +81,12 @@ type GenerateMagicLoginPageData struct {
 	Err       error
 	EmailAddr string
 }
+
+type LoginCodePageData struct {
+	Err       error
+	EmailAddr string
+}
+
+type EmailVerificationPageData struct {
+	Err error
+}